@@ -56,6 +56,9 @@ type DynaKubeStatus struct {
 	ExtensionController EecStatus        `json:"eec,omitempty"`
 	Statsd              StatsdStatus     `json:"statsd,omitempty"`
 	OneAgent            OneAgentStatus   `json:"oneAgent,omitempty"`
+
+	// Injection shows which namespaces are covered by the application injection of the DynaKube
+	Injection InjectionStatus `json:"injection,omitempty"`
 }
 
 type ConnectionInfoStatus struct {
@@ -141,6 +144,39 @@ type OneAgentInstance struct {
 	IPAddress string `json:"ipAddress,omitempty"`
 }
 
+// MaxNamespaceListStatusNames limits the namespace names in the status, to keep the size of the DynaKube small in big clusters
+const MaxNamespaceListStatusNames = 20
+
+type InjectionStatus struct {
+	// MatchedNamespaces are the namespaces which are mapped to the DynaKube
+	MatchedNamespaces NamespaceListStatus `json:"matchedNamespaces,omitempty"`
+
+	// IgnoredNamespaces match the namespace selector, but are excluded by the ignored-namespaces feature flag
+	IgnoredNamespaces NamespaceListStatus `json:"ignoredNamespaces,omitempty"`
+
+	// UninjectedNamespaces are matched namespaces with running pods, which are missing the injection
+	UninjectedNamespaces NamespaceListStatus `json:"uninjectedNamespaces,omitempty"`
+
+	// LastUninjectedProbeTimestamp indicates when the pods of the matched namespaces were last checked for the injection
+	LastUninjectedProbeTimestamp *metav1.Time `json:"lastUninjectedProbeTimestamp,omitempty"`
+}
+
+type NamespaceListStatus struct {
+	// Count is the number of namespaces
+	Count int `json:"count,omitempty"`
+
+	// Names of the namespaces, truncated to the first MaxNamespaceListStatusNames
+	Names []string `json:"names,omitempty"`
+}
+
+// NewNamespaceListStatus counts the namespaces and keeps the first names
+func NewNamespaceListStatus(names []string) NamespaceListStatus {
+	if len(names) > MaxNamespaceListStatusNames {
+		return NamespaceListStatus{Count: len(names), Names: names[:MaxNamespaceListStatusNames]}
+	}
+	return NamespaceListStatus{Count: len(names), Names: names}
+}
+
 type DynaKubePhaseType string
 
 const (
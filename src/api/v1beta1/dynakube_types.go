@@ -38,6 +38,21 @@ const (
 	ReasonTokenError string = "TokenError"
 )
 
+// DiagnosticsConditionType identifies the condition with the result of the troubleshoot checks run by the operator
+const DiagnosticsConditionType string = "Diagnostics"
+
+// Possible reasons for the Diagnostics condition
+const (
+	// ReasonDiagnosticsPassed is set when all checks passed
+	ReasonDiagnosticsPassed string = "DiagnosticsPassed"
+
+	// ReasonDiagnosticsWarning is set when checks found problems, which don't break the setup
+	ReasonDiagnosticsWarning string = "DiagnosticsWarning"
+
+	// ReasonDiagnosticsFailed is set when a check failed
+	ReasonDiagnosticsFailed string = "DiagnosticsFailed"
+)
+
 type DynaKubeProxy struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxy value",order=32,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Value string `json:"value,omitempty"`
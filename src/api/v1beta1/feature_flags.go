@@ -21,8 +21,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -73,6 +76,9 @@ const (
 	AnnotationFeatureOneAgentIgnoreProxy            = AnnotationFeaturePrefix + "oneagent-ignore-proxy"
 	AnnotationFeatureOneAgentInitialConnectRetry    = AnnotationFeaturePrefix + "oneagent-initial-connect-retry-ms"
 	AnnotationFeatureRunOneAgentContainerPrivileged = AnnotationFeaturePrefix + "oneagent-privileged"
+	AnnotationFeatureOneAgentDownloadDeadline       = AnnotationFeaturePrefix + "oneagent-download-deadline-seconds"
+	AnnotationFeatureOneAgentOfflinePVC             = AnnotationFeaturePrefix + "oneagent-offline-pvc"
+	AnnotationFeatureOneAgentOfflineSha256          = AnnotationFeaturePrefix + "oneagent-offline-sha256"
 
 	// injection (webhook)
 
@@ -83,10 +89,37 @@ const (
 
 	AnnotationFeatureWebhookReinvocationPolicy = AnnotationFeaturePrefix + "webhook-reinvocation-policy"
 	AnnotationFeatureMetadataEnrichment        = AnnotationFeaturePrefix + "metadata-enrichment"
+	AnnotationFeatureOtelInjection             = AnnotationFeaturePrefix + "otel-injection"
 
-	AnnotationFeatureIgnoreUnknownState = AnnotationFeaturePrefix + "ignore-unknown-state"
-	AnnotationFeatureIgnoredNamespaces  = AnnotationFeaturePrefix + "ignored-namespaces"
-	AnnotationFeatureAutomaticInjection = AnnotationFeaturePrefix + "automatic-injection"
+	AnnotationFeatureMetadataPodLabels       = AnnotationFeaturePrefix + "metadata-pod-labels"
+	AnnotationFeatureMetadataPodAnnotations  = AnnotationFeaturePrefix + "metadata-pod-annotations"
+	AnnotationFeatureMetadataNamespaceLabels = AnnotationFeaturePrefix + "metadata-namespace-labels"
+
+	AnnotationFeatureIgnoreUnknownState    = AnnotationFeaturePrefix + "ignore-unknown-state"
+	AnnotationFeatureIgnoredNamespaces     = AnnotationFeaturePrefix + "ignored-namespaces"
+	AnnotationFeatureAutomaticInjection    = AnnotationFeaturePrefix + "automatic-injection"
+	AnnotationFeatureReadOnlyCSIVolume     = AnnotationFeaturePrefix + "injection-readonly-volume"
+	AnnotationFeatureInjectionNodeAffinity = AnnotationFeaturePrefix + "injection-node-affinity"
+	AnnotationFeatureExcludedContainers    = AnnotationFeaturePrefix + "injection-excluded-containers"
+	AnnotationFeatureInjectionPodSelector  = AnnotationFeaturePrefix + "injection-pod-selector"
+	AnnotationFeatureInjectionPriority     = AnnotationFeaturePrefix + "injection-priority"
+	AnnotationFeatureInjectionReport       = AnnotationFeaturePrefix + "injection-report-annotation"
+
+	AnnotationFeatureWorkloadRestart              = AnnotationFeaturePrefix + "injection-workload-restart"
+	AnnotationFeatureWorkloadRestartMaxConcurrent = AnnotationFeaturePrefix + "injection-workload-restart-max-concurrent"
+	AnnotationFeatureWorkloadRestartInterval      = AnnotationFeaturePrefix + "injection-workload-restart-interval-seconds"
+
+	// validation (webhook)
+
+	AnnotationFeatureDenyMissingReferences = AnnotationFeaturePrefix + "validation-deny-missing-references"
+
+	// csi
+
+	AnnotationFeaturePredownloadVersions = AnnotationFeaturePrefix + "csi-predownload-versions"
+
+	// diagnostics
+
+	AnnotationFeatureDiagnosticsInterval = AnnotationFeaturePrefix + "diagnostics-interval-seconds"
 )
 
 var (
@@ -129,6 +162,13 @@ func (dk *DynaKube) FeatureDisableWebhookReinvocationPolicy() bool {
 	return dk.getDisableFlagWithDeprecatedAnnotation(AnnotationFeatureWebhookReinvocationPolicy, AnnotationFeatureDisableWebhookReinvocationPolicy)
 }
 
+// FeatureReadOnlyCSIVolume is a feature flag to request the csi volume of the injected pods as read-only,
+// the writable directories of the OneAgent are then provided by an emptyDir volume of the pod instead of the csi driver.
+// It's meant for nodes where the csi driver has to use bind mounts instead of overlays.
+func (dk *DynaKube) FeatureReadOnlyCSIVolume() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureReadOnlyCSIVolume) == "true"
+}
+
 // FeatureIgnoreUnknownState is a feature flag that makes the operator inject into applications even when the dynakube is in an UNKNOWN state,
 // this may cause extra host to appear in the tenant for each process.
 func (dk *DynaKube) FeatureIgnoreUnknownState() bool {
@@ -151,6 +191,133 @@ func (dk *DynaKube) FeatureIgnoredNamespaces() []string {
 	return *ignoredNamespaces
 }
 
+// FeatureInjectionNodeAffinity is a feature flag to schedule injected pods only on nodes,
+// where the csi driver has finished the setup of the code modules for the dynakube.
+func (dk *DynaKube) FeatureInjectionNodeAffinity() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureInjectionNodeAffinity) == "true"
+}
+
+// FeaturePredownloadVersions is a feature flag for agent versions the csi driver downloads in addition to the current one,
+// so they are already available on every node when the dynakube is switched to them, e.g. "[ \"1.2.3.20220101-123456\" ]"
+func (dk *DynaKube) FeaturePredownloadVersions() []string {
+	return dk.getFeatureFlagStringList(AnnotationFeaturePredownloadVersions)
+}
+
+// FeatureExcludedContainers is a feature flag for containers that shouldn't get the OneAgent injected,
+// the patterns are matched against the name and the image of the container, e.g. "[ \"istio-proxy\", \"*/fluent-bit:*\" ]"
+func (dk *DynaKube) FeatureExcludedContainers() []string {
+	return dk.getFeatureFlagStringList(AnnotationFeatureExcludedContainers)
+}
+
+// FeatureInjectionPodSelector is a feature flag to only inject pods matching the label selector, e.g. "track in (canary)".
+// Together with other dynakubes it allows routing the pods of a shared namespace to different environments.
+func (dk *DynaKube) FeatureInjectionPodSelector() string {
+	return dk.getFeatureFlagRaw(AnnotationFeatureInjectionPodSelector)
+}
+
+// InjectionPodSelector parses the pod selector feature flag, nil is returned if it isn't set.
+func (dk *DynaKube) InjectionPodSelector() (labels.Selector, error) {
+	raw := dk.FeatureInjectionPodSelector()
+	if raw == "" {
+		return nil, nil
+	}
+	selector, err := labels.Parse(raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return selector, nil
+}
+
+// FeatureInjectionPriority is a feature flag for the priority of the dynakube when multiple dynakubes could inject into the same pod,
+// the dynakube with the highest priority wins, defaults to 0
+func (dk *DynaKube) FeatureInjectionPriority() int {
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureInjectionPriority)
+	if raw == "" {
+		return 0
+	}
+
+	val, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Error(err, "failed to parse injection priority feature-flag")
+		return 0
+	}
+
+	return val
+}
+
+// FeatureWorkloadRestart is a feature flag to restart Deployments, StatefulSets and DaemonSets, which own pods with a missing or outdated injection,
+// e.g. after their namespace started to match the namespace selector or the code modules were updated.
+// A workload is restarted at most 3 times per code modules version, afterwards a warning event is emitted on it.
+func (dk *DynaKube) FeatureWorkloadRestart() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureWorkloadRestart) == "true"
+}
+
+// FeatureWorkloadRestartMaxConcurrent is a feature flag to limit how many workloads are restarted at the same time, defaults to 1
+func (dk *DynaKube) FeatureWorkloadRestartMaxConcurrent() int {
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureWorkloadRestartMaxConcurrent)
+	if raw == "" {
+		return 1
+	}
+
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 1 {
+		log.Info("invalid workload restart max concurrent feature-flag, using default", "value", raw)
+		return 1
+	}
+
+	return val
+}
+
+// FeatureWorkloadRestartInterval is a feature flag for the minimum time between two restarts of the same workload, defaults to 10 minutes
+func (dk *DynaKube) FeatureWorkloadRestartInterval() time.Duration {
+	defaultInterval := 10 * time.Minute
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureWorkloadRestartInterval)
+	if raw == "" {
+		return defaultInterval
+	}
+
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 0 {
+		log.Info("invalid workload restart interval feature-flag, using default", "value", raw)
+		return defaultInterval
+	}
+
+	return time.Duration(val) * time.Second
+}
+
+// FeatureMetadataPodLabels is a feature flag for the pod labels that are added to the metadata enrichment,
+// the entries are patterns (see path.Match) of the label keys, e.g. "[ \"team\", \"app.kubernetes.io/*\" ]"
+func (dk *DynaKube) FeatureMetadataPodLabels() []string {
+	return dk.getFeatureFlagStringList(AnnotationFeatureMetadataPodLabels)
+}
+
+// FeatureMetadataPodAnnotations is a feature flag for the pod annotations that are added to the metadata enrichment,
+// the entries are patterns (see path.Match) of the annotation keys
+func (dk *DynaKube) FeatureMetadataPodAnnotations() []string {
+	return dk.getFeatureFlagStringList(AnnotationFeatureMetadataPodAnnotations)
+}
+
+// FeatureMetadataNamespaceLabels is a feature flag for the namespace labels that are added to the metadata enrichment,
+// the entries are patterns (see path.Match) of the label keys
+func (dk *DynaKube) FeatureMetadataNamespaceLabels() []string {
+	return dk.getFeatureFlagStringList(AnnotationFeatureMetadataNamespaceLabels)
+}
+
+// getFeatureFlagStringList parses a feature flag containing a json array of strings, an invalid value is ignored
+func (dk *DynaKube) getFeatureFlagStringList(annotation string) []string {
+	raw := dk.getFeatureFlagRaw(annotation)
+	if raw == "" {
+		return nil
+	}
+	values := []string{}
+	err := json.Unmarshal([]byte(raw), &values)
+	if err != nil {
+		log.Error(err, "failed to unmarshal feature-flag", "annotation", annotation)
+		return nil
+	}
+	return values
+}
+
 func (dk *DynaKube) getDefaultIgnoredNamespaces() []string {
 	defaultIgnoredNamespaces := []string{
 		fmt.Sprintf("^%s$", dk.Namespace),
@@ -171,6 +338,13 @@ func (dk *DynaKube) FeatureAutomaticKubernetesApiMonitoringClusterName() string
 	return dk.getFeatureFlagRaw(AnnotationFeatureAutomaticK8sApiMonitoringClusterName)
 }
 
+// FeatureOtelInjection is a feature flag to configure the OpenTelemetry SDKs of the injected containers
+// to export to Dynatrace, it's only used if the metadata enrichment is enabled.
+// The data ingest token is used for the export, so it needs the openTelemetryTrace.ingest and logs.ingest scopes.
+func (dk *DynaKube) FeatureOtelInjection() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureOtelInjection) == "true"
+}
+
 // FeatureDisableMetadataEnrichment is a feature flag to disable metadata enrichment,
 func (dk *DynaKube) FeatureDisableMetadataEnrichment() bool {
 	return dk.getDisableFlagWithDeprecatedAnnotation(AnnotationFeatureMetadataEnrichment, AnnotationFeatureDisableMetadataEnrichment)
@@ -261,10 +435,77 @@ func (dk *DynaKube) FeatureAgentInitialConnectRetry() int {
 	return val
 }
 
+// FeatureAgentDownloadDeadline is a feature flag for the time in seconds the init container of injected pods retries
+// to download the OneAgent, defaults to 0, so the init container fails fast after trying every endpoint once
+func (dk *DynaKube) FeatureAgentDownloadDeadline() int {
+	defaultDeadline := 0
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureOneAgentDownloadDeadline)
+	if raw == "" {
+		return defaultDeadline
+	}
+
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 0 {
+		log.Info("invalid oneagent download deadline feature-flag, using default", "value", raw)
+		return defaultDeadline
+	}
+
+	return val
+}
+
+// FeatureOneAgentOfflinePVC is a feature flag for the name of a PersistentVolumeClaim, which has to exist in the injected namespaces
+// and contains the pre-seeded OneAgent package, the init container installs the OneAgent from it without reaching the tenant.
+// It's only used if the csi driver isn't used. A package on the nodes has to be provided by the cluster admin as a PersistentVolume,
+// the DynaKube can't reference host paths, as they would be mounted into the pods of every injected namespace.
+func (dk *DynaKube) FeatureOneAgentOfflinePVC() string {
+	return dk.getFeatureFlagRaw(AnnotationFeatureOneAgentOfflinePVC)
+}
+
+// FeatureOneAgentOfflineSha256 is a feature flag for the expected sha256 checksum of the pre-seeded OneAgent package,
+// it's required for the offline installation, as a checksum next to the package could be replaced together with it
+func (dk *DynaKube) FeatureOneAgentOfflineSha256() string {
+	return dk.getFeatureFlagRaw(AnnotationFeatureOneAgentOfflineSha256)
+}
+
+// FeatureOneAgentOfflineInstall is true if the injected OneAgents are installed from a pre-seeded package instead of being downloaded
+func (dk *DynaKube) FeatureOneAgentOfflineInstall() bool {
+	return !dk.NeedsCSIDriver() && dk.FeatureOneAgentOfflinePVC() != ""
+}
+
+// FeatureInjectionReportAnnotation is a feature flag to add the injection report of the init container to the injected pods as annotation,
+// it requires that the service accounts of the pods are allowed to patch pods
+func (dk *DynaKube) FeatureInjectionReportAnnotation() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureInjectionReport) == "true"
+}
+
 func (dk *DynaKube) FeatureAgentRunPrivileged() bool {
 	return dk.getFeatureFlagRaw(AnnotationFeatureRunOneAgentContainerPrivileged) == "true"
 }
 
+// FeatureDenyMissingReferences is a feature flag to deny a DynaKube which references missing or incomplete
+// secrets, configmaps or priority classes, instead of only warning about them. References that can't be read stay warnings.
+func (dk *DynaKube) FeatureDenyMissingReferences() bool {
+	return dk.getFeatureFlagRaw(AnnotationFeatureDenyMissingReferences) == "true"
+}
+
+// FeatureDiagnosticsInterval is a feature flag for the time between two runs of the troubleshoot checks by the operator,
+// the diagnostics are disabled by default, as they pull images, probe the network and list the events of the cluster
+func (dk *DynaKube) FeatureDiagnosticsInterval() time.Duration {
+	defaultInterval := time.Duration(0)
+	raw := dk.getFeatureFlagRaw(AnnotationFeatureDiagnosticsInterval)
+	if raw == "" {
+		return defaultInterval
+	}
+
+	val, err := strconv.Atoi(raw)
+	if err != nil || val < 0 {
+		log.Info("invalid diagnostics interval feature-flag, using default", "value", raw)
+		return defaultInterval
+	}
+
+	return time.Duration(val) * time.Second
+}
+
 func (dk *DynaKube) getFeatureFlagRaw(annotation string) string {
 	if raw, ok := dk.Annotations[annotation]; ok {
 		return raw
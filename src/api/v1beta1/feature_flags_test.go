@@ -2,9 +2,12 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func createDynakubeWithAnnotation(keyValues ...string) DynaKube {
@@ -169,3 +172,210 @@ func TestDeprecatedEnableAnnotations(t *testing.T) {
 	dynakube = createDynakubeWithAnnotation()
 	assert.False(t, dynakube.FeatureActiveGateAuthToken())
 }
+
+func TestFeaturePredownloadVersions(t *testing.T) {
+	t.Run(`not set`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.Empty(t, dynakube.FeaturePredownloadVersions())
+	})
+	t.Run(`versions`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeaturePredownloadVersions, `["1.2.3", "1.2.4"]`)
+
+		assert.Equal(t, []string{"1.2.3", "1.2.4"}, dynakube.FeaturePredownloadVersions())
+	})
+	t.Run(`invalid value`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeaturePredownloadVersions, "1.2.3")
+
+		assert.Empty(t, dynakube.FeaturePredownloadVersions())
+	})
+}
+
+func TestFeatureExcludedContainers(t *testing.T) {
+	t.Run(`not set`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.Empty(t, dynakube.FeatureExcludedContainers())
+	})
+	t.Run(`patterns`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureExcludedContainers, `["istio-proxy", "*/fluent-bit:*"]`)
+
+		assert.Equal(t, []string{"istio-proxy", "*/fluent-bit:*"}, dynakube.FeatureExcludedContainers())
+	})
+	t.Run(`invalid value`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureExcludedContainers, "istio-proxy")
+
+		assert.Empty(t, dynakube.FeatureExcludedContainers())
+	})
+}
+
+func TestFeatureMetadataAllowLists(t *testing.T) {
+	t.Run(`not set`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.Empty(t, dynakube.FeatureMetadataPodLabels())
+		assert.Empty(t, dynakube.FeatureMetadataPodAnnotations())
+		assert.Empty(t, dynakube.FeatureMetadataNamespaceLabels())
+	})
+	t.Run(`patterns`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(
+			AnnotationFeatureMetadataPodLabels, `["team", "app.kubernetes.io/*"]`,
+			AnnotationFeatureMetadataPodAnnotations, `["cost-center"]`,
+			AnnotationFeatureMetadataNamespaceLabels, `["owner"]`)
+
+		assert.Equal(t, []string{"team", "app.kubernetes.io/*"}, dynakube.FeatureMetadataPodLabels())
+		assert.Equal(t, []string{"cost-center"}, dynakube.FeatureMetadataPodAnnotations())
+		assert.Equal(t, []string{"owner"}, dynakube.FeatureMetadataNamespaceLabels())
+	})
+}
+
+func TestInjectionRouting(t *testing.T) {
+	t.Run(`defaults`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		selector, err := dynakube.InjectionPodSelector()
+
+		require.NoError(t, err)
+		assert.Nil(t, selector)
+		assert.Equal(t, 0, dynakube.FeatureInjectionPriority())
+	})
+	t.Run(`pod selector and priority`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(
+			AnnotationFeatureInjectionPodSelector, "track in (canary)",
+			AnnotationFeatureInjectionPriority, "10")
+
+		selector, err := dynakube.InjectionPodSelector()
+
+		require.NoError(t, err)
+		assert.True(t, selector.Matches(labels.Set{"track": "canary"}))
+		assert.False(t, selector.Matches(labels.Set{"track": "stable"}))
+		assert.Equal(t, 10, dynakube.FeatureInjectionPriority())
+	})
+	t.Run(`invalid values`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(
+			AnnotationFeatureInjectionPodSelector, "track in (",
+			AnnotationFeatureInjectionPriority, "high")
+
+		_, err := dynakube.InjectionPodSelector()
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, dynakube.FeatureInjectionPriority())
+	})
+}
+
+func TestFeatureWorkloadRestart(t *testing.T) {
+	t.Run(`defaults`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.False(t, dynakube.FeatureWorkloadRestart())
+		assert.Equal(t, 1, dynakube.FeatureWorkloadRestartMaxConcurrent())
+		assert.Equal(t, 10*time.Minute, dynakube.FeatureWorkloadRestartInterval())
+	})
+	t.Run(`enabled with limits`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(
+			AnnotationFeatureWorkloadRestart, "true",
+			AnnotationFeatureWorkloadRestartMaxConcurrent, "3",
+			AnnotationFeatureWorkloadRestartInterval, "60")
+
+		assert.True(t, dynakube.FeatureWorkloadRestart())
+		assert.Equal(t, 3, dynakube.FeatureWorkloadRestartMaxConcurrent())
+		assert.Equal(t, time.Minute, dynakube.FeatureWorkloadRestartInterval())
+	})
+	t.Run(`invalid values`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(
+			AnnotationFeatureWorkloadRestartMaxConcurrent, "0",
+			AnnotationFeatureWorkloadRestartInterval, "soon")
+
+		assert.Equal(t, 1, dynakube.FeatureWorkloadRestartMaxConcurrent())
+		assert.Equal(t, 10*time.Minute, dynakube.FeatureWorkloadRestartInterval())
+	})
+}
+
+func TestFeatureDenyMissingReferences(t *testing.T) {
+	t.Run(`default`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.False(t, dynakube.FeatureDenyMissingReferences())
+	})
+	t.Run(`enabled`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureDenyMissingReferences, "true")
+
+		assert.True(t, dynakube.FeatureDenyMissingReferences())
+	})
+}
+
+func TestFeatureInjectionReportAnnotation(t *testing.T) {
+	t.Run(`default`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.False(t, dynakube.FeatureInjectionReportAnnotation())
+	})
+	t.Run(`enabled`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureInjectionReport, "true")
+
+		assert.True(t, dynakube.FeatureInjectionReportAnnotation())
+	})
+}
+
+func TestFeatureDiagnosticsInterval(t *testing.T) {
+	t.Run(`disabled by default`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.Equal(t, time.Duration(0), dynakube.FeatureDiagnosticsInterval())
+	})
+	t.Run(`custom interval`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureDiagnosticsInterval, "600")
+
+		assert.Equal(t, 10*time.Minute, dynakube.FeatureDiagnosticsInterval())
+	})
+	t.Run(`disabled`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureDiagnosticsInterval, "0")
+
+		assert.Equal(t, time.Duration(0), dynakube.FeatureDiagnosticsInterval())
+	})
+	t.Run(`invalid value`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureDiagnosticsInterval, "-1")
+
+		assert.Equal(t, time.Duration(0), dynakube.FeatureDiagnosticsInterval())
+	})
+}
+
+func TestFeatureAgentDownloadDeadline(t *testing.T) {
+	t.Run(`default fails fast`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+
+		assert.Equal(t, 0, dynakube.FeatureAgentDownloadDeadline())
+	})
+	t.Run(`custom deadline`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureOneAgentDownloadDeadline, "300")
+
+		assert.Equal(t, 300, dynakube.FeatureAgentDownloadDeadline())
+	})
+	t.Run(`invalid value`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureOneAgentDownloadDeadline, "soon")
+
+		assert.Equal(t, 0, dynakube.FeatureAgentDownloadDeadline())
+	})
+}
+
+func TestFeatureOneAgentOfflineInstall(t *testing.T) {
+	t.Run(`not configured`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation()
+		dynakube.Spec.OneAgent.ApplicationMonitoring = &ApplicationMonitoringSpec{}
+
+		assert.False(t, dynakube.FeatureOneAgentOfflineInstall())
+	})
+	t.Run(`pvc`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureOneAgentOfflinePVC, "oneagent-package")
+		dynakube.Spec.OneAgent.ApplicationMonitoring = &ApplicationMonitoringSpec{}
+
+		assert.True(t, dynakube.FeatureOneAgentOfflineInstall())
+		assert.Equal(t, "oneagent-package", dynakube.FeatureOneAgentOfflinePVC())
+	})
+	t.Run(`csi driver is preferred`, func(t *testing.T) {
+		dynakube := createDynakubeWithAnnotation(AnnotationFeatureOneAgentOfflinePVC, "oneagent-package")
+		dynakube.Spec.OneAgent.CloudNativeFullStack = &CloudNativeFullStackSpec{}
+
+		assert.False(t, dynakube.FeatureOneAgentOfflineInstall())
+	})
+}
@@ -266,6 +266,14 @@ func (dynakube DynaKube) CodeModulesVersion() string {
 	return dynakube.Status.LatestAgentVersionUnixPaas
 }
 
+// CodeModulesRevision identifies the code modules that are injected into pods, it changes with the codeModulesImage or the version.
+func (dynakube DynaKube) CodeModulesRevision() string {
+	if codeModulesImage := dynakube.CodeModulesImage(); codeModulesImage != "" {
+		return codeModulesImage
+	}
+	return dynakube.CodeModulesVersion()
+}
+
 func (dk *DynaKube) NamespaceSelector() *metav1.LabelSelector {
 	return &dk.Spec.NamespaceSelector
 }
@@ -269,6 +269,48 @@ func TestCodeModulesVersion(t *testing.T) {
 	})
 }
 
+func TestCodeModulesRevision(t *testing.T) {
+	testVersion := "1.2.3"
+	t.Run(`use version`, func(t *testing.T) {
+		dk := DynaKube{
+			Spec: DynaKubeSpec{
+				OneAgent: OneAgentSpec{
+					ApplicationMonitoring: &ApplicationMonitoringSpec{
+						Version: testVersion,
+					},
+				},
+			},
+		}
+		assert.Equal(t, testVersion, dk.CodeModulesRevision())
+	})
+	t.Run(`use image`, func(t *testing.T) {
+		dk := DynaKube{
+			Spec: DynaKubeSpec{
+				OneAgent: OneAgentSpec{
+					CloudNativeFullStack: &CloudNativeFullStackSpec{
+						AppInjectionSpec: AppInjectionSpec{
+							CodeModulesImage: "image:" + testVersion,
+						},
+					},
+				},
+			},
+		}
+		assert.Equal(t, "image:"+testVersion, dk.CodeModulesRevision())
+	})
+	t.Run(`empty without code modules`, func(t *testing.T) {
+		dk := DynaKube{
+			Spec: DynaKubeSpec{
+				OneAgent: OneAgentSpec{
+					ClassicFullStack: &HostInjectSpec{
+						Version: testVersion,
+					},
+				},
+			},
+		}
+		assert.Empty(t, dk.CodeModulesRevision())
+	})
+}
+
 func TestGetRawImageTag(t *testing.T) {
 	t.Run(`with tag`, func(t *testing.T) {
 		expectedTag := "test"
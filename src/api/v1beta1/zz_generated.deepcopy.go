@@ -359,6 +359,7 @@ func (in *DynaKubeStatus) DeepCopyInto(out *DynaKubeStatus) {
 	in.ExtensionController.DeepCopyInto(&out.ExtensionController)
 	in.Statsd.DeepCopyInto(&out.Statsd)
 	in.OneAgent.DeepCopyInto(&out.OneAgent)
+	in.Injection.DeepCopyInto(&out.Injection)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeStatus.
@@ -456,6 +457,28 @@ func (in *HostInjectSpec) DeepCopy() *HostInjectSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InjectionStatus) DeepCopyInto(out *InjectionStatus) {
+	*out = *in
+	in.MatchedNamespaces.DeepCopyInto(&out.MatchedNamespaces)
+	in.IgnoredNamespaces.DeepCopyInto(&out.IgnoredNamespaces)
+	in.UninjectedNamespaces.DeepCopyInto(&out.UninjectedNamespaces)
+	if in.LastUninjectedProbeTimestamp != nil {
+		in, out := &in.LastUninjectedProbeTimestamp, &out.LastUninjectedProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InjectionStatus.
+func (in *InjectionStatus) DeepCopy() *InjectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InjectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesMonitoringSpec) DeepCopyInto(out *KubernetesMonitoringSpec) {
 	*out = *in
@@ -472,6 +495,26 @@ func (in *KubernetesMonitoringSpec) DeepCopy() *KubernetesMonitoringSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceListStatus) DeepCopyInto(out *NamespaceListStatus) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceListStatus.
+func (in *NamespaceListStatus) DeepCopy() *NamespaceListStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceListStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OneAgentInstance) DeepCopyInto(out *OneAgentInstance) {
 	*out = *in
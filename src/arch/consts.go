@@ -3,6 +3,7 @@ package arch
 const (
 	FlavorDefault     = "default"
 	FlavorMultidistro = "multidistro"
+	FlavorMusl        = "musl"
 
 	ArchX86   = "x86"
 	ArchARM   = "arm"
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	use       = "csi-db"
+	listUse   = "list"
+	diffUse   = "diff"
+	repairUse = "repair"
+)
+
+var (
+	dbPath    = ""
+	rootDir   = ""
+	dryRun    = true
+	correct   = false
+	collectGC = false
+	namespace = ""
+)
+
+type CommandBuilder struct {
+	configProvider config.Provider
+	namespace      string
+	filesystem     afero.Fs
+}
+
+func NewCsiDbCommandBuilder() CommandBuilder {
+	return CommandBuilder{}
+}
+
+func (builder CommandBuilder) SetConfigProvider(provider config.Provider) CommandBuilder {
+	builder.configProvider = provider
+	return builder
+}
+
+func (builder CommandBuilder) SetNamespace(namespace string) CommandBuilder {
+	builder.namespace = namespace
+	return builder
+}
+
+func (builder CommandBuilder) getNamespace() string {
+	if namespace != "" {
+		return namespace
+	}
+	return builder.namespace
+}
+
+func (builder CommandBuilder) setFilesystem(filesystem afero.Fs) CommandBuilder {
+	builder.filesystem = filesystem
+	return builder
+}
+
+func (builder CommandBuilder) getFilesystem() afero.Fs {
+	if builder.filesystem == nil {
+		builder.filesystem = afero.NewOsFs()
+	}
+
+	return builder.filesystem
+}
+
+func (builder CommandBuilder) Build() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Inspect and repair the metadata database of the csi driver",
+	}
+
+	addFlags(cmd)
+
+	listCmd := &cobra.Command{
+		Use:   listUse,
+		Short: "List the stored dynakubes, tenants, volumes and used versions",
+		RunE:  builder.buildListRun(),
+	}
+	diffCmd := &cobra.Command{
+		Use:   diffUse,
+		Short: "Compare the stored metadata with the files of the csi driver",
+		RunE:  builder.buildDiffRun(),
+	}
+	repairCmd := &cobra.Command{
+		Use:   repairUse,
+		Short: "Run the metadata correction and garbage collection of the csi driver",
+		RunE:  builder.buildRepairRun(),
+	}
+	addRepairFlags(repairCmd)
+
+	cmd.AddCommand(listCmd, diffCmd, repairCmd)
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&dbPath, "db-path", dtcsi.MetadataAccessPath, "Path of the csi metadata database.")
+	cmd.PersistentFlags().StringVar(&rootDir, "root-dir", dtcsi.DataPath, "Data directory of the csi driver.")
+}
+
+func addRepairFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", true, "Only log the changes instead of applying them.")
+	cmd.Flags().BoolVar(&correct, "correct", false, "Remove entries of pods and dynakubes that no longer exist in the cluster.")
+	cmd.Flags().BoolVar(&collectGC, "gc", false, "Run the garbage collection for every dynakube of the namespace.")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Namespace of the dynakubes, defaults to the namespace of the pod.")
+}
+
+func (builder CommandBuilder) buildListRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		access, err := metadata.NewReadOnlyAccess(dbPath)
+		if err != nil {
+			return err
+		}
+		return printOverview(cmd.OutOrStdout(), access)
+	}
+}
+
+func (builder CommandBuilder) buildDiffRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		access, err := metadata.NewReadOnlyAccess(dbPath)
+		if err != nil {
+			return err
+		}
+		diff, err := newFilesystemDiff(afero.Afero{Fs: builder.getFilesystem()}, access, metadata.PathResolver{RootDir: rootDir})
+		if err != nil {
+			return err
+		}
+		diff.print(cmd.OutOrStdout())
+		return nil
+	}
+}
+
+func (builder CommandBuilder) buildRepairRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		access, err := builder.openAccess()
+		if err != nil {
+			return err
+		}
+
+		kubeConfig, err := builder.configProvider.GetConfig()
+		if err != nil {
+			return err
+		}
+		kubeClient, err := client.New(kubeConfig, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return err
+		}
+
+		return repair(cmd.Context(), kubeClient, access, repairOptions{
+			namespace: builder.getNamespace(),
+			csiOpts:   dtcsi.CSIOptions{RootDir: rootDir},
+			dryRun:    dryRun,
+			correct:   correct,
+			collectGC: collectGC,
+		})
+	}
+}
+
+// openAccess opens the database read-only for dry-runs, so the command never changes the state of the csi driver by accident
+func (builder CommandBuilder) openAccess() (metadata.Access, error) {
+	if dryRun {
+		access, err := metadata.NewReadOnlyAccess(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		return metadata.NewDryRunAccess(access), nil
+	}
+	return metadata.NewAccess(dbPath)
+}
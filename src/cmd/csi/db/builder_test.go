@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsiDbCommandBuilder(t *testing.T) {
+	t.Run("build command", func(t *testing.T) {
+		builder := NewCsiDbCommandBuilder()
+		csiDbCommand := builder.Build()
+
+		assert.NotNil(t, csiDbCommand)
+		assert.Equal(t, use, csiDbCommand.Use)
+		assert.Len(t, csiDbCommand.Commands(), 3)
+	})
+	t.Run("set config provider", func(t *testing.T) {
+		expectedProvider := &config.MockProvider{}
+		builder := NewCsiDbCommandBuilder().SetConfigProvider(expectedProvider)
+
+		assert.Equal(t, expectedProvider, builder.configProvider)
+	})
+	t.Run("set namespace", func(t *testing.T) {
+		builder := NewCsiDbCommandBuilder().SetNamespace("namespace")
+
+		assert.Equal(t, "namespace", builder.getNamespace())
+	})
+	t.Run("set filesystem", func(t *testing.T) {
+		expectedFs := afero.NewMemMapFs()
+		builder := NewCsiDbCommandBuilder()
+
+		assert.Equal(t, afero.NewOsFs(), builder.getFilesystem())
+
+		builder = builder.setFilesystem(expectedFs)
+
+		assert.Equal(t, expectedFs, builder.getFilesystem())
+	})
+}
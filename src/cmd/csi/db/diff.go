@@ -0,0 +1,214 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// filesystemDiff lists the differences between the stored metadata and the files in the data directory of the csi driver,
+// the entries are paths (for missing files the path where the file is expected)
+type filesystemDiff struct {
+	missingVersions    []string
+	unusedVersions     []string
+	missingImages      []string
+	unusedImages       []string
+	missingVolumeDirs  []string
+	orphanedVolumeDirs []string
+	missingOsAgentDirs []string
+	unknownTenantDirs  []string
+	usedVersions       map[string]bool
+	usedImageDigests   map[string]bool
+	latestVersions     map[string]bool
+	storedVolumeIDs    map[string]bool
+	fs                 afero.Afero
+	path               metadata.PathResolver
+}
+
+func newFilesystemDiff(fs afero.Afero, access metadata.Access, path metadata.PathResolver) (*filesystemDiff, error) {
+	overview, err := metadata.NewAccessOverview(access)
+	if err != nil {
+		return nil, err
+	}
+	usedImageDigests, err := access.GetUsedImageDigests()
+	if err != nil {
+		return nil, err
+	}
+	usedVersions, err := access.GetAllUsedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &filesystemDiff{
+		usedVersions:     usedVersions,
+		usedImageDigests: usedImageDigests,
+		latestVersions:   map[string]bool{},
+		storedVolumeIDs:  map[string]bool{},
+		fs:               fs,
+		path:             path,
+	}
+	for _, dynakube := range overview.Dynakubes {
+		diff.latestVersions[dynakube.LatestVersion] = true
+	}
+	for _, volume := range overview.Volumes {
+		diff.storedVolumeIDs[volume.VolumeID] = true
+	}
+
+	diff.compareVolumes(overview.Volumes)
+	diff.compareOsAgentVolumes(overview.OsAgentVolumes)
+	if err := diff.compareImages(); err != nil {
+		return nil, err
+	}
+	if err := diff.compareTenants(getTenantUUIDs(overview)); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// compareVolumes checks that the binaries and the run directory of every volume exist,
+// the version of a volume is either an agent version of the tenant or the digest of a code modules image
+func (diff *filesystemDiff) compareVolumes(volumes []*metadata.Volume) {
+	missingVersions := map[string]bool{}
+	for _, volume := range volumes {
+		versionDir := diff.path.AgentBinaryDirForVersion(volume.TenantUUID, volume.Version)
+		if !diff.exists(versionDir) && !diff.exists(diff.path.AgentSharedBinaryDirForImage(volume.Version)) {
+			missingVersions[versionDir] = true
+		}
+		if volumeDir := diff.path.AgentRunDirForVolume(volume.TenantUUID, volume.VolumeID); !diff.exists(volumeDir) {
+			diff.missingVolumeDirs = append(diff.missingVolumeDirs, volumeDir)
+		}
+	}
+	diff.missingVersions = sortedKeys(missingVersions)
+}
+
+func (diff *filesystemDiff) compareOsAgentVolumes(osVolumes []*metadata.OsAgentVolume) {
+	for _, osVolume := range osVolumes {
+		if osAgentDir := diff.path.OsAgentDir(osVolume.TenantUUID); osVolume.Mounted && !diff.exists(osAgentDir) {
+			diff.missingOsAgentDirs = append(diff.missingOsAgentDirs, osAgentDir)
+		}
+	}
+}
+
+func (diff *filesystemDiff) compareImages() error {
+	for _, imageDigest := range sortedKeys(diff.usedImageDigests) {
+		if imageDir := diff.path.AgentSharedBinaryDirForImage(imageDigest); !diff.exists(imageDir) {
+			diff.missingImages = append(diff.missingImages, imageDir)
+		}
+	}
+
+	imageDirs, err := diff.readDirNames(diff.path.AgentSharedBinaryDirBase())
+	if err != nil {
+		return err
+	}
+	for _, imageDigest := range imageDirs {
+		if !diff.usedImageDigests[imageDigest] && !diff.usedVersions[imageDigest] {
+			diff.unusedImages = append(diff.unusedImages, diff.path.AgentSharedBinaryDirForImage(imageDigest))
+		}
+	}
+	return nil
+}
+
+// compareTenants checks the directories of the stored tenants and looks for tenant directories nothing is stored for
+func (diff *filesystemDiff) compareTenants(tenantUUIDs []string) error {
+	for _, tenantUUID := range tenantUUIDs {
+		versions, err := diff.readDirNames(diff.path.AgentBinaryDir(tenantUUID))
+		if err != nil {
+			return err
+		}
+		for _, version := range versions {
+			if !diff.usedVersions[version] && !diff.latestVersions[version] {
+				diff.unusedVersions = append(diff.unusedVersions, diff.path.AgentBinaryDirForVersion(tenantUUID, version))
+			}
+		}
+
+		volumeIDs, err := diff.readDirNames(diff.path.AgentRunDir(tenantUUID))
+		if err != nil {
+			return err
+		}
+		for _, volumeID := range volumeIDs {
+			if !diff.storedVolumeIDs[volumeID] {
+				diff.orphanedVolumeDirs = append(diff.orphanedVolumeDirs, diff.path.AgentRunDirForVolume(tenantUUID, volumeID))
+			}
+		}
+	}
+
+	knownTenantUUIDs := map[string]bool{}
+	for _, tenantUUID := range tenantUUIDs {
+		knownTenantUUIDs[tenantUUID] = true
+	}
+	dirNames, err := diff.readDirNames(diff.path.RootDir)
+	if err != nil {
+		return err
+	}
+	for _, dirName := range dirNames {
+		if !knownTenantUUIDs[dirName] && diff.isTenantDir(dirName) {
+			diff.unknownTenantDirs = append(diff.unknownTenantDirs, diff.path.TenantDir(dirName))
+		}
+	}
+	return nil
+}
+
+// isTenantDir checks for the directories only a tenant directory contains
+func (diff *filesystemDiff) isTenantDir(name string) bool {
+	return diff.exists(diff.path.AgentBinaryDir(name)) ||
+		diff.exists(diff.path.AgentRunDir(name)) ||
+		diff.exists(diff.path.OsAgentDir(name))
+}
+
+func (diff *filesystemDiff) exists(path string) bool {
+	exists, _ := diff.fs.Exists(path)
+	return exists
+}
+
+// readDirNames returns the names of the directories in the given directory, a missing directory has no entries
+func (diff *filesystemDiff) readDirNames(path string) ([]string, error) {
+	entries, err := diff.fs.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (diff *filesystemDiff) isEmpty() bool {
+	return len(diff.missingVersions) == 0 && len(diff.unusedVersions) == 0 &&
+		len(diff.missingImages) == 0 && len(diff.unusedImages) == 0 &&
+		len(diff.missingVolumeDirs) == 0 && len(diff.orphanedVolumeDirs) == 0 &&
+		len(diff.missingOsAgentDirs) == 0 && len(diff.unknownTenantDirs) == 0
+}
+
+func (diff *filesystemDiff) print(out io.Writer) {
+	if diff.isEmpty() {
+		fmt.Fprintf(out, "metadata matches the files in %s\n", diff.path.RootDir)
+		return
+	}
+	printSection(out, "versions used by volumes, but missing", diff.missingVersions)
+	printSection(out, "versions not used by any volume or dynakube", diff.unusedVersions)
+	printSection(out, "images used by dynakubes, but missing", diff.missingImages)
+	printSection(out, "images not used by any volume or dynakube", diff.unusedImages)
+	printSection(out, "volumes without run directory", diff.missingVolumeDirs)
+	printSection(out, "run directories without volume", diff.orphanedVolumeDirs)
+	printSection(out, "mounted osagent volumes without directory", diff.missingOsAgentDirs)
+	printSection(out, "tenant directories without metadata", diff.unknownTenantDirs)
+}
+
+func printSection(out io.Writer, title string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "%s (%d):\n", title, len(paths))
+	for _, path := range paths {
+		fmt.Fprintf(out, "  %s\n", path)
+	}
+}
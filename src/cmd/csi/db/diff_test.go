@@ -0,0 +1,87 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRootDir      = "/data"
+	testTenantUUID   = "a-tenant-uuid"
+	testDynakubeName = "a-dynakube"
+	testVersion      = "1.2.3"
+	testImageDigest  = "123456789"
+	testVolumeID     = "a-volume"
+	testPodName      = "a-pod"
+)
+
+var testPath = metadata.PathResolver{RootDir: testRootDir}
+
+func TestFilesystemDiff(t *testing.T) {
+	t.Run(`consistent metadata`, func(t *testing.T) {
+		fs, access := newDiffTestSetup(t)
+		mkdirs(t, fs,
+			testPath.AgentBinaryDirForVersion(testTenantUUID, testVersion),
+			testPath.AgentRunDirForVolume(testTenantUUID, testVolumeID),
+		)
+
+		diff, err := newFilesystemDiff(fs, access, testPath)
+		require.NoError(t, err)
+
+		assert.True(t, diff.isEmpty())
+		out := &bytes.Buffer{}
+		diff.print(out)
+		assert.Contains(t, out.String(), "metadata matches")
+	})
+	t.Run(`missing files`, func(t *testing.T) {
+		fs, access := newDiffTestSetup(t)
+		require.NoError(t, access.UpdateDynakube(metadata.NewDynakube(testDynakubeName, testTenantUUID, testVersion, testImageDigest)))
+
+		diff, err := newFilesystemDiff(fs, access, testPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{testPath.AgentBinaryDirForVersion(testTenantUUID, testVersion)}, diff.missingVersions)
+		assert.Equal(t, []string{testPath.AgentRunDirForVolume(testTenantUUID, testVolumeID)}, diff.missingVolumeDirs)
+		assert.Equal(t, []string{testPath.AgentSharedBinaryDirForImage(testImageDigest)}, diff.missingImages)
+	})
+	t.Run(`unused files`, func(t *testing.T) {
+		fs, access := newDiffTestSetup(t)
+		mkdirs(t, fs,
+			testPath.AgentBinaryDirForVersion(testTenantUUID, testVersion),
+			testPath.AgentBinaryDirForVersion(testTenantUUID, "1.0.0"),
+			testPath.AgentRunDirForVolume(testTenantUUID, testVolumeID),
+			testPath.AgentRunDirForVolume(testTenantUUID, "other-volume"),
+			testPath.AgentSharedBinaryDirForImage(testImageDigest),
+			testPath.AgentBinaryDir("other-tenant"),
+			testPath.AgentTempUnzipDir(),
+		)
+
+		diff, err := newFilesystemDiff(fs, access, testPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{testPath.AgentBinaryDirForVersion(testTenantUUID, "1.0.0")}, diff.unusedVersions)
+		assert.Equal(t, []string{testPath.AgentRunDirForVolume(testTenantUUID, "other-volume")}, diff.orphanedVolumeDirs)
+		assert.Equal(t, []string{testPath.AgentSharedBinaryDirForImage(testImageDigest)}, diff.unusedImages)
+		assert.Equal(t, []string{testPath.TenantDir("other-tenant")}, diff.unknownTenantDirs)
+	})
+}
+
+func newDiffTestSetup(t *testing.T) (afero.Afero, metadata.Access) {
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+	access := metadata.FakeMemoryDB()
+	require.NoError(t, access.InsertDynakube(metadata.NewDynakube(testDynakubeName, testTenantUUID, testVersion, "")))
+	require.NoError(t, access.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+	return fs, access
+}
+
+func mkdirs(t *testing.T, fs afero.Afero, paths ...string) {
+	for _, path := range paths {
+		require.NoError(t, fs.MkdirAll(path, os.ModePerm))
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+)
+
+func printOverview(out io.Writer, access metadata.Access) error {
+	overview, err := metadata.NewAccessOverview(access)
+	if err != nil {
+		return err
+	}
+	usedImageDigests, err := access.GetUsedImageDigests()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "DYNAKUBE\tTENANT\tLATEST VERSION\tIMAGE DIGEST")
+	for _, dynakube := range overview.Dynakubes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", dynakube.Name, dynakube.TenantUUID, dynakube.LatestVersion, dynakube.ImageDigest)
+	}
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, "TENANT\tUSED VERSIONS")
+	for _, tenantUUID := range getTenantUUIDs(overview) {
+		usedVersions, err := access.GetUsedVersions(tenantUUID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", tenantUUID, joinKeys(usedVersions))
+	}
+	fmt.Fprintln(writer)
+
+	fmt.Fprintf(writer, "USED IMAGE DIGESTS\t%s\n", joinKeys(usedImageDigests))
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, "VOLUME\tPOD\tTENANT\tVERSION")
+	for _, volume := range overview.Volumes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", volume.VolumeID, volume.PodName, volume.TenantUUID, volume.Version)
+	}
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, "OSAGENT VOLUME\tTENANT\tMOUNTED\tLAST MODIFIED")
+	for _, osVolume := range overview.OsAgentVolumes {
+		lastModified := ""
+		if osVolume.LastModified != nil {
+			lastModified = osVolume.LastModified.String()
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\n", osVolume.VolumeID, osVolume.TenantUUID, osVolume.Mounted, lastModified)
+	}
+
+	return writer.Flush()
+}
+
+// getTenantUUIDs returns the tenants of the dynakubes and volumes, as volumes can outlive the dynakube they were created for
+func getTenantUUIDs(overview *metadata.AccessOverview) []string {
+	tenantUUIDs := map[string]bool{}
+	for _, dynakube := range overview.Dynakubes {
+		tenantUUIDs[dynakube.TenantUUID] = true
+	}
+	for _, volume := range overview.Volumes {
+		tenantUUIDs[volume.TenantUUID] = true
+	}
+	for _, osVolume := range overview.OsAgentVolumes {
+		tenantUUIDs[osVolume.TenantUUID] = true
+	}
+	return sortedKeys(tenantUUIDs)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinKeys(set map[string]bool) string {
+	return strings.Join(sortedKeys(set), ",")
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintOverview(t *testing.T) {
+	access := metadata.FakeMemoryDB()
+	timestamp := time.Now()
+	require.NoError(t, access.InsertDynakube(metadata.NewDynakube(testDynakubeName, testTenantUUID, testVersion, testImageDigest)))
+	require.NoError(t, access.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+	require.NoError(t, access.InsertOsAgentVolume(metadata.NewOsAgentVolume("osagent-volume", testTenantUUID, true, &timestamp)))
+	out := &bytes.Buffer{}
+
+	err := printOverview(out, access)
+
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), testDynakubeName)
+	assert.Contains(t, out.String(), testPodName)
+	assert.Contains(t, out.String(), "osagent-volume")
+	assert.Regexp(t, testTenantUUID+` +`+testVersion, out.String())
+	assert.Regexp(t, `USED IMAGE DIGESTS +`+testImageDigest, out.String())
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	csigc "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/gc"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("csi-db")
+)
+
+type repairOptions struct {
+	namespace string
+	csiOpts   dtcsi.CSIOptions
+	dryRun    bool
+	correct   bool
+	collectGC bool
+}
+
+func repair(ctx context.Context, kubeClient client.Client, access metadata.Access, opts repairOptions) error {
+	if !opts.correct && !opts.collectGC {
+		return errors.New("nothing to repair, use --correct and/or --gc")
+	}
+	log.Info("repairing csi metadata", "dryRun", opts.dryRun, "correct", opts.correct, "gc", opts.collectGC)
+
+	if opts.correct {
+		if err := metadata.CorrectMetadata(kubeClient, access); err != nil {
+			return err
+		}
+	}
+
+	if opts.collectGC {
+		if opts.namespace == "" {
+			return errors.New("the namespace of the dynakubes is required for the garbage collection")
+		}
+		gc := csigc.NewCSIGarbageCollector(kubeClient, opts.csiOpts, access)
+		if opts.dryRun {
+			gc = csigc.NewDryRunCSIGarbageCollector(kubeClient, opts.csiOpts, access)
+		}
+		if err := gc.CollectGarbage(ctx, opts.namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepair(t *testing.T) {
+	t.Run(`nothing to repair`, func(t *testing.T) {
+		err := repair(context.TODO(), fake.NewClient(), metadata.FakeMemoryDB(), repairOptions{})
+
+		assert.Error(t, err)
+	})
+	t.Run(`dry-run correction keeps entries`, func(t *testing.T) {
+		access := metadata.FakeMemoryDB()
+		require.NoError(t, access.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+
+		err := repair(context.TODO(), fake.NewClient(), metadata.NewDryRunAccess(access), repairOptions{dryRun: true, correct: true})
+
+		require.NoError(t, err)
+		volume, err := access.GetVolume(testVolumeID)
+		require.NoError(t, err)
+		assert.NotNil(t, volume)
+	})
+	t.Run(`correction removes entries`, func(t *testing.T) {
+		access := metadata.FakeMemoryDB()
+		require.NoError(t, access.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+
+		err := repair(context.TODO(), fake.NewClient(), access, repairOptions{correct: true})
+
+		require.NoError(t, err)
+		volume, err := access.GetVolume(testVolumeID)
+		require.NoError(t, err)
+		assert.Nil(t, volume)
+	})
+	t.Run(`gc requires namespace`, func(t *testing.T) {
+		err := repair(context.TODO(), fake.NewClient(), metadata.FakeMemoryDB(), repairOptions{collectGC: true})
+
+		assert.Error(t, err)
+	})
+}
@@ -19,7 +19,9 @@ import (
 const use = "csi-provisioner"
 
 var (
-	probeAddress = ""
+	nodeId                = ""
+	probeAddress          = ""
+	serveMetadataOverview = false
 )
 
 type CommandBuilder struct {
@@ -62,6 +64,7 @@ func (builder CommandBuilder) setFilesystem(filesystem afero.Fs) CommandBuilder
 func (builder CommandBuilder) getCsiOptions() dtcsi.CSIOptions {
 	if builder.csiOptions == nil {
 		builder.csiOptions = &dtcsi.CSIOptions{
+			NodeId:  nodeId,
 			RootDir: dtcsi.DataPath,
 		}
 	}
@@ -97,7 +100,9 @@ func (builder CommandBuilder) Build() *cobra.Command {
 }
 
 func addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&nodeId, "node-id", "", "Node the provisioner runs on, used to mark the node as ready for the code modules.")
 	cmd.PersistentFlags().StringVar(&probeAddress, "health-probe-bind-address", ":10090", "The address the probe endpoint binds to.")
+	cmd.PersistentFlags().BoolVar(&serveMetadataOverview, "serve-metadata-overview", false, "Serves the overview of the metadata database on the metrics port, the endpoint isn't authenticated.")
 }
 
 func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
@@ -124,6 +129,13 @@ func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
 			return err
 		}
 
+		if serveMetadataOverview {
+			err = csiManager.AddMetricsExtraHandler(dtcsi.MetadataOverviewPath, metadata.NewOverviewHandler(access))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
 		err = csiprovisioner.NewOneAgentProvisioner(csiManager, builder.getCsiOptions(), access).SetupWithManager(csiManager)
 		if err != nil {
 			return err
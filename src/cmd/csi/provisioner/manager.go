@@ -1,7 +1,10 @@
 package provisioner
 
 import (
+	"strconv"
+
 	cmdManager "github.com/Dynatrace/dynatrace-operator/src/cmd/manager"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/rest"
@@ -11,12 +14,13 @@ import (
 )
 
 const (
-	metricsBindAddress   = ":8090"
 	defaultProbeAddress  = ":8091"
 	livenessEndpointName = "/livez"
 	livezEndpointName    = "livez"
 )
 
+var metricsBindAddress = ":" + strconv.Itoa(dtcsi.ProvisionerMetricsPort)
+
 type csiDriverManagerProvider struct {
 	probeAddress string
 }
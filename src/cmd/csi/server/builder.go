@@ -7,6 +7,7 @@ import (
 	cmdManager "github.com/Dynatrace/dynatrace-operator/src/cmd/manager"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	csidriver "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver"
+	appvolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes/app"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -18,9 +19,10 @@ import (
 const use = "csi-server"
 
 var (
-	nodeId       = ""
-	probeAddress = ""
-	endpoint     = ""
+	nodeId           = ""
+	probeAddress     = ""
+	endpoint         = ""
+	appMountStrategy = ""
 )
 
 type CommandBuilder struct {
@@ -63,9 +65,10 @@ func (builder CommandBuilder) setFilesystem(filesystem afero.Fs) CommandBuilder
 func (builder CommandBuilder) getCsiOptions() dtcsi.CSIOptions {
 	if builder.csiOptions == nil {
 		builder.csiOptions = &dtcsi.CSIOptions{
-			NodeId:   nodeId,
-			Endpoint: endpoint,
-			RootDir:  dtcsi.DataPath,
+			NodeId:           nodeId,
+			Endpoint:         endpoint,
+			RootDir:          dtcsi.DataPath,
+			AppMountStrategy: appMountStrategy,
 		}
 	}
 
@@ -103,6 +106,7 @@ func addFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&nodeId, "node-id", "", "node id")
 	cmd.PersistentFlags().StringVar(&endpoint, "endpoint", "unix:///tmp/csi.sock", "CSI endpoint")
 	cmd.PersistentFlags().StringVar(&probeAddress, "health-probe-bind-address", ":10080", "The address the probe endpoint binds to.")
+	cmd.PersistentFlags().StringVar(&appMountStrategy, "app-mount-strategy", appvolumes.AutoMountStrategy, "How the agent binaries are mounted into app volumes (auto, overlay or bind).")
 }
 
 func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
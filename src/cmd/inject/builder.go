@@ -0,0 +1,128 @@
+package inject
+
+import (
+	"os"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	use                     = "inject"
+	defaultWebhookNamespace = "dynatrace"
+)
+
+var (
+	dryRun           = false
+	podFile          = ""
+	dynakubeName     = ""
+	podNamespace     = ""
+	webhookNamespace = ""
+)
+
+type CommandBuilder struct {
+	configProvider config.Provider
+	namespace      string
+}
+
+func NewInjectCommandBuilder() CommandBuilder {
+	return CommandBuilder{}
+}
+
+func (builder CommandBuilder) SetConfigProvider(provider config.Provider) CommandBuilder {
+	builder.configProvider = provider
+	return builder
+}
+
+func (builder CommandBuilder) SetNamespace(namespace string) CommandBuilder {
+	builder.namespace = namespace
+	return builder
+}
+
+func (builder CommandBuilder) getWebhookNamespace() string {
+	if webhookNamespace != "" {
+		return webhookNamespace
+	}
+	if builder.namespace != "" {
+		return builder.namespace
+	}
+	return defaultWebhookNamespace
+}
+
+func (builder CommandBuilder) Build() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Show what the webhook would inject into a pod",
+		RunE:  builder.buildRun(),
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only preview the injection, required as the pod is never created by this command.")
+	cmd.Flags().StringVarP(&podFile, "filename", "f", "", "File with the pod manifest as yaml or json.")
+	cmd.Flags().StringVar(&dynakubeName, "dynakube", "", "Name of the DynaKube, defaults to the DynaKube assigned to the namespace of the pod.")
+	cmd.Flags().StringVarP(&podNamespace, "namespace", "n", "", "Namespace of the pod, overrides the namespace of the manifest.")
+	cmd.Flags().StringVar(&webhookNamespace, "webhook-namespace", "", "Namespace of the operator and the webhook, defaults to \"dynatrace\".")
+}
+
+func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !dryRun {
+			return errors.New("only --dry-run is supported")
+		}
+		pod, err := readPod(podFile)
+		if err != nil {
+			return err
+		}
+		if podNamespace != "" {
+			pod.Namespace = podNamespace
+		}
+
+		kubeConfig, err := builder.configProvider.GetConfig()
+		if err != nil {
+			return err
+		}
+		kubeClient, err := client.New(kubeConfig, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return err
+		}
+
+		previewer, err := pod_mutator.NewInjectionPreviewerForCluster(cmd.Context(), kubeClient, builder.getWebhookNamespace())
+		if err != nil {
+			return err
+		}
+		preview, err := previewer.Preview(cmd.Context(), pod, dynakubeName)
+		if err != nil {
+			return err
+		}
+		return printPreview(cmd.OutOrStdout(), preview)
+	}
+}
+
+func readPod(path string) (*corev1.Pod, error) {
+	if path == "" {
+		return nil, errors.New("the pod manifest is required, use -f")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var pod corev1.Pod
+	if err := yaml.Unmarshal(content, &pod); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if pod.Kind != "" && pod.Kind != "Pod" {
+		return nil, errors.Errorf("expected a Pod manifest, got %s", pod.Kind)
+	}
+	return &pod, nil
+}
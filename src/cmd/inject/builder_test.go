@@ -0,0 +1,65 @@
+package inject
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectCommandBuilder(t *testing.T) {
+	t.Run("build command", func(t *testing.T) {
+		builder := NewInjectCommandBuilder()
+		injectCommand := builder.Build()
+
+		assert.NotNil(t, injectCommand)
+		assert.Equal(t, use, injectCommand.Use)
+		assert.NotNil(t, injectCommand.RunE)
+	})
+	t.Run("set config provider", func(t *testing.T) {
+		expectedProvider := &config.MockProvider{}
+		builder := NewInjectCommandBuilder().SetConfigProvider(expectedProvider)
+
+		assert.Equal(t, expectedProvider, builder.configProvider)
+	})
+	t.Run("webhook namespace", func(t *testing.T) {
+		assert.Equal(t, defaultWebhookNamespace, NewInjectCommandBuilder().getWebhookNamespace())
+		assert.Equal(t, "namespace", NewInjectCommandBuilder().SetNamespace("namespace").getWebhookNamespace())
+	})
+	t.Run("dry-run is required", func(t *testing.T) {
+		injectCommand := NewInjectCommandBuilder().Build()
+
+		err := injectCommand.RunE(injectCommand, nil)
+
+		require.Error(t, err)
+	})
+}
+
+func TestReadPod(t *testing.T) {
+	t.Run("yaml manifest", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pod.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: test\n  namespace: test-namespace\n"), 0644))
+
+		pod, err := readPod(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "test", pod.Name)
+		assert.Equal(t, "test-namespace", pod.Namespace)
+	})
+	t.Run("other kind", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "deployment.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("apiVersion: apps/v1\nkind: Deployment\n"), 0644))
+
+		_, err := readPod(path)
+
+		require.Error(t, err)
+	})
+	t.Run("missing file name", func(t *testing.T) {
+		_, err := readPod("")
+
+		require.Error(t, err)
+	})
+}
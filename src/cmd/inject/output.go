@@ -0,0 +1,55 @@
+package inject
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+func printPreview(out io.Writer, preview *pod_mutator.InjectionPreview) error {
+	fmt.Fprintf(out, "namespace: %s\ndynakube: %s\n\n", preview.Namespace, preview.DynaKube)
+
+	fmt.Fprintln(out, "decisions:")
+	for _, decision := range preview.Decisions {
+		fmt.Fprintf(out, "  - %s\n", decision)
+	}
+	printAnnotations(out, "pod annotations", preview.PodAnnotations)
+	printAnnotations(out, "namespace annotations", preview.NamespaceAnnotations)
+
+	patch, err := json.MarshalIndent(preview.Patch, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintf(out, "\njson patch:\n%s\n", patch)
+
+	pod, err := yaml.Marshal(preview.Pod)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintf(out, "\nresulting pod:\n%s", pod)
+	return nil
+}
+
+func printAnnotations(out io.Writer, title string, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s:\n", title)
+	for _, key := range sortedKeys(annotations) {
+		fmt.Fprintf(out, "  %s: %s\n", key, annotations[key])
+	}
+}
+
+func sortedKeys(annotations map[string]string) []string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
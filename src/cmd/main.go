@@ -18,10 +18,14 @@ import (
 	"os"
 
 	cmdConfig "github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	csiDb "github.com/Dynatrace/dynatrace-operator/src/cmd/csi/db"
 	csiProvisioner "github.com/Dynatrace/dynatrace-operator/src/cmd/csi/provisioner"
 	csiServer "github.com/Dynatrace/dynatrace-operator/src/cmd/csi/server"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/inject"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/mapping"
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/operator"
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/standalone"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/supportarchive"
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/troubleshoot"
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/webhook"
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
@@ -74,11 +78,35 @@ func createCsiProvisionerCommandBuilder() csiProvisioner.CommandBuilder {
 		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
 }
 
+func createCsiDbCommandBuilder() csiDb.CommandBuilder {
+	return csiDb.NewCsiDbCommandBuilder().
+		SetNamespace(os.Getenv(envPodNamespace)).
+		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
+}
+
+func createInjectCommandBuilder() inject.CommandBuilder {
+	return inject.NewInjectCommandBuilder().
+		SetNamespace(os.Getenv(envPodNamespace)).
+		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
+}
+
+func createMappingCommandBuilder() mapping.CommandBuilder {
+	return mapping.NewMappingCommandBuilder().
+		SetNamespace(os.Getenv(envPodNamespace)).
+		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
+}
+
 func createTroubleshootCommandBuilder() troubleshoot.CommandBuilder {
 	return troubleshoot.NewTroubleshootCommandBuilder().
 		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
 }
 
+func createSupportArchiveCommandBuilder() supportarchive.CommandBuilder {
+	return supportarchive.NewSupportArchiveCommandBuilder().
+		SetNamespace(os.Getenv(envPodNamespace)).
+		SetConfigProvider(cmdConfig.NewKubeConfigProvider())
+}
+
 func rootCommand(_ *cobra.Command, _ []string) error {
 	return errors.New("operator binary must be called with one of the subcommands")
 }
@@ -92,8 +120,12 @@ func main() {
 		createOperatorCommandBuilder().Build(),
 		createCsiServerCommandBuilder().Build(),
 		createCsiProvisionerCommandBuilder().Build(),
+		createCsiDbCommandBuilder().Build(),
 		standalone.NewStandaloneCommand(),
 		createTroubleshootCommandBuilder().Build(),
+		createInjectCommandBuilder().Build(),
+		createMappingCommandBuilder().Build(),
+		createSupportArchiveCommandBuilder().Build(),
 	)
 
 	err := cmd.Execute()
@@ -0,0 +1,82 @@
+package mapping
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	use              = "namespace-mapping"
+	defaultNamespace = "dynatrace"
+)
+
+var (
+	dynakubeNamespace = ""
+)
+
+type CommandBuilder struct {
+	configProvider config.Provider
+	namespace      string
+}
+
+func NewMappingCommandBuilder() CommandBuilder {
+	return CommandBuilder{}
+}
+
+func (builder CommandBuilder) SetConfigProvider(provider config.Provider) CommandBuilder {
+	builder.configProvider = provider
+	return builder
+}
+
+func (builder CommandBuilder) SetNamespace(namespace string) CommandBuilder {
+	builder.namespace = namespace
+	return builder
+}
+
+func (builder CommandBuilder) getDynakubeNamespace() string {
+	if dynakubeNamespace != "" {
+		return dynakubeNamespace
+	}
+	if builder.namespace != "" {
+		return builder.namespace
+	}
+	return defaultNamespace
+}
+
+func (builder CommandBuilder) Build() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Show which namespaces are covered by the DynaKubes of the cluster",
+		RunE:  builder.buildRun(),
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&dynakubeNamespace, "namespace", "n", "", "Namespace of the DynaKubes, defaults to \"dynatrace\".")
+}
+
+func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		kubeConfig, err := builder.configProvider.GetConfig()
+		if err != nil {
+			return err
+		}
+		kubeClient, err := client.New(kubeConfig, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return err
+		}
+
+		mapping, err := getClusterMapping(cmd.Context(), kubeClient, builder.getDynakubeNamespace())
+		if err != nil {
+			return err
+		}
+		printClusterMapping(cmd.OutOrStdout(), mapping)
+		return nil
+	}
+}
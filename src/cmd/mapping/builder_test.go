@@ -0,0 +1,84 @@
+package mapping
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMappingCommandBuilder(t *testing.T) {
+	t.Run("build command", func(t *testing.T) {
+		mappingCommand := NewMappingCommandBuilder().Build()
+
+		assert.NotNil(t, mappingCommand)
+		assert.Equal(t, use, mappingCommand.Use)
+		assert.NotNil(t, mappingCommand.RunE)
+	})
+	t.Run("set config provider", func(t *testing.T) {
+		expectedProvider := &config.MockProvider{}
+		builder := NewMappingCommandBuilder().SetConfigProvider(expectedProvider)
+
+		assert.Equal(t, expectedProvider, builder.configProvider)
+	})
+	t.Run("dynakube namespace", func(t *testing.T) {
+		assert.Equal(t, defaultNamespace, NewMappingCommandBuilder().getDynakubeNamespace())
+		assert.Equal(t, "namespace", NewMappingCommandBuilder().SetNamespace("namespace").getDynakubeNamespace())
+	})
+}
+
+func TestClusterMapping(t *testing.T) {
+	clt := fake.NewClient(
+		createDynakube("app-dk", &dynatracev1beta1.ApplicationMonitoringSpec{}),
+		createDynakube("host-dk", nil),
+		createNamespace("app", "app-dk"),
+		createNamespace("dynatrace", ""),
+		createNamespace("other", ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "app"}},
+	)
+
+	mapping, err := getClusterMapping(context.Background(), clt, "dynatrace")
+	require.NoError(t, err)
+
+	require.Len(t, mapping.dynakubes, 1)
+	assert.Equal(t, "app-dk", mapping.dynakubes[0].name)
+	assert.Equal(t, []string{"app"}, mapping.dynakubes[0].mapping.Matched)
+	assert.Equal(t, []string{"dynatrace"}, mapping.dynakubes[0].mapping.Ignored)
+	assert.Equal(t, []string{"app"}, mapping.dynakubes[0].mapping.Uninjected)
+	assert.Equal(t, []string{"dynatrace", "other"}, mapping.unmapped)
+
+	var out bytes.Buffer
+	printClusterMapping(&out, mapping)
+	assert.Equal(t, "dynakube: app-dk\n"+
+		"  matched namespaces (1): app\n"+
+		"  ignored namespaces (1): dynatrace\n"+
+		"  namespaces with uninjected pods (1): app\n"+
+		"\n"+
+		"namespaces without dynakube (2): dynatrace, other\n", out.String())
+}
+
+func createDynakube(name string, appMonitoring *dynatracev1beta1.ApplicationMonitoringSpec) *dynatracev1beta1.DynaKube {
+	dynakube := &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dynatrace"}}
+	if appMonitoring != nil {
+		dynakube.Spec.OneAgent.ApplicationMonitoring = appMonitoring
+	} else {
+		dynakube.Spec.OneAgent.HostMonitoring = &dynatracev1beta1.HostInjectSpec{}
+	}
+	return dynakube
+}
+
+func createNamespace(name string, dynakubeName string) *corev1.Namespace {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if dynakubeName != "" {
+		namespace.Labels = map[string]string{dtwebhook.InjectionInstanceLabel: dynakubeName}
+	}
+	return namespace
+}
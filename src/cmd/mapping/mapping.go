@@ -0,0 +1,57 @@
+package mapping
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type dynakubeMapping struct {
+	name    string
+	mapping mapper.NamespaceMapping
+}
+
+type clusterMapping struct {
+	dynakubes []dynakubeMapping
+	// unmapped namespaces aren't matched by any dynakube
+	unmapped []string
+}
+
+func getClusterMapping(ctx context.Context, apiReader client.Reader, dynakubeNamespace string) (clusterMapping, error) {
+	var dynakubeList dynatracev1beta1.DynaKubeList
+	if err := apiReader.List(ctx, &dynakubeList, client.InNamespace(dynakubeNamespace)); err != nil {
+		return clusterMapping{}, errors.WithStack(err)
+	}
+	var namespaceList corev1.NamespaceList
+	if err := apiReader.List(ctx, &namespaceList); err != nil {
+		return clusterMapping{}, errors.WithStack(err)
+	}
+
+	result := clusterMapping{}
+	mapped := map[string]bool{}
+	for i := range dynakubeList.Items {
+		dynakube := &dynakubeList.Items[i]
+		if !dynakube.NeedAppInjection() {
+			continue
+		}
+		namespaceMapping, err := mapper.NewDynakubeMapper(ctx, nil, apiReader, dynakubeNamespace, dynakube).GetNamespaceMapping()
+		if err != nil {
+			return clusterMapping{}, errors.WithMessagef(err, "failed to map namespaces of dynakube %s", dynakube.Name)
+		}
+		for _, namespace := range namespaceMapping.Matched {
+			mapped[namespace] = true
+		}
+		result.dynakubes = append(result.dynakubes, dynakubeMapping{name: dynakube.Name, mapping: namespaceMapping})
+	}
+
+	for _, namespace := range namespaceList.Items {
+		if !mapped[namespace.Name] {
+			result.unmapped = append(result.unmapped, namespace.Name)
+		}
+	}
+	return result, nil
+}
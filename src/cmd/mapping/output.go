@@ -0,0 +1,25 @@
+package mapping
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func printClusterMapping(out io.Writer, mapping clusterMapping) {
+	if len(mapping.dynakubes) == 0 {
+		fmt.Fprintln(out, "no dynakube with application injection found")
+	}
+	for _, dynakube := range mapping.dynakubes {
+		fmt.Fprintf(out, "dynakube: %s\n", dynakube.name)
+		printNamespaces(out, "matched namespaces", dynakube.mapping.Matched)
+		printNamespaces(out, "ignored namespaces", dynakube.mapping.Ignored)
+		printNamespaces(out, "namespaces with uninjected pods", dynakube.mapping.Uninjected)
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "namespaces without dynakube (%d): %s\n", len(mapping.unmapped), strings.Join(mapping.unmapped, ", "))
+}
+
+func printNamespaces(out io.Writer, title string, namespaces []string) {
+	fmt.Fprintf(out, "  %s (%d): %s\n", title, len(namespaces), strings.Join(namespaces, ", "))
+}
@@ -2,7 +2,9 @@ package operator
 
 import (
 	cmdManager "github.com/Dynatrace/dynatrace-operator/src/cmd/manager"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/troubleshoot"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/diagnostics"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/nodes"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
@@ -78,6 +80,11 @@ func (provider operatorManagerProvider) CreateManager(namespace string, cfg *res
 		return nil, err
 	}
 
+	err = diagnostics.Add(mgr, namespace, troubleshoot.Diagnose)
+	if err != nil {
+		return nil, err
+	}
+
 	err = provider.addCertificateController(mgr, namespace)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,47 @@
+package supportarchive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// supportArchive writes the collected files as tar.gz
+type supportArchive struct {
+	tarWriter  *tar.Writer
+	gzipWriter *gzip.Writer
+	modTime    time.Time
+}
+
+func newSupportArchive(out io.Writer) *supportArchive {
+	gzipWriter := gzip.NewWriter(out)
+	return &supportArchive{
+		tarWriter:  tar.NewWriter(gzipWriter),
+		gzipWriter: gzipWriter,
+		modTime:    time.Now(),
+	}
+}
+
+func (archive *supportArchive) addFile(name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: archive.modTime,
+	}
+	if err := archive.tarWriter.WriteHeader(header); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := archive.tarWriter.Write(content)
+	return errors.WithStack(err)
+}
+
+func (archive *supportArchive) close() error {
+	if err := archive.tarWriter.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(archive.gzipWriter.Close())
+}
@@ -0,0 +1,158 @@
+package supportarchive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+const (
+	use              = "support-archive"
+	defaultNamespace = "dynatrace"
+	stdoutPath       = "-"
+
+	defaultTailLines   = 1000
+	csiMetadataTimeout = 10 * time.Second
+)
+
+var (
+	namespaceFlagValue   string
+	archivePathFlagValue string
+	tailLinesFlagValue   int64
+)
+
+type CommandBuilder struct {
+	configProvider config.Provider
+	namespace      string
+}
+
+func NewSupportArchiveCommandBuilder() CommandBuilder {
+	return CommandBuilder{}
+}
+
+func (builder CommandBuilder) SetConfigProvider(provider config.Provider) CommandBuilder {
+	builder.configProvider = provider
+	return builder
+}
+
+func (builder CommandBuilder) SetNamespace(namespace string) CommandBuilder {
+	builder.namespace = namespace
+	return builder
+}
+
+func (builder CommandBuilder) getNamespace() string {
+	if namespaceFlagValue != "" {
+		return namespaceFlagValue
+	}
+	if builder.namespace != "" {
+		return builder.namespace
+	}
+	return defaultNamespace
+}
+
+func (builder CommandBuilder) GetCluster(kubeConfig *rest.Config) (cluster.Cluster, error) {
+	return cluster.New(kubeConfig, clusterOptions)
+}
+
+func (builder CommandBuilder) Build() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Collect the logs, objects and troubleshoot results of the operator into a tar.gz for support cases",
+		RunE:  builder.buildRun(),
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&namespaceFlagValue, "namespace", "n", "", "Namespace of the operator, defaults to the namespace of the pod or \"dynatrace\".")
+	cmd.Flags().StringVar(&archivePathFlagValue, "archive-path", "",
+		"Path of the archive, defaults to a file in the temp directory, use \"-\" to write it to stdout.")
+	cmd.Flags().Int64Var(&tailLinesFlagValue, "tail", defaultTailLines, "Number of recent log lines collected per container.")
+}
+
+func clusterOptions(opts *cluster.Options) {
+	opts.Scheme = scheme.Scheme
+}
+
+func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		kubeConfig, err := builder.configProvider.GetConfig()
+		if err != nil {
+			return err
+		}
+		k8scluster, err := builder.GetCluster(kubeConfig)
+		if err != nil {
+			return err
+		}
+		clientSet, err := kubernetes.NewForConfig(kubeConfig)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		archivePath := getArchivePath(archivePathFlagValue, time.Now())
+		setLogOutput(archivePath, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		out, closeOut, err := openArchiveOutput(cmd.OutOrStdout(), archivePath)
+		if err != nil {
+			return err
+		}
+		defer closeOut()
+
+		archive := newSupportArchive(out)
+		collectorCtx := &collectorContext{
+			ctx:             context.Background(),
+			apiReader:       k8scluster.GetAPIReader(),
+			clientSet:       clientSet,
+			httpClient:      &http.Client{Timeout: csiMetadataTimeout},
+			namespace:       builder.getNamespace(),
+			tailLines:       tailLinesFlagValue,
+			csiMetadataPort: dtcsi.ProvisionerMetricsPort,
+			archive:         archive,
+		}
+		if err := collect(collectorCtx, getCollectors()); err != nil {
+			return err
+		}
+		if err := archive.close(); err != nil {
+			return err
+		}
+
+		if archivePath != stdoutPath {
+			fmt.Fprintln(cmd.OutOrStdout(), archivePath)
+		}
+		return nil
+	}
+}
+
+func getArchivePath(flagValue string, now time.Time) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("dynatrace-operator-support-archive-%s.tar.gz", now.UTC().Format("20060102-150405")))
+}
+
+// openArchiveOutput opens the file of the archive, or returns stdout
+func openArchiveOutput(stdout io.Writer, archivePath string) (io.Writer, func(), error) {
+	if archivePath == stdoutPath {
+		return stdout, func() {}, nil
+	}
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return file, func() { _ = file.Close() }, nil
+}
@@ -0,0 +1,40 @@
+package supportarchive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportArchiveCommandBuilder(t *testing.T) {
+	t.Run("build command", func(t *testing.T) {
+		supportArchiveCommand := NewSupportArchiveCommandBuilder().Build()
+
+		assert.NotNil(t, supportArchiveCommand)
+		assert.Equal(t, use, supportArchiveCommand.Use)
+		assert.NotNil(t, supportArchiveCommand.RunE)
+		assert.NotNil(t, supportArchiveCommand.Flags().Lookup("archive-path"))
+		assert.NotNil(t, supportArchiveCommand.Flags().Lookup("tail"))
+	})
+	t.Run("set config provider", func(t *testing.T) {
+		expectedProvider := &config.MockProvider{}
+		builder := NewSupportArchiveCommandBuilder().SetConfigProvider(expectedProvider)
+
+		assert.Equal(t, expectedProvider, builder.configProvider)
+	})
+	t.Run("namespace", func(t *testing.T) {
+		assert.Equal(t, defaultNamespace, NewSupportArchiveCommandBuilder().getNamespace())
+		assert.Equal(t, "namespace", NewSupportArchiveCommandBuilder().SetNamespace("namespace").getNamespace())
+	})
+}
+
+func TestGetArchivePath(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "archive.tar.gz", getArchivePath("archive.tar.gz", now))
+	assert.Equal(t, stdoutPath, getArchivePath(stdoutPath, now))
+	assert.Equal(t, "dynatrace-operator-support-archive-20220801-123000.tar.gz", filepath.Base(getArchivePath("", now)))
+}
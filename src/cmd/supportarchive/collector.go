@@ -0,0 +1,62 @@
+package supportarchive
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type collectorContext struct {
+	ctx             context.Context
+	apiReader       client.Reader
+	clientSet       kubernetes.Interface
+	httpClient      *http.Client
+	namespace       string
+	tailLines       int64
+	csiMetadataPort int
+	archive         *supportArchive
+	failures        []string
+}
+
+// collectorFunc adds files to the archive, failures of single objects are recorded and don't stop the collector
+type collectorFunc func(collectorCtx *collectorContext) error
+
+func getCollectors() []collectorFunc {
+	return []collectorFunc{
+		collectTroubleshootReports,
+		collectManifests,
+		collectInjectionSecrets,
+		collectNodeStatus,
+		collectOneAgentPodStatus,
+		collectLogs,
+		collectCSIMetadata,
+	}
+}
+
+// collect runs every collector, the failures are listed in the errors file of the archive instead of aborting the collection
+func collect(collectorCtx *collectorContext, collectors []collectorFunc) error {
+	for _, collector := range collectors {
+		if err := collector(collectorCtx); err != nil {
+			collectorCtx.recordFailure(err)
+		}
+	}
+	if len(collectorCtx.failures) == 0 {
+		return nil
+	}
+	return collectorCtx.archive.addFile(errorsFileName, []byte(strings.Join(collectorCtx.failures, "\n")+"\n"))
+}
+
+func (collectorCtx *collectorContext) recordFailure(err error) {
+	log.Info("failed to collect", "error", err.Error())
+	collectorCtx.failures = append(collectorCtx.failures, err.Error())
+}
+
+// addFile adds the file to the archive, failing to add a file is recorded
+func (collectorCtx *collectorContext) addFile(name string, content []byte) {
+	if err := collectorCtx.archive.addFile(name, content); err != nil {
+		collectorCtx.recordFailure(err)
+	}
+}
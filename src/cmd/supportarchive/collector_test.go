@@ -0,0 +1,116 @@
+package supportarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testNamespace    = "dynatrace"
+	testAppNamespace = "app"
+	testDynakube     = "dynakube"
+	testNodeName     = "node"
+)
+
+func TestCollect(t *testing.T) {
+	t.Run("files are added", func(t *testing.T) {
+		collectorCtx, archive := newTestCollectorContext()
+
+		require.NoError(t, collect(collectorCtx, []collectorFunc{
+			addingFile("first.txt", "first"),
+			addingFile("second.txt", "second"),
+		}))
+
+		files := readTestArchive(t, collectorCtx, archive)
+		assert.Equal(t, map[string]string{"first.txt": "first", "second.txt": "second"}, files)
+	})
+	t.Run("failures are listed in the archive", func(t *testing.T) {
+		collectorCtx, archive := newTestCollectorContext()
+
+		require.NoError(t, collect(collectorCtx, []collectorFunc{
+			failing("first failure"),
+			addingFile("file.txt", "content"),
+			failing("second failure"),
+		}))
+
+		files := readTestArchive(t, collectorCtx, archive)
+		assert.Equal(t, "content", files["file.txt"])
+		assert.Equal(t, "first failure\nsecond failure\n", files[errorsFileName])
+	})
+	t.Run("archive written to stdout stays valid when collectors fail", func(t *testing.T) {
+		defer func(previousLog logr.Logger) { log = previousLog }(log)
+		var stdout, stderr bytes.Buffer
+		setLogOutput(stdoutPath, &stdout, &stderr)
+		collectorCtx, _ := newTestCollectorContext()
+		collectorCtx.archive = newSupportArchive(&stdout)
+
+		require.NoError(t, collect(collectorCtx, []collectorFunc{
+			failing("failure"),
+			addingFile("file.txt", "content"),
+		}))
+
+		files := readTestArchive(t, collectorCtx, &stdout)
+		assert.Equal(t, "content", files["file.txt"])
+		assert.Contains(t, stderr.String(), "failed to collect")
+	})
+}
+
+func newTestCollectorContext(objects ...client.Object) (*collectorContext, *bytes.Buffer) {
+	archive := &bytes.Buffer{}
+	return &collectorContext{
+		ctx:        context.TODO(),
+		apiReader:  fake.NewClient(objects...),
+		clientSet:  fakeclientset.NewSimpleClientset(),
+		httpClient: http.DefaultClient,
+		namespace:  testNamespace,
+		tailLines:  defaultTailLines,
+		archive:    newSupportArchive(archive),
+	}, archive
+}
+
+// readTestArchive closes the archive and returns the contents of its files by their names
+func readTestArchive(t *testing.T, collectorCtx *collectorContext, archive *bytes.Buffer) map[string]string {
+	require.NoError(t, collectorCtx.archive.close())
+
+	gzipReader, err := gzip.NewReader(archive)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+}
+
+func addingFile(name string, content string) collectorFunc {
+	return func(collectorCtx *collectorContext) error {
+		collectorCtx.addFile(name, []byte(content))
+		return nil
+	}
+}
+
+func failing(message string) collectorFunc {
+	return func(_ *collectorContext) error {
+		return errors.New(message)
+	}
+}
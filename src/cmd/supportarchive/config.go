@@ -0,0 +1,37 @@
+package supportarchive
+
+import (
+	"io"
+
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const (
+	troubleshootDir = "troubleshoot"
+	manifestsDir    = "manifests"
+	logsDir         = "logs"
+	statusDir       = "status"
+	csiMetadataDir  = "csi-metadata"
+	errorsFileName  = "errors.txt"
+
+	// redactedValue replaces the values of secrets, like the tokens in the log of the standalone init
+	redactedValue = "***"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("support-archive")
+)
+
+// setLogOutput writes the log to stderr if the archive is written to stdout, as the log would corrupt the archive otherwise
+func setLogOutput(archivePath string, stdout io.Writer, stderr io.Writer) {
+	logOutput := stdout
+	if archivePath == stdoutPath {
+		logOutput = stderr
+	}
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	log = ctrlzap.New(ctrlzap.WriteTo(logOutput), ctrlzap.Encoder(zapcore.NewJSONEncoder(config))).WithName("support-archive")
+}
@@ -0,0 +1,75 @@
+package supportarchive
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// collectCSIMetadata adds the metadata overview, which the provisioner of each csi driver pod serves on its metrics port
+func collectCSIMetadata(collectorCtx *collectorContext) error {
+	var daemonSet appsv1.DaemonSet
+	err := collectorCtx.apiReader.Get(collectorCtx.ctx, client.ObjectKey{Name: dtcsi.DaemonSetName, Namespace: collectorCtx.namespace}, &daemonSet)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.WithMessagef(err, "failed to get daemonset '%s'", dtcsi.DaemonSetName)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(daemonSet.Spec.Selector)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var podList corev1.PodList
+	err = collectorCtx.apiReader.List(collectorCtx.ctx, &podList, client.InNamespace(collectorCtx.namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to list the pods of daemonset '%s'", dtcsi.DaemonSetName)
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		content, err := getCSIMetadata(collectorCtx, pod.Status.PodIP)
+		if err != nil {
+			collectorCtx.recordFailure(errors.WithMessagef(err, "failed to get the csi metadata of node '%s'", pod.Spec.NodeName))
+			continue
+		}
+		collectorCtx.addFile(path.Join(csiMetadataDir, pod.Spec.NodeName+".json"), content)
+	}
+	return nil
+}
+
+func getCSIMetadata(collectorCtx *collectorContext, podIP string) ([]byte, error) {
+	url := "http://" + net.JoinHostPort(podIP, strconv.Itoa(collectorCtx.csiMetadataPort)) + dtcsi.MetadataOverviewPath
+	request, err := http.NewRequestWithContext(collectorCtx.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	response, err := collectorCtx.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errors.New("the metadata overview isn't served, the provisioner has to run with --serve-metadata-overview")
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %d: %s", response.StatusCode, string(content))
+	}
+	return content, nil
+}
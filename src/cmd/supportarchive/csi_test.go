@@ -0,0 +1,99 @@
+package supportarchive
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectCSIMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != dtcsi.MetadataOverviewPath {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write([]byte(`{"dynakubes":[]}`))
+	}))
+	defer server.Close()
+	serverUrl, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverUrl.Port())
+	require.NoError(t, err)
+
+	t.Run("metadata of the csi pods", func(t *testing.T) {
+		collectorCtx, archive := newTestCollectorContext(
+			testBuildCSIDaemonSet(),
+			testBuildCSIPod("csi-1", testNodeName, serverUrl.Hostname()),
+			testBuildCSIPod("csi-2", "other-node", ""))
+		collectorCtx.csiMetadataPort = port
+
+		require.NoError(t, collectCSIMetadata(collectorCtx))
+
+		files := readTestArchive(t, collectorCtx, archive)
+		assert.Equal(t, `{"dynakubes":[]}`, files["csi-metadata/"+testNodeName+".json"])
+		assert.Len(t, files, 1)
+	})
+	t.Run("no csi driver", func(t *testing.T) {
+		collectorCtx, archive := newTestCollectorContext()
+
+		require.NoError(t, collectCSIMetadata(collectorCtx))
+
+		assert.Empty(t, readTestArchive(t, collectorCtx, archive))
+	})
+	t.Run("unreachable provisioner", func(t *testing.T) {
+		collectorCtx, archive := newTestCollectorContext(
+			testBuildCSIDaemonSet(),
+			testBuildCSIPod("csi-1", testNodeName, serverUrl.Hostname()))
+		collectorCtx.csiMetadataPort = port + 1
+
+		require.NoError(t, collectCSIMetadata(collectorCtx))
+
+		assert.Empty(t, readTestArchive(t, collectorCtx, archive))
+		require.Len(t, collectorCtx.failures, 1)
+		assert.Contains(t, collectorCtx.failures[0], testNodeName)
+	})
+	t.Run("metadata overview not served", func(t *testing.T) {
+		notServingServer := httptest.NewServer(http.NotFoundHandler())
+		defer notServingServer.Close()
+		notServingUrl, err := url.Parse(notServingServer.URL)
+		require.NoError(t, err)
+		notServingPort, err := strconv.Atoi(notServingUrl.Port())
+		require.NoError(t, err)
+		collectorCtx, archive := newTestCollectorContext(
+			testBuildCSIDaemonSet(),
+			testBuildCSIPod("csi-1", testNodeName, notServingUrl.Hostname()))
+		collectorCtx.csiMetadataPort = notServingPort
+
+		require.NoError(t, collectCSIMetadata(collectorCtx))
+
+		assert.Empty(t, readTestArchive(t, collectorCtx, archive))
+		require.Len(t, collectorCtx.failures, 1)
+		assert.Contains(t, collectorCtx.failures[0], "--serve-metadata-overview")
+	})
+}
+
+func testBuildCSIDaemonSet() *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DaemonSetName, Namespace: testNamespace},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "csi"}},
+		},
+	}
+}
+
+func testBuildCSIPod(name string, nodeName string, podIP string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: map[string]string{"app": "csi"}},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{PodIP: podIP},
+	}
+}
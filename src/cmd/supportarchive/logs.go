@@ -0,0 +1,54 @@
+package supportarchive
+
+import (
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// collectLogs adds the recent logs of every container in the namespace, for restarted containers also the logs of the previous run
+func collectLogs(collectorCtx *collectorContext) error {
+	var podList corev1.PodList
+	if err := collectorCtx.apiReader.List(collectorCtx.ctx, &podList, client.InNamespace(collectorCtx.namespace)); err != nil {
+		return errors.WithMessagef(err, "failed to list the pods of namespace '%s'", collectorCtx.namespace)
+	}
+
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			collectContainerLogs(collectorCtx, pod, status.Name, false)
+			if status.RestartCount > 0 {
+				collectContainerLogs(collectorCtx, pod, status.Name, true)
+			}
+		}
+	}
+	return nil
+}
+
+func collectContainerLogs(collectorCtx *collectorContext, pod corev1.Pod, container string, previous bool) {
+	logOptions := &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &collectorCtx.tailLines,
+		Previous:  previous,
+	}
+	stream, err := collectorCtx.clientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions).Stream(collectorCtx.ctx)
+	if err != nil {
+		collectorCtx.recordFailure(errors.WithMessagef(err, "failed to get the logs of container '%s' of pod '%s'", container, pod.Name))
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	content, err := io.ReadAll(stream)
+	if err != nil {
+		collectorCtx.recordFailure(errors.WithMessagef(err, "failed to read the logs of container '%s' of pod '%s'", container, pod.Name))
+		return
+	}
+
+	fileName := container + ".log"
+	if previous {
+		fileName = container + "-previous.log"
+	}
+	collectorCtx.addFile(path.Join(logsDir, pod.Name, fileName), content)
+}
@@ -0,0 +1,30 @@
+package supportarchive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectLogs(t *testing.T) {
+	collectorCtx, archive := newTestCollectorContext(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "operator"},
+				{Name: "sidecar", RestartCount: 1},
+			},
+		},
+	})
+
+	require.NoError(t, collectLogs(collectorCtx))
+
+	files := readTestArchive(t, collectorCtx, archive)
+	assert.Equal(t, "fake logs", files["logs/operator/operator.log"])
+	assert.Equal(t, "fake logs", files["logs/operator/sidecar.log"])
+	assert.Equal(t, "fake logs", files["logs/operator/sidecar-previous.log"])
+	assert.NotContains(t, files, "logs/operator/operator-previous.log")
+}
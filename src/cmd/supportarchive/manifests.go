@@ -0,0 +1,195 @@
+package supportarchive
+
+import (
+	"path"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// credentialArgPrefixes are the OneAgent arguments, whose values can contain credentials
+var credentialArgPrefixes = []string{"--set-proxy=", "--set-tenant-token="}
+
+// collectManifests adds the objects managed by the operator, the values of secrets and the credentials of dynakubes and workloads are redacted
+func collectManifests(collectorCtx *collectorContext) error {
+	namespacedLists := []client.ObjectList{
+		&dynatracev1beta1.DynaKubeList{},
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&appsv1.DaemonSetList{},
+		&corev1.PodList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.SecretList{},
+		&corev1.EventList{},
+	}
+	for _, list := range namespacedLists {
+		if err := collectorCtx.apiReader.List(collectorCtx.ctx, list, client.InNamespace(collectorCtx.namespace)); err != nil {
+			collectorCtx.recordFailure(errors.WithMessagef(err, "failed to list %T", list))
+			continue
+		}
+		objects, err := meta.ExtractList(list)
+		if err != nil {
+			collectorCtx.recordFailure(errors.WithStack(err))
+			continue
+		}
+		for _, object := range objects {
+			addManifest(collectorCtx, object.(client.Object))
+		}
+	}
+
+	clusterObjects := []client.Object{
+		&admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName}},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName}},
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DriverName}},
+	}
+	for _, object := range clusterObjects {
+		name := object.GetName()
+		err := collectorCtx.apiReader.Get(collectorCtx.ctx, client.ObjectKeyFromObject(object), object)
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			collectorCtx.recordFailure(errors.WithMessagef(err, "failed to get %T '%s'", object, name))
+			continue
+		}
+		addManifest(collectorCtx, object)
+	}
+	return nil
+}
+
+// addManifest adds the object as yaml, the files are grouped by the namespace and the kind of the objects
+func addManifest(collectorCtx *collectorContext, object client.Object) {
+	gvk, err := apiutil.GVKForObject(object, scheme.Scheme)
+	if err != nil {
+		collectorCtx.recordFailure(errors.WithStack(err))
+		return
+	}
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	object.SetManagedFields(nil)
+	if secret, isSecret := object.(*corev1.Secret); isSecret {
+		redactSecret(secret)
+	}
+	if dynakube, isDynakube := object.(*dynatracev1beta1.DynaKube); isDynakube {
+		redactDynakube(dynakube)
+	}
+	if podSpec := getPodSpec(object); podSpec != nil {
+		redactPodSpec(podSpec)
+		delete(object.GetAnnotations(), lastAppliedConfigAnnotation)
+	}
+
+	content, err := yaml.Marshal(object)
+	if err != nil {
+		collectorCtx.recordFailure(errors.WithStack(err))
+		return
+	}
+	namespace := object.GetNamespace()
+	if namespace == "" {
+		namespace = "cluster"
+	}
+	collectorCtx.addFile(path.Join(manifestsDir, namespace, strings.ToLower(gvk.Kind), object.GetName()+".yaml"), content)
+}
+
+// redactSecret replaces the values of the secret, so only the keys and whether a value is set remain
+func redactSecret(secret *corev1.Secret) {
+	for key, value := range secret.Data {
+		if len(value) > 0 {
+			secret.Data[key] = []byte(redactedValue)
+		}
+	}
+	for key, value := range secret.StringData {
+		if value != "" {
+			secret.StringData[key] = redactedValue
+		}
+	}
+	delete(secret.Annotations, lastAppliedConfigAnnotation)
+}
+
+// redactDynakube replaces the values of the dynakube, which can contain credentials, like the user and password of the proxy
+func redactDynakube(dynakube *dynatracev1beta1.DynaKube) {
+	if dynakube.Spec.Proxy != nil && dynakube.Spec.Proxy.Value != "" {
+		dynakube.Spec.Proxy.Value = redactedValue
+	}
+	if customProperties := dynakube.Spec.ActiveGate.CustomProperties; customProperties != nil && customProperties.Value != "" {
+		customProperties.Value = redactedValue
+	}
+	for _, hostInjectSpec := range []*dynatracev1beta1.HostInjectSpec{
+		dynakube.Spec.OneAgent.ClassicFullStack,
+		dynakube.Spec.OneAgent.HostMonitoring,
+		getCloudNativeHostInjectSpec(dynakube),
+	} {
+		if hostInjectSpec != nil {
+			redactArgs(hostInjectSpec.Args)
+		}
+	}
+	delete(dynakube.Annotations, lastAppliedConfigAnnotation)
+}
+
+func getCloudNativeHostInjectSpec(dynakube *dynatracev1beta1.DynaKube) *dynatracev1beta1.HostInjectSpec {
+	if dynakube.Spec.OneAgent.CloudNativeFullStack == nil {
+		return nil
+	}
+	return &dynakube.Spec.OneAgent.CloudNativeFullStack.HostInjectSpec
+}
+
+func getPodSpec(object client.Object) *corev1.PodSpec {
+	switch typedObject := object.(type) {
+	case *appsv1.DaemonSet:
+		return &typedObject.Spec.Template.Spec
+	case *appsv1.Deployment:
+		return &typedObject.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return &typedObject.Spec.Template.Spec
+	case *corev1.Pod:
+		return &typedObject.Spec
+	}
+	return nil
+}
+
+// redactPodSpec replaces the literal values of the env vars and the credential arguments of the containers,
+// e.g. the OneAgent daemonset sets the proxy of the dynakube as literal env var, references to secrets and configmaps remain
+func redactPodSpec(podSpec *corev1.PodSpec) {
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			redactContainer(&containers[i])
+		}
+	}
+	for i := range podSpec.EphemeralContainers {
+		redactContainer((*corev1.Container)(&podSpec.EphemeralContainers[i].EphemeralContainerCommon))
+	}
+}
+
+func redactContainer(container *corev1.Container) {
+	for i, env := range container.Env {
+		if env.Value != "" {
+			container.Env[i].Value = redactedValue
+		}
+	}
+	redactArgs(container.Command)
+	redactArgs(container.Args)
+}
+
+func redactArgs(args []string) {
+	for i, arg := range args {
+		for _, prefix := range credentialArgPrefixes {
+			if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
+				args[i] = prefix + redactedValue
+			}
+		}
+	}
+}
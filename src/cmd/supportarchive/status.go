@@ -0,0 +1,152 @@
+package supportarchive
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// collectInjectionSecrets lists which injection secrets exist in the mapped namespaces, their content isn't collected
+func collectInjectionSecrets(collectorCtx *collectorContext) error {
+	dynakubes, err := getDynakubes(collectorCtx)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	writer := tabwriter.NewWriter(&content, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "DYNAKUBE\tNAMESPACE\tSECRET\tPRESENT")
+	for _, dynakube := range dynakubes {
+		if !dynakube.NeedAppInjection() {
+			continue
+		}
+		namespaces, err := mapper.GetNamespacesForDynakube(collectorCtx.ctx, collectorCtx.apiReader, dynakube.Name)
+		if err != nil {
+			collectorCtx.recordFailure(errors.WithMessagef(err, "failed to list the namespaces of dynakube '%s'", dynakube.Name))
+			continue
+		}
+		for _, namespace := range namespaces {
+			for _, secretName := range []string{config.AgentInitSecretName, config.EnrichmentEndpointSecretName} {
+				name := dtwebhook.SecretNameForDynakube(secretName, namespace, dynakube.Name)
+				present, err := isSecretPresent(collectorCtx, namespace.Name, name)
+				if err != nil {
+					collectorCtx.recordFailure(err)
+					continue
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%t\n", dynakube.Name, namespace.Name, name, present)
+			}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	collectorCtx.addFile(path.Join(statusDir, "injection-secrets.txt"), content.Bytes())
+	return nil
+}
+
+func isSecretPresent(collectorCtx *collectorContext, namespace string, name string) (bool, error) {
+	var secret corev1.Secret
+	err := collectorCtx.apiReader.Get(collectorCtx.ctx, client.ObjectKey{Name: name, Namespace: namespace}, &secret)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.WithMessagef(err, "failed to query secret '%s:%s'", namespace, name)
+	}
+	return true, nil
+}
+
+// collectNodeStatus summarizes the conditions and taints of the nodes
+func collectNodeStatus(collectorCtx *collectorContext) error {
+	var nodeList corev1.NodeList
+	if err := collectorCtx.apiReader.List(collectorCtx.ctx, &nodeList); err != nil {
+		return errors.WithMessage(err, "failed to list the nodes")
+	}
+
+	var content bytes.Buffer
+	writer := tabwriter.NewWriter(&content, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "NODE\tREADY\tUNSCHEDULABLE\tPRESSURE\tTAINTS\tKUBELET")
+	for _, node := range nodeList.Items {
+		conditions := []string{}
+		ready := corev1.ConditionUnknown
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				ready = condition.Status
+			} else if condition.Status == corev1.ConditionTrue {
+				conditions = append(conditions, string(condition.Type))
+			}
+		}
+		taints := []string{}
+		for _, taint := range node.Spec.Taints {
+			taints = append(taints, taint.Key+":"+string(taint.Effect))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\t%s\t%s\n", node.Name, ready, node.Spec.Unschedulable,
+			joinOrNone(conditions), joinOrNone(taints), node.Status.NodeInfo.KubeletVersion)
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	collectorCtx.addFile(path.Join(statusDir, "nodes.txt"), content.Bytes())
+	return nil
+}
+
+// collectOneAgentPodStatus summarizes the state of the OneAgent pods, the full pods are part of the manifests
+func collectOneAgentPodStatus(collectorCtx *collectorContext) error {
+	var podList corev1.PodList
+	err := collectorCtx.apiReader.List(collectorCtx.ctx, &podList,
+		client.InNamespace(collectorCtx.namespace),
+		client.MatchingLabels{kubeobjects.AppNameLabel: kubeobjects.OneAgentComponentLabel})
+	if err != nil {
+		return errors.WithMessage(err, "failed to list the OneAgent pods")
+	}
+
+	var content bytes.Buffer
+	writer := tabwriter.NewWriter(&content, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "POD\tDYNAKUBE\tNODE\tPHASE\tREADY\tRESTARTS\tREASON")
+	for _, pod := range podList.Items {
+		var restarts int32
+		reasons := []string{}
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+			if status.State.Waiting != nil {
+				reasons = append(reasons, status.State.Waiting.Reason)
+			}
+			if status.LastTerminationState.Terminated != nil {
+				reasons = append(reasons, "last terminated: "+status.LastTerminationState.Terminated.Reason)
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%t\t%d\t%s\n", pod.Name, pod.Labels[kubeobjects.AppCreatedByLabel], pod.Spec.NodeName,
+			pod.Status.Phase, isPodReady(pod), restarts, joinOrNone(reasons))
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	collectorCtx.addFile(path.Join(statusDir, "oneagent-pods.txt"), content.Bytes())
+	return nil
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,92 @@
+package supportarchive
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectInjectionSecrets(t *testing.T) {
+	collectorCtx, archive := newTestCollectorContext(
+		&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testDynakube, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{}},
+			},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   testAppNamespace,
+			Labels: map[string]string{dtwebhook.InjectionInstanceLabel: testDynakube},
+		}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: config.AgentInitSecretName, Namespace: testAppNamespace},
+			Data:       map[string][]byte{"config": []byte("secret")},
+		})
+
+	require.NoError(t, collectInjectionSecrets(collectorCtx))
+
+	content := readTestArchive(t, collectorCtx, archive)["status/injection-secrets.txt"]
+	assert.Regexp(t, testDynakube+` +`+testAppNamespace+` +`+config.AgentInitSecretName+` +true`, content)
+	assert.Regexp(t, testDynakube+` +`+testAppNamespace+` +`+config.EnrichmentEndpointSecretName+` +false`, content)
+	assert.NotContains(t, content, "secret\n")
+}
+
+func TestCollectNodeStatus(t *testing.T) {
+	collectorCtx, archive := newTestCollectorContext(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: testNodeName},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints:        []corev1.Taint{{Key: "taint", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.24.0"},
+		},
+	})
+
+	require.NoError(t, collectNodeStatus(collectorCtx))
+
+	content := readTestArchive(t, collectorCtx, archive)["status/nodes.txt"]
+	assert.Regexp(t, testNodeName+` +False +true +DiskPressure +taint:NoSchedule +v1.24.0`, content)
+}
+
+func TestCollectOneAgentPodStatus(t *testing.T) {
+	collectorCtx, archive := newTestCollectorContext(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "oneagent-abc",
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					kubeobjects.AppNameLabel:      kubeobjects.OneAgentComponentLabel,
+					kubeobjects.AppCreatedByLabel: testDynakube,
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: testNodeName},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					RestartCount:         3,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}},
+				}},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: testNamespace}})
+
+	require.NoError(t, collectOneAgentPodStatus(collectorCtx))
+
+	content := readTestArchive(t, collectorCtx, archive)["status/oneagent-pods.txt"]
+	assert.Regexp(t, `oneagent-abc +`+testDynakube+` +`+testNodeName+` +Running +false +3 +CrashLoopBackOff,last terminated: Error`, content)
+	assert.NotContains(t, content, "operator")
+}
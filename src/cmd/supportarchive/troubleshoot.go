@@ -0,0 +1,37 @@
+package supportarchive
+
+import (
+	"bytes"
+	"path"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/cmd/troubleshoot"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// collectTroubleshootReports runs the checks of the troubleshoot command for every dynakube of the namespace
+func collectTroubleshootReports(collectorCtx *collectorContext) error {
+	dynakubes, err := getDynakubes(collectorCtx)
+	if err != nil {
+		return err
+	}
+	for _, dynakube := range dynakubes {
+		var report, reportLog bytes.Buffer
+		if err := troubleshoot.WriteJsonReport(collectorCtx.apiReader, collectorCtx.namespace, dynakube.Name, &report, &reportLog); err != nil {
+			collectorCtx.recordFailure(errors.WithMessagef(err, "failed to run the troubleshoot checks for dynakube '%s'", dynakube.Name))
+			continue
+		}
+		collectorCtx.addFile(path.Join(troubleshootDir, dynakube.Name+".json"), report.Bytes())
+		collectorCtx.addFile(path.Join(troubleshootDir, dynakube.Name+".log"), reportLog.Bytes())
+	}
+	return nil
+}
+
+func getDynakubes(collectorCtx *collectorContext) ([]dynatracev1beta1.DynaKube, error) {
+	var dynakubeList dynatracev1beta1.DynaKubeList
+	if err := collectorCtx.apiReader.List(collectorCtx.ctx, &dynakubeList, client.InNamespace(collectorCtx.namespace)); err != nil {
+		return nil, errors.WithMessagef(err, "failed to list the dynakubes of namespace '%s'", collectorCtx.namespace)
+	}
+	return dynakubeList.Items, nil
+}
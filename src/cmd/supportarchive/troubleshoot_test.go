@@ -0,0 +1,25 @@
+package supportarchive
+
+import (
+	"encoding/json"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCollectTroubleshootReports(t *testing.T) {
+	collectorCtx, archive := newTestCollectorContext(
+		&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: testDynakube, Namespace: testNamespace}})
+
+	require.NoError(t, collectTroubleshootReports(collectorCtx))
+
+	files := readTestArchive(t, collectorCtx, archive)
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(files["troubleshoot/"+testDynakube+".json"]), &report))
+	assert.Equal(t, testDynakube, report["dynakube"])
+	assert.NotEmpty(t, report["checks"])
+	assert.NotEmpty(t, files["troubleshoot/"+testDynakube+".log"])
+}
@@ -2,24 +2,35 @@ package troubleshoot
 
 import (
 	"net/http"
+	"os"
+	"strings"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/cmd/config"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/validation"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 )
 
 const (
-	use               = "troubleshoot"
-	dynakubeFlagName  = "dynakube"
-	namespaceFlagName = "namespace"
+	use                     = "troubleshoot"
+	dynakubeFlagName        = "dynakube"
+	namespaceFlagName       = "namespace"
+	outputFlagName          = "output"
+	outputFlagShorthand     = "o"
+	continueOnErrorFlagName = "continue-on-error"
+	podFlagName             = "pod"
 )
 
 var (
-	dynakubeFlagValue  string
-	namespaceFlagValue string
+	dynakubeFlagValue        string
+	namespaceFlagValue       string
+	outputFlagValue          string
+	continueOnErrorFlagValue bool
+	podFlagValue             string
 )
 
 type CommandBuilder struct {
@@ -41,8 +52,9 @@ func (builder CommandBuilder) GetCluster(kubeConfig *rest.Config) (cluster.Clust
 
 func (builder CommandBuilder) Build() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:  use,
-		RunE: builder.buildRun(),
+		Use:   use,
+		Short: "Check the setup of the operator, exits with 2 on warnings and 3 on failures",
+		RunE:  builder.buildRun(),
 	}
 
 	addFlags(cmd)
@@ -53,6 +65,11 @@ func (builder CommandBuilder) Build() *cobra.Command {
 func addFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&dynakubeFlagValue, dynakubeFlagName, "dynakube", "Specify a different Dynakube name.")
 	cmd.PersistentFlags().StringVar(&namespaceFlagValue, namespaceFlagName, "dynatrace", "Specify a different Namespace.")
+	cmd.PersistentFlags().StringVarP(&outputFlagValue, outputFlagName, outputFlagShorthand, outputFormatText,
+		"Print the results of the checks as json or yaml, the log is written to stderr then.")
+	cmd.PersistentFlags().BoolVar(&continueOnErrorFlagValue, continueOnErrorFlagName, false,
+		"Run every check which doesn't depend on a failed check, instead of stopping at the first failure.")
+	cmd.PersistentFlags().StringVar(&podFlagValue, podFlagName, "", "Check if a pod is injected, and if not, why. Specify it as namespace/name.")
 }
 
 func clusterOptions(opts *cluster.Options) {
@@ -61,6 +78,21 @@ func clusterOptions(opts *cluster.Options) {
 
 func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFlagValue); err != nil {
+			return err
+		}
+		podNamespace, podName, err := parsePodFlag(podFlagValue)
+		if err != nil {
+			return err
+		}
+		// the log is written to stderr if the results are printed in a structured format
+		logOutput := os.Stdout
+		if outputFlagValue != outputFormatText {
+			logOutput = os.Stderr
+		}
+		// the validation of the api url logs the reason, it's only set by the command, as the package of the validation is shared
+		validation.SetLogger(newTroubleshootLogger(logOutput, "[dynakube  ] "))
+
 		kubeConfig, err := builder.configProvider.GetConfig()
 		if err != nil {
 			return err
@@ -78,24 +110,41 @@ func (builder CommandBuilder) buildRun() func(*cobra.Command, []string) error {
 
 		apiReader := k8scluster.GetAPIReader()
 
-		tests := []troubleshootFunc{
-			checkNamespace,
-			checkDynakube,
-			checkDTClusterConnection,
-			checkImagePullable,
+		troubleshootCtx := troubleshootContext{
+			apiReader:     apiReader,
+			httpClient:    newHttpClient(),
+			namespaceName: namespaceFlagValue,
+			dynakubeName:  dynakubeFlagValue,
+			podNamespace:  podNamespace,
+			podName:       podName,
+			logOutput:     logOutput,
 		}
-
-		httpClient := &http.Client{
-			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		report := runChecks(&troubleshootCtx, getChecks(&troubleshootCtx), continueOnErrorFlagValue)
+		if err := printReport(cmd.OutOrStdout(), report, outputFlagValue); err != nil {
+			return err
 		}
 
-		troubleshootCtx := troubleshootContext{apiReader: apiReader, httpClient: httpClient, namespaceName: namespaceFlagValue, dynakubeName: dynakubeFlagValue}
-		for _, test := range tests {
-			if err := test(&troubleshootCtx); err != nil {
-				logErrorf(err.Error())
-				return nil
-			}
+		if exitCode := report.exitCode(); exitCode != exitCodePassed {
+			os.Exit(exitCode)
 		}
 		return nil
 	}
 }
+
+func newHttpClient() *http.Client {
+	return &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+// parsePodFlag splits the value of the pod flag into namespace and name, an empty value disables the pod check
+func parsePodFlag(value string) (string, string, error) {
+	if value == "" {
+		return "", "", nil
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid value '%s' of --%s, use namespace/name", value, podFlagName)
+	}
+	return parts[0], parts[1], nil
+}
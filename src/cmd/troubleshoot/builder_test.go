@@ -14,5 +14,7 @@ func TestTroubleshootCommandBuilder(t *testing.T) {
 		assert.NotNil(t, csiCommand)
 		assert.Equal(t, use, csiCommand.Use)
 		assert.NotNil(t, csiCommand.RunE)
+		assert.NotNil(t, csiCommand.PersistentFlags().Lookup(outputFlagName))
+		assert.NotNil(t, csiCommand.PersistentFlags().Lookup(continueOnErrorFlagName))
 	})
 }
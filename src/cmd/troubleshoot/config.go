@@ -1,9 +1,13 @@
 package troubleshoot
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -20,10 +24,18 @@ type troubleshootContext struct {
 	dynatraceApiSecret     corev1.Secret
 	pullSecret             corev1.Secret
 	proxySecret            corev1.Secret
+	certificatesSecret     corev1.Secret
+	injectedNamespaces     []corev1.Namespace // namespaces mapped to the dynakube
+	csiNodes               []string           // nodes with a pod of the csi driver daemonset
+	podNamespace           string             // namespace of the pod provided in the command line
+	podName                string             // name of the pod provided in the command line
+
+	dynatraceClientProperties *dynakube.DynatraceClientProperties
+	dynatraceHttpClient       *http.Client                 // transport of the dynatrace client, with the proxy and the trusted CAs of the dynakube
+	communicationHosts        []dtclient.CommunicationHost // communication hosts of the network zone of the dynakube
+
+	logOutput io.Writer   // output of the log of the checks, stdout if not set
+	log       logr.Logger // logger of the current check, switched by each check
 }
 
 type troubleshootFunc func(troubleshootCtx *troubleshootContext) error
-
-var (
-	log = newTroubleshootLogger("[          ]")
-)
@@ -0,0 +1,197 @@
+package troubleshoot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	eventReasonFailedMount = "FailedMount"
+
+	// diskUsageWarningPercent is the usage of the filesystem of the csi data dir, above which the troubleshoot command warns
+	diskUsageWarningPercent = 90
+)
+
+func checkCSI(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[csi       ] ")
+
+	troubleshootCtx.logNewTestf("checking if the csi driver is ready for '%s:%s' Dynakube ...", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+
+	if !troubleshootCtx.dynakube.NeedsCSIDriver() {
+		troubleshootCtx.logOkf("'%s:%s' Dynakube doesn't use the csi driver", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+		return nil
+	}
+
+	err := runSubChecks(troubleshootCtx, []troubleshootFunc{
+		checkCSIDriverRegistration,
+		checkCSIPods,
+		checkCSINodeStatus,
+		checkCSIMounts,
+	})
+	if err != nil && !isWarning(err) {
+		return errors.Wrap(err, "csi driver isn't ready")
+	}
+
+	troubleshootCtx.logOkf("csi driver is ready")
+	return err
+}
+
+func checkCSIDriverRegistration(troubleshootCtx *troubleshootContext) error {
+	var csiDriver storagev1.CSIDriver
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtcsi.DriverName}, &csiDriver); err != nil {
+		return errorWithMessagef(err, "csi driver '%s' isn't registered", dtcsi.DriverName)
+	}
+
+	troubleshootCtx.logInfof("csi driver '%s' is registered", dtcsi.DriverName)
+	return nil
+}
+
+// checkCSIPods checks that the csi driver pods are ready on the nodes the daemonset is scheduled to,
+// nodes excluded by its node selector or tolerations don't need a pod
+func checkCSIPods(troubleshootCtx *troubleshootContext) error {
+	var daemonSet appsv1.DaemonSet
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtcsi.DaemonSetName, Namespace: troubleshootCtx.namespaceName}, &daemonSet); err != nil {
+		return errorWithMessagef(err, "missing daemonset '%s:%s'", troubleshootCtx.namespaceName, dtcsi.DaemonSetName)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(daemonSet.Spec.Selector)
+	if err != nil {
+		return errorWithMessagef(err, "invalid selector of daemonset '%s:%s'", troubleshootCtx.namespaceName, dtcsi.DaemonSetName)
+	}
+
+	var podList corev1.PodList
+	if err := troubleshootCtx.apiReader.List(context.TODO(), &podList, client.InNamespace(troubleshootCtx.namespaceName), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return errorWithMessagef(err, "failed to list the pods of daemonset '%s:%s'", troubleshootCtx.namespaceName, dtcsi.DaemonSetName)
+	}
+	troubleshootCtx.csiNodes = []string{}
+	notReadyNodes := []string{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		troubleshootCtx.csiNodes = append(troubleshootCtx.csiNodes, pod.Spec.NodeName)
+		if !isPodReady(pod) {
+			notReadyNodes = append(notReadyNodes, pod.Spec.NodeName)
+		}
+	}
+	sort.Strings(troubleshootCtx.csiNodes)
+	sort.Strings(notReadyNodes)
+	if len(notReadyNodes) > 0 {
+		return errors.Errorf("no ready csi driver pod on the nodes '%s'", strings.Join(notReadyNodes, "', '"))
+	}
+	if daemonSet.Status.NumberReady < daemonSet.Status.DesiredNumberScheduled {
+		return errors.Errorf("only %d of %d csi driver pods of daemonset '%s:%s' are ready",
+			daemonSet.Status.NumberReady, daemonSet.Status.DesiredNumberScheduled, troubleshootCtx.namespaceName, dtcsi.DaemonSetName)
+	}
+
+	troubleshootCtx.logInfof("csi driver pods are ready on all %d nodes of the daemonset", len(troubleshootCtx.csiNodes))
+	return nil
+}
+
+// checkCSINodeStatus checks the status published by the provisioner of each node,
+// it has to have installed the current code modules version of the dynakube
+func checkCSINodeStatus(troubleshootCtx *troubleshootContext) error {
+	expectedVersion := troubleshootCtx.dynakube.CodeModulesVersion()
+	outdatedNodes := []string{}
+	fullNodes := []string{}
+
+	for _, nodeName := range troubleshootCtx.csiNodes {
+		var nodeStatus corev1.ConfigMap
+		err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtcsi.NodeStatusConfigMapName(nodeName), Namespace: troubleshootCtx.namespaceName}, &nodeStatus)
+		if k8serrors.IsNotFound(err) {
+			outdatedNodes = append(outdatedNodes, nodeName+" (no status)")
+			continue
+		} else if err != nil {
+			return errorWithMessagef(err, "failed to query the csi status of node '%s'", nodeName)
+		}
+
+		version, installed := nodeStatus.Data[dtcsi.NodeStatusVersionKey(troubleshootCtx.dynakubeName)]
+		if !installed || (expectedVersion != "" && version != expectedVersion) {
+			outdatedNodes = append(outdatedNodes, fmt.Sprintf("%s (version '%s')", nodeName, version))
+		}
+
+		if usage, ok := getDiskUsagePercent(nodeStatus); ok {
+			troubleshootCtx.logInfof("csi data dir of node '%s' uses %d%% of the disk", nodeName, usage)
+			if usage >= diskUsageWarningPercent {
+				fullNodes = append(fullNodes, fmt.Sprintf("%s (%d%%)", nodeName, usage))
+			}
+		}
+	}
+	if len(outdatedNodes) > 0 && expectedVersion == "" {
+		return errors.Errorf("code modules aren't installed on the nodes '%s'", strings.Join(outdatedNodes, "', '"))
+	} else if len(outdatedNodes) > 0 {
+		return errors.Errorf("code modules version '%s' isn't installed on the nodes '%s'", expectedVersion, strings.Join(outdatedNodes, "', '"))
+	}
+	if len(fullNodes) > 0 {
+		return newWarningf("disks of the csi data dir are almost full on the nodes '%s'", strings.Join(fullNodes, "', '"))
+	}
+
+	troubleshootCtx.logInfof("code modules are installed on all nodes")
+	return nil
+}
+
+// checkCSIMounts lists the nodes, where the kubelet failed to mount the volumes of the csi driver into pods
+func checkCSIMounts(troubleshootCtx *troubleshootContext) error {
+	var eventList corev1.EventList
+	if err := troubleshootCtx.apiReader.List(context.TODO(), &eventList, client.MatchingFields{"reason": eventReasonFailedMount}); err != nil {
+		return errorWithMessagef(err, "failed to list the events")
+	}
+
+	failedPodsPerNode := map[string]map[string]bool{}
+	for _, event := range eventList.Items {
+		if event.InvolvedObject.Kind != "Pod" || !strings.Contains(event.Message, dtcsi.DriverName) {
+			continue
+		}
+		node := event.Source.Host
+		if node == "" {
+			node = "unknown node"
+		}
+		if failedPodsPerNode[node] == nil {
+			failedPodsPerNode[node] = map[string]bool{}
+		}
+		failedPodsPerNode[node][event.InvolvedObject.Namespace+":"+event.InvolvedObject.Name] = true
+	}
+	if len(failedPodsPerNode) == 0 {
+		troubleshootCtx.logInfof("no failing mounts of the csi driver")
+		return nil
+	}
+
+	nodes := make([]string, 0, len(failedPodsPerNode))
+	for node, pods := range failedPodsPerNode {
+		nodes = append(nodes, fmt.Sprintf("%s (%d pods)", node, len(pods)))
+	}
+	sort.Strings(nodes)
+	return newWarningf("mounts of the csi driver are failing on the nodes '%s', check the events of the pods", strings.Join(nodes, "', '"))
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func getDiskUsagePercent(nodeStatus corev1.ConfigMap) (uint64, bool) {
+	used, err := strconv.ParseUint(nodeStatus.Data[dtcsi.NodeStatusDataDirUsedKey], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	total, err := strconv.ParseUint(nodeStatus.Data[dtcsi.NodeStatusDataDirTotalKey], 10, 64)
+	if err != nil || total == 0 {
+		return 0, false
+	}
+	return used * 100 / total, true
+}
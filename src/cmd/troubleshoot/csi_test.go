@@ -0,0 +1,188 @@
+package troubleshoot
+
+import (
+	"strconv"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testNodeName      = "node"
+	testOtherNodeName = "other-node"
+)
+
+func TestCSI(t *testing.T) {
+	dynakube := testNewDynakubeBuilder(testNamespace, testDynakube).withCloudNativeFullStackImageVersion(testVersion).build()
+	dynakube.Status.LatestAgentVersionUnixPaas = testVersion
+
+	t.Run("csi driver is ready", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10))...)
+
+		assert.NoError(t, checkCSI(troubleshootCtx))
+		assert.Len(t, troubleshootCtx.csiNodes, 2)
+	})
+	t.Run("csi driver isn't registered", func(t *testing.T) {
+		objects := testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10))
+		troubleshootCtx := newCSITroubleshootContext(dynakube, objects[1:]...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "isn't registered")
+	})
+	t.Run("csi pod isn't ready", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionFalse),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10))...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no ready csi driver pod on the nodes '"+testOtherNodeName+"'")
+	})
+	t.Run("nodes excluded by the daemonset are ignored", func(t *testing.T) {
+		taintedNode := testBuildNode("tainted-node")
+		taintedNode.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "other", Effect: corev1.TaintEffectNoSchedule}}
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10),
+			taintedNode)...)
+
+		assert.NoError(t, checkCSI(troubleshootCtx))
+		assert.Equal(t, []string{testNodeName, testOtherNodeName}, troubleshootCtx.csiNodes)
+	})
+	t.Run("csi pod isn't created yet", func(t *testing.T) {
+		objects := testBuildCSIObjects(testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10))
+		objects[1].(*appsv1.DaemonSet).Status.NumberReady = 1
+		troubleshootCtx := newCSITroubleshootContext(dynakube, objects...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 1 of 2 csi driver pods")
+	})
+	t.Run("version isn't installed", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, "1.247", 1, 10))...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version '"+testVersion+"' isn't installed on the nodes '"+testOtherNodeName+" (version '1.247')'")
+	})
+	t.Run("node status is missing", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue))...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), testOtherNodeName+" (no status)")
+	})
+	t.Run("full disk is a warning", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 95, 100))...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.True(t, isWarning(err))
+		assert.Contains(t, err.Error(), testOtherNodeName+" (95%)")
+	})
+	t.Run("failing mounts are a warning", func(t *testing.T) {
+		troubleshootCtx := newCSITroubleshootContext(dynakube, testBuildCSIObjects(
+			testBuildCSIPod(testOtherNodeName, corev1.ConditionTrue),
+			testBuildCSINodeStatus(testOtherNodeName, testVersion, 1, 10),
+			testBuildFailedMountEvent("event-1", "pod-1", dtcsi.DriverName),
+			testBuildFailedMountEvent("event-2", "pod-1", dtcsi.DriverName),
+			testBuildFailedMountEvent("event-3", "pod-2", dtcsi.DriverName),
+			testBuildFailedMountEvent("event-4", "pod-3", "other.csi.driver"))...)
+
+		err := checkCSI(troubleshootCtx)
+		require.Error(t, err)
+		assert.True(t, isWarning(err))
+		assert.Contains(t, err.Error(), "failing on the nodes '"+testNodeName+" (2 pods)'")
+	})
+	t.Run("no csi driver", func(t *testing.T) {
+		classicDynakube := testNewDynakubeBuilder(testNamespace, testDynakube).withClassicFullStackImageVersion(testVersion).build()
+		troubleshootCtx := newCSITroubleshootContext(classicDynakube)
+
+		assert.NoError(t, checkCSI(troubleshootCtx))
+	})
+}
+
+func newCSITroubleshootContext(dynakube *dynatracev1beta1.DynaKube, objects ...client.Object) *troubleshootContext {
+	troubleshootCtx := newWebhookTroubleshootContext(append(objects, dynakube)...)
+	troubleshootCtx.dynakube = *dynakube
+	return troubleshootCtx
+}
+
+// testBuildCSIObjects builds a csi driver with two nodes, the first one is ready, the objects of the second one are provided
+func testBuildCSIObjects(otherObjects ...client.Object) []client.Object {
+	return append([]client.Object{
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DriverName}},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DaemonSetName, Namespace: testNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "csi"}},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 2},
+		},
+		testBuildNode(testNodeName),
+		testBuildNode(testOtherNodeName),
+		testBuildCSIPod(testNodeName, corev1.ConditionTrue),
+		testBuildCSINodeStatus(testNodeName, testVersion, 1, 10),
+	}, otherObjects...)
+}
+
+func testBuildNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func testBuildCSIPod(nodeName string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dtcsi.DaemonSetName + "-" + nodeName,
+			Namespace: testNamespace,
+			Labels:    map[string]string{"app": "csi"},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+		},
+	}
+}
+
+func testBuildCSINodeStatus(nodeName string, version string, usedBytes int, totalBytes int) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: dtcsi.NodeStatusConfigMapName(nodeName), Namespace: testNamespace},
+		Data: map[string]string{
+			dtcsi.NodeStatusVersionKey(testDynakube): version,
+			dtcsi.NodeStatusDataDirUsedKey:           strconv.Itoa(usedBytes),
+			dtcsi.NodeStatusDataDirTotalKey:          strconv.Itoa(totalBytes),
+		},
+	}
+}
+
+func testBuildFailedMountEvent(name string, podName string, driverName string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testAppNamespace},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      podName,
+			Namespace: testAppNamespace,
+		},
+		Reason:  eventReasonFailedMount,
+		Message: "MountVolume.SetUp failed for volume \"oneagent-bin\" : rpc error: code = Unknown desc = driver " + driverName + " failed",
+		Source:  corev1.EventSource{Host: testNodeName},
+	}
+}
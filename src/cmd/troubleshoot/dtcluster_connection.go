@@ -2,16 +2,16 @@ package troubleshoot
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
 )
 
 func checkDTClusterConnection(troubleshootCtx *troubleshootContext) error {
-	log = newTroubleshootLogger("[dtcluster ] ")
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[dtcluster ] ")
 
-	logNewTestf("checking if tenant is accessible ...")
+	troubleshootCtx.logNewTestf("checking if tenant is accessible ...")
 
 	tests := []troubleshootFunc{
 		checkConnection,
@@ -19,12 +19,11 @@ func checkDTClusterConnection(troubleshootCtx *troubleshootContext) error {
 
 	for _, test := range tests {
 		if err := test(troubleshootCtx); err != nil {
-			logErrorf(err.Error())
-			return fmt.Errorf("tenant isn't  accessible")
+			return errors.Wrap(err, "tenant isn't accessible")
 		}
 	}
 
-	logOkf("tenant is accessible")
+	troubleshootCtx.logOkf("tenant is accessible")
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/webhook/validation"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -18,9 +19,9 @@ const (
 )
 
 func checkDynakube(troubleshootCtx *troubleshootContext) error {
-	log = newTroubleshootLogger("[dynakube  ] ")
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[dynakube  ] ")
 
-	logNewTestf("checking if '%s:%s' Dynakube is configured correctly", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+	troubleshootCtx.logNewTestf("checking if '%s:%s' Dynakube is configured correctly", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
 
 	tests := []troubleshootFunc{
 		checkDynakubeCrdExists,
@@ -39,12 +40,11 @@ func checkDynakube(troubleshootCtx *troubleshootContext) error {
 
 	for _, test := range tests {
 		if err := test(troubleshootCtx); err != nil {
-			logErrorf(err.Error())
-			return fmt.Errorf("'%s:%s' Dynakube isn't valid", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+			return errors.Wrapf(err, "'%s:%s' Dynakube isn't valid", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
 		}
 	}
 
-	logOkf("'%s:%s' Dynakube is valid", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+	troubleshootCtx.logOkf("'%s:%s' Dynakube is valid", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
 	return nil
 }
 
@@ -53,7 +53,7 @@ func checkDynakubeCrdExists(troubleshootCtx *troubleshootContext) error {
 	if err := troubleshootCtx.apiReader.List(context.TODO(), dynakubeList, &client.ListOptions{Namespace: troubleshootCtx.namespaceName}); err != nil {
 		return errorWithMessagef(err, "CRD for Dynakube missing")
 	}
-	logInfof("CRD for Dynakube exists")
+	troubleshootCtx.logInfof("CRD for Dynakube exists")
 	return nil
 }
 
@@ -64,14 +64,13 @@ func getSelectedDynakubeIfItExists(troubleshootCtx *troubleshootContext) error {
 	} else {
 		troubleshootCtx.dynakube = dynakube
 	}
-	logInfof("using '%s:%s' Dynakube", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+	troubleshootCtx.logInfof("using '%s:%s' Dynakube", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
 	return nil
 }
 
 func checkApiUrl(troubleshootCtx *troubleshootContext) error {
-	logInfof("checking if api url is valid")
+	troubleshootCtx.logInfof("checking if api url is valid")
 
-	validation.SetLogger(log)
 	if validation.NoApiUrl(nil, &troubleshootCtx.dynakube) != "" {
 		return fmt.Errorf("api url is invalid")
 	}
@@ -79,12 +78,12 @@ func checkApiUrl(troubleshootCtx *troubleshootContext) error {
 		return fmt.Errorf("api url is invalid")
 	}
 
-	logInfof("api url is valid")
+	troubleshootCtx.logInfof("api url is valid")
 	return nil
 }
 
 func evaluateDynatraceApiSecretName(troubleshootCtx *troubleshootContext) error {
-	logInfof("checking if secret is valid")
+	troubleshootCtx.logInfof("checking if secret is valid")
 
 	// use dynakube name or tokens value if set
 	troubleshootCtx.dynatraceApiSecretName = troubleshootCtx.dynakubeName
@@ -96,13 +95,13 @@ func evaluateDynatraceApiSecretName(troubleshootCtx *troubleshootContext) error
 }
 
 func getDynatraceApiSecretIfItExists(troubleshootCtx *troubleshootContext) error {
-	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, log)
+	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, troubleshootCtx.logger())
 	if secret, err := query.Get(types.NamespacedName{Namespace: troubleshootCtx.namespaceName, Name: troubleshootCtx.dynatraceApiSecretName}); err != nil {
 		return errorWithMessagef(err, "'%s:%s' secret is missing", troubleshootCtx.namespaceName, troubleshootCtx.dynatraceApiSecretName)
 	} else {
 		troubleshootCtx.dynatraceApiSecret = secret
 	}
-	logInfof("'%s:%s' secret exists", troubleshootCtx.namespaceName, troubleshootCtx.dynatraceApiSecretName)
+	troubleshootCtx.logInfof("'%s:%s' secret exists", troubleshootCtx.namespaceName, troubleshootCtx.dynatraceApiSecretName)
 	return nil
 }
 
@@ -116,24 +115,24 @@ func checkIfDynatraceApiSecretHasApiToken(troubleshootCtx *troubleshootContext)
 		return fmt.Errorf("'apiToken' token is empty  in '%s:%s' secret", troubleshootCtx.namespaceName, troubleshootCtx.dynatraceApiSecretName)
 	}
 
-	logInfof("secret token 'apiToken' exists")
+	troubleshootCtx.logInfof("secret token 'apiToken' exists")
 	return nil
 }
 
 func evaluatePullSecret(troubleshootCtx *troubleshootContext) error {
 	if troubleshootCtx.dynakube.Spec.CustomPullSecret == "" {
 		troubleshootCtx.pullSecretName = troubleshootCtx.dynakubeName + pullSecretSuffix
-		logInfof("customPullSecret not used")
+		troubleshootCtx.logInfof("customPullSecret not used")
 		return nil
 	}
 
 	troubleshootCtx.pullSecretName = troubleshootCtx.dynakube.Spec.CustomPullSecret
-	logInfof("'%s:%s' pull secret is used", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
+	troubleshootCtx.logInfof("'%s:%s' pull secret is used", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
 	return nil
 }
 
 func getPullSecretIfItExists(troubleshootCtx *troubleshootContext) error {
-	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, log)
+	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, troubleshootCtx.logger())
 	secret, err := query.Get(types.NamespacedName{Namespace: troubleshootCtx.namespaceName, Name: troubleshootCtx.pullSecretName})
 	if err != nil {
 		return errorWithMessagef(err, "'%s:%s' pull secret is missing", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
@@ -141,7 +140,7 @@ func getPullSecretIfItExists(troubleshootCtx *troubleshootContext) error {
 		troubleshootCtx.pullSecret = secret
 	}
 
-	logInfof("pull secret '%s:%s' exists", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
+	troubleshootCtx.logInfof("pull secret '%s:%s' exists", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
 	return nil
 }
 
@@ -150,18 +149,18 @@ func checkPullSecretHasRequiredTokens(troubleshootCtx *troubleshootContext) erro
 		return errorWithMessagef(err, "invalid '%s:%s' secret", troubleshootCtx.namespaceName, troubleshootCtx.pullSecretName)
 	}
 
-	logInfof("secret token '%s' exists", dtpullsecret.DockerConfigJson)
+	troubleshootCtx.logInfof("secret token '%s' exists", dtpullsecret.DockerConfigJson)
 	return nil
 }
 
 func evaluateProxySecret(troubleshootCtx *troubleshootContext) error {
 	if troubleshootCtx.dynakube.Spec.Proxy == nil || troubleshootCtx.dynakube.Spec.Proxy.ValueFrom == "" {
-		logInfof("proxy secret not used")
+		troubleshootCtx.logInfof("proxy secret not used")
 		return nil
 	}
 
 	troubleshootCtx.proxySecretName = troubleshootCtx.dynakube.Spec.Proxy.ValueFrom
-	logInfof("'%s:%s' proxy secret is used", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
+	troubleshootCtx.logInfof("'%s:%s' proxy secret is used", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
 	return nil
 }
 
@@ -170,14 +169,14 @@ func getProxySecretIfItExists(troubleshootCtx *troubleshootContext) error {
 		return nil
 	}
 
-	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, log)
+	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, troubleshootCtx.logger())
 	if secret, err := query.Get(types.NamespacedName{Namespace: troubleshootCtx.namespaceName, Name: troubleshootCtx.proxySecretName}); err != nil {
 		return errorWithMessagef(err, "'%s:%s' proxy secret is missing", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
 	} else {
 		troubleshootCtx.proxySecret = secret
 	}
 
-	logInfof("custom pull secret '%s:%s' exists", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
+	troubleshootCtx.logInfof("custom pull secret '%s:%s' exists", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
 	return nil
 }
 
@@ -190,6 +189,6 @@ func checkProxySecretHasRequiredTokens(troubleshootCtx *troubleshootContext) err
 		return errorWithMessagef(err, "invalid '%s:%s' secret", troubleshootCtx.namespaceName, troubleshootCtx.proxySecretName)
 	}
 
-	logInfof("secret token '%s' exists", dtclient.CustomProxySecretKey)
+	troubleshootCtx.logInfof("secret token '%s' exists", dtclient.CustomProxySecretKey)
 	return nil
 }
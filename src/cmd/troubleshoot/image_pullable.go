@@ -39,30 +39,37 @@ type Auths struct {
 }
 
 func checkImagePullable(troubleshootCtx *troubleshootContext) error {
-	log = newTroubleshootLogger("[imagepull ] ")
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[imagepull ] ")
 
 	if err := addProxy(troubleshootCtx); err != nil {
 		return err
 	}
 
+	// a warning of one component must not hide the failure of the other one
+	var warning error
+
 	if troubleshootCtx.dynakube.NeedsOneAgent() {
 		err := checkOneAgentImagePullable(troubleshootCtx)
-		if err != nil {
+		if isWarning(err) {
+			warning = err
+		} else if err != nil {
 			return err
 		}
 	}
 
 	if troubleshootCtx.dynakube.NeedsActiveGate() {
 		err := checkActiveGateImagePullable(troubleshootCtx)
-		if err != nil {
+		if isWarning(err) {
+			warning = err
+		} else if err != nil {
 			return err
 		}
 	}
-	return nil
+	return warning
 }
 
 func checkOneAgentImagePullable(troubleshootCtx *troubleshootContext) error {
-	logNewTestf("checking if OneAgent image is pullable ...")
+	troubleshootCtx.logNewTestf("checking if OneAgent image is pullable ...")
 
 	pullSecret, err := getPullSecretToken(troubleshootCtx)
 	if err != nil {
@@ -74,7 +81,7 @@ func checkOneAgentImagePullable(troubleshootCtx *troubleshootContext) error {
 		return err
 	}
 
-	if err = checkComponentImagePullable(troubleshootCtx.httpClient, "OneAgent", pullSecret, dynakubeOneAgentImage); err != nil {
+	if err = checkComponentImagePullable(troubleshootCtx, "OneAgent", pullSecret, dynakubeOneAgentImage); err != nil {
 		return err
 	}
 
@@ -82,7 +89,7 @@ func checkOneAgentImagePullable(troubleshootCtx *troubleshootContext) error {
 }
 
 func checkActiveGateImagePullable(troubleshootCtx *troubleshootContext) error {
-	logNewTestf("checking if ActiveGate image is pullable ...")
+	troubleshootCtx.logNewTestf("checking if ActiveGate image is pullable ...")
 
 	pullSecret, err := getPullSecretToken(troubleshootCtx)
 	if err != nil {
@@ -94,64 +101,71 @@ func checkActiveGateImagePullable(troubleshootCtx *troubleshootContext) error {
 		return err
 	}
 
-	if err = checkComponentImagePullable(troubleshootCtx.httpClient, "ActiveGate", pullSecret, dynakubeActiveGateImage); err != nil {
+	if err = checkComponentImagePullable(troubleshootCtx, "ActiveGate", pullSecret, dynakubeActiveGateImage); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func checkComponentImagePullable(httpClient *http.Client, componentName string, pullSecret string, componentImage string) error {
+func checkComponentImagePullable(troubleshootCtx *troubleshootContext, componentName string, pullSecret string, componentImage string) error {
 	// split activegate image into registry and image name
 	componentRegistry, componentImage, componentVersion, err := splitImageName(componentImage)
 	if err != nil {
 		return err
 	}
-	logInfof("using '%s' on '%s' with version '%s' as %s image", componentImage, componentRegistry, componentVersion, componentName)
+	troubleshootCtx.logInfof("using '%s' on '%s' with version '%s' as %s image", componentImage, componentRegistry, componentVersion, componentName)
 
 	imageWorks := false
+	unreachableRegistries := []string{}
 
 	// parse docker config
 	var result Auths
 	json.Unmarshal([]byte(pullSecret), &result)
 
 	for registry, endpoint := range result.Auths {
-		logInfof("checking images for registry '%s'", registry)
+		troubleshootCtx.logInfof("checking images for registry '%s'", registry)
 
 		apiToken := base64.StdEncoding.EncodeToString([]byte(endpoint.Username + ":" + endpoint.Password))
 
-		if statusCode, err := connectToDockerRegistry(httpClient, "HEAD", "https://"+registry+"/v2/", "Basic", apiToken); err != nil {
-			logErrorf("registry '%s' unreachable", registry)
+		if statusCode, err := connectToDockerRegistry(troubleshootCtx.httpClient, "HEAD", "https://"+registry+"/v2/", "Basic", apiToken); err != nil {
+			troubleshootCtx.logErrorf("registry '%s' unreachable", registry)
+			unreachableRegistries = append(unreachableRegistries, registry)
 			continue
 		} else {
 			if statusCode != 200 {
-				logErrorf("registry '%s' unreachable (%d)", registry, statusCode)
+				troubleshootCtx.logErrorf("registry '%s' unreachable (%d)", registry, statusCode)
+				unreachableRegistries = append(unreachableRegistries, registry)
 				continue
 			} else {
-				logInfof("registry '%s' is accessible", registry)
+				troubleshootCtx.logInfof("registry '%s' is accessible", registry)
 			}
 		}
 
-		if statusCode, err := connectToDockerRegistry(httpClient, "HEAD", "https://"+registry+"/v2/"+componentImage+"/manifests/"+componentVersion, "Basic", apiToken); err != nil {
-			logErrorf("registry '%s' unreachable", registry)
+		if statusCode, err := connectToDockerRegistry(troubleshootCtx.httpClient, "HEAD", "https://"+registry+"/v2/"+componentImage+"/manifests/"+componentVersion, "Basic", apiToken); err != nil {
+			troubleshootCtx.logErrorf("registry '%s' unreachable", registry)
 			continue
 		} else {
 			if statusCode != 200 {
-				logErrorf("image '%s' with version '%s' not found on registry '%s'", componentImage, componentVersion, registry)
+				troubleshootCtx.logErrorf("image '%s' with version '%s' not found on registry '%s'", componentImage, componentVersion, registry)
 				continue
 			} else {
-				logInfof("image '%s' with version '%s' exists on registry '%s", componentImage, componentVersion, registry)
+				troubleshootCtx.logInfof("image '%s' with version '%s' exists on registry '%s", componentImage, componentVersion, registry)
 			}
 		}
 
 		imageWorks = true
 	}
 
-	if imageWorks {
-		logOkf("%s image '%s' found", componentName, componentRegistry+"/"+componentImage)
-	} else {
+	if !imageWorks {
 		return fmt.Errorf("%s image '%s' missing", componentName, componentRegistry+"/"+componentImage)
 	}
+
+	troubleshootCtx.logOkf("%s image '%s' found", componentName, componentRegistry+"/"+componentImage)
+	if len(unreachableRegistries) > 0 {
+		return newWarningf("%s image '%s' found, but the registries '%s' of the pull secret are unreachable",
+			componentName, componentRegistry+"/"+componentImage, strings.Join(unreachableRegistries, "', '"))
+	}
 	return nil
 }
 
@@ -175,7 +189,7 @@ func addProxy(troubleshootCtx *troubleshootContext) error {
 		}
 		t := troubleshootCtx.httpClient.Transport.(*http.Transport)
 		t.Proxy = http.ProxyURL(p)
-		logInfof("using  '%s' proxy to connect to the registry", p.Host)
+		troubleshootCtx.logInfof("using  '%s' proxy to connect to the registry", p.Host)
 	}
 
 	return nil
@@ -241,7 +255,7 @@ func getOneAgentImageEndpoint(troubleshootCtx *troubleshootContext) string {
 		imageEndpoint = imageEndpoint + ":" + version
 	}
 
-	logInfof("OneAgent image endpoint '%s'", imageEndpoint)
+	troubleshootCtx.logInfof("OneAgent image endpoint '%s'", imageEndpoint)
 	return imageEndpoint
 }
 
@@ -256,7 +270,7 @@ func getActiveGateImageEndpoint(troubleshootCtx *troubleshootContext) string {
 		imageEndpoint = troubleshootCtx.dynakube.Spec.ActiveGate.Image
 	}
 
-	logInfof("ActiveGate image endpoint '%s'", imageEndpoint)
+	troubleshootCtx.logInfof("ActiveGate image endpoint '%s'", imageEndpoint)
 	return imageEndpoint
 }
 
@@ -284,11 +298,9 @@ func splitImageName(imageName string) (registry string, image string, version st
 	if len(fields) == 1 || len(fields) >= 2 && fields[1] == "" {
 		// no version set, default to latest
 		version = "latest"
-		logInfof("using latest image version")
 	} else if len(fields) >= 2 {
 		image = fields[0]
 		version = fields[1]
-		logInfof("using custom image version")
 	} else {
 		err = fmt.Errorf("invalid version of the image {\"image\": \"%s\"}", image)
 	}
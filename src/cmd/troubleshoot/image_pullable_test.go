@@ -149,7 +149,7 @@ func TestImagePullableDockerClient(t *testing.T) {
 		authsBytes, err := json.Marshal(auths)
 		assert.NoErrorf(t, err, "fix it please")
 
-		err = checkComponentImagePullable(dockerServer.Client(), "ActiveGate", string(authsBytes), server+"/"+testImage+":"+testVersion)
+		err = checkComponentImagePullable(&troubleshootContext{httpClient: dockerServer.Client()}, "ActiveGate", string(authsBytes), server+"/"+testImage+":"+testVersion)
 		assert.NoErrorf(t, err, "unexpected error")
 	})
 }
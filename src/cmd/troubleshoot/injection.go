@@ -0,0 +1,137 @@
+package troubleshoot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func checkInjection(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[injection ] ")
+
+	troubleshootCtx.logNewTestf("checking if the namespaces of '%s:%s' Dynakube are prepared for the injection ...", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+
+	if !troubleshootCtx.dynakube.NeedAppInjection() {
+		troubleshootCtx.logOkf("'%s:%s' Dynakube doesn't use application injection", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+		return nil
+	}
+
+	err := runSubChecks(troubleshootCtx, []troubleshootFunc{
+		checkNamespaceInjectionLabels,
+		checkInjectionSecrets,
+	})
+	if err != nil && !isWarning(err) {
+		return errors.Wrap(err, "namespaces aren't prepared for the injection")
+	}
+
+	troubleshootCtx.logOkf("namespaces are prepared for the injection")
+	return err
+}
+
+func checkNamespaceInjectionLabels(troubleshootCtx *troubleshootContext) error {
+	dkMapper := mapper.NewDynakubeMapper(context.TODO(), nil, troubleshootCtx.apiReader, troubleshootCtx.namespaceName, &troubleshootCtx.dynakube)
+	outdatedNamespaces, err := dkMapper.MatchingNamespaces()
+	if err != nil {
+		return errorWithMessagef(err, "failed to match the namespaces")
+	}
+	if len(outdatedNamespaces) > 0 {
+		names := make([]string, 0, len(outdatedNamespaces))
+		for _, namespace := range outdatedNamespaces {
+			names = append(names, namespace.Name)
+		}
+		return errors.Errorf("'%s' label of the namespaces '%s' is outdated", dtwebhook.InjectionInstanceLabel, strings.Join(names, "', '"))
+	}
+
+	injectedNamespaces, err := mapper.GetNamespacesForDynakube(context.TODO(), troubleshootCtx.apiReader, troubleshootCtx.dynakubeName)
+	if err != nil {
+		return errorWithMessagef(err, "failed to list the namespaces")
+	}
+	troubleshootCtx.injectedNamespaces = injectedNamespaces
+	if len(injectedNamespaces) == 0 {
+		return newWarningf("no namespace is mapped to '%s:%s' Dynakube", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+	}
+
+	troubleshootCtx.logInfof("%d namespaces are mapped to '%s:%s' Dynakube", len(injectedNamespaces), troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+	return nil
+}
+
+func checkInjectionSecrets(troubleshootCtx *troubleshootContext) error {
+	missingSecrets := []string{}
+	for _, namespace := range troubleshootCtx.injectedNamespaces {
+		for _, secretName := range []string{config.AgentInitSecretName, config.EnrichmentEndpointSecretName} {
+			name := dtwebhook.SecretNameForDynakube(secretName, namespace, troubleshootCtx.dynakubeName)
+
+			var secret corev1.Secret
+			err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: namespace.Name}, &secret)
+			if k8serrors.IsNotFound(err) {
+				missingSecrets = append(missingSecrets, namespace.Name+":"+name)
+			} else if err != nil {
+				return errorWithMessagef(err, "failed to query '%s:%s' secret", namespace.Name, name)
+			}
+		}
+	}
+	if len(missingSecrets) > 0 {
+		return errors.Errorf("secrets '%s' are missing", strings.Join(missingSecrets, "', '"))
+	}
+
+	troubleshootCtx.logInfof("secrets for the injection exist in all mapped namespaces")
+	return nil
+}
+
+func checkPod(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[pod       ] ")
+
+	troubleshootCtx.logNewTestf("checking if pod '%s:%s' is injected ...", troubleshootCtx.podNamespace, troubleshootCtx.podName)
+
+	var pod corev1.Pod
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: troubleshootCtx.podName, Namespace: troubleshootCtx.podNamespace}, &pod); err != nil {
+		return errorWithMessagef(err, "pod '%s:%s' is missing", troubleshootCtx.podNamespace, troubleshootCtx.podName)
+	}
+
+	var namespace corev1.Namespace
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: troubleshootCtx.podNamespace}, &namespace); err != nil {
+		return errorWithMessagef(err, "missing namespace '%s'", troubleshootCtx.podNamespace)
+	}
+
+	if pod.Annotations[dtwebhook.AnnotationDynatraceInjected] != "true" {
+		return errors.Errorf("pod '%s:%s' isn't injected, %s", troubleshootCtx.podNamespace, troubleshootCtx.podName, getMissingInjectionReason(pod, namespace))
+	}
+
+	troubleshootCtx.logOkf("pod '%s:%s' is injected", troubleshootCtx.podNamespace, troubleshootCtx.podName)
+	if reasons := getSkipReasons(pod); len(reasons) > 0 {
+		return newWarningf("pod '%s:%s' is only partially injected, %s", troubleshootCtx.podNamespace, troubleshootCtx.podName, strings.Join(reasons, ", "))
+	}
+	return nil
+}
+
+func getMissingInjectionReason(pod corev1.Pod, namespace corev1.Namespace) string {
+	if reasons := getSkipReasons(pod); len(reasons) > 0 {
+		return "the webhook skipped it: " + strings.Join(reasons, ", ")
+	}
+	if pod.Annotations[dtwebhook.AnnotationOneAgentInject] == "false" && pod.Annotations[dtwebhook.AnnotationDataIngestInject] == "false" {
+		return "it opts out with the '" + dtwebhook.AnnotationOneAgentInject + "' and '" + dtwebhook.AnnotationDataIngestInject + "' annotations"
+	}
+	dynakubeName := namespace.Labels[dtwebhook.InjectionInstanceLabel]
+	if dynakubeName == "" && len(dtwebhook.RoutedDynakubeNames(namespace)) == 0 {
+		return "its namespace '" + namespace.Name + "' isn't mapped to a Dynakube, check the namespaceSelector of the Dynakubes"
+	}
+	return "it was created before its namespace was mapped to '" + dynakubeName + "' Dynakube or while the webhook was unavailable"
+}
+
+func getSkipReasons(pod corev1.Pod) []string {
+	reasons := []string{}
+	if reason := pod.Annotations[dtwebhook.AnnotationOneAgentReason]; reason != "" {
+		reasons = append(reasons, "OneAgent: "+reason)
+	}
+	if reason := pod.Annotations[dtwebhook.AnnotationDataIngestReason]; reason != "" {
+		reasons = append(reasons, "data-ingest: "+reason)
+	}
+	return reasons
+}
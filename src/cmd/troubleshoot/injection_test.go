@@ -0,0 +1,175 @@
+package troubleshoot
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testAppNamespace = "app"
+	testPodName      = "app-pod"
+)
+
+func TestInjection(t *testing.T) {
+	dynakube := testNewDynakubeBuilder(testNamespace, testDynakube).withCloudNativeFullStackImageVersion(testVersion).build()
+
+	t.Run("namespaces are prepared", func(t *testing.T) {
+		troubleshootCtx := newInjectionTroubleshootContext(dynakube,
+			testBuildNamespace(testNamespace),
+			testBuildMappedNamespace(testAppNamespace),
+			testNewSecretBuilder(testAppNamespace, config.AgentInitSecretName).build(),
+			testNewSecretBuilder(testAppNamespace, config.EnrichmentEndpointSecretName).build())
+
+		assert.NoError(t, checkInjection(troubleshootCtx))
+		assert.Len(t, troubleshootCtx.injectedNamespaces, 1)
+	})
+	t.Run("namespace label is outdated", func(t *testing.T) {
+		troubleshootCtx := newInjectionTroubleshootContext(dynakube,
+			testBuildNamespace(testNamespace),
+			testBuildNamespace(testAppNamespace))
+
+		err := checkInjection(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'"+testAppNamespace+"' is outdated")
+	})
+	t.Run("secrets are missing", func(t *testing.T) {
+		troubleshootCtx := newInjectionTroubleshootContext(dynakube,
+			testBuildNamespace(testNamespace),
+			testBuildMappedNamespace(testAppNamespace),
+			testNewSecretBuilder(testAppNamespace, config.AgentInitSecretName).build())
+
+		err := checkInjection(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), testAppNamespace+":"+config.EnrichmentEndpointSecretName)
+		assert.NotContains(t, err.Error(), testAppNamespace+":"+config.AgentInitSecretName)
+	})
+	t.Run("no mapped namespace is a warning", func(t *testing.T) {
+		troubleshootCtx := newInjectionTroubleshootContext(dynakube, testBuildNamespace(testNamespace))
+
+		assert.True(t, isWarning(checkInjection(troubleshootCtx)))
+	})
+	t.Run("no application injection", func(t *testing.T) {
+		classicDynakube := testNewDynakubeBuilder(testNamespace, testDynakube).withClassicFullStackImageVersion(testVersion).build()
+		troubleshootCtx := newInjectionTroubleshootContext(classicDynakube, testBuildNamespace(testAppNamespace))
+
+		assert.NoError(t, checkInjection(troubleshootCtx))
+	})
+}
+
+func TestPod(t *testing.T) {
+	t.Run("pod is injected", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(
+			testBuildMappedNamespace(testAppNamespace),
+			testBuildPod(map[string]string{dtwebhook.AnnotationDynatraceInjected: "true"}))
+
+		assert.NoError(t, checkPod(troubleshootCtx))
+	})
+	t.Run("pod is partially injected", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(
+			testBuildMappedNamespace(testAppNamespace),
+			testBuildPod(map[string]string{
+				dtwebhook.AnnotationDynatraceInjected: "true",
+				dtwebhook.AnnotationOneAgentReason:    "NoBootstrapperConfig",
+			}))
+
+		err := checkPod(troubleshootCtx)
+		require.Error(t, err)
+		assert.True(t, isWarning(err))
+		assert.Contains(t, err.Error(), "NoBootstrapperConfig")
+	})
+	t.Run("pod is missing", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(testBuildMappedNamespace(testAppNamespace))
+
+		assert.Error(t, checkPod(troubleshootCtx))
+	})
+	t.Run("injection was skipped", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(
+			testBuildMappedNamespace(testAppNamespace),
+			testBuildPod(map[string]string{dtwebhook.AnnotationDataIngestReason: "NoDynakube"}))
+
+		err := checkPod(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the webhook skipped it: data-ingest: NoDynakube")
+	})
+	t.Run("pod opts out", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(
+			testBuildMappedNamespace(testAppNamespace),
+			testBuildPod(map[string]string{
+				dtwebhook.AnnotationOneAgentInject:   "false",
+				dtwebhook.AnnotationDataIngestInject: "false",
+			}))
+
+		err := checkPod(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "opts out")
+	})
+	t.Run("namespace isn't mapped", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(testBuildNamespace(testAppNamespace), testBuildPod(nil))
+
+		err := checkPod(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "isn't mapped to a Dynakube")
+	})
+	t.Run("pod was created before the mapping", func(t *testing.T) {
+		troubleshootCtx := newPodTroubleshootContext(testBuildMappedNamespace(testAppNamespace), testBuildPod(nil))
+
+		err := checkPod(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "created before its namespace was mapped to '"+testDynakube+"' Dynakube")
+	})
+}
+
+func TestParsePodFlag(t *testing.T) {
+	namespace, name, err := parsePodFlag(testAppNamespace + "/" + testPodName)
+	require.NoError(t, err)
+	assert.Equal(t, testAppNamespace, namespace)
+	assert.Equal(t, testPodName, name)
+
+	namespace, name, err = parsePodFlag("")
+	require.NoError(t, err)
+	assert.Empty(t, namespace)
+	assert.Empty(t, name)
+
+	_, _, err = parsePodFlag(testPodName)
+	assert.Error(t, err)
+}
+
+func newInjectionTroubleshootContext(dynakube *dynatracev1beta1.DynaKube, objects ...client.Object) *troubleshootContext {
+	troubleshootCtx := newWebhookTroubleshootContext(append(objects, dynakube)...)
+	troubleshootCtx.dynakube = *dynakube
+	return troubleshootCtx
+}
+
+func newPodTroubleshootContext(objects ...client.Object) *troubleshootContext {
+	clt := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objects...).
+		Build()
+	return &troubleshootContext{apiReader: clt, namespaceName: testNamespace, podNamespace: testAppNamespace, podName: testPodName}
+}
+
+func testBuildMappedNamespace(namespace string) *corev1.Namespace {
+	mappedNamespace := testBuildNamespace(namespace)
+	mappedNamespace.Labels = map[string]string{dtwebhook.InjectionInstanceLabel: testDynakube}
+	return mappedNamespace
+}
+
+func testBuildPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testPodName,
+			Namespace:   testAppNamespace,
+			Annotations: annotations,
+		},
+	}
+}
@@ -2,6 +2,7 @@ package troubleshoot
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/go-logr/logr"
@@ -15,16 +16,22 @@ const (
 	prefixNewTest = "--- "
 	prefixOk      = " \u221A  "
 	prefixError   = " \u00D7  "
+	prefixWarning = " !  "
 	levelNewTest  = 1
 	levelOk       = 2
 	levelError    = 3
+	levelWarning  = 4
 )
 
 type troubleshootLogger struct {
 	logger logr.Logger
 }
 
-func newTroubleshootLogger(testName string) logr.Logger {
+// newTroubleshootLogger writes the log of the check to the output, or to stdout if no output is set
+func newTroubleshootLogger(logOutput io.Writer, testName string) logr.Logger {
+	if logOutput == nil {
+		logOutput = os.Stdout
+	}
 	config := zap.NewProductionEncoderConfig()
 	config.TimeKey = ""
 	config.LevelKey = ""
@@ -33,7 +40,7 @@ func newTroubleshootLogger(testName string) logr.Logger {
 
 	return logr.New(
 		troubleshootLogger{
-			logger: ctrlzap.New(ctrlzap.WriteTo(os.Stdout), ctrlzap.Encoder(zapcore.NewConsoleEncoder(config))).WithName(testName),
+			logger: ctrlzap.New(ctrlzap.WriteTo(logOutput), ctrlzap.Encoder(zapcore.NewConsoleEncoder(config))).WithName(testName),
 		},
 	)
 }
@@ -42,20 +49,32 @@ func newTroubleshootLogger(testName string) logr.Logger {
 // Troubleshoot fmt-like log wrappers
 //
 
-func logNewTestf(format string, v ...interface{}) {
-	log.V(levelNewTest).Info(fmt.Sprintf(format, v...))
+// logger returns the logger of the current check, the log of the context is used before the first check switched it
+func (troubleshootCtx *troubleshootContext) logger() logr.Logger {
+	if troubleshootCtx.log.GetSink() == nil {
+		troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[          ]")
+	}
+	return troubleshootCtx.log
+}
+
+func (troubleshootCtx *troubleshootContext) logNewTestf(format string, v ...interface{}) {
+	troubleshootCtx.logger().V(levelNewTest).Info(fmt.Sprintf(format, v...))
+}
+
+func (troubleshootCtx *troubleshootContext) logInfof(format string, v ...interface{}) {
+	troubleshootCtx.logger().Info(fmt.Sprintf(format, v...))
 }
 
-func logInfof(format string, v ...interface{}) {
-	log.Info(fmt.Sprintf(format, v...))
+func (troubleshootCtx *troubleshootContext) logOkf(format string, v ...interface{}) {
+	troubleshootCtx.logger().V(levelOk).Info(fmt.Sprintf(format, v...))
 }
 
-func logOkf(format string, v ...interface{}) {
-	log.V(levelOk).Info(fmt.Sprintf(format, v...))
+func (troubleshootCtx *troubleshootContext) logErrorf(format string, v ...interface{}) {
+	troubleshootCtx.logger().V(levelError).Info(fmt.Sprintf(format, v...))
 }
 
-func logErrorf(format string, v ...interface{}) {
-	log.V(levelError).Info(fmt.Sprintf(format, v...))
+func (troubleshootCtx *troubleshootContext) logWarningf(format string, v ...interface{}) {
+	troubleshootCtx.logger().V(levelWarning).Info(fmt.Sprintf(format, v...))
 }
 
 func errorWithMessagef(err error, format string, v ...interface{}) error {
@@ -78,6 +97,8 @@ func (dtl troubleshootLogger) Info(level int, msg string, keysAndValues ...inter
 	case levelError:
 		// no stack trace
 		dtl.logger.Info(prefixError+msg, keysAndValues...)
+	case levelWarning:
+		dtl.logger.Info(prefixWarning+msg, keysAndValues...)
 	default:
 		dtl.logger.Info(prefixInfo+msg, keysAndValues...)
 	}
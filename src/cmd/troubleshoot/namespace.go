@@ -8,15 +8,15 @@ import (
 )
 
 func checkNamespace(troubleshootCtx *troubleshootContext) error {
-	log = newTroubleshootLogger("[namespace ] ")
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[namespace ] ")
 
-	logNewTestf("checking if namespace '%s' exists ...", troubleshootCtx.namespaceName)
+	troubleshootCtx.logNewTestf("checking if namespace '%s' exists ...", troubleshootCtx.namespaceName)
 
 	var namespace corev1.Namespace
 	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: troubleshootCtx.namespaceName}, &namespace); err != nil {
 		return errorWithMessagef(err, "missing namespace '%s'", troubleshootCtx.namespaceName)
 	}
 
-	logOkf("using namespace '%s'", troubleshootCtx.namespaceName)
+	troubleshootCtx.logOkf("using namespace '%s'", troubleshootCtx.namespaceName)
 	return nil
 }
@@ -0,0 +1,285 @@
+package troubleshoot
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	hopProxy      = "proxy"
+	hopDns        = "dns"
+	hopTls        = "tls"
+	hopTimeout    = "timeout"
+	hopConnection = "connection"
+
+	communicationEndpointPath = "/communication"
+	networkProbeTimeout       = 10 * time.Second
+)
+
+// checkNetwork checks the connection to the tenant with the same transport as the operator, so with the proxy and the trusted CAs of the dynakube
+func checkNetwork(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[network   ] ")
+
+	troubleshootCtx.logNewTestf("checking if the communication hosts of '%s:%s' Dynakube are reachable ...", troubleshootCtx.namespaceName, troubleshootCtx.dynakubeName)
+
+	err := runSubChecks(troubleshootCtx, []troubleshootFunc{
+		checkTrustedCAs,
+		buildDynatraceHttpClient,
+		checkProxyReachable,
+		checkConnectionInfo,
+		checkCommunicationHosts,
+	})
+	if err != nil && !isWarning(err) {
+		return errors.Wrap(err, "communication hosts aren't reachable")
+	}
+
+	troubleshootCtx.logOkf("communication hosts are reachable")
+	return err
+}
+
+// checkTrustedCAs checks that the trusted CAs configmap contains certificates, the operator silently ignores invalid ones
+func checkTrustedCAs(troubleshootCtx *troubleshootContext) error {
+	if troubleshootCtx.dynakube.Spec.SkipCertCheck {
+		troubleshootCtx.logInfof("certificate validation is disabled by skipCertCheck")
+	}
+	trustedCAs := troubleshootCtx.dynakube.Spec.TrustedCAs
+	if trustedCAs == "" {
+		troubleshootCtx.logInfof("trusted CAs not used")
+		return nil
+	}
+
+	var configMap corev1.ConfigMap
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: trustedCAs, Namespace: troubleshootCtx.namespaceName}, &configMap); err != nil {
+		return errorWithMessagef(err, "'%s:%s' trusted CAs configmap is missing", troubleshootCtx.namespaceName, trustedCAs)
+	}
+
+	certificates, err := parseCertificates(configMap.Data[dtclient.CustomCertificatesConfigMapKey])
+	if err != nil {
+		return errorWithMessagef(err, "invalid '%s:%s' trusted CAs configmap", troubleshootCtx.namespaceName, trustedCAs)
+	}
+	for _, certificate := range certificates {
+		if time.Now().After(certificate.NotAfter) {
+			return errors.Errorf("certificate '%s' of '%s:%s' trusted CAs configmap expired at %s",
+				certificate.Subject.CommonName, troubleshootCtx.namespaceName, trustedCAs, certificate.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	troubleshootCtx.logInfof("'%s:%s' trusted CAs configmap contains %d certificates", troubleshootCtx.namespaceName, trustedCAs, len(certificates))
+	return nil
+}
+
+// buildDynatraceHttpClient builds the http client with the options the operator uses for the dynatrace client
+func buildDynatraceHttpClient(troubleshootCtx *troubleshootContext) error {
+	properties, err := dynakube.NewDynatraceClientProperties(context.TODO(), troubleshootCtx.apiReader, troubleshootCtx.dynakube)
+	if err != nil {
+		return errorWithMessagef(err, "failed to configure DynatraceAPI client")
+	}
+	opts, err := dynakube.NewDynatraceClientOptions(*properties)
+	if err != nil {
+		return errorWithMessagef(err, "failed to configure DynatraceAPI client")
+	}
+
+	troubleshootCtx.dynatraceClientProperties = properties
+	troubleshootCtx.dynatraceHttpClient = dtclient.NewHttpClient(opts...)
+	troubleshootCtx.dynatraceHttpClient.Timeout = networkProbeTimeout
+	return nil
+}
+
+// checkProxyReachable checks that a tcp connection to the proxy can be opened, so failures of later requests aren't caused by the proxy itself
+func checkProxyReachable(troubleshootCtx *troubleshootContext) error {
+	proxyUrl, err := getDynatraceProxyUrl(troubleshootCtx)
+	if err != nil {
+		return err
+	} else if proxyUrl == nil {
+		troubleshootCtx.logInfof("proxy not used")
+		return nil
+	}
+
+	connection, err := net.DialTimeout("tcp", getHostWithPort(proxyUrl), networkProbeTimeout)
+	if err != nil {
+		return errors.Errorf("proxy '%s' isn't reachable (%s)", proxyUrl.Host, describeNetworkError(err))
+	}
+	_ = connection.Close()
+
+	troubleshootCtx.logInfof("proxy '%s' is reachable", proxyUrl.Host)
+	return nil
+}
+
+// checkConnectionInfo gets the communication hosts of the network zone from the api url
+func checkConnectionInfo(troubleshootCtx *troubleshootContext) error {
+	dtc, err := dynakube.BuildDynatraceClient(*troubleshootCtx.dynatraceClientProperties)
+	if err != nil {
+		return errorWithMessagef(err, "failed to build DynatraceAPI client")
+	}
+
+	// the network zone is queried without fallback to the default network zone, so a network zone without hosts is reported
+	networkZone := troubleshootCtx.dynakube.Spec.NetworkZone
+	var connectionInfo dtclient.ConnectionInfo
+	if networkZone != "" {
+		connectionInfo, err = dtc.GetNetworkZoneConnectionInfo(networkZone)
+	} else {
+		connectionInfo, err = dtc.GetConnectionInfo()
+	}
+	if err != nil && networkZone != "" {
+		return errors.Errorf("failed to get the communication hosts of network zone '%s' from '%s' (%s)",
+			networkZone, troubleshootCtx.dynakube.Spec.APIURL, describeNetworkError(err))
+	} else if err != nil {
+		return errors.Errorf("failed to get the communication hosts from '%s' (%s)", troubleshootCtx.dynakube.Spec.APIURL, describeNetworkError(err))
+	}
+	if len(connectionInfo.CommunicationHosts) == 0 && networkZone != "" {
+		return errors.Errorf("network zone '%s' has no communication hosts", networkZone)
+	} else if len(connectionInfo.CommunicationHosts) == 0 {
+		return errors.New("tenant has no communication hosts")
+	}
+
+	troubleshootCtx.communicationHosts = connectionInfo.CommunicationHosts
+	if networkZone != "" {
+		troubleshootCtx.logInfof("network zone '%s' has %d communication hosts", networkZone, len(connectionInfo.CommunicationHosts))
+	} else {
+		troubleshootCtx.logInfof("tenant has %d communication hosts", len(connectionInfo.CommunicationHosts))
+	}
+	return nil
+}
+
+// checkCommunicationHosts sends a request to every communication host, any http response proves that it is reachable.
+// The OneAgents need one reachable host, so only unreachable hosts are a warning.
+func checkCommunicationHosts(troubleshootCtx *troubleshootContext) error {
+	unreachableHosts := []string{}
+	for _, communicationHost := range troubleshootCtx.communicationHosts {
+		hostUrl := fmt.Sprintf("%s://%s%s", communicationHost.Protocol,
+			net.JoinHostPort(communicationHost.Host, strconv.FormatUint(uint64(communicationHost.Port), 10)), communicationEndpointPath)
+
+		response, err := troubleshootCtx.dynatraceHttpClient.Get(hostUrl)
+		if err != nil {
+			troubleshootCtx.logErrorf("communication host '%s' isn't reachable (%s)", hostUrl, describeNetworkError(err))
+			unreachableHosts = append(unreachableHosts, fmt.Sprintf("%s (%s)", hostUrl, describeNetworkError(err)))
+			continue
+		}
+		_ = response.Body.Close()
+		troubleshootCtx.logInfof("communication host '%s' is reachable", hostUrl)
+	}
+
+	if len(unreachableHosts) == len(troubleshootCtx.communicationHosts) {
+		return errors.Errorf("no communication host is reachable '%s'", strings.Join(unreachableHosts, "', '"))
+	} else if len(unreachableHosts) > 0 {
+		return newWarningf("communication hosts aren't reachable '%s'", strings.Join(unreachableHosts, "', '"))
+	}
+	return nil
+}
+
+func getDynatraceProxyUrl(troubleshootCtx *troubleshootContext) (*url.URL, error) {
+	proxy := troubleshootCtx.dynakube.Spec.Proxy
+	proxyValue := ""
+	if proxy != nil && proxy.ValueFrom != "" {
+		var err error
+		proxyValue, err = kubeobjects.ExtractToken(&troubleshootCtx.proxySecret, dtclient.CustomProxySecretKey)
+		if err != nil {
+			return nil, errorWithMessagef(err, "failed to extract proxy secret field")
+		}
+	} else if proxy != nil {
+		proxyValue = proxy.Value
+	}
+	if proxyValue == "" {
+		return nil, nil
+	}
+
+	proxyUrl, err := url.Parse(proxyValue)
+	if err != nil {
+		return nil, errorWithMessagef(err, "could not parse proxy URL")
+	}
+	return proxyUrl, nil
+}
+
+func getHostWithPort(hostUrl *url.URL) string {
+	if hostUrl.Port() != "" {
+		return hostUrl.Host
+	}
+	if hostUrl.Scheme == "https" {
+		return net.JoinHostPort(hostUrl.Hostname(), "443")
+	}
+	return net.JoinHostPort(hostUrl.Hostname(), "80")
+}
+
+func parseCertificates(pemData string) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	rest := []byte(pemData)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.Errorf("field '%s' contains no certificate", dtclient.CustomCertificatesConfigMapKey)
+	}
+	return certificates, nil
+}
+
+// describeNetworkError prefixes the error with the hop of the connection, which failed
+func describeNetworkError(err error) string {
+	return fmt.Sprintf("%s: %s", getFailedHop(err), err.Error())
+}
+
+func getFailedHop(err error) string {
+	var opError *net.OpError
+	var dnsError *net.DNSError
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	var recordHeaderError tls.RecordHeaderError
+	var netError net.Error
+
+	switch {
+	case errors.As(err, &opError) && opError.Op == "proxyconnect":
+		return hopProxy
+	case errors.As(err, &dnsError):
+		return hopDns
+	case errors.As(err, &unknownAuthorityError), errors.As(err, &hostnameError),
+		errors.As(err, &certificateInvalidError), errors.As(err, &recordHeaderError):
+		return hopTls
+	case errors.As(err, &netError) && netError.Timeout():
+		return hopTimeout
+	case isProxyStatusError(err):
+		return hopProxy
+	}
+	return hopConnection
+}
+
+// isProxyStatusError detects a proxy answering the CONNECT request with an error status, the transport doesn't return a typed error for it
+func isProxyStatusError(err error) bool {
+	var urlError *url.Error
+	if !errors.As(err, &urlError) {
+		return false
+	}
+	statusCode, _, found := strings.Cut(urlError.Err.Error(), " ")
+	if !found {
+		return false
+	}
+	code, err := strconv.Atoi(statusCode)
+	return err == nil && http.StatusText(code) != ""
+}
@@ -0,0 +1,157 @@
+package troubleshoot
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testTrustedCAs         = "trusted-cas"
+	testNetworkZone        = "zone"
+	testEmptyNetworkZone   = "empty-zone"
+	testUnreachableHostUrl = "https://127.0.0.1:1/communication"
+)
+
+func TestNetwork(t *testing.T) {
+	reachableHosts := []string{}
+	requestedNetworkZones := []string{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/api/v1/deployment/installer/agent/connectioninfo" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		endpoints := reachableHosts
+		requestedNetworkZones = append(requestedNetworkZones, request.URL.Query().Get("networkZone"))
+		// the fallback would return the communication hosts of the default network zone
+		if request.URL.Query().Get("networkZone") == testEmptyNetworkZone && request.URL.Query().Get("defaultZoneFallback") != "true" {
+			endpoints = []string{}
+		}
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"communicationEndpoints": endpoints})
+	}))
+	defer server.Close()
+	serverCertificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	reachableHosts = []string{server.URL + "/communication"}
+
+	t.Run("communication hosts are reachable with the trusted CAs", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.TrustedCAs = testTrustedCAs
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube, testBuildTrustedCAs(serverCertificate))
+
+		assert.NoError(t, checkNetwork(troubleshootCtx))
+		assert.Len(t, troubleshootCtx.communicationHosts, 1)
+	})
+	t.Run("api url isn't trusted without the trusted CAs", func(t *testing.T) {
+		troubleshootCtx := newNetworkTroubleshootContext(testBuildNetworkDynakube(server.URL))
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get the communication hosts from '"+server.URL+"/api' (tls: ")
+	})
+	t.Run("communication hosts are reachable without certificate validation", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.SkipCertCheck = true
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		assert.NoError(t, checkNetwork(troubleshootCtx))
+	})
+	t.Run("unreachable communication host is a warning", func(t *testing.T) {
+		reachableHosts = []string{server.URL + "/communication", testUnreachableHostUrl}
+		defer func() { reachableHosts = []string{server.URL + "/communication"} }()
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.SkipCertCheck = true
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.True(t, isWarning(err))
+		assert.Contains(t, err.Error(), testUnreachableHostUrl+" (connection: ")
+	})
+	t.Run("no reachable communication host", func(t *testing.T) {
+		reachableHosts = []string{testUnreachableHostUrl}
+		defer func() { reachableHosts = []string{server.URL + "/communication"} }()
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.SkipCertCheck = true
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.False(t, isWarning(err))
+		assert.Contains(t, err.Error(), "no communication host is reachable")
+	})
+	t.Run("network zone without communication hosts", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.SkipCertCheck = true
+		dynakube.Spec.NetworkZone = testEmptyNetworkZone
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "communication hosts of network zone '"+testEmptyNetworkZone+"'")
+	})
+	t.Run("network zone with communication hosts", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.SkipCertCheck = true
+		dynakube.Spec.NetworkZone = testNetworkZone
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+		requestedNetworkZones = []string{}
+
+		assert.NoError(t, checkNetwork(troubleshootCtx))
+		assert.Equal(t, []string{testNetworkZone}, requestedNetworkZones)
+	})
+	t.Run("trusted CAs configmap is missing", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.TrustedCAs = testTrustedCAs
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'"+testNamespace+":"+testTrustedCAs+"' trusted CAs configmap is missing")
+	})
+	t.Run("trusted CAs configmap contains no certificate", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.TrustedCAs = testTrustedCAs
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube, testBuildTrustedCAs("not a certificate"))
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contains no certificate")
+	})
+	t.Run("proxy isn't reachable", func(t *testing.T) {
+		dynakube := testBuildNetworkDynakube(server.URL)
+		dynakube.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: "http://127.0.0.1:1"}
+		troubleshootCtx := newNetworkTroubleshootContext(dynakube)
+
+		err := checkNetwork(troubleshootCtx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "proxy '127.0.0.1:1' isn't reachable (connection: ")
+	})
+}
+
+func newNetworkTroubleshootContext(dynakube *dynatracev1beta1.DynaKube, objects ...client.Object) *troubleshootContext {
+	troubleshootCtx := newWebhookTroubleshootContext(append(objects,
+		testNewSecretBuilder(testNamespace, testDynakube).dataAppend(dtclient.DynatraceApiToken, testApiToken).build())...)
+	troubleshootCtx.dynakube = *dynakube
+	return troubleshootCtx
+}
+
+func testBuildNetworkDynakube(serverUrl string) *dynatracev1beta1.DynaKube {
+	return testNewDynakubeBuilder(testNamespace, testDynakube).withApiUrl(serverUrl + "/api").build()
+}
+
+func testBuildTrustedCAs(certificates string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testTrustedCAs, Namespace: testNamespace},
+		Data:       map[string]string{dtclient.CustomCertificatesConfigMapKey: certificates},
+	}
+}
@@ -0,0 +1,325 @@
+package troubleshoot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/diagnostics"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputFormatText = ""
+	outputFormatJson = "json"
+	outputFormatYaml = "yaml"
+
+	// exit code 1 is used by the operator binary for errors of the command itself
+	exitCodePassed   = 0
+	exitCodeWarnings = 2
+	exitCodeFailures = 3
+)
+
+type checkStatus string
+
+const (
+	statusPassed  checkStatus = "pass"
+	statusWarning checkStatus = "warn"
+	statusFailed  checkStatus = "fail"
+	statusSkipped checkStatus = "skipped"
+)
+
+// troubleshootCheck is a named troubleshootFunc, which is only run if all of its prerequisites did not fail
+type troubleshootCheck struct {
+	name          string
+	run           troubleshootFunc
+	remediation   string
+	prerequisites []string
+}
+
+type checkResult struct {
+	Name        string      `json:"name"`
+	Status      checkStatus `json:"status"`
+	Message     string      `json:"message,omitempty"`
+	Remediation string      `json:"remediation,omitempty"`
+	Duration    string      `json:"duration"`
+}
+
+type troubleshootReport struct {
+	Namespace string        `json:"namespace"`
+	Dynakube  string        `json:"dynakube"`
+	Status    checkStatus   `json:"status"`
+	Checks    []checkResult `json:"checks"`
+}
+
+// troubleshootWarning is returned by a troubleshootFunc for problems which don't break the setup
+type troubleshootWarning struct {
+	message string
+}
+
+func (warning troubleshootWarning) Error() string {
+	return warning.message
+}
+
+func newWarningf(format string, v ...interface{}) error {
+	return troubleshootWarning{message: fmt.Sprintf(format, v...)}
+}
+
+func isWarning(err error) bool {
+	var warning troubleshootWarning
+	return errors.As(err, &warning)
+}
+
+// runSubChecks runs the troubleshootFuncs of a check until the first failure, warnings don't stop the sub checks and are combined
+func runSubChecks(troubleshootCtx *troubleshootContext, subChecks []troubleshootFunc) error {
+	warnings := []string{}
+	for _, subCheck := range subChecks {
+		err := subCheck(troubleshootCtx)
+		if isWarning(err) {
+			warnings = append(warnings, err.Error())
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(warnings) > 0 {
+		return newWarningf("%s", strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+func getChecks(troubleshootCtx *troubleshootContext) []troubleshootCheck {
+	checks := []troubleshootCheck{
+		{
+			name:        "namespace",
+			run:         checkNamespace,
+			remediation: "Specify the namespace of the operator with --namespace.",
+		},
+		{
+			name:          "dynakube",
+			run:           checkDynakube,
+			remediation:   "Specify the Dynakube with --dynakube and make sure the secrets it references exist and contain the required tokens.",
+			prerequisites: []string{"namespace"},
+		},
+		{
+			name:          "dtcluster",
+			run:           checkDTClusterConnection,
+			remediation:   "Check the api url, the apiToken and the proxy of the Dynakube, and that the tenant is reachable from the cluster.",
+			prerequisites: []string{"dynakube"},
+		},
+		{
+			name:          "network",
+			run:           checkNetwork,
+			remediation:   "Check the proxy and the trusted CAs of the Dynakube, and that the communication hosts of its network zone are reachable from the cluster.",
+			prerequisites: []string{"dynakube"},
+		},
+		{
+			name:          "imagepull",
+			run:           checkImagePullable,
+			remediation:   "Check that the registries of the pull secret are reachable and that the configured images and versions exist.",
+			prerequisites: []string{"dynakube"},
+		},
+		{
+			name:          "webhook",
+			run:           checkWebhook,
+			remediation:   "Check the pods and the events of the webhook deployment.",
+			prerequisites: []string{"namespace"},
+		},
+		{
+			name:          "certificates",
+			run:           checkCertificates,
+			remediation:   "Check the log of the operator, it creates and renews the certificates and updates the webhook configurations.",
+			prerequisites: []string{"namespace"},
+		},
+		{
+			name:          "injection",
+			run:           checkInjection,
+			remediation:   "Check the namespaceSelector of the Dynakube and the log of the operator, it labels the namespaces and creates the secrets.",
+			prerequisites: []string{"dynakube"},
+		},
+		{
+			name:          "csi",
+			run:           checkCSI,
+			remediation:   "Check the pods of the csi driver daemonset, the log of their provisioner container and the events of the pods failing to mount.",
+			prerequisites: []string{"dynakube"},
+		},
+	}
+	if troubleshootCtx.podName != "" {
+		checks = append(checks, troubleshootCheck{
+			name:          "pod",
+			run:           checkPod,
+			remediation:   "Restart the pod after fixing the reason, the webhook only injects pods on their creation.",
+			prerequisites: []string{"namespace"},
+		})
+	}
+	return checks
+}
+
+// runChecks runs the checks in order, after the first failure the remaining checks are skipped,
+// unless continueOnError is set, then only the checks depending on a failed check are skipped
+func runChecks(troubleshootCtx *troubleshootContext, checks []troubleshootCheck, continueOnError bool) troubleshootReport {
+	report := troubleshootReport{
+		Namespace: troubleshootCtx.namespaceName,
+		Dynakube:  troubleshootCtx.dynakubeName,
+		Checks:    make([]checkResult, 0, len(checks)),
+	}
+	statuses := map[string]checkStatus{}
+	stopped := false
+
+	for _, check := range checks {
+		var result checkResult
+		if failedPrerequisite := getFailedPrerequisite(check, statuses); stopped || failedPrerequisite != "" {
+			result = newSkippedResult(check, failedPrerequisite)
+		} else {
+			result = runCheck(troubleshootCtx, check)
+		}
+		statuses[check.name] = result.Status
+		report.Checks = append(report.Checks, result)
+
+		if result.Status == statusFailed && !continueOnError {
+			stopped = true
+		}
+	}
+	report.Status = getOverallStatus(report.Checks)
+	return report
+}
+
+func runCheck(troubleshootCtx *troubleshootContext, check troubleshootCheck) checkResult {
+	start := time.Now()
+	err := check.run(troubleshootCtx)
+	result := checkResult{
+		Name:     check.name,
+		Status:   statusPassed,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+
+	switch {
+	case isWarning(err):
+		troubleshootCtx.logWarningf(err.Error())
+		result.Status = statusWarning
+		result.Message = err.Error()
+		result.Remediation = check.remediation
+	case err != nil:
+		troubleshootCtx.logErrorf(err.Error())
+		result.Status = statusFailed
+		result.Message = err.Error()
+		result.Remediation = check.remediation
+	}
+	return result
+}
+
+func newSkippedResult(check troubleshootCheck, failedPrerequisite string) checkResult {
+	message := "skipped, because a previous check failed"
+	if failedPrerequisite != "" {
+		message = fmt.Sprintf("skipped, because the '%s' check failed", failedPrerequisite)
+	}
+	return checkResult{
+		Name:     check.name,
+		Status:   statusSkipped,
+		Message:  message,
+		Duration: time.Duration(0).String(),
+	}
+}
+
+func getFailedPrerequisite(check troubleshootCheck, statuses map[string]checkStatus) string {
+	for _, prerequisite := range check.prerequisites {
+		if status := statuses[prerequisite]; status == statusFailed || status == statusSkipped {
+			return prerequisite
+		}
+	}
+	return ""
+}
+
+func getOverallStatus(results []checkResult) checkStatus {
+	status := statusPassed
+	for _, result := range results {
+		switch result.Status {
+		case statusFailed:
+			return statusFailed
+		case statusWarning:
+			status = statusWarning
+		}
+	}
+	return status
+}
+
+func (report troubleshootReport) exitCode() int {
+	switch report.Status {
+	case statusFailed:
+		return exitCodeFailures
+	case statusWarning:
+		return exitCodeWarnings
+	}
+	return exitCodePassed
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputFormatText, outputFormatJson, outputFormatYaml:
+		return nil
+	}
+	return errors.Errorf("unsupported output format '%s', use one of: %s", format, strings.Join([]string{outputFormatJson, outputFormatYaml}, ", "))
+}
+
+// WriteJsonReport runs every check for the dynakube, which doesn't depend on a failed check, and writes the report as json to out,
+// the log of the checks is written to logWriter. It's used to include the checks in the support archive.
+func WriteJsonReport(apiReader client.Reader, namespace string, dynakube string, out io.Writer, logWriter io.Writer) error {
+	troubleshootCtx := troubleshootContext{
+		apiReader:     apiReader,
+		httpClient:    newHttpClient(),
+		namespaceName: namespace,
+		dynakubeName:  dynakube,
+		logOutput:     logWriter,
+	}
+	report := runChecks(&troubleshootCtx, getChecks(&troubleshootCtx), true)
+	return printReport(out, report, outputFormatJson)
+}
+
+// Diagnose runs every check for the dynakube, which doesn't depend on a failed check, and returns the failures and warnings.
+// It's used for the periodic diagnostics of the operator, the log of the checks is discarded.
+func Diagnose(apiReader client.Reader, namespace string, dynakube string) []diagnostics.Finding {
+	troubleshootCtx := troubleshootContext{
+		apiReader:     apiReader,
+		httpClient:    newHttpClient(),
+		namespaceName: namespace,
+		dynakubeName:  dynakube,
+		logOutput:     io.Discard,
+	}
+	report := runChecks(&troubleshootCtx, getChecks(&troubleshootCtx), true)
+
+	findings := []diagnostics.Finding{}
+	for _, result := range report.Checks {
+		if result.Status == statusFailed || result.Status == statusWarning {
+			findings = append(findings, diagnostics.Finding{
+				Check:   result.Name,
+				Warning: result.Status == statusWarning,
+				Message: result.Message,
+			})
+		}
+	}
+	return findings
+}
+
+// printReport prints the report in the given structured format, the text format is already covered by the log
+func printReport(out io.Writer, report troubleshootReport, format string) error {
+	var data []byte
+	var err error
+
+	switch format {
+	case outputFormatJson:
+		data, err = json.MarshalIndent(report, "", "  ")
+		data = append(data, '\n')
+	case outputFormatYaml:
+		data, err = yaml.Marshal(report)
+	default:
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = out.Write(data)
+	return errors.WithStack(err)
+}
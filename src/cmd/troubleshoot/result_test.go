@@ -0,0 +1,169 @@
+package troubleshoot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRunChecks(t *testing.T) {
+	t.Run(`all checks pass`, func(t *testing.T) {
+		report := runChecks(newTestTroubleshootContext(), createTestChecks(nil, nil, nil), false)
+
+		assert.Equal(t, statusPassed, report.Status)
+		assert.Equal(t, []checkStatus{statusPassed, statusPassed, statusPassed}, getStatuses(report))
+		assert.Equal(t, exitCodePassed, report.exitCode())
+		assert.Equal(t, testNamespace, report.Namespace)
+		assert.Equal(t, testDynakube, report.Dynakube)
+	})
+	t.Run(`warnings don't stop the checks`, func(t *testing.T) {
+		report := runChecks(newTestTroubleshootContext(), createTestChecks(nil, newWarningf("warning"), nil), false)
+
+		assert.Equal(t, statusWarning, report.Status)
+		assert.Equal(t, []checkStatus{statusPassed, statusWarning, statusPassed}, getStatuses(report))
+		assert.Equal(t, exitCodeWarnings, report.exitCode())
+		assert.Equal(t, "warning", report.Checks[1].Message)
+		assert.NotEmpty(t, report.Checks[1].Remediation)
+	})
+	t.Run(`first failure skips the remaining checks`, func(t *testing.T) {
+		report := runChecks(newTestTroubleshootContext(), createTestChecks(fmt.Errorf("failure"), nil, nil), false)
+
+		assert.Equal(t, statusFailed, report.Status)
+		assert.Equal(t, []checkStatus{statusFailed, statusSkipped, statusSkipped}, getStatuses(report))
+		assert.Equal(t, exitCodeFailures, report.exitCode())
+		assert.Equal(t, "failure", report.Checks[0].Message)
+	})
+	t.Run(`continue on error runs independent checks`, func(t *testing.T) {
+		report := runChecks(newTestTroubleshootContext(), createTestChecks(nil, fmt.Errorf("failure"), newWarningf("warning")), true)
+
+		assert.Equal(t, statusFailed, report.Status)
+		assert.Equal(t, []checkStatus{statusPassed, statusFailed, statusWarning}, getStatuses(report))
+	})
+	t.Run(`continue on error skips dependent checks`, func(t *testing.T) {
+		report := runChecks(newTestTroubleshootContext(), createTestChecks(fmt.Errorf("failure"), nil, nil), true)
+
+		assert.Equal(t, []checkStatus{statusFailed, statusSkipped, statusSkipped}, getStatuses(report))
+		assert.Equal(t, "skipped, because the 'first' check failed", report.Checks[1].Message)
+	})
+	t.Run(`log is written to the output of the context`, func(t *testing.T) {
+		out := &bytes.Buffer{}
+		other := &bytes.Buffer{}
+		troubleshootCtx := newTestTroubleshootContext()
+		troubleshootCtx.logOutput = out
+		otherTroubleshootCtx := newTestTroubleshootContext()
+		otherTroubleshootCtx.logOutput = other
+
+		runChecks(troubleshootCtx, createTestChecks(nil, newWarningf("first warning"), nil), false)
+		runChecks(otherTroubleshootCtx, createTestChecks(nil, newWarningf("other warning"), nil), false)
+
+		assert.Contains(t, out.String(), "first warning")
+		assert.NotContains(t, out.String(), "other warning")
+		assert.Contains(t, other.String(), "other warning")
+	})
+}
+
+func TestGetChecks(t *testing.T) {
+	t.Run(`pod check only with pod flag`, func(t *testing.T) {
+		troubleshootCtx := newTestTroubleshootContext()
+		assert.NotContains(t, getCheckNames(getChecks(troubleshootCtx)), "pod")
+
+		troubleshootCtx.podNamespace, troubleshootCtx.podName = "app", "app-pod"
+		assert.Contains(t, getCheckNames(getChecks(troubleshootCtx)), "pod")
+	})
+	t.Run(`prerequisites are checked before`, func(t *testing.T) {
+		checked := map[string]bool{}
+		for _, check := range getChecks(newTestTroubleshootContext()) {
+			for _, prerequisite := range check.prerequisites {
+				assert.True(t, checked[prerequisite], "%s must run before %s", prerequisite, check.name)
+			}
+			checked[check.name] = true
+		}
+	})
+}
+
+func TestPrintReport(t *testing.T) {
+	report := runChecks(newTestTroubleshootContext(), createTestChecks(nil, fmt.Errorf("failure"), nil), false)
+
+	t.Run(`json`, func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, printReport(out, report, outputFormatJson))
+
+		var printed troubleshootReport
+		require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+		assert.Equal(t, report, printed)
+	})
+	t.Run(`yaml`, func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, printReport(out, report, outputFormatYaml))
+
+		var printed troubleshootReport
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &printed))
+		assert.Equal(t, report, printed)
+	})
+	t.Run(`text is covered by the log`, func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, printReport(out, report, outputFormatText))
+
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestDiagnose(t *testing.T) {
+	t.Run(`failures are returned, skipped checks are not`, func(t *testing.T) {
+		apiReader := newWebhookTroubleshootContext().apiReader
+
+		findings := Diagnose(apiReader, testNamespace, testDynakube)
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, "namespace", findings[0].Check)
+		assert.False(t, findings[0].Warning)
+		assert.NotEmpty(t, findings[0].Message)
+	})
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat(outputFormatText))
+	assert.NoError(t, validateOutputFormat(outputFormatJson))
+	assert.NoError(t, validateOutputFormat(outputFormatYaml))
+	assert.Error(t, validateOutputFormat("xml"))
+}
+
+func newTestTroubleshootContext() *troubleshootContext {
+	return &troubleshootContext{namespaceName: testNamespace, dynakubeName: testDynakube}
+}
+
+// createTestChecks creates three checks, the second and third one depend on the first one
+func createTestChecks(first, second, third error) []troubleshootCheck {
+	return []troubleshootCheck{
+		{name: "first", run: returning(first), remediation: "fix first"},
+		{name: "second", run: returning(second), remediation: "fix second", prerequisites: []string{"first"}},
+		{name: "third", run: returning(third), remediation: "fix third", prerequisites: []string{"first"}},
+	}
+}
+
+func returning(err error) troubleshootFunc {
+	return func(_ *troubleshootContext) error {
+		return err
+	}
+}
+
+func getCheckNames(checks []troubleshootCheck) []string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.name)
+	}
+	return names
+}
+
+func getStatuses(report troubleshootReport) []checkStatus {
+	statuses := make([]checkStatus, 0, len(report.Checks))
+	for _, result := range report.Checks {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
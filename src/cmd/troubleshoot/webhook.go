@@ -0,0 +1,164 @@
+package troubleshoot
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certificateExpiryThreshold is the renewal threshold of the certificates controller,
+// certificates this close to their expiry should have been renewed already
+const certificateExpiryThreshold = 12 * time.Hour
+
+func checkWebhook(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[webhook   ] ")
+
+	troubleshootCtx.logNewTestf("checking if the webhook is ready ...")
+
+	err := runSubChecks(troubleshootCtx, []troubleshootFunc{
+		checkWebhookService,
+		checkWebhookEndpoints,
+	})
+	if err != nil && !isWarning(err) {
+		return errors.Wrap(err, "webhook isn't ready")
+	}
+
+	troubleshootCtx.logOkf("webhook is ready")
+	return err
+}
+
+func checkWebhookService(troubleshootCtx *troubleshootContext) error {
+	var service corev1.Service
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtwebhook.DeploymentName, Namespace: troubleshootCtx.namespaceName}, &service); err != nil {
+		return errorWithMessagef(err, "'%s:%s' service is missing", troubleshootCtx.namespaceName, dtwebhook.DeploymentName)
+	}
+	troubleshootCtx.logInfof("'%s:%s' service exists", troubleshootCtx.namespaceName, dtwebhook.DeploymentName)
+	return nil
+}
+
+func checkWebhookEndpoints(troubleshootCtx *troubleshootContext) error {
+	var endpoints corev1.Endpoints
+	if err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtwebhook.DeploymentName, Namespace: troubleshootCtx.namespaceName}, &endpoints); err != nil {
+		return errorWithMessagef(err, "'%s:%s' endpoints are missing", troubleshootCtx.namespaceName, dtwebhook.DeploymentName)
+	}
+
+	readyAddresses, notReadyAddresses := 0, 0
+	for _, subset := range endpoints.Subsets {
+		readyAddresses += len(subset.Addresses)
+		notReadyAddresses += len(subset.NotReadyAddresses)
+	}
+	if readyAddresses == 0 {
+		return errors.Errorf("'%s:%s' service has no ready endpoints, check the pods of the '%s' deployment",
+			troubleshootCtx.namespaceName, dtwebhook.DeploymentName, dtwebhook.DeploymentName)
+	}
+	if notReadyAddresses > 0 {
+		return newWarningf("'%s:%s' service has %d ready and %d not ready endpoints",
+			troubleshootCtx.namespaceName, dtwebhook.DeploymentName, readyAddresses, notReadyAddresses)
+	}
+
+	troubleshootCtx.logInfof("'%s:%s' service has %d ready endpoints", troubleshootCtx.namespaceName, dtwebhook.DeploymentName, readyAddresses)
+	return nil
+}
+
+func checkCertificates(troubleshootCtx *troubleshootContext) error {
+	troubleshootCtx.log = newTroubleshootLogger(troubleshootCtx.logOutput, "[certs     ] ")
+
+	troubleshootCtx.logNewTestf("checking if the webhook certificates are valid ...")
+
+	err := runSubChecks(troubleshootCtx, []troubleshootFunc{
+		getCertificatesSecretIfItExists,
+		checkCertificatesExpiration,
+		checkMutatingWebhookConfigurationCABundle,
+		checkValidatingWebhookConfigurationCABundle,
+	})
+	if err != nil && !isWarning(err) {
+		return errors.Wrap(err, "webhook certificates aren't valid")
+	}
+
+	troubleshootCtx.logOkf("webhook certificates are valid")
+	return err
+}
+
+func getCertificatesSecretIfItExists(troubleshootCtx *troubleshootContext) error {
+	query := kubeobjects.NewSecretQuery(context.TODO(), nil, troubleshootCtx.apiReader, troubleshootCtx.logger())
+	secret, err := query.Get(client.ObjectKey{Name: dtwebhook.SecretCertsName, Namespace: troubleshootCtx.namespaceName})
+	if err != nil {
+		return errorWithMessagef(err, "'%s:%s' certificates secret is missing", troubleshootCtx.namespaceName, dtwebhook.SecretCertsName)
+	}
+	if len(secret.Data[certificates.RootCert]) == 0 {
+		return errors.Errorf("'%s:%s' certificates secret has no '%s'", troubleshootCtx.namespaceName, dtwebhook.SecretCertsName, certificates.RootCert)
+	}
+	troubleshootCtx.certificatesSecret = secret
+
+	troubleshootCtx.logInfof("'%s:%s' certificates secret exists", troubleshootCtx.namespaceName, dtwebhook.SecretCertsName)
+	return nil
+}
+
+func checkCertificatesExpiration(troubleshootCtx *troubleshootContext) error {
+	for _, certName := range []string{certificates.RootCert, certificates.ServerCert} {
+		isValid, err := kubeobjects.ValidateCertificateExpiration(troubleshootCtx.certificatesSecret.Data[certName], certificateExpiryThreshold, time.Now(), troubleshootCtx.logger())
+		if err != nil || !isValid {
+			return newWarningf("'%s' of the certificates secret is invalid or expires within %s, check the log of the operator", certName, certificateExpiryThreshold)
+		}
+	}
+
+	troubleshootCtx.logInfof("certificates don't expire within %s", certificateExpiryThreshold)
+	return nil
+}
+
+func checkMutatingWebhookConfigurationCABundle(troubleshootCtx *troubleshootContext) error {
+	var webhookConfiguration admissionregistrationv1.MutatingWebhookConfiguration
+	err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtwebhook.DeploymentName}, &webhookConfiguration)
+	if k8serrors.IsNotFound(err) {
+		return newWarningf("mutating webhook configuration '%s' not found, this is normal when deployed using OLM", dtwebhook.DeploymentName)
+	} else if err != nil {
+		return errorWithMessagef(err, "failed to query the mutating webhook configuration '%s'", dtwebhook.DeploymentName)
+	}
+
+	for _, mutatingWebhook := range webhookConfiguration.Webhooks {
+		if err := checkCABundle(troubleshootCtx, mutatingWebhook.Name, mutatingWebhook.ClientConfig); err != nil {
+			return err
+		}
+	}
+
+	troubleshootCtx.logInfof("CA bundle of the mutating webhook configuration '%s' matches the certificates", dtwebhook.DeploymentName)
+	return nil
+}
+
+func checkValidatingWebhookConfigurationCABundle(troubleshootCtx *troubleshootContext) error {
+	var webhookConfiguration admissionregistrationv1.ValidatingWebhookConfiguration
+	err := troubleshootCtx.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtwebhook.DeploymentName}, &webhookConfiguration)
+	if k8serrors.IsNotFound(err) {
+		return newWarningf("validating webhook configuration '%s' not found, this is normal when deployed using OLM", dtwebhook.DeploymentName)
+	} else if err != nil {
+		return errorWithMessagef(err, "failed to query the validating webhook configuration '%s'", dtwebhook.DeploymentName)
+	}
+
+	for _, validatingWebhook := range webhookConfiguration.Webhooks {
+		if err := checkCABundle(troubleshootCtx, validatingWebhook.Name, validatingWebhook.ClientConfig); err != nil {
+			return err
+		}
+	}
+
+	troubleshootCtx.logInfof("CA bundle of the validating webhook configuration '%s' matches the certificates", dtwebhook.DeploymentName)
+	return nil
+}
+
+// checkCABundle compares the CA bundle with the root certificate, the certificates controller appends the previous root certificate to the bundle
+func checkCABundle(troubleshootCtx *troubleshootContext, webhookName string, clientConfig admissionregistrationv1.WebhookClientConfig) error {
+	rootCert := troubleshootCtx.certificatesSecret.Data[certificates.RootCert]
+	if len(clientConfig.CABundle) == 0 || !bytes.HasPrefix(clientConfig.CABundle, rootCert) {
+		return errors.Errorf("CA bundle of webhook '%s' doesn't match the '%s' of the certificates secret, restart the operator to update it",
+			webhookName, certificates.RootCert)
+	}
+	return nil
+}
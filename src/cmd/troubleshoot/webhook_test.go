@@ -0,0 +1,154 @@
+package troubleshoot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Run("webhook is ready", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(testBuildWebhookService(), testBuildWebhookEndpoints(1, 0))
+
+		assert.NoError(t, checkWebhook(troubleshootCtx))
+	})
+	t.Run("service is missing", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(testBuildWebhookEndpoints(1, 0))
+
+		err := checkWebhook(troubleshootCtx)
+		require.Error(t, err)
+		assert.False(t, isWarning(err))
+	})
+	t.Run("no ready endpoints", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(testBuildWebhookService(), testBuildWebhookEndpoints(0, 1))
+
+		err := checkWebhook(troubleshootCtx)
+		require.Error(t, err)
+		assert.False(t, isWarning(err))
+		assert.Contains(t, err.Error(), "no ready endpoints")
+	})
+	t.Run("some endpoints not ready", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(testBuildWebhookService(), testBuildWebhookEndpoints(1, 1))
+
+		assert.True(t, isWarning(checkWebhook(troubleshootCtx)))
+	})
+}
+
+func TestCertificates(t *testing.T) {
+	validCerts := testCreateCertificates(t, time.Now())
+
+	t.Run("certificates are valid", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(
+			testBuildCertificatesSecret(validCerts),
+			testBuildMutatingWebhookConfiguration(validCerts[certificates.RootCert]),
+			testBuildValidatingWebhookConfiguration(append(validCerts[certificates.RootCert], []byte("old")...)))
+
+		assert.NoError(t, checkCertificates(troubleshootCtx))
+	})
+	t.Run("certificates secret is missing", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext()
+
+		err := checkCertificates(troubleshootCtx)
+		require.Error(t, err)
+		assert.False(t, isWarning(err))
+	})
+	t.Run("CA bundle doesn't match", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(
+			testBuildCertificatesSecret(validCerts),
+			testBuildMutatingWebhookConfiguration([]byte("other")),
+			testBuildValidatingWebhookConfiguration(validCerts[certificates.RootCert]))
+
+		err := checkCertificates(troubleshootCtx)
+		require.Error(t, err)
+		assert.False(t, isWarning(err))
+		assert.Contains(t, err.Error(), "CA bundle of webhook")
+	})
+	t.Run("missing webhook configurations are warnings", func(t *testing.T) {
+		troubleshootCtx := newWebhookTroubleshootContext(testBuildCertificatesSecret(validCerts))
+
+		assert.True(t, isWarning(checkCertificates(troubleshootCtx)))
+	})
+	t.Run("certificates near expiry are warnings", func(t *testing.T) {
+		// the server certificate is valid for 7 days
+		expiringCerts := testCreateCertificates(t, time.Now().Add(-7*24*time.Hour+time.Hour))
+		troubleshootCtx := newWebhookTroubleshootContext(
+			testBuildCertificatesSecret(expiringCerts),
+			testBuildMutatingWebhookConfiguration(expiringCerts[certificates.RootCert]),
+			testBuildValidatingWebhookConfiguration(expiringCerts[certificates.RootCert]))
+
+		err := checkCertificates(troubleshootCtx)
+		require.Error(t, err)
+		assert.True(t, isWarning(err))
+		assert.Contains(t, err.Error(), certificates.ServerCert)
+	})
+}
+
+func newWebhookTroubleshootContext(objects ...client.Object) *troubleshootContext {
+	clt := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objects...).
+		Build()
+	return &troubleshootContext{apiReader: clt, namespaceName: testNamespace, dynakubeName: testDynakube}
+}
+
+func testCreateCertificates(t *testing.T, now time.Time) map[string][]byte {
+	certs := certificates.Certs{
+		Domain: dtwebhook.DeploymentName + "." + testNamespace + ".svc",
+		Now:    now,
+	}
+	require.NoError(t, certs.ValidateCerts())
+	return certs.Data
+}
+
+func testBuildWebhookService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName, Namespace: testNamespace},
+	}
+}
+
+func testBuildWebhookEndpoints(ready int, notReady int) *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName, Namespace: testNamespace},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses:         make([]corev1.EndpointAddress, ready),
+				NotReadyAddresses: make([]corev1.EndpointAddress, notReady),
+			},
+		},
+	}
+}
+
+func testBuildCertificatesSecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.SecretCertsName, Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func testBuildMutatingWebhookConfiguration(caBundle []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "webhook.pod.dynatrace.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle}},
+		},
+	}
+}
+
+func testBuildValidatingWebhookConfiguration(caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.DeploymentName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "webhook.dynatrace.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle}},
+		},
+	}
+}
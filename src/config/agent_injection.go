@@ -13,8 +13,17 @@ const (
 
 	AgentCurlOptionsFileName = "curl_options.conf"
 
+	AgentDownloadResultFileName = "download_result.json"
+	// AgentFlavorDownloadResultFileNameTemplate is used for the flavors of single containers
+	AgentFlavorDownloadResultFileNameTemplate = "download_result_%s.json"
+	// AgentFlavorSubPathTemplate is the directory in the bin volume, the flavors of single containers are installed into
+	AgentFlavorSubPathTemplate = "flavors/%s"
+
 	AgentInstallerMode InstallMode = "installer"
 	AgentCsiMode       InstallMode = "provisioned"
+	AgentOfflineMode   InstallMode = "offline"
+
+	AgentOfflinePackageFilename = "oneagent.zip"
 
 	AgentInstallModeEnv     = "MODE"
 	AgentInstallerUrlEnv    = "INSTALLER_URL"
@@ -26,9 +35,13 @@ const (
 	AgentContainerNameEnvTemplate  = "CONTAINER_%d_NAME"
 	AgentContainerImageEnvTemplate = "CONTAINER_%d_IMAGE"
 
+	AgentContainerFlavorEnvTemplate       = "CONTAINER_%d_FLAVOR"
+	AgentContainerTechnologiesEnvTemplate = "CONTAINER_%d_TECHNOLOGIES"
+
 	AgentInjectedEnv = "ONEAGENT_INJECTED"
 
-	AgentBinDirMount    = "/mnt/bin"
-	AgentShareDirMount  = "/mnt/share"
-	AgentConfigDirMount = "/mnt/config"
+	AgentBinDirMount     = "/mnt/bin"
+	AgentShareDirMount   = "/mnt/share"
+	AgentConfigDirMount  = "/mnt/config"
+	AgentOfflineDirMount = "/mnt/offline"
 )
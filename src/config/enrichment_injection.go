@@ -6,6 +6,11 @@ const (
 	EnrichmentInjectedEnv        = "DATA_INGEST_INJECTED"
 	EnrichmentWorkloadKindEnv    = "DT_WORKLOAD_KIND"
 	EnrichmentWorkloadNameEnv    = "DT_WORKLOAD_NAME"
+	EnrichmentMetadataEnv        = "DT_METADATA_ATTRIBUTES"
+
+	EnrichmentPodLabelPrefix       = "k8s.pod.label."
+	EnrichmentPodAnnotationPrefix  = "k8s.pod.annotation."
+	EnrichmentNamespaceLabelPrefix = "k8s.namespace.label."
 )
 
 var (
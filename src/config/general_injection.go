@@ -1,7 +1,12 @@
 package config
 
 const (
-	InjectionFailurePolicyEnv = "FAILURE_POLICY"
+	InjectionFailurePolicyEnv    = "FAILURE_POLICY"
+	InjectionReportAnnotationEnv = "INJECTION_REPORT_ANNOTATION"
+
+	InjectionReportFileName = "injection_report.json"
+	// InjectionReportAnnotation is added to the injected pods by the init container, if it is enabled by the feature flag
+	InjectionReportAnnotation = "dynakube.dynatrace.com/injection-report"
 
 	K8sNodeNameEnv    = "K8S_NODE_NAME"
 	K8sPodNameEnv     = "K8S_PODNAME"
@@ -30,15 +30,62 @@ const (
 	OverlayVarDirPath    = "var"
 	OverlayWorkDirPath   = "work"
 	SharedAgentBinDir    = "codemodules"
+	AgentContentStoreDir = "contentstore"
 	SharedAgentConfigDir = "config"
 
 	DaemonSetName = "dynatrace-oneagent-csi-driver"
+
+	// ProvisionerMetricsPort is the port of the metrics server of the provisioner, it also serves the MetadataOverviewPath
+	ProvisionerMetricsPort = 8090
+	// MetadataOverviewPath serves the overview of the metadata database as json, it's collected by the support-archive command
+	// it's only served if the provisioner runs with --serve-metadata-overview
+	MetadataOverviewPath = "/metadata"
+
+	AgentProcessModuleConfigPath = "agent/conf/ruxitagentproc.conf"
+
+	// CodeModulesNotReadyTaint can be added to new nodes (for example by the node template of the cluster autoscaler),
+	// the csi provisioner removes it once the code modules of every dynakube are ready on the node
+	CodeModulesNotReadyTaint = DriverName + "/not-ready"
 )
 
+// AgentWritableDirs are the directories of the agent installation that have to be writable,
+// if the agent binaries are mounted read-only
+var AgentWritableDirs = []string{"log", "var", "config"}
+
 var MetadataAccessPath = filepath.Join(DataPath, "csi.db")
 
+// CodeModulesReadyLabel is set on a node by the csi provisioner, when the code modules of the dynakube can be mounted on the node
+func CodeModulesReadyLabel(dynakubeName string) string {
+	return DriverName + "/" + dynakubeName
+}
+
 type CSIOptions struct {
-	NodeId   string
-	Endpoint string
-	RootDir  string
+	NodeId           string
+	Endpoint         string
+	RootDir          string
+	AppMountStrategy string
+}
+
+const (
+	// NodeStatusDataDirUsedKey and NodeStatusDataDirTotalKey hold the disk usage of the filesystem of the csi data dir in bytes
+	NodeStatusDataDirUsedKey  = "dataDirUsedBytes"
+	NodeStatusDataDirTotalKey = "dataDirTotalBytes"
+
+	nodeStatusTenantUUIDKeySuffix = ".tenantUUID"
+	nodeStatusVersionKeySuffix    = ".version"
+)
+
+// NodeStatusConfigMapName is the name of the config map the csi provisioner publishes the status of its node in
+func NodeStatusConfigMapName(nodeName string) string {
+	return DaemonSetName + "-status-" + nodeName
+}
+
+// NodeStatusTenantUUIDKey is the key of the node status config map holding the tenant of the dynakube
+func NodeStatusTenantUUIDKey(dynakubeName string) string {
+	return dynakubeName + nodeStatusTenantUUIDKeySuffix
+}
+
+// NodeStatusVersionKey is the key of the node status config map holding the code modules version installed for the dynakube
+func NodeStatusVersionKey(dynakubeName string) string {
+	return dynakubeName + nodeStatusVersionKeySuffix
 }
@@ -17,8 +17,31 @@ var (
 		Help:      "Memory usage of the csi driver in bytes",
 	})
 	memoryMetricTick = 5000 * time.Millisecond
+
+	mountReconciliationMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "csi_driver",
+		Name:      "mount_reconciliation_fixes",
+		Help:      "Number of inconsistencies between the mounts and the csi metadata fixed by the mount reconciliation",
+	}, []string{"action"})
+	mountReconciliationFailuresMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "csi_driver",
+		Name:      "mount_reconciliation_failures",
+		Help:      "Number of inconsistencies between the mounts and the csi metadata the mount reconciliation failed to fix",
+	}, []string{"action"})
+	mountReconciliationRunsMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "csi_driver",
+		Name:      "mount_reconciliation_runs",
+		Help:      "Number of mount reconciliation runs",
+	})
+	mountReconciliationTick = 5 * time.Minute
 )
 
 func init() {
 	metrics.Registry.MustRegister(memoryUsageMetric)
+	metrics.Registry.MustRegister(mountReconciliationMetric)
+	metrics.Registry.MustRegister(mountReconciliationFailuresMetric)
+	metrics.Registry.MustRegister(mountReconciliationRunsMetric)
 }
@@ -0,0 +1,405 @@
+package csidriver
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	appvolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes/app"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/mount"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	mountInfoPath = "/proc/self/mountinfo"
+
+	overlayFsType  = "overlay"
+	lowerDirOption = "lowerdir="
+
+	removedOrphanedMountEvent      = "RemovedOrphanedMount"
+	removedStaleVolumeEvent        = "RemovedStaleVolume"
+	reregisteredVolumeEvent        = "ReregisteredVolume"
+	correctedOsAgentVolumeEvent    = "CorrectedOsAgentVolume"
+	reregisteredOsAgentVolumeEvent = "ReregisteredOsAgentVolume"
+)
+
+// targetPathRegex matches the target paths kubelet hands to the driver, for example:
+// /var/lib/kubelet/pods/<pod-uid>/volumes/kubernetes.io~csi/<volume-id>/mount
+var targetPathRegex = regexp.MustCompile(`/pods/([^/]+)/volumes/kubernetes\.io~csi/([^/]+)/mount$`)
+
+type overlayMount struct {
+	tenantUUID string
+	volumeID   string
+	version    string
+	path       string
+}
+
+type targetMount struct {
+	podUID     types.UID
+	volumeID   string
+	tenantUUID string
+	path       string
+}
+
+// mountState is the part of the mount table of the node that belongs to the csi driver
+type mountState struct {
+	overlays    map[string]overlayMount
+	appTargets  map[string]targetMount
+	hostTargets map[string]targetMount
+}
+
+// mountReconciler compares the mounts of the node with the stored csi metadata
+// and fixes the differences that are left behind when the csi driver crashes or is restarted.
+type mountReconciler struct {
+	client        client.Client
+	apiReader     client.Reader
+	opts          dtcsi.CSIOptions
+	fs            afero.Afero
+	mounter       mount.Interface
+	db            metadata.Access
+	path          metadata.PathResolver
+	recorder      record.EventRecorder
+	mountLock     *sync.RWMutex
+	mountInfoPath string
+	mountStrategy string
+}
+
+func newMountReconciler(svr *CSIDriverServer) *mountReconciler {
+	return &mountReconciler{
+		client:        svr.client,
+		apiReader:     svr.apiReader,
+		opts:          svr.opts,
+		fs:            svr.fs,
+		mounter:       svr.mounter,
+		db:            svr.db,
+		path:          svr.path,
+		recorder:      svr.recorder,
+		mountLock:     &svr.mountLock,
+		mountInfoPath: mountInfoPath,
+		mountStrategy: svr.mountStrategy,
+	}
+}
+
+// Reconcile blocks publishing and unpublishing of volumes while the mounts are compared to the metadata.
+func (reconciler *mountReconciler) Reconcile(ctx context.Context) error {
+	reconciler.mountLock.Lock()
+	defer reconciler.mountLock.Unlock()
+	mountReconciliationRunsMetric.Inc()
+
+	state, err := reconciler.readMountState()
+	if err != nil {
+		return err
+	}
+	log.Info("read csi mounts of the node", "overlays", len(state.overlays), "appTargets", len(state.appTargets), "hostTargets", len(state.hostTargets))
+
+	// the osagent volumes are reconciled even if some app volumes couldn't be fixed
+	appVolumesErr := reconciler.reconcileAppVolumes(ctx, state)
+	if err := reconciler.reconcileOsAgentVolumes(ctx, state); err != nil {
+		return err
+	}
+	return appVolumesErr
+}
+
+func (reconciler *mountReconciler) readMountState() (*mountState, error) {
+	mountInfos, err := mount.ParseMountInfo(reconciler.mountInfoPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	state := &mountState{
+		overlays:    map[string]overlayMount{},
+		appTargets:  map[string]targetMount{},
+		hostTargets: map[string]targetMount{},
+	}
+	for _, mountInfo := range mountInfos {
+		if overlay := reconciler.parseOverlayMount(mountInfo); overlay != nil {
+			// the bind mount strategy has several mounts in the mapped directory, the version is taken from the one that has it
+			if known, ok := state.overlays[overlay.volumeID]; ok && overlay.version == "" {
+				overlay.version = known.version
+			}
+			state.overlays[overlay.volumeID] = *overlay
+			continue
+		}
+		target := parseTargetMount(mountInfo)
+		if target == nil {
+			continue
+		}
+		// targets of other csi drivers end up in appTargets as well, they are ignored as they have no mapped directory
+		if target.tenantUUID == "" {
+			state.appTargets[target.volumeID] = *target
+		} else {
+			state.hostTargets[target.volumeID] = *target
+		}
+	}
+	return state, nil
+}
+
+// parseOverlayMount returns the mapped directory of a mount in the form of <root-dir>/<tenant-uuid>/run/<volume-id>/mapped[/...],
+// depending on the mount strategy of the node it's either an overlay of the agent binaries mounted at the mapped directory
+// or one of the read-only bind mounts of the entries of the agent binaries mounted in the mapped directory
+func (reconciler *mountReconciler) parseOverlayMount(mountInfo mount.MountInfo) *overlayMount {
+	relativePath, err := filepath.Rel(reconciler.opts.RootDir, mountInfo.MountPoint)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(relativePath, string(filepath.Separator))
+	if len(parts) < 4 || parts[1] != dtcsi.AgentRunDir || parts[3] != dtcsi.OverlayMappedDirPath {
+		return nil
+	}
+	var version string
+	switch {
+	case mountInfo.FsType == overlayFsType:
+		version = reconciler.parseVersion(mountInfo.SuperOptions)
+	case len(parts) == 4:
+		version = parseBindVersion(mountInfo.Root)
+	case len(parts) == 5:
+		version = parseBindVersion(filepath.Dir(mountInfo.Root))
+	}
+	return &overlayMount{
+		tenantUUID: parts[0],
+		volumeID:   parts[2],
+		version:    version,
+		path:       filepath.Join(reconciler.opts.RootDir, filepath.Join(parts[:4]...)),
+	}
+}
+
+// parseBindVersion returns the version (or image digest) from the root of a bind mounted binary directory,
+// the root is relative to the filesystem of the node, so only the end of the path is checked
+func parseBindVersion(root string) string {
+	switch filepath.Base(filepath.Dir(root)) {
+	case dtcsi.AgentBinaryDir, dtcsi.SharedAgentBinDir:
+		return filepath.Base(root)
+	}
+	return ""
+}
+
+// parseVersion returns the version (or image digest) the same way it's stored by the app volume publisher
+func (reconciler *mountReconciler) parseVersion(superOptions []string) string {
+	for _, option := range superOptions {
+		if !strings.HasPrefix(option, lowerDirOption) {
+			continue
+		}
+		for _, lowerDir := range strings.Split(strings.TrimPrefix(option, lowerDirOption), ":") {
+			relativePath, err := filepath.Rel(reconciler.opts.RootDir, lowerDir)
+			if err != nil {
+				continue
+			}
+			parts := strings.Split(relativePath, string(filepath.Separator))
+			if len(parts) == 3 && parts[1] == dtcsi.AgentBinaryDir {
+				return parts[2]
+			}
+			if len(parts) == 2 && parts[0] == dtcsi.SharedAgentBinDir {
+				return parts[1]
+			}
+		}
+	}
+	return ""
+}
+
+// parseTargetMount returns the mount if its mount point is a csi target path,
+// for host volumes the tenantUUID is taken from the root of the bind mount (.../<tenant-uuid>/osagent)
+func parseTargetMount(mountInfo mount.MountInfo) *targetMount {
+	matches := targetPathRegex.FindStringSubmatch(mountInfo.MountPoint)
+	if matches == nil {
+		return nil
+	}
+	target := &targetMount{
+		podUID:   types.UID(matches[1]),
+		volumeID: matches[2],
+		path:     mountInfo.MountPoint,
+	}
+	if filepath.Base(mountInfo.Root) == "osagent" {
+		target.tenantUUID = filepath.Base(filepath.Dir(mountInfo.Root))
+	}
+	return target
+}
+
+func (reconciler *mountReconciler) reconcileAppVolumes(ctx context.Context, state *mountState) error {
+	volumes, err := reconciler.db.GetAllVolumes()
+	if err != nil {
+		return err
+	}
+	storedVolumes := map[string]*metadata.Volume{}
+	for _, volume := range volumes {
+		storedVolumes[volume.VolumeID] = volume
+		if _, ok := state.overlays[volume.VolumeID]; ok {
+			continue
+		}
+		if err := reconciler.removeStaleVolume(ctx, volume); err != nil {
+			return err
+		}
+	}
+
+	var failedVolumeIDs []string
+	for volumeID, overlay := range state.overlays {
+		if _, ok := storedVolumes[volumeID]; ok {
+			continue
+		}
+		if target, ok := state.appTargets[volumeID]; ok {
+			if err := reconciler.reregisterVolume(ctx, overlay, target); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := reconciler.removeOrphanedMount(ctx, overlay); err != nil {
+			log.Error(err, "failed to remove orphaned mount", "volumeID", volumeID, "path", overlay.path)
+			failedVolumeIDs = append(failedVolumeIDs, volumeID)
+		}
+	}
+	if len(failedVolumeIDs) > 0 {
+		sort.Strings(failedVolumeIDs)
+		return errors.Errorf("failed to remove the orphaned mounts of the volumes %s", strings.Join(failedVolumeIDs, ", "))
+	}
+	return nil
+}
+
+// removeStaleVolume removes a volume entry that has no overlay mount anymore, so no pod can be using it
+func (reconciler *mountReconciler) removeStaleVolume(ctx context.Context, volume *metadata.Volume) error {
+	log.Info("removing stale volume entry", "volumeID", volume.VolumeID, "pod", volume.PodName, "tenantUUID", volume.TenantUUID)
+	if err := reconciler.db.DeleteVolume(volume.VolumeID); err != nil {
+		return err
+	}
+	if err := reconciler.fs.RemoveAll(reconciler.path.AgentRunDirForVolume(volume.TenantUUID, volume.VolumeID)); err != nil {
+		log.Info("failed to remove run directory of stale volume", "volumeID", volume.VolumeID, "error", err.Error())
+	}
+	mountReconciliationMetric.WithLabelValues(removedStaleVolumeEvent).Inc()
+	reconciler.sendTenantEvent(ctx, volume.TenantUUID, corev1.EventTypeNormal, removedStaleVolumeEvent,
+		"Removed volume %s of pod %s from the csi metadata, it was no longer mounted", volume.VolumeID, volume.PodName)
+	return nil
+}
+
+// removeOrphanedMount unmounts an overlay that is neither stored nor used by any pod,
+// the nested mounts of the mount strategy are unmounted before the overlay, as they keep it busy otherwise
+func (reconciler *mountReconciler) removeOrphanedMount(ctx context.Context, overlay overlayMount) error {
+	log.Info("removing orphaned overlay mount", "volumeID", overlay.volumeID, "path", overlay.path)
+	if err := appvolumes.UnmountAgent(reconciler.mountStrategy, reconciler.fs, reconciler.mounter, reconciler.path, overlay.path); err != nil {
+		mountReconciliationFailuresMetric.WithLabelValues(removedOrphanedMountEvent).Inc()
+		return err
+	}
+	if err := reconciler.fs.RemoveAll(reconciler.path.AgentRunDirForVolume(overlay.tenantUUID, overlay.volumeID)); err != nil {
+		log.Info("failed to remove run directory of orphaned mount", "volumeID", overlay.volumeID, "error", err.Error())
+	}
+	mountReconciliationMetric.WithLabelValues(removedOrphanedMountEvent).Inc()
+	reconciler.sendTenantEvent(ctx, overlay.tenantUUID, corev1.EventTypeNormal, removedOrphanedMountEvent,
+		"Unmounted orphaned volume %s, it was not used by any pod", overlay.volumeID)
+	return nil
+}
+
+// reregisterVolume stores a volume entry for an overlay that is still in use by a pod
+func (reconciler *mountReconciler) reregisterVolume(ctx context.Context, overlay overlayMount, target targetMount) error {
+	pod, err := reconciler.getPodOnNode(ctx, target.podUID)
+	if err != nil {
+		return err
+	}
+	if pod == nil || overlay.version == "" {
+		log.Info("can't re-register volume, pod or version unknown", "volumeID", overlay.volumeID, "podUID", target.podUID, "version", overlay.version)
+		return nil
+	}
+	volume := metadata.NewVolume(overlay.volumeID, pod.Name, overlay.version, overlay.tenantUUID)
+	log.Info("re-registering volume", "volumeID", volume.VolumeID, "pod", volume.PodName, "version", volume.Version, "tenantUUID", volume.TenantUUID)
+	if err := reconciler.db.InsertVolume(volume); err != nil {
+		return err
+	}
+	mountReconciliationMetric.WithLabelValues(reregisteredVolumeEvent).Inc()
+	reconciler.sendEvent(pod, corev1.EventTypeNormal, reregisteredVolumeEvent,
+		"Re-registered mounted volume %s in the csi metadata", volume.VolumeID)
+	return nil
+}
+
+func (reconciler *mountReconciler) reconcileOsAgentVolumes(ctx context.Context, state *mountState) error {
+	osVolumes, err := reconciler.db.GetAllOsAgentVolumes()
+	if err != nil {
+		return err
+	}
+	storedVolumes := map[string]*metadata.OsAgentVolume{}
+	for _, osVolume := range osVolumes {
+		storedVolumes[osVolume.TenantUUID] = osVolume
+		if _, ok := state.hostTargets[osVolume.VolumeID]; !osVolume.Mounted || ok {
+			continue
+		}
+		log.Info("marking osagent volume as unmounted", "volumeID", osVolume.VolumeID, "tenantUUID", osVolume.TenantUUID)
+		osVolume.Mounted = false
+		if err := reconciler.db.UpdateOsAgentVolume(osVolume); err != nil {
+			return err
+		}
+		mountReconciliationMetric.WithLabelValues(correctedOsAgentVolumeEvent).Inc()
+		reconciler.sendTenantEvent(ctx, osVolume.TenantUUID, corev1.EventTypeNormal, correctedOsAgentVolumeEvent,
+			"Marked osagent volume %s as unmounted, it was no longer mounted", osVolume.VolumeID)
+	}
+
+	for volumeID, target := range state.hostTargets {
+		osVolume, ok := storedVolumes[target.tenantUUID]
+		if ok && osVolume.Mounted {
+			continue
+		}
+		log.Info("re-registering osagent volume", "volumeID", volumeID, "tenantUUID", target.tenantUUID)
+		if err := reconciler.storeOsAgentVolume(osVolume, volumeID, target.tenantUUID); err != nil {
+			return err
+		}
+		mountReconciliationMetric.WithLabelValues(reregisteredOsAgentVolumeEvent).Inc()
+		reconciler.sendTenantEvent(ctx, target.tenantUUID, corev1.EventTypeNormal, reregisteredOsAgentVolumeEvent,
+			"Re-registered mounted osagent volume %s in the csi metadata", volumeID)
+	}
+	return nil
+}
+
+func (reconciler *mountReconciler) storeOsAgentVolume(osVolume *metadata.OsAgentVolume, volumeID, tenantUUID string) error {
+	timestamp := time.Now()
+	if osVolume == nil {
+		return reconciler.db.InsertOsAgentVolume(metadata.NewOsAgentVolume(volumeID, tenantUUID, true, &timestamp))
+	}
+	osVolume.VolumeID = volumeID
+	osVolume.Mounted = true
+	osVolume.LastModified = &timestamp
+	return reconciler.db.UpdateOsAgentVolume(osVolume)
+}
+
+// getPodOnNode looks up the pod using its UID, the APIReader is used as the pod can be in any namespace
+func (reconciler *mountReconciler) getPodOnNode(ctx context.Context, podUID types.UID) (*corev1.Pod, error) {
+	var podList corev1.PodList
+	err := reconciler.apiReader.List(ctx, &podList, client.MatchingFields{"spec.nodeName": reconciler.opts.NodeId})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range podList.Items {
+		if podList.Items[i].UID == podUID {
+			return &podList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// sendTenantEvent sends the event to every DynaKube that uses the given tenant
+func (reconciler *mountReconciler) sendTenantEvent(ctx context.Context, tenantUUID, eventType, reason, messageFmt string, args ...interface{}) {
+	var dynakubeList dynatracev1beta1.DynaKubeList
+	if err := reconciler.client.List(ctx, &dynakubeList); err != nil {
+		log.Info("failed to list dynakubes for event", "reason", reason, "error", err.Error())
+		return
+	}
+	for i := range dynakubeList.Items {
+		uuid, err := dynakubeList.Items[i].TenantUUID()
+		if err != nil || uuid != tenantUUID {
+			continue
+		}
+		reconciler.sendEvent(&dynakubeList.Items[i], eventType, reason, messageFmt, args...)
+	}
+}
+
+func (reconciler *mountReconciler) sendEvent(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if reconciler.recorder == nil {
+		return
+	}
+	reconciler.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
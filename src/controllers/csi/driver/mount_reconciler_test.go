@@ -0,0 +1,348 @@
+package csidriver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/mount"
+)
+
+const (
+	testRootDir      = "/data"
+	testTenantUUID   = "a-tenant-uuid"
+	testVersion      = "1.2.3"
+	testVolumeID     = "a-volume"
+	testPodUID       = "a-pod-uid"
+	testPodName      = "a-pod"
+	testNodeName     = "a-node"
+	testDynakubeName = "a-dynakube"
+)
+
+func TestMountReconciler_parseOverlayMount(t *testing.T) {
+	reconciler := mountReconciler{opts: dtcsi.CSIOptions{RootDir: testRootDir}}
+
+	t.Run(`url overlay`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/bin/1.2.3"))
+
+		require.NotNil(t, overlay)
+		assert.Equal(t, testTenantUUID, overlay.tenantUUID)
+		assert.Equal(t, testVolumeID, overlay.volumeID)
+		assert.Equal(t, testVersion, overlay.version)
+	})
+	t.Run(`image overlay`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/config:/data/codemodules/123456789"))
+
+		require.NotNil(t, overlay)
+		assert.Equal(t, "123456789", overlay.version)
+	})
+	t.Run(`bind mounted binaries`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(mount.MountInfo{
+			Root:       "/var/lib/kubelet/plugins/csi.oneagent.dynatrace.com/data/a-tenant-uuid/bin/1.2.3",
+			MountPoint: filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath),
+			FsType:     "ext4",
+		})
+
+		require.NotNil(t, overlay)
+		assert.Equal(t, testVolumeID, overlay.volumeID)
+		assert.Equal(t, testVersion, overlay.version)
+	})
+	t.Run(`bind mounted entry of the binaries`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(mount.MountInfo{
+			Root:       "/var/lib/kubelet/plugins/csi.oneagent.dynatrace.com/data/a-tenant-uuid/bin/1.2.3/agent",
+			MountPoint: filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath, "agent"),
+			FsType:     "ext4",
+		})
+
+		require.NotNil(t, overlay)
+		assert.Equal(t, testVolumeID, overlay.volumeID)
+		assert.Equal(t, testVersion, overlay.version)
+		assert.Equal(t, filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath), overlay.path)
+	})
+	t.Run(`bind mounted writable directory`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(mount.MountInfo{
+			Root:       "/var/lib/kubelet/plugins/csi.oneagent.dynatrace.com/data/a-tenant-uuid/run/a-volume/var/log",
+			MountPoint: filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath, "log"),
+			FsType:     "ext4",
+		})
+
+		require.NotNil(t, overlay)
+		assert.Equal(t, testVolumeID, overlay.volumeID)
+		assert.Empty(t, overlay.version)
+	})
+	t.Run(`not an overlay of the driver`, func(t *testing.T) {
+		overlay := reconciler.parseOverlayMount(mount.MountInfo{FsType: overlayFsType, MountPoint: "/var/lib/docker/overlay2/merged"})
+
+		assert.Nil(t, overlay)
+	})
+}
+
+func TestMountReconciler_parseTargetMount(t *testing.T) {
+	t.Run(`app target`, func(t *testing.T) {
+		target := parseTargetMount(mount.MountInfo{MountPoint: testTargetPath(testVolumeID)})
+
+		require.NotNil(t, target)
+		assert.Equal(t, testPodUID, string(target.podUID))
+		assert.Equal(t, testVolumeID, target.volumeID)
+		assert.Empty(t, target.tenantUUID)
+	})
+	t.Run(`host target`, func(t *testing.T) {
+		target := parseTargetMount(mount.MountInfo{
+			Root:       "/var/lib/kubelet/plugins/csi.oneagent.dynatrace.com/data/a-tenant-uuid/osagent",
+			MountPoint: testTargetPath(testVolumeID),
+		})
+
+		require.NotNil(t, target)
+		assert.Equal(t, testTenantUUID, target.tenantUUID)
+	})
+	t.Run(`not a target`, func(t *testing.T) {
+		assert.Nil(t, parseTargetMount(mount.MountInfo{MountPoint: "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~empty-dir/vol"}))
+	})
+}
+
+func TestMountReconciler_Reconcile(t *testing.T) {
+	t.Run(`removes stale volume entries`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t)
+		require.NoError(t, reconciler.db.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		volume, err := reconciler.db.GetVolume(testVolumeID)
+		require.NoError(t, err)
+		assert.Nil(t, volume)
+		assertEventReasons(t, reconciler.recorder, removedStaleVolumeEvent)
+	})
+	t.Run(`unmounts orphaned overlays`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t, testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/bin/1.2.3"))
+		overlayPath := filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath)
+		reconciler.mounter.(*mount.FakeMounter).MountPoints = []mount.MountPoint{{Path: overlayPath}}
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assert.Empty(t, reconciler.mounter.(*mount.FakeMounter).MountPoints)
+		assertEventReasons(t, reconciler.recorder, removedOrphanedMountEvent)
+	})
+	t.Run(`unmounts the nested mounts of orphaned bind mounts first`, func(t *testing.T) {
+		mappedDir := filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath)
+		reconciler := newMountReconcilerForTesting(t,
+			mount.MountInfo{Root: "/data/a-tenant-uuid/bin/1.2.3/agent", MountPoint: filepath.Join(mappedDir, "agent"), FsType: "ext4", Source: "/dev/sda1"},
+			mount.MountInfo{Root: "/data/a-tenant-uuid/run/a-volume/var/log", MountPoint: filepath.Join(mappedDir, "log"), FsType: "ext4", Source: "/dev/sda1"},
+		)
+		mounter := reconciler.mounter.(*mount.FakeMounter)
+		mounter.MountPoints = []mount.MountPoint{
+			{Path: filepath.Join(mappedDir, "agent")},
+			{Path: filepath.Join(mappedDir, "agent", "conf", "ruxitagentproc.conf")},
+			{Path: filepath.Join(mappedDir, "log")},
+		}
+		mounter.UnmountFunc = failOnBusyUnmount(mounter)
+		before := testutil.ToFloat64(mountReconciliationMetric.WithLabelValues(removedOrphanedMountEvent))
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assert.Empty(t, mounter.MountPoints)
+		assert.Equal(t, before+1, testutil.ToFloat64(mountReconciliationMetric.WithLabelValues(removedOrphanedMountEvent)))
+		assertEventReasons(t, reconciler.recorder, removedOrphanedMountEvent)
+	})
+	t.Run(`failed unmount of orphaned overlay is returned`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t, testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/bin/1.2.3"))
+		overlayPath := filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath)
+		mounter := reconciler.mounter.(*mount.FakeMounter)
+		mounter.MountPoints = []mount.MountPoint{{Path: overlayPath}}
+		mounter.UnmountFunc = func(path string) error {
+			return fmt.Errorf("device or resource busy")
+		}
+		require.NoError(t, reconciler.fs.MkdirAll(overlayPath, os.ModePerm))
+		before := testutil.ToFloat64(mountReconciliationFailuresMetric.WithLabelValues(removedOrphanedMountEvent))
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), testVolumeID)
+		assert.Len(t, mounter.MountPoints, 1)
+		assert.Equal(t, before+1, testutil.ToFloat64(mountReconciliationFailuresMetric.WithLabelValues(removedOrphanedMountEvent)))
+		exists, _ := reconciler.fs.DirExists(overlayPath)
+		assert.True(t, exists)
+		assertEventReasons(t, reconciler.recorder)
+	})
+	t.Run(`re-registers mounted volumes`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t,
+			testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/bin/1.2.3"),
+			mount.MountInfo{Root: "/", MountPoint: testTargetPath(testVolumeID), FsType: overlayFsType, Source: overlayFsType},
+		)
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		volume, err := reconciler.db.GetVolume(testVolumeID)
+		require.NoError(t, err)
+		require.NotNil(t, volume)
+		assert.Equal(t, testPodName, volume.PodName)
+		assert.Equal(t, testVersion, volume.Version)
+		assert.Equal(t, testTenantUUID, volume.TenantUUID)
+		assertEventReasons(t, reconciler.recorder, reregisteredVolumeEvent)
+	})
+	t.Run(`re-registers volumes mounted by the bind strategy`, func(t *testing.T) {
+		mappedDir := filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, testVolumeID, dtcsi.OverlayMappedDirPath)
+		reconciler := newMountReconcilerForTesting(t,
+			mount.MountInfo{Root: "/data/a-tenant-uuid/bin/1.2.3/agent", MountPoint: filepath.Join(mappedDir, "agent"), FsType: "ext4", Source: "/dev/sda1"},
+			mount.MountInfo{Root: "/data/a-tenant-uuid/run/a-volume/var/log", MountPoint: filepath.Join(mappedDir, "log"), FsType: "ext4", Source: "/dev/sda1"},
+			mount.MountInfo{Root: "/data/a-tenant-uuid/run/a-volume/mapped", MountPoint: testTargetPath(testVolumeID), FsType: "ext4", Source: "/dev/sda1"},
+		)
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		volume, err := reconciler.db.GetVolume(testVolumeID)
+		require.NoError(t, err)
+		require.NotNil(t, volume)
+		assert.Equal(t, testVersion, volume.Version)
+		assertEventReasons(t, reconciler.recorder, reregisteredVolumeEvent)
+	})
+	t.Run(`corrects osagent volumes`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t)
+		timestamp := time.Now()
+		require.NoError(t, reconciler.db.InsertOsAgentVolume(metadata.NewOsAgentVolume(testVolumeID, testTenantUUID, true, &timestamp)))
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		osVolume, err := reconciler.db.GetOsAgentVolumeViaTenantUUID(testTenantUUID)
+		require.NoError(t, err)
+		assert.False(t, osVolume.Mounted)
+		assertEventReasons(t, reconciler.recorder, correctedOsAgentVolumeEvent)
+	})
+	t.Run(`re-registers mounted osagent volumes`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t, mount.MountInfo{
+			Root:       "/var/lib/kubelet/plugins/csi.oneagent.dynatrace.com/data/a-tenant-uuid/osagent",
+			MountPoint: testTargetPath(testVolumeID),
+			FsType:     "ext4",
+			Source:     "/dev/sda1",
+		})
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		osVolume, err := reconciler.db.GetOsAgentVolumeViaTenantUUID(testTenantUUID)
+		require.NoError(t, err)
+		require.NotNil(t, osVolume)
+		assert.True(t, osVolume.Mounted)
+		assert.Equal(t, testVolumeID, osVolume.VolumeID)
+		assertEventReasons(t, reconciler.recorder, reregisteredOsAgentVolumeEvent)
+	})
+	t.Run(`consistent state is not changed`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t,
+			testOverlayMountInfo(testVolumeID, "lowerdir=/data/a-tenant-uuid/bin/1.2.3"),
+			mount.MountInfo{Root: "/", MountPoint: testTargetPath(testVolumeID), FsType: overlayFsType, Source: overlayFsType},
+		)
+		require.NoError(t, reconciler.db.InsertVolume(metadata.NewVolume(testVolumeID, testPodName, testVersion, testTenantUUID)))
+
+		err := reconciler.Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assertEventReasons(t, reconciler.recorder)
+	})
+	t.Run(`missing mountinfo`, func(t *testing.T) {
+		reconciler := newMountReconcilerForTesting(t)
+		reconciler.mountInfoPath = filepath.Join(t.TempDir(), "not-existing")
+
+		err := reconciler.Reconcile(context.TODO())
+
+		assert.Error(t, err)
+	})
+}
+
+func newMountReconcilerForTesting(t *testing.T, mountInfos ...mount.MountInfo) *mountReconciler {
+	mountInfoPath := filepath.Join(t.TempDir(), "mountinfo")
+	require.NoError(t, os.WriteFile(mountInfoPath, []byte(formatMountInfo(mountInfos)), 0600))
+
+	fakeClient := fake.NewClient(
+		&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName},
+			Spec:       dynatracev1beta1.DynaKubeSpec{APIURL: fmt.Sprintf("https://%s.dev.dynatracelabs.com/api", testTenantUUID)},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: testPodName, Namespace: "test-namespace", UID: testPodUID},
+			Spec:       corev1.PodSpec{NodeName: testNodeName},
+		},
+	)
+
+	return &mountReconciler{
+		client:        fakeClient,
+		apiReader:     fakeClient,
+		opts:          dtcsi.CSIOptions{RootDir: testRootDir, NodeId: testNodeName},
+		fs:            afero.Afero{Fs: afero.NewMemMapFs()},
+		mounter:       mount.NewFakeMounter([]mount.MountPoint{}),
+		db:            metadata.FakeMemoryDB(),
+		path:          metadata.PathResolver{RootDir: testRootDir},
+		recorder:      record.NewFakeRecorder(10),
+		mountLock:     &sync.RWMutex{},
+		mountInfoPath: mountInfoPath,
+	}
+}
+
+func testOverlayMountInfo(volumeID string, lowerDir string) mount.MountInfo {
+	return mount.MountInfo{
+		Root:         "/",
+		MountPoint:   filepath.Join(testRootDir, testTenantUUID, dtcsi.AgentRunDir, volumeID, dtcsi.OverlayMappedDirPath),
+		FsType:       overlayFsType,
+		Source:       overlayFsType,
+		SuperOptions: []string{"rw", lowerDir},
+	}
+}
+
+// failOnBusyUnmount fails the unmount of paths that still have mounts below them, like the kernel does
+func failOnBusyUnmount(mounter *mount.FakeMounter) func(string) error {
+	return func(path string) error {
+		for _, mountPoint := range mounter.MountPoints {
+			if strings.HasPrefix(mountPoint.Path, path+string(filepath.Separator)) {
+				return fmt.Errorf("unmount %s: device or resource busy", path)
+			}
+		}
+		return nil
+	}
+}
+
+func testTargetPath(volumeID string) string {
+	return fmt.Sprintf("/var/lib/kubelet/pods/%s/volumes/kubernetes.io~csi/%s/mount", testPodUID, volumeID)
+}
+
+// formatMountInfo writes the mount infos in the format of /proc/self/mountinfo
+func formatMountInfo(mountInfos []mount.MountInfo) string {
+	var lines []string
+	for i, mountInfo := range mountInfos {
+		superOptions := strings.Join(mountInfo.SuperOptions, ",")
+		if superOptions == "" {
+			superOptions = "rw"
+		}
+		lines = append(lines, fmt.Sprintf("%d 1 0:%d %s %s rw,relatime - %s %s %s",
+			i+100, i+50, mountInfo.Root, mountInfo.MountPoint, mountInfo.FsType, mountInfo.Source, superOptions))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func assertEventReasons(t *testing.T, recorder record.EventRecorder, reasons ...string) {
+	events := recorder.(*record.FakeRecorder).Events
+	require.Equal(t, len(reasons), len(events))
+	for _, reason := range reasons {
+		assert.Contains(t, <-events, reason)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
@@ -36,18 +37,25 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/mount"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CSIDriverServer struct {
-	client  client.Client
-	opts    dtcsi.CSIOptions
-	fs      afero.Afero
-	mounter mount.Interface
-	db      metadata.Access
-	path    metadata.PathResolver
+	client    client.Client
+	apiReader client.Reader
+	opts      dtcsi.CSIOptions
+	fs        afero.Afero
+	mounter   mount.Interface
+	db        metadata.Access
+	path      metadata.PathResolver
+	recorder  record.EventRecorder
+
+	// mountLock makes sure that volumes are not (un)published while the mounts are reconciled
+	mountLock     sync.RWMutex
+	mountStrategy string
 
 	publishers map[string]csivolumes.Publisher
 }
@@ -67,6 +75,8 @@ func NewServer(client client.Client, opts dtcsi.CSIOptions, db metadata.Access)
 }
 
 func (svr *CSIDriverServer) SetupWithManager(mgr ctrl.Manager) error {
+	svr.apiReader = mgr.GetAPIReader()
+	svr.recorder = mgr.GetEventRecorderFor("CSIDriverServer")
 	return mgr.Add(svr)
 }
 
@@ -83,11 +93,17 @@ func (svr *CSIDriverServer) Start(ctx context.Context) error {
 		}
 	}
 
+	svr.mountStrategy = appvolumes.ResolveMountStrategy(ctx, svr.apiReader, svr.opts, svr.fs, svr.mounter)
 	svr.publishers = map[string]csivolumes.Publisher{
-		appvolumes.Mode:  appvolumes.NewAppVolumePublisher(svr.client, svr.fs, svr.mounter, svr.db, svr.path),
+		appvolumes.Mode:  appvolumes.NewAppVolumePublisher(svr.client, svr.fs, svr.mounter, svr.db, svr.path, svr.mountStrategy),
 		hostvolumes.Mode: hostvolumes.NewHostVolumePublisher(svr.client, svr.fs, svr.mounter, svr.db, svr.path),
 	}
 
+	mountReconciler := newMountReconciler(svr)
+	if err := mountReconciler.Reconcile(ctx); err != nil {
+		log.Error(err, "failed to reconcile mounts on startup")
+	}
+
 	log.Info("starting listener", "protocol", proto, "address", addr)
 
 	listener, err := net.Listen(proto, addr)
@@ -98,6 +114,7 @@ func (svr *CSIDriverServer) Start(ctx context.Context) error {
 	server := grpc.NewServer(grpc.UnaryInterceptor(logGRPC()))
 	go func() {
 		ticker := time.NewTicker(memoryMetricTick)
+		mountTicker := time.NewTicker(mountReconciliationTick)
 		done := false
 		for !done {
 			select {
@@ -110,6 +127,10 @@ func (svr *CSIDriverServer) Start(ctx context.Context) error {
 				var m runtime.MemStats
 				runtime.ReadMemStats(&m)
 				memoryUsageMetric.Set(float64(m.Alloc))
+			case <-mountTicker.C:
+				if err := mountReconciler.Reconcile(ctx); err != nil {
+					log.Error(err, "failed to reconcile mounts")
+				}
 			}
 		}
 	}()
@@ -142,6 +163,9 @@ func (svr *CSIDriverServer) NodePublishVolume(ctx context.Context, req *csi.Node
 		return nil, err
 	}
 
+	svr.mountLock.RLock()
+	defer svr.mountLock.RUnlock()
+
 	if isMounted, err := isMounted(svr.mounter, volumeCfg.TargetPath); err != nil {
 		return nil, err
 	} else if isMounted {
@@ -168,6 +192,10 @@ func (svr *CSIDriverServer) NodeUnpublishVolume(ctx context.Context, req *csi.No
 	if err != nil {
 		return nil, err
 	}
+
+	svr.mountLock.RLock()
+	defer svr.mountLock.RUnlock()
+
 	for _, publisher := range svr.publishers {
 		canUnpublish, err := publisher.CanUnpublishVolume(volumeInfo)
 		if err != nil {
@@ -0,0 +1,312 @@
+package appvolumes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/mount"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MountStrategyLabel can be set on a node to override the mount strategy configured for the csi driver
+	MountStrategyLabel = "csi.oneagent.dynatrace.com/app-mount-strategy"
+
+	AutoMountStrategy    = "auto"
+	OverlayMountStrategy = "overlay"
+	BindMountStrategy    = "bind"
+
+	overlayProbeDir = "overlay_probe"
+)
+
+// mountStrategy mounts the agent binaries of an app volume to its mapped directory,
+// the mapped directory is then bind mounted to the target path with the options of the strategy.
+type mountStrategy interface {
+	mountAgent(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error
+	unmountAgent(targetPath string, mappedDir string) error
+	targetMountOptions() []string
+}
+
+func newMountStrategy(strategy string, fs afero.Afero, mounter mount.Interface, path metadata.PathResolver) mountStrategy {
+	if strategy == BindMountStrategy {
+		return &bindMountStrategy{fs: fs, mounter: mounter, path: path}
+	}
+	return &overlayMountStrategy{fs: fs, mounter: mounter, path: path}
+}
+
+// ResolveMountStrategy determines the mount strategy used for the app volumes on this node.
+// The label of the node takes precedence over the configured strategy,
+// in case of the auto strategy the driver checks if it's able to create overlay mounts.
+func ResolveMountStrategy(ctx context.Context, apiReader client.Reader, opts dtcsi.CSIOptions, fs afero.Afero, mounter mount.Interface) string {
+	strategy := opts.AppMountStrategy
+	var node corev1.Node
+	if err := apiReader.Get(ctx, client.ObjectKey{Name: opts.NodeId}, &node); err != nil {
+		log.Info("failed to get node for mount strategy label, using configured strategy", "node", opts.NodeId, "error", err.Error())
+	} else if nodeStrategy, ok := node.Labels[MountStrategyLabel]; ok {
+		strategy = nodeStrategy
+	}
+
+	switch strategy {
+	case OverlayMountStrategy, BindMountStrategy:
+	default:
+		strategy = detectMountStrategy(fs, mounter, metadata.PathResolver{RootDir: opts.RootDir})
+	}
+	log.Info("using mount strategy for app volumes", "strategy", strategy)
+	return strategy
+}
+
+// UnmountAgent unmounts the mapped directory of an app volume and the nested mounts of the mount strategy,
+// it's used for the mapped directories that aren't tracked by the csi metadata anymore
+func UnmountAgent(strategy string, fs afero.Afero, mounter mount.Interface, path metadata.PathResolver, mappedDir string) error {
+	return newMountStrategy(strategy, fs, mounter, path).unmountAgent("", mappedDir)
+}
+
+// detectMountStrategy tries to create an overlay mount in the data directory of the driver
+func detectMountStrategy(fs afero.Afero, mounter mount.Interface, path metadata.PathResolver) string {
+	probeDir := filepath.Join(path.RootDir, overlayProbeDir)
+	defer func() { _ = fs.RemoveAll(probeDir) }()
+
+	lowerDir := filepath.Join(probeDir, "lower")
+	upperDir := filepath.Join(probeDir, dtcsi.OverlayVarDirPath)
+	workDir := filepath.Join(probeDir, dtcsi.OverlayWorkDirPath)
+	mappedDir := filepath.Join(probeDir, dtcsi.OverlayMappedDirPath)
+	for _, dir := range []string{lowerDir, upperDir, workDir, mappedDir} {
+		if err := fs.MkdirAll(dir, os.ModePerm); err != nil {
+			log.Info("failed to create overlay probe directory", "path", dir, "error", err.Error())
+			return BindMountStrategy
+		}
+	}
+
+	overlayOptions := []string{
+		"lowerdir=" + lowerDir,
+		"upperdir=" + upperDir,
+		"workdir=" + workDir,
+	}
+	if err := mounter.Mount("overlay", mappedDir, "overlay", overlayOptions); err != nil {
+		log.Info("overlay mounts are not supported on this node", "error", err.Error())
+		return BindMountStrategy
+	}
+	_ = mounter.Unmount(mappedDir)
+	return OverlayMountStrategy
+}
+
+// overlayMountStrategy uses the agent binaries as the lower dir of an overlay,
+// so every volume has its own writable copy of the agent directory
+type overlayMountStrategy struct {
+	fs      afero.Afero
+	mounter mount.Interface
+	path    metadata.PathResolver
+}
+
+func (strategy *overlayMountStrategy) buildLowerDir(bindCfg *csivolumes.BindConfig) string {
+	var directories []string
+	if bindCfg.ImageDigest == "" {
+		directories = []string{
+			strategy.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, bindCfg.Version),
+		}
+	} else {
+		directories = []string{
+			strategy.path.AgentConfigDir(bindCfg.TenantUUID),
+			strategy.path.AgentSharedBinaryDirForImage(bindCfg.ImageDigest),
+		}
+	}
+
+	return strings.Join(directories, ":")
+}
+
+func (strategy *overlayMountStrategy) mountAgent(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
+	upperDir := strategy.path.OverlayVarDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
+	_ = strategy.fs.MkdirAll(upperDir, os.ModePerm)
+
+	workDir := strategy.path.OverlayWorkDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
+	_ = strategy.fs.MkdirAll(workDir, os.ModePerm)
+
+	overlayOptions := []string{
+		"lowerdir=" + strategy.buildLowerDir(bindCfg),
+		"upperdir=" + upperDir,
+		"workdir=" + workDir,
+	}
+
+	mappedDir := strategy.path.OverlayMappedDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
+	if err := strategy.mounter.Mount("overlay", mappedDir, "overlay", overlayOptions); err != nil {
+		return err
+	}
+
+	// the writable directories end up in the upper dir of the volume, the lower dirs aren't changed
+	if err := createWritableDirs(strategy.fs, mappedDir); err != nil {
+		_ = strategy.unmountAgent("", mappedDir)
+		return err
+	}
+	return nil
+}
+
+func (strategy *overlayMountStrategy) unmountAgent(targetPath string, mappedDir string) error {
+	return unmountTree(strategy.mounter, targetPath, mappedDir)
+}
+
+func (strategy *overlayMountStrategy) targetMountOptions() []string {
+	return []string{"bind"}
+}
+
+// bindMountStrategy is used on nodes that can't create overlay mounts,
+// the entries of the agent binaries are bind mounted read-only into the mapped directory of the volume and only the writable directories
+// of the agent are bind mounted from the var directory of the volume (unless the volume is requested as read-only,
+// then the writable directories are provided by the pod)
+type bindMountStrategy struct {
+	fs      afero.Afero
+	mounter mount.Interface
+	path    metadata.PathResolver
+}
+
+func (strategy *bindMountStrategy) mountAgent(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
+	binaryDir := strategy.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, bindCfg.Version)
+	if bindCfg.ImageDigest != "" {
+		binaryDir = strategy.path.AgentSharedBinaryDirForImage(bindCfg.ImageDigest)
+	}
+
+	mappedDir := strategy.path.OverlayMappedDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
+	if err := strategy.mountBinaries(binaryDir, mappedDir); err != nil {
+		_ = strategy.unmountAgent("", mappedDir)
+		return err
+	}
+
+	if err := strategy.mountNestedDirs(bindCfg, volumeCfg, mappedDir); err != nil {
+		_ = strategy.unmountAgent("", mappedDir)
+		return err
+	}
+	return nil
+}
+
+// mountBinaries bind mounts the entries of the binary directory one by one, so the mount points and the writable directories
+// are created in the mapped directory of the volume and the binary directory, which is shared by all volumes, isn't changed
+func (strategy *bindMountStrategy) mountBinaries(binaryDir string, mappedDir string) error {
+	entries, err := strategy.fs.ReadDir(binaryDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if isAgentWritableDir(entry.Name()) {
+			continue
+		}
+		source := filepath.Join(binaryDir, entry.Name())
+		target := filepath.Join(mappedDir, entry.Name())
+		if err := strategy.createMountPoint(source, target); err != nil {
+			return err
+		}
+		if err := strategy.mounter.Mount(source, target, "", []string{"bind", "ro"}); err != nil {
+			return err
+		}
+	}
+	return createWritableDirs(strategy.fs, mappedDir)
+}
+
+func (strategy *bindMountStrategy) createMountPoint(source string, target string) error {
+	isDir, err := strategy.fs.IsDir(source)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if isDir {
+		return errors.WithStack(strategy.fs.MkdirAll(target, os.ModePerm))
+	}
+	return errors.WithStack(strategy.fs.WriteFile(target, nil, 0644))
+}
+
+func (strategy *bindMountStrategy) mountNestedDirs(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig, mappedDir string) error {
+	if bindCfg.ImageDigest != "" {
+		processModuleConfig := strategy.path.AgentConfigProcessModuleConfig(bindCfg.TenantUUID)
+		if exists, _ := strategy.fs.Exists(processModuleConfig); exists {
+			target := filepath.Join(mappedDir, dtcsi.AgentProcessModuleConfigPath)
+			if err := strategy.mounter.Mount(processModuleConfig, target, "", []string{"bind", "ro"}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if volumeCfg.ReadOnly {
+		return nil
+	}
+
+	varDir := strategy.path.OverlayVarDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
+	for _, writableDir := range dtcsi.AgentWritableDirs {
+		source := filepath.Join(varDir, writableDir)
+		if err := strategy.fs.MkdirAll(source, os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := strategy.mounter.Mount(source, filepath.Join(mappedDir, writableDir), "", []string{"bind"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountAgent unmounts the nested mounts before their parents,
+// the target path contains the same nested mounts as the mapped directory because it's a recursive bind mount
+func (strategy *bindMountStrategy) unmountAgent(targetPath string, mappedDir string) error {
+	return unmountTree(strategy.mounter, targetPath, mappedDir)
+}
+
+func (strategy *bindMountStrategy) targetMountOptions() []string {
+	return []string{"rbind"}
+}
+
+// createWritableDirs creates the writable directories of the agent in the mapped directory of the volume,
+// they are the mount points of the writable directories in case the pod provides them for a read-only volume
+func createWritableDirs(fs afero.Afero, mappedDir string) error {
+	for _, writableDir := range dtcsi.AgentWritableDirs {
+		if err := fs.MkdirAll(filepath.Join(mappedDir, writableDir), os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func isAgentWritableDir(name string) bool {
+	for _, writableDir := range dtcsi.AgentWritableDirs {
+		if name == writableDir {
+			return true
+		}
+	}
+	return false
+}
+
+// unmountTree unmounts the given paths and every mount below them, the deepest mounts first,
+// paths that aren't mounted are skipped
+func unmountTree(mounter mount.Interface, paths ...string) error {
+	mountPoints, err := mounter.List()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var mountedPaths []string
+	for _, mountPoint := range mountPoints {
+		for _, path := range paths {
+			if path != "" && (mountPoint.Path == path || strings.HasPrefix(mountPoint.Path, path+string(filepath.Separator))) {
+				mountedPaths = append(mountedPaths, mountPoint.Path)
+				break
+			}
+		}
+	}
+	sort.SliceStable(mountedPaths, func(i, j int) bool {
+		return len(mountedPaths[i]) > len(mountedPaths[j])
+	})
+
+	var unmountErrors []string
+	for _, mountedPath := range mountedPaths {
+		if err := mounter.Unmount(mountedPath); err != nil {
+			log.Error(err, "Unmount failed", "path", mountedPath)
+			unmountErrors = append(unmountErrors, err.Error())
+		}
+	}
+	if len(unmountErrors) > 0 {
+		return errors.Errorf("failed to unmount %d of %d mounts: %s", len(unmountErrors), len(mountedPaths), strings.Join(unmountErrors, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package appvolumes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/mount"
+)
+
+const testNodeName = "a-node"
+
+type failingOverlayMounter struct {
+	mount.FakeMounter
+}
+
+func (mounter *failingOverlayMounter) Mount(source string, target string, fstype string, options []string) error {
+	if fstype == "overlay" {
+		return errors.New("overlay not supported")
+	}
+	return mounter.FakeMounter.Mount(source, target, fstype, options)
+}
+
+func TestResolveMountStrategy(t *testing.T) {
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+
+	t.Run(`configured strategy`, func(t *testing.T) {
+		opts := dtcsi.CSIOptions{NodeId: testNodeName, RootDir: "/", AppMountStrategy: BindMountStrategy}
+
+		strategy := ResolveMountStrategy(context.TODO(), fake.NewClient(), opts, fs, mount.NewFakeMounter(nil))
+
+		assert.Equal(t, BindMountStrategy, strategy)
+	})
+	t.Run(`node label overrides configured strategy`, func(t *testing.T) {
+		opts := dtcsi.CSIOptions{NodeId: testNodeName, RootDir: "/", AppMountStrategy: OverlayMountStrategy}
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   testNodeName,
+			Labels: map[string]string{MountStrategyLabel: BindMountStrategy},
+		}}
+
+		strategy := ResolveMountStrategy(context.TODO(), fake.NewClient(node), opts, fs, mount.NewFakeMounter(nil))
+
+		assert.Equal(t, BindMountStrategy, strategy)
+	})
+	t.Run(`auto detects overlay support`, func(t *testing.T) {
+		opts := dtcsi.CSIOptions{NodeId: testNodeName, RootDir: "/", AppMountStrategy: AutoMountStrategy}
+		mounter := mount.NewFakeMounter(nil)
+
+		strategy := ResolveMountStrategy(context.TODO(), fake.NewClient(), opts, fs, mounter)
+
+		assert.Equal(t, OverlayMountStrategy, strategy)
+		assert.Empty(t, mounter.MountPoints)
+	})
+	t.Run(`auto falls back to bind`, func(t *testing.T) {
+		opts := dtcsi.CSIOptions{NodeId: testNodeName, RootDir: "/", AppMountStrategy: AutoMountStrategy}
+
+		strategy := ResolveMountStrategy(context.TODO(), fake.NewClient(), opts, fs, &failingOverlayMounter{})
+
+		assert.Equal(t, BindMountStrategy, strategy)
+	})
+}
+
+func TestOverlayMountStrategy(t *testing.T) {
+	t.Run(`writable directories are created in the volume`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		mockUrlDynakubeMetadata(t, &publisher)
+
+		_, err := publisher.PublishVolume(context.TODO(), createTestVolumeConfig())
+		require.NoError(t, err)
+
+		for _, writableDir := range dtcsi.AgentWritableDirs {
+			exists, _ := publisher.fs.DirExists(filepath.Join("/a-tenant-uuid/run/a-volume/mapped", writableDir))
+			assert.True(t, exists)
+			exists, _ = publisher.fs.DirExists(filepath.Join("/a-tenant-uuid/bin/1.2-3", writableDir))
+			assert.False(t, exists)
+		}
+	})
+}
+
+func TestBindMountStrategy(t *testing.T) {
+	t.Run(`publish and unpublish using url`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		publisher.strategy = newMountStrategy(BindMountStrategy, publisher.fs, mounter, publisher.path)
+		mockUrlDynakubeMetadata(t, &publisher)
+		createTestBinaryDir(t, publisher.fs, "/a-tenant-uuid/bin/1.2-3")
+
+		_, err := publisher.PublishVolume(context.TODO(), createTestVolumeConfig())
+		require.NoError(t, err)
+
+		require.Len(t, mounter.MountPoints, 3+len(dtcsi.AgentWritableDirs))
+		assert.Equal(t, "/a-tenant-uuid/bin/1.2-3/agent", mounter.MountPoints[0].Device)
+		assert.Equal(t, "/a-tenant-uuid/run/a-volume/mapped/agent", mounter.MountPoints[0].Path)
+		assert.Equal(t, []string{"bind", "ro"}, mounter.MountPoints[0].Opts)
+		assert.Equal(t, "/a-tenant-uuid/bin/1.2-3/manifest.json", mounter.MountPoints[1].Device)
+		assert.Equal(t, "/a-tenant-uuid/run/a-volume/mapped/manifest.json", mounter.MountPoints[1].Path)
+		assert.Equal(t, "/a-tenant-uuid/run/a-volume/var/log", mounter.MountPoints[2].Device)
+		assert.Equal(t, "/a-tenant-uuid/run/a-volume/mapped/log", mounter.MountPoints[2].Path)
+		target := mounter.MountPoints[len(mounter.MountPoints)-1]
+		assert.Equal(t, testTargetPath, target.Path)
+		assert.Equal(t, []string{"rbind"}, target.Opts)
+
+		isFile, _ := publisher.fs.Exists("/a-tenant-uuid/run/a-volume/mapped/manifest.json")
+		assert.True(t, isFile)
+		for _, writableDir := range dtcsi.AgentWritableDirs {
+			exists, _ := publisher.fs.DirExists(filepath.Join("/a-tenant-uuid/bin/1.2-3", writableDir))
+			assert.False(t, exists)
+		}
+
+		_, err = publisher.UnpublishVolume(context.TODO(), createTestVolumeInfo())
+		require.NoError(t, err)
+		assert.Empty(t, mounter.MountPoints)
+	})
+	t.Run(`read-only volume using code modules image`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		publisher.strategy = newMountStrategy(BindMountStrategy, publisher.fs, mounter, publisher.path)
+		mockImageDynakubeMetadata(t, &publisher)
+		binaryDir := metadata.PathResolver{RootDir: "/"}.AgentSharedBinaryDirForImage(testImageDigest)
+		createTestBinaryDir(t, publisher.fs, binaryDir)
+		require.NoError(t, publisher.fs.WriteFile(publisher.path.AgentConfigProcessModuleConfig(testTenantUUID), []byte("conf"), 0644))
+		volumeCfg := createTestVolumeConfig()
+		volumeCfg.ReadOnly = true
+
+		_, err := publisher.PublishVolume(context.TODO(), volumeCfg)
+		require.NoError(t, err)
+
+		require.Len(t, mounter.MountPoints, 4)
+		assert.Equal(t, filepath.Join(binaryDir, "agent"), mounter.MountPoints[0].Device)
+		assert.Equal(t, "/a-tenant-uuid/run/a-volume/mapped/agent/conf/ruxitagentproc.conf", mounter.MountPoints[2].Path)
+		assert.Equal(t, testTargetPath, mounter.MountPoints[3].Path)
+		for _, writableDir := range dtcsi.AgentWritableDirs {
+			exists, _ := publisher.fs.DirExists(filepath.Join("/a-tenant-uuid/run/a-volume/mapped", writableDir))
+			assert.True(t, exists)
+			exists, _ = publisher.fs.DirExists(filepath.Join(binaryDir, writableDir))
+			assert.False(t, exists)
+		}
+	})
+}
+
+func TestUnmountTree(t *testing.T) {
+	mounter := mount.NewFakeMounter([]mount.MountPoint{
+		{Path: "/target"},
+		{Path: "/target/agent"},
+		{Path: "/target/agent/conf/ruxitagentproc.conf"},
+		{Path: "/target-other"},
+	})
+	var unmounted []string
+	mounter.UnmountFunc = func(path string) error {
+		unmounted = append(unmounted, path)
+		return nil
+	}
+
+	require.NoError(t, unmountTree(mounter, "/target", ""))
+
+	assert.Equal(t, []string{"/target/agent/conf/ruxitagentproc.conf", "/target/agent", "/target"}, unmounted)
+	require.Len(t, mounter.MountPoints, 1)
+	assert.Equal(t, "/target-other", mounter.MountPoints[0].Path)
+}
+
+func createTestBinaryDir(t *testing.T, fs afero.Afero, binaryDir string) {
+	require.NoError(t, fs.MkdirAll(filepath.Join(binaryDir, "agent", "conf"), os.ModePerm))
+	require.NoError(t, fs.WriteFile(filepath.Join(binaryDir, "manifest.json"), []byte("{}"), 0644))
+}
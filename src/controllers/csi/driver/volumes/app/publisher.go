@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
@@ -35,22 +34,24 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func NewAppVolumePublisher(client client.Client, fs afero.Afero, mounter mount.Interface, db metadata.Access, path metadata.PathResolver) csivolumes.Publisher {
+func NewAppVolumePublisher(client client.Client, fs afero.Afero, mounter mount.Interface, db metadata.Access, path metadata.PathResolver, mountStrategy string) csivolumes.Publisher {
 	return &AppVolumePublisher{
-		client:  client,
-		fs:      fs,
-		mounter: mounter,
-		db:      db,
-		path:    path,
+		client:   client,
+		fs:       fs,
+		mounter:  mounter,
+		db:       db,
+		path:     path,
+		strategy: newMountStrategy(mountStrategy, fs, mounter, path),
 	}
 }
 
 type AppVolumePublisher struct {
-	client  client.Client
-	fs      afero.Afero
-	mounter mount.Interface
-	db      metadata.Access
-	path    metadata.PathResolver
+	client   client.Client
+	fs       afero.Afero
+	mounter  mount.Interface
+	db       metadata.Access
+	path     metadata.PathResolver
+	strategy mountStrategy
 }
 
 func (publisher *AppVolumePublisher) PublishVolume(_ context.Context, volumeCfg *csivolumes.VolumeConfig) (*csi.NodePublishVolumeResponse, error) {
@@ -130,47 +131,19 @@ func (publisher *AppVolumePublisher) fireVolumeUnpublishedMetric(volume metadata
 	}
 }
 
-func (publisher *AppVolumePublisher) buildLowerDir(bindCfg *csivolumes.BindConfig) string {
-	var directories []string
-	if bindCfg.ImageDigest == "" {
-		directories = []string{
-			publisher.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, bindCfg.Version),
-		}
-	} else {
-		directories = []string{
-			publisher.path.AgentConfigDir(bindCfg.TenantUUID),
-			publisher.path.AgentSharedBinaryDirForImage(bindCfg.ImageDigest),
-		}
-	}
-
-	return strings.Join(directories, ":")
-}
-
 func (publisher *AppVolumePublisher) mountOneAgent(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
 	mappedDir := publisher.path.OverlayMappedDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
 	_ = publisher.fs.MkdirAll(mappedDir, os.ModePerm)
 
-	upperDir := publisher.path.OverlayVarDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
-	_ = publisher.fs.MkdirAll(upperDir, os.ModePerm)
-
-	workDir := publisher.path.OverlayWorkDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
-	_ = publisher.fs.MkdirAll(workDir, os.ModePerm)
-
-	overlayOptions := []string{
-		"lowerdir=" + publisher.buildLowerDir(bindCfg),
-		"upperdir=" + upperDir,
-		"workdir=" + workDir,
-	}
-
 	if err := publisher.fs.MkdirAll(volumeCfg.TargetPath, os.ModePerm); err != nil {
 		return err
 	}
 
-	if err := publisher.mounter.Mount("overlay", mappedDir, "overlay", overlayOptions); err != nil {
+	if err := publisher.strategy.mountAgent(bindCfg, volumeCfg); err != nil {
 		return err
 	}
-	if err := publisher.mounter.Mount(mappedDir, volumeCfg.TargetPath, "", []string{"bind"}); err != nil {
-		_ = publisher.mounter.Unmount(mappedDir)
+	if err := publisher.mounter.Mount(mappedDir, volumeCfg.TargetPath, "", publisher.strategy.targetMountOptions()); err != nil {
+		_ = publisher.strategy.unmountAgent("", mappedDir)
 		return err
 	}
 
@@ -178,15 +151,12 @@ func (publisher *AppVolumePublisher) mountOneAgent(bindCfg *csivolumes.BindConfi
 }
 
 func (publisher *AppVolumePublisher) umountOneAgent(targetPath string, overlayFSPath string) error {
-	if err := publisher.mounter.Unmount(targetPath); err != nil {
-		log.Error(err, "Unmount failed", "path", targetPath)
-	}
-
+	mappedDir := ""
 	if filepath.IsAbs(overlayFSPath) {
-		agentDirectoryForPod := filepath.Join(overlayFSPath, dtcsi.OverlayMappedDirPath)
-		if err := publisher.mounter.Unmount(agentDirectoryForPod); err != nil {
-			log.Error(err, "Unmount failed", "path", agentDirectoryForPod)
-		}
+		mappedDir = filepath.Join(overlayFSPath, dtcsi.OverlayMappedDirPath)
+	}
+	if err := publisher.strategy.unmountAgent(targetPath, mappedDir); err != nil {
+		log.Info("failed to unmount the agent", "targetPath", targetPath, "error", err.Error())
 	}
 
 	return nil
@@ -213,12 +213,16 @@ func newPublisherForTesting(t *testing.T, mounter *mount.FakeMounter) AppVolumeP
 
 	tmpFs := afero.NewMemMapFs()
 
+	fs := afero.Afero{Fs: tmpFs}
+	path := metadata.PathResolver{RootDir: csiOptions.RootDir}
+
 	return AppVolumePublisher{
-		client:  fake.NewClient(objects...),
-		fs:      afero.Afero{Fs: tmpFs},
-		mounter: mounter,
-		db:      metadata.FakeMemoryDB(),
-		path:    metadata.PathResolver{RootDir: csiOptions.RootDir},
+		client:   fake.NewClient(objects...),
+		fs:       fs,
+		mounter:  mounter,
+		db:       metadata.FakeMemoryDB(),
+		path:     path,
+		strategy: newMountStrategy(OverlayMountStrategy, fs, mounter, path),
 	}
 }
 
@@ -2,6 +2,7 @@ package csigc
 
 import (
 	"os"
+	"syscall"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -76,13 +77,53 @@ func removeUnusedVersion(fs *afero.Afero, binaryPath string) {
 	}
 }
 
+// runContentStoreGarbageCollection removes the files of the content store that are no longer linked by any version directory
+func (gc *CSIGarbageCollector) runContentStoreGarbageCollection() {
+	fs := &afero.Afero{Fs: gc.fs}
+	storeDir := gc.path.AgentContentStoreDir()
+
+	exists, _ := fs.DirExists(storeDir)
+	if !exists {
+		return
+	}
+
+	var reclaimed int64
+	err := fs.Walk(storeDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || linkCount(info) != 1 {
+			return nil
+		}
+		if err := fs.Remove(path); err != nil {
+			log.Info("failed to remove unused file from content store", "path", path, "error", err.Error())
+			return nil
+		}
+		reclaimed += info.Size()
+		return nil
+	})
+	if err != nil {
+		log.Info("failed to collect the content store", "error", err.Error())
+	}
+	reclaimedMemoryMetric.Add(float64(reclaimed))
+}
+
+// linkCount returns the number of hardlinks of the file, 0 if the filesystem doesn't provide it
+func linkCount(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Nlink)
+	}
+	return 0
+}
+
+// dirSize only counts files that aren't linked anywhere else, as the space of the other files isn't freed by removing the directory
 func dirSize(fs *afero.Afero, path string) (int64, error) {
 	var size int64
 	err := fs.Walk(path, func(_ string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() {
+		if !info.IsDir() && linkCount(info) <= 1 {
 			size += info.Size()
 		}
 		return err
@@ -2,6 +2,7 @@ package csigc
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -101,6 +103,44 @@ func TestRunBinaryGarbageCollection(t *testing.T) {
 	})
 }
 
+func TestRunContentStoreGarbageCollection(t *testing.T) {
+	t.Run(`removes files only linked by the content store`, func(t *testing.T) {
+		resetMetrics()
+		rootDir := t.TempDir()
+		gc := &CSIGarbageCollector{
+			fs:   afero.NewOsFs(),
+			path: metadata.PathResolver{RootDir: rootDir},
+		}
+		unusedFile := gc.path.AgentContentStoreFile("aa-755")
+		usedFile := gc.path.AgentContentStoreFile("bb-755")
+		linkedFile := filepath.Join(rootDir, "linked")
+		require.NoError(t, os.MkdirAll(filepath.Dir(unusedFile), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Dir(usedFile), 0755))
+		require.NoError(t, os.WriteFile(unusedFile, []byte("unused"), 0755))
+		require.NoError(t, os.WriteFile(usedFile, []byte("used"), 0755))
+		require.NoError(t, os.Link(usedFile, linkedFile))
+
+		gc.runContentStoreGarbageCollection()
+
+		assert.NoFileExists(t, unusedFile)
+		assert.FileExists(t, usedFile)
+		assert.Equal(t, float64(len("unused")), testutil.ToFloat64(reclaimedMemoryMetric))
+	})
+	t.Run(`linked files aren't counted as reclaimed memory`, func(t *testing.T) {
+		rootDir := t.TempDir()
+		versionDir := filepath.Join(rootDir, "version")
+		require.NoError(t, os.MkdirAll(versionDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, "unique"), []byte("unique"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, "shared"), []byte("shared"), 0755))
+		require.NoError(t, os.Link(filepath.Join(versionDir, "shared"), filepath.Join(rootDir, "shared")))
+
+		size, err := dirSize(&afero.Afero{Fs: afero.NewOsFs()}, versionDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(len("unique")), size)
+	})
+}
+
 func TestBinaryGarbageCollector_getUsedVersions(t *testing.T) {
 	gc := NewMockGarbageCollector()
 	gc.mockUsedVersions(testVersion1, testVersion2, testVersion3)
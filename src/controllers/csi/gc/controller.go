@@ -51,6 +51,13 @@ func NewCSIGarbageCollector(apiReader client.Reader, opts dtcsi.CSIOptions, db m
 	}
 }
 
+// NewDryRunCSIGarbageCollector returns a CSIGarbageCollector that only logs the files it would remove
+func NewDryRunCSIGarbageCollector(apiReader client.Reader, opts dtcsi.CSIOptions, db metadata.Access) *CSIGarbageCollector {
+	gc := NewCSIGarbageCollector(apiReader, opts, db)
+	gc.fs = dryRunFs{Fs: gc.fs}
+	return gc
+}
+
 func (gc *CSIGarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dynatracev1beta1.DynaKube{}).
@@ -74,32 +81,54 @@ func (gc *CSIGarbageCollector) Reconcile(ctx context.Context, request reconcile.
 		return reconcileResult, err
 	}
 
+	return reconcileResult, gc.collectGarbage(*dynakube, dynakubeList)
+}
+
+// CollectGarbage runs the garbage collection once for every DynaKube in the namespace
+func (gc *CSIGarbageCollector) CollectGarbage(ctx context.Context, namespace string) error {
+	dynakubeList, err := getAllDynakubes(ctx, gc.apiReader, namespace)
+	if err != nil {
+		return err
+	}
+	for _, dynakube := range dynakubeList.Items {
+		log.Info("running OneAgent garbage collection", "namespace", dynakube.Namespace, "name", dynakube.Name)
+		if err := gc.collectGarbage(dynakube, dynakubeList); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gc *CSIGarbageCollector) collectGarbage(dynakube dynatracev1beta1.DynaKube, dynakubeList *dynatracev1beta1.DynaKubeList) error {
 	if !isSafeToGC(gc.db, dynakubeList) {
 		log.Info("dynakube metadata is in a unfinished state, checking later")
-		return reconcileResult, nil
+		return nil
 	}
 
-	gcInfo, err := collectGCInfo(*dynakube, dynakubeList)
+	gcInfo, err := collectGCInfo(dynakube, dynakubeList)
 	if err != nil {
-		return reconcileResult, err
+		return err
 	}
 	if gcInfo == nil {
-		return reconcileResult, nil
+		return nil
 	}
 
 	log.Info("running binary garbage collection")
 	gc.runBinaryGarbageCollection(gcInfo.pinnedVersions, gcInfo.tenantUUID, gcInfo.latestAgentVersion)
 
+	log.Info("running content store garbage collection")
+	gc.runContentStoreGarbageCollection()
+
 	log.Info("running log garbage collection")
 	gc.runLogGarbageCollection(gcInfo.tenantUUID)
 
 	log.Info("running shared images garbage collection")
 	if err := gc.runSharedImagesGarbageCollection(); err != nil {
 		log.Info("failed to garbage collect the shared images")
-		return reconcileResult, err
+		return err
 	}
 
-	return reconcileResult, nil
+	return nil
 }
 
 func getDynakubeFromRequest(ctx context.Context, apiReader client.Reader, request reconcile.Request) (*dynatracev1beta1.DynaKube, error) {
@@ -173,6 +202,7 @@ func isUpgrading(dkMetadata *metadata.Dynakube, filteredDynakubes map[string]dyn
 // A pinned version is either:
 // - the image tag or digest set in the custom resource (this doesn't matter in context of the GC)
 // - the version set in the custom resource if applicationMonitoring is used
+// - the versions the csi driver should predownload
 func getAllPinnedVersionsForTenantUUID(dynakubeList *dynatracev1beta1.DynaKubeList, tenantUUID string) (pinnedVersionSet, error) {
 	pinnedVersions := make(pinnedVersionSet)
 	for _, dynakube := range dynakubeList.Items {
@@ -188,6 +218,9 @@ func getAllPinnedVersionsForTenantUUID(dynakubeList *dynatracev1beta1.DynaKubeLi
 		if codeModuleVersion != "" {
 			pinnedVersions[codeModuleVersion] = true
 		}
+		for _, version := range dynakube.FeaturePredownloadVersions() {
+			pinnedVersions[version] = true
+		}
 	}
 	return pinnedVersions, nil
 }
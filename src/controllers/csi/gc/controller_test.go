@@ -119,6 +119,32 @@ func TestCollectGCInfo(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, gcInfo.pinnedVersions, 2)
 	})
+	t.Run(`predownloaded versions are pinned`, func(t *testing.T) {
+		dynakube := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeaturePredownloadVersions: `["1.2.3", "1.2.4"]`,
+				},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: apiUrl,
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				LatestAgentVersionUnixPaas: latestVersion,
+			},
+		}
+		dkList := dynatracev1beta1.DynaKubeList{
+			Items: []dynatracev1beta1.DynaKube{
+				dynakube,
+			},
+		}
+
+		gcInfo, err := collectGCInfo(dynakube, &dkList)
+		require.NoError(t, err)
+		assert.False(t, gcInfo.pinnedVersions.isNotPinned("1.2.3"))
+		assert.False(t, gcInfo.pinnedVersions.isNotPinned("1.2.4"))
+	})
 }
 
 func TestIsSafeToGC(t *testing.T) {
@@ -0,0 +1,20 @@
+package csigc
+
+import (
+	"github.com/spf13/afero"
+)
+
+// dryRunFs reads from the wrapped filesystem, but only logs the files that would be removed
+type dryRunFs struct {
+	afero.Fs
+}
+
+func (fs dryRunFs) Remove(name string) error {
+	log.Info("dry-run: would remove", "path", name)
+	return nil
+}
+
+func (fs dryRunFs) RemoveAll(path string) error {
+	log.Info("dry-run: would remove", "path", path)
+	return nil
+}
@@ -0,0 +1,18 @@
+package csigc
+
+import (
+	"testing"
+)
+
+func TestDryRunGarbageCollection(t *testing.T) {
+	t.Run("keeps unused versions", func(t *testing.T) {
+		resetMetrics()
+		gc := NewMockGarbageCollector()
+		gc.fs = dryRunFs{Fs: gc.fs}
+		gc.mockUnusedVersions(testVersion1, testVersion2)
+
+		gc.runBinaryGarbageCollection(pinnedVersionSet{}, testTenantUUID, testVersion3)
+
+		gc.assertVersionExists(t, testVersion1, testVersion2)
+	})
+}
@@ -0,0 +1,51 @@
+package metadata
+
+// DryRunAccess reads from the wrapped Access, but only logs the changes instead of storing them.
+type DryRunAccess struct {
+	Access
+}
+
+// NewDryRunAccess wraps the given Access, so every write operation is skipped.
+func NewDryRunAccess(access Access) *DryRunAccess {
+	return &DryRunAccess{Access: access}
+}
+
+func (access *DryRunAccess) Setup(path string) error {
+	log.Info("dry-run: skipped database setup", "path", path)
+	return nil
+}
+
+func (access *DryRunAccess) InsertDynakube(dynakube *Dynakube) error {
+	log.Info("dry-run: would insert dynakube", "dynakube", dynakube)
+	return nil
+}
+
+func (access *DryRunAccess) UpdateDynakube(dynakube *Dynakube) error {
+	log.Info("dry-run: would update dynakube", "dynakube", dynakube)
+	return nil
+}
+
+func (access *DryRunAccess) DeleteDynakube(dynakubeName string) error {
+	log.Info("dry-run: would delete dynakube", "name", dynakubeName)
+	return nil
+}
+
+func (access *DryRunAccess) InsertOsAgentVolume(volume *OsAgentVolume) error {
+	log.Info("dry-run: would insert osagent volume", "volume", volume)
+	return nil
+}
+
+func (access *DryRunAccess) UpdateOsAgentVolume(volume *OsAgentVolume) error {
+	log.Info("dry-run: would update osagent volume", "volume", volume)
+	return nil
+}
+
+func (access *DryRunAccess) InsertVolume(volume *Volume) error {
+	log.Info("dry-run: would insert volume", "volume", volume)
+	return nil
+}
+
+func (access *DryRunAccess) DeleteVolume(volumeID string) error {
+	log.Info("dry-run: would delete volume", "volumeID", volumeID)
+	return nil
+}
@@ -0,0 +1,24 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunAccess(t *testing.T) {
+	db := FakeMemoryDB()
+	require.NoError(t, db.InsertVolume(NewVolume("volume-id", "pod-name", "1.2.3", "tenant-uuid")))
+	dryRunDB := NewDryRunAccess(db)
+
+	require.NoError(t, dryRunDB.DeleteVolume("volume-id"))
+	require.NoError(t, dryRunDB.InsertDynakube(NewDynakube("dynakube", "tenant-uuid", "1.2.3", "")))
+
+	volume, err := dryRunDB.GetVolume("volume-id")
+	require.NoError(t, err)
+	assert.NotNil(t, volume)
+	dynakubes, err := db.GetAllDynakubes()
+	require.NoError(t, err)
+	assert.Empty(t, dynakubes)
+}
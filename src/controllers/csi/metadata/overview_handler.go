@@ -0,0 +1,19 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NewOverviewHandler serves the AccessOverview as json, so the metadata of a node can be collected without accessing the node
+func NewOverviewHandler(access Access) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		overview, err := NewAccessOverview(access)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(overview)
+	})
+}
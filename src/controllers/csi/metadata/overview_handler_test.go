@@ -0,0 +1,36 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverviewHandler(t *testing.T) {
+	t.Run(`serves overview`, func(t *testing.T) {
+		db := FakeMemoryDB()
+		require.NoError(t, db.InsertDynakube(NewDynakube("dynakube", "tenant", "1.2.3", "")))
+		require.NoError(t, db.InsertVolume(NewVolume("volume", "pod", "1.2.3", "tenant")))
+		recorder := httptest.NewRecorder()
+
+		NewOverviewHandler(db).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metadata", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var overview AccessOverview
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &overview))
+		require.Len(t, overview.Dynakubes, 1)
+		assert.Equal(t, "1.2.3", overview.Dynakubes[0].LatestVersion)
+		assert.Len(t, overview.Volumes, 1)
+	})
+	t.Run(`failing database`, func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		NewOverviewHandler(&FakeFailDB{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metadata", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
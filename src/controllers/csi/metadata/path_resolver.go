@@ -54,10 +54,24 @@ func (pr PathResolver) AgentSharedBinaryDirForImage(digest string) string {
 	return filepath.Join(pr.AgentSharedBinaryDirBase(), digest)
 }
 
+// AgentContentStoreDir contains the files of the agent versions downloaded via url,
+// the files in the version directories of the tenants are hardlinks to them
+func (pr PathResolver) AgentContentStoreDir() string {
+	return filepath.Join(pr.RootDir, dtcsi.AgentContentStoreDir)
+}
+
+func (pr PathResolver) AgentContentStoreFile(contentKey string) string {
+	return filepath.Join(pr.AgentContentStoreDir(), contentKey[:2], contentKey)
+}
+
 func (pr PathResolver) AgentConfigDir(tenantUUID string) string {
 	return filepath.Join(pr.TenantDir(tenantUUID), dtcsi.SharedAgentConfigDir)
 }
 
+func (pr PathResolver) AgentConfigProcessModuleConfig(tenantUUID string) string {
+	return filepath.Join(pr.AgentConfigDir(tenantUUID), dtcsi.AgentProcessModuleConfigPath)
+}
+
 func (pr PathResolver) InnerAgentBinaryDirForSymlinkForVersion(tenantUUID string, version string) string {
 	return filepath.Join(pr.AgentBinaryDirForVersion(tenantUUID, version), "agent", "bin", "current")
 }
@@ -24,4 +24,5 @@ func TestPathResolver(t *testing.T) {
 	assert.Equal(t, filepath.Join(agentRunDirForVolume, "mapped"), pathResolver.OverlayMappedDir(tenantUUID, fakeVolume))
 	assert.Equal(t, filepath.Join(agentRunDirForVolume, "var"), pathResolver.OverlayVarDir(tenantUUID, fakeVolume))
 	assert.Equal(t, filepath.Join(agentRunDirForVolume, "work"), pathResolver.OverlayWorkDir(tenantUUID, fakeVolume))
+	assert.Equal(t, filepath.Join(rootDir, "contentstore", "ab", "abcdef-755"), pathResolver.AgentContentStoreFile("abcdef-755"))
 }
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/mattn/go-sqlite3"
@@ -176,6 +177,19 @@ func NewAccess(path string) (Access, error) {
 	return &access, nil
 }
 
+// NewReadOnlyAccess connects to an existing database without creating or altering its tables,
+// so it can be inspected while the csi driver is running.
+func NewReadOnlyAccess(path string) (Access, error) {
+	access := SqliteAccess{}
+	if err := access.connect(sqliteDriverName, fmt.Sprintf("file:%s?mode=ro", path)); err != nil {
+		return nil, err
+	}
+	if err := access.conn.Ping(); err != nil {
+		return nil, errors.WithStack(errors.WithMessagef(err, "couldn't open db %s read-only", path))
+	}
+	return &access, nil
+}
+
 func (access *SqliteAccess) connect(driver, path string) error {
 	db, err := sql.Open(driver, path)
 	if err != nil {
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -476,3 +477,28 @@ func TestDeleteVolume(t *testing.T) {
 	assert.Equal(t, len(podNames), 1)
 	assert.Equal(t, testVolume1.VolumeID, podNames[testVolume1.PodName])
 }
+
+func TestNewReadOnlyAccess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "csi.db")
+	db, err := NewAccess(path)
+	require.NoError(t, err)
+	require.NoError(t, db.InsertVolume(NewVolume("volume-id", "pod-name", "1.2.3", "tenant-uuid")))
+
+	t.Run(`reads existing database`, func(t *testing.T) {
+		readOnlyDB, err := NewReadOnlyAccess(path)
+		require.NoError(t, err)
+
+		volume, err := readOnlyDB.GetVolume("volume-id")
+		require.NoError(t, err)
+		require.NotNil(t, volume)
+		assert.Equal(t, "pod-name", volume.PodName)
+
+		err = readOnlyDB.DeleteVolume("volume-id")
+		assert.Error(t, err)
+	})
+	t.Run(`missing database`, func(t *testing.T) {
+		_, err := NewReadOnlyAccess(filepath.Join(t.TempDir(), "not-existing.db"))
+
+		assert.Error(t, err)
+	})
+}
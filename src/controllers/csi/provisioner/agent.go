@@ -25,6 +25,8 @@ type agentUpdater struct {
 	tenantUUID    string
 	installer     installer.Installer
 	recorder      updaterEventRecorder
+	// deduplicate replaces the files of a newly installed version with hardlinks to the content store
+	deduplicate bool
 }
 
 func newAgentUrlUpdater(
@@ -35,9 +37,20 @@ func newAgentUrlUpdater(
 	path metadata.PathResolver,
 	recorder record.EventRecorder,
 	dk *dynatracev1beta1.DynaKube) (*agentUpdater, error) {
+	return newAgentUrlUpdaterForVersion(ctx, fs, dtc, dk.CodeModulesVersion(), previousVersion, path, recorder, dk)
+}
+
+func newAgentUrlUpdaterForVersion(
+	ctx context.Context,
+	fs afero.Fs,
+	dtc dtclient.Client,
+	targetVersion string,
+	previousVersion string,
+	path metadata.PathResolver,
+	recorder record.EventRecorder,
+	dk *dynatracev1beta1.DynaKube) (*agentUpdater, error) {
 
 	tenantUUID := dk.ConnectionInfo().TenantUUID
-	targetVersion := dk.CodeModulesVersion()
 
 	agentInstaller := url.NewUrlInstaller(fs, dtc, getUrlProperties(targetVersion, previousVersion, path))
 	eventRecorder := updaterEventRecorder{
@@ -52,6 +65,7 @@ func newAgentUrlUpdater(
 		tenantUUID:    tenantUUID,
 		installer:     agentInstaller,
 		recorder:      eventRecorder,
+		deduplicate:   true,
 	}, nil
 }
 
@@ -156,6 +170,11 @@ func (updater *agentUpdater) installAgent() error {
 	}
 	if isNewlyInstalled {
 		updater.recorder.sendInstalledAgentVersionEvent(updater.targetVersion, updater.tenantUUID)
+		if updater.deduplicate {
+			if err := deduplicateAgentFiles(updater.fs, updater.path, updater.targetDir); err != nil {
+				log.Info("failed to deduplicate agent files", "version", updater.targetVersion, "error", err.Error())
+			}
+		}
 	}
 	return nil
 }
@@ -19,6 +19,7 @@ package csiprovisioner
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
@@ -54,6 +55,10 @@ type OneAgentProvisioner struct {
 	recorder     record.EventRecorder
 	db           metadata.Access
 	path         metadata.PathResolver
+
+	// predownloadedVersions holds the versions that were predownloaded for each dynakube,
+	// so they're only installed again if the configured versions change
+	predownloadedVersions map[string]string
 }
 
 // NewOneAgentProvisioner returns a new OneAgentProvisioner
@@ -82,12 +87,16 @@ func (provisioner *OneAgentProvisioner) Reconcile(ctx context.Context, request r
 	dk, err := provisioner.getDynaKube(ctx, request.NamespacedName)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
+			provisioner.updateNodeReadiness(ctx, request.Name, request.Namespace, false)
+			provisioner.updateNodeStatus(ctx, request.Name, request.Namespace, nil)
 			return reconcile.Result{}, provisioner.db.DeleteDynakube(request.Name)
 		}
 		return reconcile.Result{}, err
 	}
 	if !dk.NeedsCSIDriver() {
 		log.Info("CSI driver not needed")
+		provisioner.updateNodeReadiness(ctx, request.Name, request.Namespace, false)
+		provisioner.updateNodeStatus(ctx, request.Name, request.Namespace, nil)
 		return reconcile.Result{RequeueAfter: longRequeueDuration}, provisioner.db.DeleteDynakube(request.Name)
 	}
 
@@ -142,9 +151,50 @@ func (provisioner *OneAgentProvisioner) Reconcile(ctx context.Context, request r
 		return reconcile.Result{}, err
 	}
 
+	provisioner.updateNodeReadiness(ctx, dk.Name, dk.Namespace, true)
+	provisioner.updateNodeStatus(ctx, dk.Name, dk.Namespace, dynakubeMetadata)
+
+	if dk.CodeModulesImage() == "" && provisioner.needsPredownload(dk) {
+		provisioner.predownloadVersions(ctx, dtc, dk, latestProcessModuleConfigCache)
+	}
+
 	return reconcile.Result{RequeueAfter: defaultRequeueDuration}, nil
 }
 
+func (provisioner *OneAgentProvisioner) needsPredownload(dk *dynatracev1beta1.DynaKube) bool {
+	return len(dk.FeaturePredownloadVersions()) > 0 && provisioner.predownloadedVersions[dk.Name] != getPredownloadKey(dk)
+}
+
+// getPredownloadKey identifies the predownloaded versions, the tenant is part of it, as the versions are installed per tenant
+func getPredownloadKey(dk *dynatracev1beta1.DynaKube) string {
+	return dk.ConnectionInfo().TenantUUID + ":" + strings.Join(dk.FeaturePredownloadVersions(), ",")
+}
+
+// predownloadVersions installs the versions configured for the dynakube in addition to the current one,
+// failures are only logged as the current version is already available, they're retried on the next reconcile
+func (provisioner *OneAgentProvisioner) predownloadVersions(ctx context.Context, dtc dtclient.Client, dk *dynatracev1beta1.DynaKube, latestProcessModuleConfigCache *processModuleConfigCache) {
+	failed := false
+	for _, version := range dk.FeaturePredownloadVersions() {
+		agentUpdater, err := newAgentUrlUpdaterForVersion(ctx, provisioner.fs, dtc, version, "", provisioner.path, provisioner.recorder, dk)
+		if err != nil {
+			log.Info("failed to set up the download of the agent version", "version", version, "error", err.Error())
+			failed = true
+			continue
+		}
+		if _, err := agentUpdater.updateAgent(latestProcessModuleConfigCache); err != nil {
+			log.Info("failed to predownload agent version", "version", version, "error", err.Error())
+			failed = true
+		}
+	}
+	if failed {
+		return
+	}
+	if provisioner.predownloadedVersions == nil {
+		provisioner.predownloadedVersions = map[string]string{}
+	}
+	provisioner.predownloadedVersions[dk.Name] = getPredownloadKey(dk)
+}
+
 func (provisioner *OneAgentProvisioner) updateAgentInstallation(ctx context.Context, dtc dtclient.Client, dynakubeMetadata *metadata.Dynakube, dk *dynatracev1beta1.DynaKube) (
 	latestProcessModuleConfigCache *processModuleConfigCache,
 	requeue bool,
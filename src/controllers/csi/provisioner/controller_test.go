@@ -513,6 +513,28 @@ func TestHasCodeModulesWithCSIVolumeEnabled(t *testing.T) {
 	})
 }
 
+func TestNeedsPredownload(t *testing.T) {
+	dk := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        dkName,
+			Annotations: map[string]string{dynatracev1beta1.AnnotationFeaturePredownloadVersions: `["1.2.3", "1.2.4"]`},
+		},
+	}
+	dk.Status.ConnectionInfo.TenantUUID = tenantUUID
+	provisioner := &OneAgentProvisioner{}
+
+	assert.True(t, provisioner.needsPredownload(dk))
+
+	provisioner.predownloadedVersions = map[string]string{dkName: getPredownloadKey(dk)}
+	assert.False(t, provisioner.needsPredownload(dk))
+
+	dk.Annotations[dynatracev1beta1.AnnotationFeaturePredownloadVersions] = `["1.2.3"]`
+	assert.True(t, provisioner.needsPredownload(dk))
+
+	dk.Annotations = nil
+	assert.False(t, provisioner.needsPredownload(dk))
+}
+
 func buildValidApplicationMonitoringSpec(_ *testing.T) *dynatracev1beta1.ApplicationMonitoringSpec {
 	useCSIDriver := true
 	return &dynatracev1beta1.ApplicationMonitoringSpec{
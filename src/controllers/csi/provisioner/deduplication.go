@@ -0,0 +1,117 @@
+package csiprovisioner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const deduplicationTempSuffix = ".dedup"
+
+// tenantSpecificDirs are changed for each tenant after the installation (e.g. the ruxitagentproc.conf),
+// so their files must not be shared between the version directories
+var tenantSpecificDirs = append([]string{filepath.Join("agent", "conf")}, dtcsi.AgentWritableDirs...)
+
+// deduplicateAgentFiles replaces the files of an agent installation with hardlinks to the content store,
+// so the same version downloaded for multiple tenants only takes up the disk space once
+func deduplicateAgentFiles(fs afero.Fs, path metadata.PathResolver, installDir string) error {
+	if _, ok := fs.(*afero.OsFs); !ok {
+		log.Info("skipping deduplication of agent files, filesystem doesn't support hardlinks")
+		return nil
+	}
+
+	deduplicatedFiles := 0
+	err := afero.Walk(fs, installDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(installDir, filePath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if info.IsDir() {
+			if isTenantSpecificDir(relativePath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := deduplicateFile(fs, path, filePath, info); err != nil {
+			log.Info("failed to deduplicate agent file", "path", filePath, "error", err.Error())
+			return nil
+		}
+		deduplicatedFiles++
+		return nil
+	})
+	log.Info("deduplicated agent files", "installDir", installDir, "files", deduplicatedFiles)
+	return errors.WithStack(err)
+}
+
+func isTenantSpecificDir(relativePath string) bool {
+	for _, dir := range tenantSpecificDirs {
+		if relativePath == dir || strings.HasPrefix(relativePath, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// deduplicateFile moves the file into the content store if it's the first file with this content,
+// otherwise the file is replaced by a hardlink to the stored one
+func deduplicateFile(fs afero.Fs, path metadata.PathResolver, filePath string, info os.FileInfo) error {
+	contentKey, err := getContentKey(fs, filePath, info)
+	if err != nil {
+		return err
+	}
+	storePath := path.AgentContentStoreFile(contentKey)
+
+	storeInfo, err := os.Stat(storePath)
+	if os.IsNotExist(err) {
+		if err := fs.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(os.Link(filePath, storePath))
+	} else if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if os.SameFile(info, storeInfo) {
+		return nil
+	}
+	// the file is replaced by a rename, so it's never missing in the version directory
+	tmpPath := filePath + deduplicationTempSuffix
+	if err := os.Link(storePath, tmpPath); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// getContentKey returns the sha256 of the content together with the permissions,
+// because hardlinks share the permissions of the file
+func getContentKey(fs afero.Fs, filePath string, info os.FileInfo) (string, error) {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fmt.Sprintf("%x-%o", hash.Sum(nil), info.Mode().Perm()), nil
+}
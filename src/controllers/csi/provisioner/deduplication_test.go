@@ -0,0 +1,67 @@
+package csiprovisioner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicateAgentFiles(t *testing.T) {
+	t.Run(`same files of different tenants are linked to the content store`, func(t *testing.T) {
+		path := metadata.PathResolver{RootDir: t.TempDir()}
+		fs := afero.NewOsFs()
+		firstDir := path.AgentBinaryDirForVersion("tenant1", testVersion)
+		secondDir := path.AgentBinaryDirForVersion("tenant2", testVersion)
+		createAgentFiles(t, firstDir)
+		createAgentFiles(t, secondDir)
+
+		require.NoError(t, deduplicateAgentFiles(fs, path, firstDir))
+		require.NoError(t, deduplicateAgentFiles(fs, path, secondDir))
+
+		assertSameFile(t, true, filepath.Join(firstDir, "agent", "lib64", "liboneagentproc.so"), filepath.Join(secondDir, "agent", "lib64", "liboneagentproc.so"))
+		assertSameFile(t, false, filepath.Join(firstDir, "agent", "conf", "ruxitagentproc.conf"), filepath.Join(secondDir, "agent", "conf", "ruxitagentproc.conf"))
+		assertSameFile(t, false, filepath.Join(firstDir, "agent", "lib64", "liboneagentproc.so"), filepath.Join(firstDir, "agent", "lib64", "other.so"))
+
+		content, err := os.ReadFile(filepath.Join(secondDir, "agent", "lib64", "liboneagentproc.so"))
+		require.NoError(t, err)
+		assert.Equal(t, "binary", string(content))
+	})
+	t.Run(`skipped for filesystems without hardlinks`, func(t *testing.T) {
+		path := metadata.PathResolver{RootDir: "/"}
+		fs := afero.NewMemMapFs()
+		installDir := path.AgentBinaryDirForVersion("tenant1", testVersion)
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(installDir, "file"), []byte("binary"), 0755))
+
+		require.NoError(t, deduplicateAgentFiles(fs, path, installDir))
+
+		exists, err := afero.DirExists(fs, path.AgentContentStoreDir())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func createAgentFiles(t *testing.T, installDir string) {
+	files := map[string]string{
+		filepath.Join("agent", "lib64", "liboneagentproc.so"): "binary",
+		filepath.Join("agent", "lib64", "other.so"):           "other",
+		filepath.Join("agent", "conf", "ruxitagentproc.conf"): "conf",
+	}
+	for file, content := range files {
+		filePath := filepath.Join(installDir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0755))
+	}
+}
+
+func assertSameFile(t *testing.T, expected bool, firstPath string, secondPath string) {
+	firstInfo, err := os.Stat(firstPath)
+	require.NoError(t, err)
+	secondInfo, err := os.Stat(secondPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, os.SameFile(firstInfo, secondInfo))
+}
@@ -0,0 +1,100 @@
+package csiprovisioner
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const codeModulesReadyLabelValue = "true"
+
+// updateNodeReadiness labels the node of the provisioner, if the code modules of the dynakube are ready to be mounted,
+// once every dynakube that needs the csi driver is ready the startup taint of the node is removed.
+// Pods can use the label to avoid nodes where their volume can't be provided yet.
+func (provisioner *OneAgentProvisioner) updateNodeReadiness(ctx context.Context, dynakubeName string, namespace string, ready bool) {
+	if provisioner.opts.NodeId == "" {
+		return
+	}
+	if err := provisioner.setNodeReadiness(ctx, dynakubeName, namespace, ready); err != nil {
+		log.Info("failed to update the code modules readiness of the node", "node", provisioner.opts.NodeId, "dynakube", dynakubeName, "error", err.Error())
+	}
+}
+
+func (provisioner *OneAgentProvisioner) setNodeReadiness(ctx context.Context, dynakubeName string, namespace string, ready bool) error {
+	var node corev1.Node
+	if err := provisioner.apiReader.Get(ctx, client.ObjectKey{Name: provisioner.opts.NodeId}, &node); err != nil {
+		return errors.WithStack(err)
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+
+	changed := false
+	label := dtcsi.CodeModulesReadyLabel(dynakubeName)
+	_, isLabeled := node.Labels[label]
+	if ready && !isLabeled {
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[label] = codeModulesReadyLabelValue
+		changed = true
+	} else if !ready && isLabeled {
+		delete(node.Labels, label)
+		changed = true
+	}
+
+	if ready && hasCodeModulesNotReadyTaint(node) {
+		allReady, err := provisioner.areAllDynakubesReady(ctx, node, namespace)
+		if err != nil {
+			return err
+		}
+		if allReady {
+			log.Info("code modules of every dynakube are ready, removing startup taint", "node", node.Name)
+			node.Spec.Taints = removeCodeModulesNotReadyTaint(node.Spec.Taints)
+			changed = true
+		}
+	}
+
+	// the node is only patched if the label or the taint has to change, as this runs on every reconcile
+	if !changed {
+		return nil
+	}
+	return errors.WithStack(provisioner.client.Patch(ctx, &node, patch))
+}
+
+func (provisioner *OneAgentProvisioner) areAllDynakubesReady(ctx context.Context, node corev1.Node, namespace string) (bool, error) {
+	var dynakubeList dynatracev1beta1.DynaKubeList
+	if err := provisioner.apiReader.List(ctx, &dynakubeList, client.InNamespace(namespace)); err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, dynakube := range dynakubeList.Items {
+		if !dynakube.NeedsCSIDriver() {
+			continue
+		}
+		if _, ok := node.Labels[dtcsi.CodeModulesReadyLabel(dynakube.Name)]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func hasCodeModulesNotReadyTaint(node corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == dtcsi.CodeModulesNotReadyTaint {
+			return true
+		}
+	}
+	return false
+}
+
+func removeCodeModulesNotReadyTaint(taints []corev1.Taint) []corev1.Taint {
+	var remainingTaints []corev1.Taint
+	for _, taint := range taints {
+		if taint.Key != dtcsi.CodeModulesNotReadyTaint {
+			remainingTaints = append(remainingTaints, taint)
+		}
+	}
+	return remainingTaints
+}
@@ -0,0 +1,128 @@
+package csiprovisioner
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testNodeName  = "test-node"
+	testNamespace = "test-namespace"
+)
+
+func TestOneAgentProvisioner_updateNodeReadiness(t *testing.T) {
+	t.Run(`labels ready node`, func(t *testing.T) {
+		provisioner := newNodeReadinessProvisioner(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName}})
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, true)
+
+		node := getTestNode(t, provisioner)
+		assert.Equal(t, codeModulesReadyLabelValue, node.Labels[dtcsi.CodeModulesReadyLabel(dkName)])
+	})
+	t.Run(`removes label of not ready dynakube`, func(t *testing.T) {
+		provisioner := newNodeReadinessProvisioner(&v1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   testNodeName,
+			Labels: map[string]string{dtcsi.CodeModulesReadyLabel(dkName): codeModulesReadyLabelValue},
+		}})
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, false)
+
+		node := getTestNode(t, provisioner)
+		assert.NotContains(t, node.Labels, dtcsi.CodeModulesReadyLabel(dkName))
+	})
+	t.Run(`removes startup taint once every dynakube is ready`, func(t *testing.T) {
+		provisioner := newNodeReadinessProvisioner(newTaintedNode(), newCSIDynakube(dkName))
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, true)
+
+		node := getTestNode(t, provisioner)
+		require.Len(t, node.Spec.Taints, 1)
+		assert.Equal(t, "other-taint", node.Spec.Taints[0].Key)
+	})
+	t.Run(`keeps startup taint while other dynakubes are not ready`, func(t *testing.T) {
+		provisioner := newNodeReadinessProvisioner(newTaintedNode(), newCSIDynakube(dkName), newCSIDynakube(otherDkName))
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, true)
+
+		node := getTestNode(t, provisioner)
+		assert.Len(t, node.Spec.Taints, 2)
+	})
+	t.Run(`doesn't patch unchanged node`, func(t *testing.T) {
+		clt := &patchCountingClient{Client: fake.NewClient(&v1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   testNodeName,
+			Labels: map[string]string{dtcsi.CodeModulesReadyLabel(dkName): codeModulesReadyLabelValue},
+		}})}
+		provisioner := &OneAgentProvisioner{client: clt, apiReader: clt, opts: dtcsi.CSIOptions{NodeId: testNodeName}}
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, true)
+		provisioner.updateNodeReadiness(context.TODO(), otherDkName, testNamespace, false)
+
+		assert.Zero(t, clt.patches)
+	})
+	t.Run(`no node id`, func(t *testing.T) {
+		provisioner := newNodeReadinessProvisioner(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName}})
+		provisioner.opts.NodeId = ""
+
+		provisioner.updateNodeReadiness(context.TODO(), dkName, testNamespace, true)
+
+		node := getTestNode(t, provisioner)
+		assert.Empty(t, node.Labels)
+	})
+}
+
+type patchCountingClient struct {
+	client.Client
+	patches int
+}
+
+func (clt *patchCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	clt.patches++
+	return clt.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func newNodeReadinessProvisioner(objs ...client.Object) *OneAgentProvisioner {
+	fakeClient := fake.NewClient(objs...)
+	return &OneAgentProvisioner{
+		client:    fakeClient,
+		apiReader: fakeClient,
+		opts:      dtcsi.CSIOptions{NodeId: testNodeName},
+	}
+}
+
+func newTaintedNode() *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: testNodeName},
+		Spec: v1.NodeSpec{
+			Taints: []v1.Taint{
+				{Key: dtcsi.CodeModulesNotReadyTaint, Effect: v1.TaintEffectNoSchedule},
+				{Key: "other-taint", Effect: v1.TaintEffectNoSchedule},
+			},
+		},
+	}
+}
+
+func newCSIDynakube(name string) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+			},
+		},
+	}
+}
+
+func getTestNode(t *testing.T, provisioner *OneAgentProvisioner) v1.Node {
+	var node v1.Node
+	require.NoError(t, provisioner.apiReader.Get(context.TODO(), client.ObjectKey{Name: testNodeName}, &node))
+	return node
+}
@@ -0,0 +1,147 @@
+package csiprovisioner
+
+import (
+	"context"
+	"strconv"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateNodeStatus publishes the installed code modules version of the dynakube and the disk usage of the data dir
+// in a config map per node, so the troubleshoot command can check the provisioners without accessing the nodes.
+// If dynakubeMetadata is nil the entries of the dynakube are removed.
+func (provisioner *OneAgentProvisioner) updateNodeStatus(ctx context.Context, dynakubeName string, namespace string, dynakubeMetadata *metadata.Dynakube) {
+	if provisioner.opts.NodeId == "" {
+		return
+	}
+	if err := provisioner.setNodeStatus(ctx, dynakubeName, namespace, dynakubeMetadata); err != nil {
+		log.Info("failed to update the status of the node", "node", provisioner.opts.NodeId, "dynakube", dynakubeName, "error", err.Error())
+	}
+}
+
+// setNodeStatus owns the config map by the node, so it's garbage collected with the node,
+// it's only updated if an entry changed, the disk usage only counts if it changed by more than 1% of the disk
+func (provisioner *OneAgentProvisioner) setNodeStatus(ctx context.Context, dynakubeName string, namespace string, dynakubeMetadata *metadata.Dynakube) error {
+	var configMap corev1.ConfigMap
+	err := provisioner.apiReader.Get(ctx, client.ObjectKey{Name: dtcsi.NodeStatusConfigMapName(provisioner.opts.NodeId), Namespace: namespace}, &configMap)
+	isMissing := k8serrors.IsNotFound(err)
+	if isMissing && dynakubeMetadata == nil {
+		return nil
+	} else if err != nil && !isMissing {
+		return errors.WithStack(err)
+	}
+
+	var node corev1.Node
+	if err := provisioner.apiReader.Get(ctx, client.ObjectKey{Name: provisioner.opts.NodeId}, &node); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if isMissing {
+		configMap = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dtcsi.NodeStatusConfigMapName(provisioner.opts.NodeId),
+				Namespace: namespace,
+			},
+		}
+		setNodeOwner(&configMap, node)
+		provisioner.setNodeStatusData(&configMap, dynakubeName, dynakubeMetadata)
+		return errors.WithStack(provisioner.client.Create(ctx, &configMap))
+	}
+
+	previousData := make(map[string]string, len(configMap.Data))
+	for key, value := range configMap.Data {
+		previousData[key] = value
+	}
+	ownerChanged := setNodeOwner(&configMap, node)
+	provisioner.setNodeStatusData(&configMap, dynakubeName, dynakubeMetadata)
+	if !ownerChanged && !isNodeStatusChanged(previousData, configMap.Data) {
+		return nil
+	}
+	return errors.WithStack(provisioner.client.Update(ctx, &configMap))
+}
+
+// setNodeOwner sets the node as the only owner of the config map, it returns false if it's already the owner
+func setNodeOwner(configMap *corev1.ConfigMap, node corev1.Node) bool {
+	for _, owner := range configMap.OwnerReferences {
+		if owner.UID == node.UID && len(configMap.OwnerReferences) == 1 {
+			return false
+		}
+	}
+	configMap.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "v1",
+		Kind:       "Node",
+		Name:       node.Name,
+		UID:        node.UID,
+	}}
+	return true
+}
+
+// isNodeStatusChanged compares the entries of the node status, changes of the used disk space below 1% of the disk are ignored
+func isNodeStatusChanged(previousData map[string]string, data map[string]string) bool {
+	if len(previousData) != len(data) {
+		return true
+	}
+	for key, value := range data {
+		previousValue, ok := previousData[key]
+		if !ok {
+			return true
+		}
+		if key != dtcsi.NodeStatusDataDirUsedKey && value != previousValue {
+			return true
+		}
+	}
+
+	used, usedErr := strconv.ParseUint(data[dtcsi.NodeStatusDataDirUsedKey], 10, 64)
+	previousUsed, previousUsedErr := strconv.ParseUint(previousData[dtcsi.NodeStatusDataDirUsedKey], 10, 64)
+	total, totalErr := strconv.ParseUint(data[dtcsi.NodeStatusDataDirTotalKey], 10, 64)
+	if usedErr != nil || previousUsedErr != nil || totalErr != nil {
+		return data[dtcsi.NodeStatusDataDirUsedKey] != previousData[dtcsi.NodeStatusDataDirUsedKey]
+	}
+	diff := used - previousUsed
+	if previousUsed > used {
+		diff = previousUsed - used
+	}
+	return diff*100 > total
+}
+
+func (provisioner *OneAgentProvisioner) setNodeStatusData(configMap *corev1.ConfigMap, dynakubeName string, dynakubeMetadata *metadata.Dynakube) {
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	if dynakubeMetadata == nil {
+		delete(configMap.Data, dtcsi.NodeStatusTenantUUIDKey(dynakubeName))
+		delete(configMap.Data, dtcsi.NodeStatusVersionKey(dynakubeName))
+	} else {
+		configMap.Data[dtcsi.NodeStatusTenantUUIDKey(dynakubeName)] = dynakubeMetadata.TenantUUID
+		configMap.Data[dtcsi.NodeStatusVersionKey(dynakubeName)] = dynakubeMetadata.LatestVersion
+	}
+
+	used, total, err := getDiskUsage(provisioner.opts.RootDir)
+	if err != nil {
+		log.Info("failed to get the disk usage of the data dir", "path", provisioner.opts.RootDir, "error", err.Error())
+		delete(configMap.Data, dtcsi.NodeStatusDataDirUsedKey)
+		delete(configMap.Data, dtcsi.NodeStatusDataDirTotalKey)
+		return
+	}
+	configMap.Data[dtcsi.NodeStatusDataDirUsedKey] = strconv.FormatUint(used, 10)
+	configMap.Data[dtcsi.NodeStatusDataDirTotalKey] = strconv.FormatUint(total, 10)
+}
+
+// getDiskUsage returns the used and the total bytes of the filesystem the path is on
+func getDiskUsage(path string) (uint64, uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	blockSize := uint64(stat.Bsize)
+	total := stat.Blocks * blockSize
+	return total - stat.Bfree*blockSize, total, nil
+}
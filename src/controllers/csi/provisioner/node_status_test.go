@@ -0,0 +1,137 @@
+package csiprovisioner
+
+import (
+	"context"
+	"testing"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testNodeUID = "test-node-uid"
+
+func TestOneAgentProvisioner_updateNodeStatus(t *testing.T) {
+	t.Run(`creates status of node`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t)
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, metadata.NewDynakube(dkName, tenantUUID, agentVersion, ""))
+
+		configMap := getTestNodeStatus(t, provisioner)
+		assert.Equal(t, tenantUUID, configMap.Data[dtcsi.NodeStatusTenantUUIDKey(dkName)])
+		assert.Equal(t, agentVersion, configMap.Data[dtcsi.NodeStatusVersionKey(dkName)])
+		assert.NotEmpty(t, configMap.Data[dtcsi.NodeStatusDataDirUsedKey])
+		assert.NotEmpty(t, configMap.Data[dtcsi.NodeStatusDataDirTotalKey])
+		require.Len(t, configMap.OwnerReferences, 1)
+		assert.Equal(t, "Node", configMap.OwnerReferences[0].Kind)
+		assert.Equal(t, testNodeName, configMap.OwnerReferences[0].Name)
+		assert.Equal(t, types.UID(testNodeUID), configMap.OwnerReferences[0].UID)
+	})
+	t.Run(`updates status of node`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t, newTestNodeStatus(map[string]string{
+			dtcsi.NodeStatusTenantUUIDKey(otherDkName): tenantUUID,
+			dtcsi.NodeStatusVersionKey(otherDkName):    "v1",
+			dtcsi.NodeStatusVersionKey(dkName):         "v1",
+		}))
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, metadata.NewDynakube(dkName, tenantUUID, agentVersion, ""))
+
+		configMap := getTestNodeStatus(t, provisioner)
+		assert.Equal(t, agentVersion, configMap.Data[dtcsi.NodeStatusVersionKey(dkName)])
+		assert.Equal(t, "v1", configMap.Data[dtcsi.NodeStatusVersionKey(otherDkName)])
+		require.Len(t, configMap.OwnerReferences, 1)
+		assert.Equal(t, types.UID(testNodeUID), configMap.OwnerReferences[0].UID)
+	})
+	t.Run(`skips update if only the disk usage changed slightly`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t)
+		dynakubeMetadata := metadata.NewDynakube(dkName, tenantUUID, agentVersion, "")
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, dynakubeMetadata)
+		configMap := getTestNodeStatus(t, provisioner)
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, dynakubeMetadata)
+
+		assert.Equal(t, configMap.ResourceVersion, getTestNodeStatus(t, provisioner).ResourceVersion)
+	})
+	t.Run(`removes dynakube from status of node`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t, newTestNodeStatus(map[string]string{
+			dtcsi.NodeStatusTenantUUIDKey(dkName): tenantUUID,
+			dtcsi.NodeStatusVersionKey(dkName):    agentVersion,
+		}))
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, nil)
+
+		configMap := getTestNodeStatus(t, provisioner)
+		assert.NotContains(t, configMap.Data, dtcsi.NodeStatusTenantUUIDKey(dkName))
+		assert.NotContains(t, configMap.Data, dtcsi.NodeStatusVersionKey(dkName))
+	})
+	t.Run(`doesn't create status to remove dynakube`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t)
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, nil)
+
+		var configMap v1.ConfigMap
+		err := provisioner.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtcsi.NodeStatusConfigMapName(testNodeName), Namespace: testNamespace}, &configMap)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`missing data dir`, func(t *testing.T) {
+		provisioner := newNodeStatusProvisioner(t)
+		provisioner.opts.RootDir = "/not/existing"
+
+		provisioner.updateNodeStatus(context.TODO(), dkName, testNamespace, metadata.NewDynakube(dkName, tenantUUID, agentVersion, ""))
+
+		configMap := getTestNodeStatus(t, provisioner)
+		assert.Equal(t, agentVersion, configMap.Data[dtcsi.NodeStatusVersionKey(dkName)])
+		assert.NotContains(t, configMap.Data, dtcsi.NodeStatusDataDirUsedKey)
+	})
+}
+
+func TestIsNodeStatusChanged(t *testing.T) {
+	data := map[string]string{
+		dtcsi.NodeStatusVersionKey(dkName): agentVersion,
+		dtcsi.NodeStatusDataDirUsedKey:     "500",
+		dtcsi.NodeStatusDataDirTotalKey:    "1000",
+	}
+	changedData := func(key string, value string) map[string]string {
+		changed := map[string]string{}
+		for k, v := range data {
+			changed[k] = v
+		}
+		changed[key] = value
+		return changed
+	}
+
+	assert.False(t, isNodeStatusChanged(data, changedData(dtcsi.NodeStatusDataDirUsedKey, "509")))
+	assert.True(t, isNodeStatusChanged(data, changedData(dtcsi.NodeStatusDataDirUsedKey, "480")))
+	assert.True(t, isNodeStatusChanged(data, changedData(dtcsi.NodeStatusVersionKey(dkName), "v1")))
+	assert.True(t, isNodeStatusChanged(data, changedData(dtcsi.NodeStatusTenantUUIDKey(dkName), tenantUUID)))
+}
+
+func newNodeStatusProvisioner(t *testing.T, objs ...client.Object) *OneAgentProvisioner {
+	objs = append(objs, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName, UID: testNodeUID}})
+	fakeClient := fake.NewClient(objs...)
+	return &OneAgentProvisioner{
+		client:    fakeClient,
+		apiReader: fakeClient,
+		opts:      dtcsi.CSIOptions{NodeId: testNodeName, RootDir: t.TempDir()},
+	}
+}
+
+func newTestNodeStatus(data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: dtcsi.NodeStatusConfigMapName(testNodeName), Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func getTestNodeStatus(t *testing.T, provisioner *OneAgentProvisioner) v1.ConfigMap {
+	var configMap v1.ConfigMap
+	require.NoError(t, provisioner.apiReader.Get(context.TODO(), client.ObjectKey{Name: dtcsi.NodeStatusConfigMapName(testNodeName), Namespace: testNamespace}, &configMap))
+	return configMap
+}
@@ -0,0 +1,217 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	controllerName = "diagnostics"
+
+	// maxConditionMessageLength keeps the condition readable, the full messages are in the events
+	maxConditionMessageLength = 1024
+)
+
+// Finding is a check, which failed or found a problem that doesn't break the setup
+type Finding struct {
+	Check   string
+	Warning bool
+	Message string
+}
+
+// DiagnoseFunc runs the checks for the dynakube and returns their findings,
+// it's provided by the troubleshoot command, which can't be imported by the controllers
+type DiagnoseFunc func(apiReader client.Reader, namespace string, dynakube string) []Finding
+
+func Add(mgr manager.Manager, _ string, diagnose DiagnoseFunc) error {
+	return NewController(mgr, diagnose).SetupWithManager(mgr)
+}
+
+func NewController(mgr manager.Manager, diagnose DiagnoseFunc) *DiagnosticsController {
+	return NewDiagnosticsController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetEventRecorderFor(controllerName), diagnose)
+}
+
+func NewDiagnosticsController(client client.Client, apiReader client.Reader, recorder record.EventRecorder, diagnose DiagnoseFunc) *DiagnosticsController {
+	return &DiagnosticsController{
+		client:           client,
+		apiReader:        apiReader,
+		recorder:         recorder,
+		diagnose:         diagnose,
+		reportedFindings: map[types.NamespacedName]map[string]Finding{},
+	}
+}
+
+// SetupWithManager only reacts to changes of the spec and the annotations, which enable the diagnostics,
+// so updates of the status don't trigger the checks, they are repeated by requeueing the dynakube after the diagnostics interval
+func (controller *DiagnosticsController) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&dynatracev1beta1.DynaKube{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Complete(controller)
+}
+
+// DiagnosticsController periodically runs the troubleshoot checks for each DynaKube, which enables them,
+// the result is reported as condition of the DynaKube and its new or changed problems as events
+type DiagnosticsController struct {
+	client    client.Client
+	apiReader client.Reader
+	recorder  record.EventRecorder
+	diagnose  DiagnoseFunc
+
+	// reportedFindings are the findings of the last run per dynakube and check, so unchanged findings aren't reported again,
+	// they are kept in memory, so after a restart of the operator the findings are reported once more
+	reportedFindings     map[types.NamespacedName]map[string]Finding
+	reportedFindingsLock sync.Mutex
+}
+
+func (controller *DiagnosticsController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var dynakube dynatracev1beta1.DynaKube
+	err := controller.apiReader.Get(ctx, request.NamespacedName, &dynakube)
+	if k8serrors.IsNotFound(err) {
+		controller.forgetFindings(request.NamespacedName)
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, errors.WithStack(err)
+	}
+
+	interval := dynakube.FeatureDiagnosticsInterval()
+	if interval == 0 {
+		controller.forgetFindings(request.NamespacedName)
+		return reconcile.Result{}, controller.removeCondition(ctx, request)
+	}
+
+	log.Info("running diagnostics", "namespace", request.Namespace, "name", request.Name)
+	findings := controller.diagnose(controller.apiReader, request.Namespace, request.Name)
+	condition := newDiagnosticsCondition(findings)
+	controller.sendEvents(&dynakube, findings, condition)
+
+	if err := controller.setCondition(ctx, request, condition); err != nil {
+		return reconcile.Result{}, err
+	}
+	log.Info("diagnostics finished", "namespace", request.Namespace, "name", request.Name, "result", condition.Reason)
+
+	return reconcile.Result{RequeueAfter: interval}, nil
+}
+
+// setCondition updates the condition on the current version of the dynakube, as the checks take a while and the dynakube controller updates the status as well
+func (controller *DiagnosticsController) setCondition(ctx context.Context, request reconcile.Request, condition metav1.Condition) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var dynakube dynatracev1beta1.DynaKube
+		if err := controller.apiReader.Get(ctx, request.NamespacedName, &dynakube); err != nil {
+			return err
+		}
+		meta.SetStatusCondition(&dynakube.Status.Conditions, condition)
+		return controller.client.Status().Update(ctx, &dynakube)
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.WithStack(err)
+}
+
+func (controller *DiagnosticsController) removeCondition(ctx context.Context, request reconcile.Request) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var dynakube dynatracev1beta1.DynaKube
+		if err := controller.apiReader.Get(ctx, request.NamespacedName, &dynakube); err != nil {
+			return err
+		}
+		if meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.DiagnosticsConditionType) == nil {
+			return nil
+		}
+		meta.RemoveStatusCondition(&dynakube.Status.Conditions, dynatracev1beta1.DiagnosticsConditionType)
+		return controller.client.Status().Update(ctx, &dynakube)
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.WithStack(err)
+}
+
+// sendEvents reports findings that are new or changed since the last run as warning, so alerting on events picks up regressions,
+// recovering from failures or warnings is reported once
+func (controller *DiagnosticsController) sendEvents(dynakube *dynatracev1beta1.DynaKube, findings []Finding, condition metav1.Condition) {
+	for _, finding := range controller.updateFindings(types.NamespacedName{Namespace: dynakube.Namespace, Name: dynakube.Name}, findings) {
+		if finding.Warning {
+			controller.recorder.Eventf(dynakube, corev1.EventTypeWarning, dynatracev1beta1.ReasonDiagnosticsWarning,
+				"check '%s' found a problem: %s", finding.Check, finding.Message)
+		} else {
+			controller.recorder.Eventf(dynakube, corev1.EventTypeWarning, dynatracev1beta1.ReasonDiagnosticsFailed,
+				"check '%s' failed: %s", finding.Check, finding.Message)
+		}
+	}
+
+	previousCondition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.DiagnosticsConditionType)
+	if condition.Reason == dynatracev1beta1.ReasonDiagnosticsPassed && previousCondition != nil && previousCondition.Reason != dynatracev1beta1.ReasonDiagnosticsPassed {
+		controller.recorder.Event(dynakube, corev1.EventTypeNormal, dynatracev1beta1.ReasonDiagnosticsPassed, "all checks passed")
+	}
+}
+
+// updateFindings stores the findings of the dynakube and returns the ones, which weren't found by the last run or have changed
+func (controller *DiagnosticsController) updateFindings(dynakube types.NamespacedName, findings []Finding) []Finding {
+	controller.reportedFindingsLock.Lock()
+	defer controller.reportedFindingsLock.Unlock()
+
+	previousFindings := controller.reportedFindings[dynakube]
+	currentFindings := make(map[string]Finding, len(findings))
+	var changedFindings []Finding
+	for _, finding := range findings {
+		currentFindings[finding.Check] = finding
+		if previousFinding, ok := previousFindings[finding.Check]; !ok || previousFinding != finding {
+			changedFindings = append(changedFindings, finding)
+		}
+	}
+	controller.reportedFindings[dynakube] = currentFindings
+	return changedFindings
+}
+
+func (controller *DiagnosticsController) forgetFindings(dynakube types.NamespacedName) {
+	controller.reportedFindingsLock.Lock()
+	defer controller.reportedFindingsLock.Unlock()
+	delete(controller.reportedFindings, dynakube)
+}
+
+// newDiagnosticsCondition is true, unless a check failed, the message lists the findings
+func newDiagnosticsCondition(findings []Finding) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    dynatracev1beta1.DiagnosticsConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonDiagnosticsPassed,
+		Message: "all checks passed",
+	}
+	if len(findings) == 0 {
+		return condition
+	}
+
+	condition.Reason = dynatracev1beta1.ReasonDiagnosticsWarning
+	messages := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		if !finding.Warning {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = dynatracev1beta1.ReasonDiagnosticsFailed
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", finding.Check, finding.Message))
+	}
+	condition.Message = strings.Join(messages, "; ")
+	if len(condition.Message) > maxConditionMessageLength {
+		condition.Message = condition.Message[:maxConditionMessageLength-3] + "..."
+	}
+	return condition
+}
@@ -0,0 +1,227 @@
+package diagnostics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	testNamespace = "dynatrace"
+	testDynakube  = "dynakube"
+)
+
+func TestReconcile(t *testing.T) {
+	t.Run(`all checks passed`, func(t *testing.T) {
+		controller, recorder := newTestDiagnosticsController(testBuildDynakube(), nil)
+
+		result, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, result.RequeueAfter)
+		condition := getTestCondition(t, controller)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonDiagnosticsPassed, condition.Reason)
+		assert.Empty(t, recorder.Events)
+	})
+	t.Run(`failures and warnings`, func(t *testing.T) {
+		controller, recorder := newTestDiagnosticsController(testBuildDynakube(), []Finding{
+			{Check: "network", Message: "no communication host is reachable"},
+			{Check: "csi", Warning: true, Message: "disks are almost full"},
+		})
+
+		_, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		condition := getTestCondition(t, controller)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonDiagnosticsFailed, condition.Reason)
+		assert.Equal(t, "network: no communication host is reachable; csi: disks are almost full", condition.Message)
+		require.Len(t, recorder.Events, 2)
+		assert.Equal(t, "Warning DiagnosticsFailed check 'network' failed: no communication host is reachable", <-recorder.Events)
+		assert.Equal(t, "Warning DiagnosticsWarning check 'csi' found a problem: disks are almost full", <-recorder.Events)
+	})
+	t.Run(`unchanged findings are only reported once`, func(t *testing.T) {
+		findings := []Finding{
+			{Check: "network", Message: "no communication host is reachable"},
+			{Check: "csi", Warning: true, Message: "disks are almost full"},
+		}
+		controller, recorder := newTestDiagnosticsController(testBuildDynakube(), findings)
+
+		_, err := controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		require.Len(t, recorder.Events, 2)
+		<-recorder.Events
+		<-recorder.Events
+
+		_, err = controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		assert.Empty(t, recorder.Events)
+
+		findings[1].Message = "disks are full"
+		_, err = controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		require.Len(t, recorder.Events, 1)
+		assert.Equal(t, "Warning DiagnosticsWarning check 'csi' found a problem: disks are full", <-recorder.Events)
+	})
+	t.Run(`reappearing findings are reported again`, func(t *testing.T) {
+		var findings []Finding
+		controller, recorder := newTestDiagnosticsController(testBuildDynakube(), nil)
+		controller.diagnose = func(client.Reader, string, string) []Finding {
+			return findings
+		}
+
+		findings = []Finding{{Check: "network", Message: "no communication host is reachable"}}
+		_, err := controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		require.Len(t, recorder.Events, 1)
+		<-recorder.Events
+
+		findings = nil
+		_, err = controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		require.Len(t, recorder.Events, 1)
+		assert.Equal(t, "Normal DiagnosticsPassed all checks passed", <-recorder.Events)
+
+		findings = []Finding{{Check: "network", Message: "no communication host is reachable"}}
+		_, err = controller.Reconcile(context.TODO(), testRequest())
+		require.NoError(t, err)
+		require.Len(t, recorder.Events, 1)
+		assert.Equal(t, "Warning DiagnosticsFailed check 'network' failed: no communication host is reachable", <-recorder.Events)
+	})
+	t.Run(`only warnings`, func(t *testing.T) {
+		controller, _ := newTestDiagnosticsController(testBuildDynakube(), []Finding{
+			{Check: "csi", Warning: true, Message: "disks are almost full"},
+		})
+
+		_, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		condition := getTestCondition(t, controller)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonDiagnosticsWarning, condition.Reason)
+	})
+	t.Run(`recovery is reported`, func(t *testing.T) {
+		dynakube := testBuildDynakube()
+		dynakube.Status.Conditions = []metav1.Condition{{
+			Type:   dynatracev1beta1.DiagnosticsConditionType,
+			Status: metav1.ConditionFalse,
+			Reason: dynatracev1beta1.ReasonDiagnosticsFailed,
+		}}
+		controller, recorder := newTestDiagnosticsController(dynakube, nil)
+
+		_, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, metav1.ConditionTrue, getTestCondition(t, controller).Status)
+		require.Len(t, recorder.Events, 1)
+		assert.Equal(t, "Normal DiagnosticsPassed all checks passed", <-recorder.Events)
+	})
+	t.Run(`custom interval`, func(t *testing.T) {
+		dynakube := testBuildDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureDiagnosticsInterval: "300"}
+		controller, _ := newTestDiagnosticsController(dynakube, nil)
+
+		result, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, result.RequeueAfter)
+	})
+	t.Run(`disabled by default`, func(t *testing.T) {
+		dynakube := testBuildDynakube()
+		dynakube.Annotations = nil
+		controller, _ := newTestDiagnosticsController(dynakube, nil)
+		controller.diagnose = func(client.Reader, string, string) []Finding {
+			t.Error("diagnostics must not run")
+			return nil
+		}
+
+		result, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+	})
+	t.Run(`disabled diagnostics remove the condition`, func(t *testing.T) {
+		dynakube := testBuildDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureDiagnosticsInterval: "0"}
+		dynakube.Status.Conditions = []metav1.Condition{{
+			Type:   dynatracev1beta1.DiagnosticsConditionType,
+			Status: metav1.ConditionTrue,
+			Reason: dynatracev1beta1.ReasonDiagnosticsPassed,
+		}}
+		controller, _ := newTestDiagnosticsController(dynakube, nil)
+		controller.diagnose = func(client.Reader, string, string) []Finding {
+			t.Error("diagnostics must not run")
+			return nil
+		}
+
+		result, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		var updated dynatracev1beta1.DynaKube
+		require.NoError(t, controller.apiReader.Get(context.TODO(), testRequest().NamespacedName, &updated))
+		assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, dynatracev1beta1.DiagnosticsConditionType))
+	})
+	t.Run(`deleted dynakube`, func(t *testing.T) {
+		controller, _ := newTestDiagnosticsController(nil, nil)
+
+		result, err := controller.Reconcile(context.TODO(), testRequest())
+
+		require.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+	})
+}
+
+func TestNewDiagnosticsCondition(t *testing.T) {
+	t.Run(`long messages are truncated`, func(t *testing.T) {
+		condition := newDiagnosticsCondition([]Finding{{Check: "network", Message: strings.Repeat("x", 2*maxConditionMessageLength)}})
+
+		assert.Len(t, condition.Message, maxConditionMessageLength)
+		assert.True(t, strings.HasSuffix(condition.Message, "..."))
+	})
+}
+
+func newTestDiagnosticsController(dynakube *dynatracev1beta1.DynaKube, findings []Finding) (*DiagnosticsController, *record.FakeRecorder) {
+	objects := []client.Object{}
+	if dynakube != nil {
+		objects = append(objects, dynakube)
+	}
+	fakeClient := fake.NewClient(objects...)
+	recorder := record.NewFakeRecorder(10)
+	return NewDiagnosticsController(fakeClient, fakeClient, recorder, func(apiReader client.Reader, namespace string, dynakube string) []Finding {
+		return findings
+	}), recorder
+}
+
+func testBuildDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{
+		Name:        testDynakube,
+		Namespace:   testNamespace,
+		Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureDiagnosticsInterval: "3600"},
+	}}
+}
+
+func testRequest() reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: testDynakube, Namespace: testNamespace}}
+}
+
+func getTestCondition(t *testing.T, controller *DiagnosticsController) *metav1.Condition {
+	var dynakube dynatracev1beta1.DynaKube
+	require.NoError(t, controller.apiReader.Get(context.TODO(), testRequest().NamespacedName, &dynakube))
+	condition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.DiagnosticsConditionType)
+	require.NotNil(t, condition)
+	return condition
+}
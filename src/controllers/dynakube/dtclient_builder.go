@@ -54,21 +54,32 @@ func NewDynatraceClientProperties(ctx context.Context, apiReader client.Reader,
 
 // BuildDynatraceClient creates a new Dynatrace client using the settings configured on the given instance.
 func BuildDynatraceClient(properties DynatraceClientProperties) (dtclient.Client, error) {
-	namespace := properties.Namespace
 	secret := properties.Secret
-	apiReader := properties.ApiReader
 
 	tokens, err := kubeobjects.NewTokens(secret)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	opts, err := NewDynatraceClientOptions(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtclient.NewClient(properties.ApiUrl, tokens.ApiToken, tokens.PaasToken, opts...)
+}
+
+// NewDynatraceClientOptions creates the options of the Dynatrace client for the proxy, the certificates and the network zone of the properties.
+func NewDynatraceClientOptions(properties DynatraceClientProperties) ([]dtclient.Option, error) {
+	namespace := properties.Namespace
+	apiReader := properties.ApiReader
+
 	opts := newOptions()
 	opts.appendCertCheck(properties.SkipCertCheck)
 	opts.appendNetworkZone(properties.NetworkZone)
 	opts.appendDisableHostsRequests(properties.DisableHostRequests)
 
-	err = opts.appendProxySettings(apiReader, properties.Proxy, namespace)
+	err := opts.appendProxySettings(apiReader, properties.Proxy, namespace)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -78,7 +89,7 @@ func BuildDynatraceClient(properties DynatraceClientProperties) (dtclient.Client
 		return nil, errors.WithStack(err)
 	}
 
-	return dtclient.NewClient(properties.ApiUrl, tokens.ApiToken, tokens.PaasToken, opts.Opts...)
+	return opts.Opts, nil
 }
 
 func newOptions() *options {
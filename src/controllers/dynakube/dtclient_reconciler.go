@@ -155,6 +155,12 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 			Scopes:    []string{dtclient.TokenScopeMetricsIngest},
 			Timestamp: &r.status.LastDataIngestTokenProbeTimestamp,
 		}
+		// the injected OpenTelemetry SDKs export traces and logs with the data ingest token too
+		if instance.FeatureOtelInjection() && !instance.FeatureDisableMetadataEnrichment() {
+			tokens[dtclient.DynatraceDataIngestToken].Scopes = append(tokens[dtclient.DynatraceDataIngestToken].Scopes,
+				dtclient.TokenScopeTracesIngest,
+				dtclient.TokenScopeLogsIngest)
+		}
 	}
 
 	for _, token := range tokens {
@@ -300,6 +300,30 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		AssertCondition(t, dk, dynatracev1beta1.DataIngestTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing, "Token on secret dynatrace:dynakube missing scopes [metrics.ingest]")
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
+	t.Run("Data ingest token has missing scopes for otel injection", func(t *testing.T) {
+		dk := base.DeepCopy()
+		dk.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureOtelInjection: "true"}
+		c := fake.NewClient(NewSecret(dynaKube, namespace, map[string]string{dtclient.DynatraceApiToken: "84", dtclient.DynatraceDataIngestToken: "69"}))
+
+		dtcMock := &dtclient.MockDynatraceClient{}
+		dtcMock.On("GetTokenScopes", "84").Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport,
+			dtclient.TokenScopeInstallerDownload,
+		}, nil)
+		dtcMock.On("GetTokenScopes", "69").Return(dtclient.TokenScopes{dtclient.TokenScopeMetricsIngest, dtclient.TokenScopeLogsIngest}, nil)
+
+		rec := &DynatraceClientReconciler{
+			Client:              c,
+			DynatraceClientFunc: StaticDynatraceClient(dtcMock),
+			Now:                 metav1.Now(),
+		}
+
+		_, _, err := rec.Reconcile(context.TODO(), dk)
+		assert.NoError(t, err)
+		assert.False(t, rec.ValidTokens)
+
+		AssertCondition(t, dk, dynatracev1beta1.DataIngestTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing, "Token on secret dynatrace:dynakube missing scopes [openTelemetryTrace.ingest]")
+		mock.AssertExpectationsForObjects(t, dtcMock)
+	})
 }
 
 func TestReconcileDynatraceClient_ProbeRequests(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
@@ -16,8 +17,10 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/istio"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/processmoduleconfigmap"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/version"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/workloadrestart"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
 	"github.com/Dynatrace/dynatrace-operator/src/initgeneration"
@@ -30,13 +33,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-const shortUpdateInterval = 30 * time.Second
+const (
+	shortUpdateInterval = 30 * time.Second
+
+	// uninjectedProbeThreshold is the minimum time between listing the pods of the matched namespaces for the injection status
+	uninjectedProbeThreshold = 5 * time.Minute
+)
 
 func Add(mgr manager.Manager, _ string) error {
 	return NewController(mgr).SetupWithManager(mgr)
@@ -44,14 +56,15 @@ func Add(mgr manager.Manager, _ string) error {
 
 // NewController returns a new ReconcileDynaKube
 func NewController(mgr manager.Manager) *DynakubeController {
-	return NewDynaKubeController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), BuildDynatraceClient, mgr.GetConfig())
+	return NewDynaKubeController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), mgr.GetEventRecorderFor("DynakubeController"), BuildDynatraceClient, mgr.GetConfig())
 }
 
-func NewDynaKubeController(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, dtcBuildFunc DynatraceClientFunc, config *rest.Config) *DynakubeController {
+func NewDynaKubeController(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dtcBuildFunc DynatraceClientFunc, config *rest.Config) *DynakubeController {
 	return &DynakubeController{
 		client:            c,
 		apiReader:         apiReader,
 		scheme:            scheme,
+		recorder:          recorder,
 		fs:                afero.Afero{Fs: afero.NewOsFs()},
 		dtcBuildFunc:      dtcBuildFunc,
 		config:            config,
@@ -65,6 +78,8 @@ func (controller *DynakubeController) SetupWithManager(mgr ctrl.Manager) error {
 		For(&dynatracev1beta1.DynaKube{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.DaemonSet{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(controller.mapNamespaceToDynakubes),
+			builder.WithPredicates(namespaceMappingPredicate())).
 		Complete(controller)
 }
 
@@ -75,6 +90,7 @@ type DynakubeController struct {
 	client            client.Client
 	apiReader         client.Reader
 	scheme            *runtime.Scheme
+	recorder          record.EventRecorder
 	fs                afero.Afero
 	dtcBuildFunc      DynatraceClientFunc
 	config            *rest.Config
@@ -266,6 +282,12 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		controller.removeOneAgentDaemonSet(dkState)
 	}
 
+	err = processmoduleconfigmap.NewReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance, dtc).Reconcile(ctx)
+	if dkState.Error(err) {
+		log.Error(err, "could not reconcile process module config snapshot")
+		return
+	}
+
 	endpointSecretGenerator := dtingestendpoint.NewEndpointSecretGenerator(controller.client, controller.apiReader, dkState.Instance.Namespace)
 	if dkState.Instance.NeedAppInjection() {
 		if err = dkMapper.MapFromDynakube(); err != nil {
@@ -282,6 +304,16 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 			return
 		}
 
+		pendingRestarts, err := workloadrestart.NewReconciler(controller.client, controller.apiReader, controller.recorder, dkState.Instance).Reconcile(ctx)
+		if err != nil {
+			log.Error(err, "could not restart workloads with outdated injection")
+		}
+		if pendingRestarts {
+			dkState.RequeueAfter = shortUpdateInterval
+		}
+
+		controller.reconcileInjectionStatus(dkState, dkMapper)
+
 		if dkState.Instance.ApplicationMonitoringMode() {
 			dkState.Instance.Status.SetPhase(dynatracev1beta1.Running)
 			dkState.Update(upd, "application monitoring reconciled")
@@ -295,12 +327,47 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		if dkState.Error(err) {
 			return
 		}
+		if !reflect.DeepEqual(dkState.Instance.Status.Injection, dynatracev1beta1.InjectionStatus{}) {
+			dkState.Instance.Status.Injection = dynatracev1beta1.InjectionStatus{}
+			dkState.Update(true, "injection status cleared")
+		}
 	}
 
 	upd = controller.determineDynaKubePhase(dkState.Instance)
 	dkState.Update(upd, "dynakube phase changed")
 }
 
+// reconcileInjectionStatus reports the namespaces covered by the dynakube in its status,
+// the pods are only listed for the uninjected namespaces, if the last check is outdated
+func (controller *DynakubeController) reconcileInjectionStatus(dkState *status.DynakubeState, dkMapper *mapper.DynakubeMapper) {
+	previousStatus := dkState.Instance.Status.Injection
+	checkPods := dkState.IsOutdated(previousStatus.LastUninjectedProbeTimestamp, uninjectedProbeThreshold)
+
+	var mapping mapper.NamespaceMapping
+	var err error
+	if checkPods {
+		mapping, err = dkMapper.GetNamespaceMapping()
+	} else {
+		mapping, err = dkMapper.GetNamespaceMappingWithoutPods()
+	}
+	if err != nil {
+		log.Error(err, "could not determine the namespaces of the dynakube")
+		return
+	}
+	injectionStatus := mapping.Status()
+	if checkPods {
+		injectionStatus.LastUninjectedProbeTimestamp = dkState.Now.DeepCopy()
+	} else {
+		injectionStatus.UninjectedNamespaces = previousStatus.UninjectedNamespaces
+		injectionStatus.LastUninjectedProbeTimestamp = previousStatus.LastUninjectedProbeTimestamp
+	}
+	if reflect.DeepEqual(dkState.Instance.Status.Injection, injectionStatus) {
+		return
+	}
+	dkState.Instance.Status.Injection = injectionStatus
+	dkState.Update(true, "injection status changed")
+}
+
 func updatePhaseIfChanged(instance *dynatracev1beta1.DynaKube, newPhase dynatracev1beta1.DynaKubePhaseType) bool {
 	if instance.Status.Phase == newPhase {
 		return false
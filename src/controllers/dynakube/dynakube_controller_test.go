@@ -8,10 +8,12 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	rcap "github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/Dynatrace/dynatrace-operator/src/version"
@@ -84,6 +86,11 @@ func TestMonitoringModesDynakube_Reconcile(t *testing.T) {
 			err = controller.client.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: testName}, instance)
 			require.NoError(t, err)
 			assert.Equal(t, dynatracev1beta1.Running, instance.Status.Phase)
+			if instance.NeedAppInjection() {
+				assert.Equal(t, []string{kubesystem.Namespace}, instance.Status.Injection.IgnoredNamespaces.Names)
+			} else {
+				assert.Empty(t, instance.Status.Injection.IgnoredNamespaces.Names)
+			}
 		})
 	}
 }
@@ -816,6 +823,47 @@ func TestGetDynakube(t *testing.T) {
 	})
 }
 
+func TestReconcileInjectionStatus(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespace,
+			Labels: map[string]string{dtwebhook.InjectionInstanceLabel: testName},
+		},
+	}
+	uninjectedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: testNamespace}}
+
+	t.Run("pods are listed if the last check is outdated", func(t *testing.T) {
+		fakeClient := fake.NewClient(namespace, uninjectedPod)
+		controller := &DynakubeController{client: fakeClient, apiReader: fakeClient}
+		dynakube := &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace}}
+		dkState := status.NewDynakubeState(dynakube)
+
+		dkMapper := mapper.NewDynakubeMapper(context.TODO(), fakeClient, fakeClient, testNamespace, dynakube)
+
+		controller.reconcileInjectionStatus(dkState, &dkMapper)
+
+		assert.Equal(t, []string{testNamespace}, dynakube.Status.Injection.MatchedNamespaces.Names)
+		assert.Equal(t, []string{testNamespace}, dynakube.Status.Injection.UninjectedNamespaces.Names)
+		assert.Equal(t, dkState.Now.Time, dynakube.Status.Injection.LastUninjectedProbeTimestamp.Time)
+	})
+	t.Run("uninjected namespaces are kept until the next check", func(t *testing.T) {
+		fakeClient := fake.NewClient(namespace, uninjectedPod)
+		controller := &DynakubeController{client: fakeClient, apiReader: fakeClient}
+		dynakube := &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace}}
+		lastProbe := metav1.Now()
+		dynakube.Status.Injection.LastUninjectedProbeTimestamp = &lastProbe
+		dkState := status.NewDynakubeState(dynakube)
+
+		dkMapper := mapper.NewDynakubeMapper(context.TODO(), fakeClient, fakeClient, testNamespace, dynakube)
+
+		controller.reconcileInjectionStatus(dkState, &dkMapper)
+
+		assert.Equal(t, []string{testNamespace}, dynakube.Status.Injection.MatchedNamespaces.Names)
+		assert.Empty(t, dynakube.Status.Injection.UninjectedNamespaces.Names)
+		assert.Equal(t, &lastProbe, dynakube.Status.Injection.LastUninjectedProbeTimestamp)
+	})
+}
+
 func TestReconcileIstio(t *testing.T) {
 	fakeClient := fake.NewClient()
 	dynakube := &dynatracev1beta1.DynaKube{}
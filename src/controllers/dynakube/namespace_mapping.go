@@ -0,0 +1,55 @@
+package dynakube
+
+import (
+	"reflect"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// namespaceMappingPredicate only passes namespaces whose mapping to dynakubes changed, so a namespace mapped by the webhook
+// reconciles its dynakubes right away, which restart the workloads with a missing injection
+func namespaceMappingPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return len(getMappedDynakubeNames(createEvent.Object)) > 0
+		},
+		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+			return !reflect.DeepEqual(getMappedDynakubeNames(updateEvent.ObjectOld), getMappedDynakubeNames(updateEvent.ObjectNew))
+		},
+		DeleteFunc: func(_ event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(_ event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// mapNamespaceToDynakubes enqueues the dynakubes the namespace is labeled for or routed to
+func (controller *DynakubeController) mapNamespaceToDynakubes(object client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, name := range getMappedDynakubeNames(object) {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: controller.operatorNamespace},
+		})
+	}
+	return requests
+}
+
+func getMappedDynakubeNames(object client.Object) []string {
+	namespace, ok := object.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+	var names []string
+	if name := namespace.Labels[dtwebhook.InjectionInstanceLabel]; name != "" {
+		names = append(names, name)
+	}
+	return append(names, dtwebhook.RoutedDynakubeNames(*namespace)...)
+}
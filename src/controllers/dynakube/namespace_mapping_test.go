@@ -0,0 +1,61 @@
+package dynakube
+
+import (
+	"testing"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNamespaceMappingPredicate(t *testing.T) {
+	mappingPredicate := namespaceMappingPredicate()
+	unmapped := createMappingTestNamespace(nil, nil)
+	mapped := createMappingTestNamespace(map[string]string{dtwebhook.InjectionInstanceLabel: testName}, nil)
+
+	t.Run("created namespace is passed if it's mapped", func(t *testing.T) {
+		assert.True(t, mappingPredicate.Create(event.CreateEvent{Object: mapped}))
+		assert.False(t, mappingPredicate.Create(event.CreateEvent{Object: unmapped}))
+	})
+	t.Run("updated namespace is passed if its mapping changed", func(t *testing.T) {
+		routed := createMappingTestNamespace(map[string]string{dtwebhook.InjectionInstanceLabel: testName},
+			map[string]string{dtwebhook.InjectionRoutedInstancesAnnotation: "other"})
+		relabeled := mapped.DeepCopy()
+		relabeled.Labels["other"] = "value"
+
+		assert.True(t, mappingPredicate.Update(event.UpdateEvent{ObjectOld: unmapped, ObjectNew: mapped}))
+		assert.True(t, mappingPredicate.Update(event.UpdateEvent{ObjectOld: mapped, ObjectNew: routed}))
+		assert.False(t, mappingPredicate.Update(event.UpdateEvent{ObjectOld: mapped, ObjectNew: relabeled}))
+	})
+	t.Run("deleted namespace isn't passed", func(t *testing.T) {
+		assert.False(t, mappingPredicate.Delete(event.DeleteEvent{Object: mapped}))
+	})
+}
+
+func TestMapNamespaceToDynakubes(t *testing.T) {
+	controller := &DynakubeController{operatorNamespace: testNamespace}
+	namespace := createMappingTestNamespace(map[string]string{dtwebhook.InjectionInstanceLabel: testName},
+		map[string]string{dtwebhook.InjectionRoutedInstancesAnnotation: "routed"})
+
+	requests := controller.mapNamespaceToDynakubes(namespace)
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: testName, Namespace: testNamespace}},
+		{NamespacedName: types.NamespacedName{Name: "routed", Namespace: testNamespace}},
+	}, requests)
+	assert.Empty(t, controller.mapNamespaceToDynakubes(createMappingTestNamespace(nil, nil)))
+}
+
+func createMappingTestNamespace(labels map[string]string, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+}
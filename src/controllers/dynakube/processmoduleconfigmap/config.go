@@ -0,0 +1,14 @@
+package processmoduleconfigmap
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+const (
+	ConfigMapSuffix = "-process-module-config"
+	ConfigMapKey    = "processModuleConfig"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("dynakube-processmoduleconfig")
+)
@@ -0,0 +1,141 @@
+package processmoduleconfigmap
+
+import (
+	"context"
+	"encoding/json"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Reconciler keeps a snapshot of the process module config in a ConfigMap,
+// so the init secrets for offline installations can be generated without reaching the tenant
+type Reconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+	dynakube  *dynatracev1beta1.DynaKube
+	dtc       dtclient.Client
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dynakube *dynatracev1beta1.DynaKube, dtc dtclient.Client) *Reconciler {
+	return &Reconciler{
+		client:    clt,
+		apiReader: apiReader,
+		scheme:    scheme,
+		dynakube:  dynakube,
+		dtc:       dtc,
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	if !r.dynakube.FeatureOneAgentOfflineInstall() {
+		return r.deleteConfigMap(ctx)
+	}
+
+	var configMap corev1.ConfigMap
+	err := r.apiReader.Get(ctx, client.ObjectKey{Name: ConfigMapName(r.dynakube.Name), Namespace: r.dynakube.Namespace}, &configMap)
+	if k8serrors.IsNotFound(err) {
+		return r.createConfigMap(ctx)
+	} else if err != nil {
+		return errors.WithStack(err)
+	}
+	return r.updateConfigMapIfOutdated(ctx, &configMap)
+}
+
+func (r *Reconciler) createConfigMap(ctx context.Context) error {
+	processModuleConfig, err := r.dtc.GetProcessModuleConfig(0)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get the process module config")
+	}
+	data, err := json.Marshal(processModuleConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapName(r.dynakube.Name),
+			Namespace: r.dynakube.Namespace,
+		},
+		Data: map[string]string{ConfigMapKey: string(data)},
+	}
+	if err := controllerutil.SetControllerReference(r.dynakube, configMap, r.scheme); err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Info("creating process module config snapshot", "name", configMap.Name, "revision", processModuleConfig.Revision)
+	return errors.WithStack(r.client.Create(ctx, configMap))
+}
+
+// updateConfigMapIfOutdated only requests changes since the revision of the snapshot,
+// the snapshot is kept if the tenant isn't reachable
+func (r *Reconciler) updateConfigMapIfOutdated(ctx context.Context, configMap *corev1.ConfigMap) error {
+	storedProcessModuleConfig, err := parseProcessModuleConfig(configMap)
+	if err != nil {
+		log.Info("invalid process module config snapshot, replacing it", "error", err.Error())
+		storedProcessModuleConfig = &dtclient.ProcessModuleConfig{}
+	}
+
+	latestProcessModuleConfig, err := r.dtc.GetProcessModuleConfig(storedProcessModuleConfig.Revision)
+	if err != nil {
+		log.Info("failed to get the process module config, keeping the snapshot", "revision", storedProcessModuleConfig.Revision, "error", err.Error())
+		return nil
+	}
+	if latestProcessModuleConfig == nil || latestProcessModuleConfig.IsEmpty() {
+		return nil
+	}
+
+	data, err := json.Marshal(latestProcessModuleConfig)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	configMap.Data = map[string]string{ConfigMapKey: string(data)}
+
+	log.Info("updating process module config snapshot", "name", configMap.Name, "revision", latestProcessModuleConfig.Revision)
+	return errors.WithStack(r.client.Update(ctx, configMap))
+}
+
+func (r *Reconciler) deleteConfigMap(ctx context.Context) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapName(r.dynakube.Name),
+			Namespace: r.dynakube.Namespace,
+		},
+	}
+	if err := r.client.Delete(ctx, configMap); err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// GetProcessModuleConfig returns the process module config snapshot of the dynakube, nil is returned if there is no snapshot yet
+func GetProcessModuleConfig(ctx context.Context, apiReader client.Reader, dynakube *dynatracev1beta1.DynaKube) (*dtclient.ProcessModuleConfig, error) {
+	var configMap corev1.ConfigMap
+	err := apiReader.Get(ctx, client.ObjectKey{Name: ConfigMapName(dynakube.Name), Namespace: dynakube.Namespace}, &configMap)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseProcessModuleConfig(&configMap)
+}
+
+func parseProcessModuleConfig(configMap *corev1.ConfigMap) (*dtclient.ProcessModuleConfig, error) {
+	var processModuleConfig dtclient.ProcessModuleConfig
+	if err := json.Unmarshal([]byte(configMap.Data[ConfigMapKey]), &processModuleConfig); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &processModuleConfig, nil
+}
+
+func ConfigMapName(dynakubeName string) string {
+	return dynakubeName + ConfigMapSuffix
+}
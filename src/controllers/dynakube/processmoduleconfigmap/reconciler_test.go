@@ -0,0 +1,144 @@
+package processmoduleconfigmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testName      = "dynakube"
+	testNamespace = "dynatrace"
+)
+
+var (
+	testProcessModuleConfig = dtclient.ProcessModuleConfig{
+		Revision:   1,
+		Properties: []dtclient.ProcessModuleProperty{{Section: "general", Key: "tenant", Value: "abc"}},
+	}
+	testUpdatedProcessModuleConfig = dtclient.ProcessModuleConfig{
+		Revision:   2,
+		Properties: []dtclient.ProcessModuleProperty{{Section: "general", Key: "tenant", Value: "def"}},
+	}
+)
+
+func TestReconcile(t *testing.T) {
+	t.Run(`create snapshot`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		clt := fake.NewClient()
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetProcessModuleConfig", uint(0)).Return(&testProcessModuleConfig, nil)
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, dtc).Reconcile(context.TODO())
+		require.NoError(t, err)
+
+		processModuleConfig, err := GetProcessModuleConfig(context.TODO(), clt, dynakube)
+		require.NoError(t, err)
+		assert.Equal(t, &testProcessModuleConfig, processModuleConfig)
+	})
+	t.Run(`update snapshot`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		clt := fake.NewClient(createTestConfigMap(t, testProcessModuleConfig))
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetProcessModuleConfig", uint(1)).Return(&testUpdatedProcessModuleConfig, nil)
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, dtc).Reconcile(context.TODO())
+		require.NoError(t, err)
+
+		processModuleConfig, err := GetProcessModuleConfig(context.TODO(), clt, dynakube)
+		require.NoError(t, err)
+		assert.Equal(t, &testUpdatedProcessModuleConfig, processModuleConfig)
+	})
+	t.Run(`keep snapshot if unchanged`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		clt := fake.NewClient(createTestConfigMap(t, testProcessModuleConfig))
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetProcessModuleConfig", uint(1)).Return(&dtclient.ProcessModuleConfig{}, nil)
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, dtc).Reconcile(context.TODO())
+		require.NoError(t, err)
+
+		processModuleConfig, err := GetProcessModuleConfig(context.TODO(), clt, dynakube)
+		require.NoError(t, err)
+		assert.Equal(t, &testProcessModuleConfig, processModuleConfig)
+	})
+	t.Run(`keep snapshot if tenant isn't reachable`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		clt := fake.NewClient(createTestConfigMap(t, testProcessModuleConfig))
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetProcessModuleConfig", uint(1)).Return(&dtclient.ProcessModuleConfig{}, fmt.Errorf("BOOM"))
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, dtc).Reconcile(context.TODO())
+		require.NoError(t, err)
+
+		processModuleConfig, err := GetProcessModuleConfig(context.TODO(), clt, dynakube)
+		require.NoError(t, err)
+		assert.Equal(t, &testProcessModuleConfig, processModuleConfig)
+	})
+	t.Run(`fail without snapshot if tenant isn't reachable`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		clt := fake.NewClient()
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetProcessModuleConfig", uint(0)).Return(&dtclient.ProcessModuleConfig{}, fmt.Errorf("BOOM"))
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, dtc).Reconcile(context.TODO())
+		require.Error(t, err)
+	})
+	t.Run(`delete snapshot if offline install is disabled`, func(t *testing.T) {
+		dynakube := createTestOfflineDynakube()
+		dynakube.Annotations = nil
+		clt := fake.NewClient(createTestConfigMap(t, testProcessModuleConfig))
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dynakube, &dtclient.MockDynatraceClient{}).Reconcile(context.TODO())
+		require.NoError(t, err)
+
+		var configMap corev1.ConfigMap
+		err = clt.Get(context.TODO(), client.ObjectKey{Name: ConfigMapName(testName), Namespace: testNamespace}, &configMap)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+}
+
+func TestGetProcessModuleConfig(t *testing.T) {
+	t.Run(`no snapshot`, func(t *testing.T) {
+		processModuleConfig, err := GetProcessModuleConfig(context.TODO(), fake.NewClient(), createTestOfflineDynakube())
+
+		require.NoError(t, err)
+		assert.Nil(t, processModuleConfig)
+	})
+}
+
+func createTestOfflineDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testName,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC: "oneagent-package"},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+			},
+		},
+	}
+}
+
+func createTestConfigMap(t *testing.T, processModuleConfig dtclient.ProcessModuleConfig) *corev1.ConfigMap {
+	data, err := json.Marshal(processModuleConfig)
+	require.NoError(t, err)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName(testName), Namespace: testNamespace},
+		Data:       map[string]string{ConfigMapKey: string(data)},
+	}
+}
@@ -0,0 +1,28 @@
+package workloadrestart
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+const (
+	// AnnotationRestartedAt is set by the operator in the pod template of a workload to restart it,
+	// like "kubectl rollout restart" does.
+	AnnotationRestartedAt = "dynatrace.com/restartedAt"
+
+	// AnnotationAutomaticRestart can be set to "false" on a Deployment, StatefulSet or DaemonSet (or in its pod template)
+	// to opt out of the automatic restarts.
+	AnnotationAutomaticRestart = "dynatrace.com/automatic-restart"
+	// AnnotationRestartAttempts is set by the operator on the workload to count its restarts for the code modules revision
+	// in AnnotationRestartRevision, pods that the webhook never sees would be restarted forever otherwise.
+	AnnotationRestartAttempts = "dynatrace.com/restart-attempts"
+	AnnotationRestartRevision = "dynatrace.com/restart-revision"
+
+	// maxRestartAttempts is the number of restarts per code modules revision, after which a workload is only reported by an event
+	maxRestartAttempts = 3
+
+	ReasonRestartAttemptsExceeded = "RestartAttemptsExceeded"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("workload-restart")
+)
@@ -0,0 +1,34 @@
+package workloadrestart
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// needsRestart checks if the pod was created before its namespace was mapped to the dynakube (missing injection)
+// or if it was injected with other code modules than the dynakube currently provides (outdated injection).
+func needsRestart(pod corev1.Pod, dynakube dynatracev1beta1.DynaKube, podSelector labels.Selector) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if podSelector != nil && !podSelector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if pod.Annotations[dtwebhook.AnnotationDynatraceInjected] != "true" {
+		return dtwebhook.IsInjectionMissing(pod)
+	}
+	return isOutdatedInjection(pod, dynakube)
+}
+
+// isOutdatedInjection compares the injected code modules with the current ones,
+// pods that were injected before the revision was recorded are left alone
+func isOutdatedInjection(pod corev1.Pod, dynakube dynatracev1beta1.DynaKube) bool {
+	if pod.Annotations[dtwebhook.AnnotationOneAgentInjected] != "true" {
+		return false
+	}
+	injectedRevision := pod.Annotations[dtwebhook.AnnotationCodeModulesRevision]
+	currentRevision := dynakube.CodeModulesRevision()
+	return injectedRevision != "" && currentRevision != "" && injectedRevision != currentRevision
+}
@@ -0,0 +1,102 @@
+package workloadrestart
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestNeedsRestart(t *testing.T) {
+	dynakube := *getTestDynakube()
+
+	t.Run(`pod without injection`, func(t *testing.T) {
+		assert.True(t, needsRestart(getTestPod(nil), dynakube, nil))
+	})
+	t.Run(`pod skipped by the webhook`, func(t *testing.T) {
+		pod := getTestPod(map[string]string{dtwebhook.AnnotationOneAgentReason: "disabled-by-annotation"})
+
+		assert.False(t, needsRestart(pod, dynakube, nil))
+	})
+	t.Run(`pod opted out of the injection`, func(t *testing.T) {
+		pod := getTestPod(map[string]string{
+			dtwebhook.AnnotationOneAgentInject:   "false",
+			dtwebhook.AnnotationDataIngestInject: "false",
+		})
+
+		assert.False(t, needsRestart(pod, dynakube, nil))
+	})
+	t.Run(`pod injected with current code modules`, func(t *testing.T) {
+		pod := getTestPod(getInjectedAnnotations(testVersion))
+
+		assert.False(t, needsRestart(pod, dynakube, nil))
+	})
+	t.Run(`pod injected with outdated code modules`, func(t *testing.T) {
+		pod := getTestPod(getInjectedAnnotations("1.0.0"))
+
+		assert.True(t, needsRestart(pod, dynakube, nil))
+	})
+	t.Run(`pod injected without recorded revision`, func(t *testing.T) {
+		pod := getTestPod(getInjectedAnnotations(""))
+
+		assert.False(t, needsRestart(pod, dynakube, nil))
+	})
+	t.Run(`pod not matching the pod selector`, func(t *testing.T) {
+		podSelector, _ := labels.Parse("track=canary")
+
+		assert.False(t, needsRestart(getTestPod(nil), dynakube, podSelector))
+	})
+	t.Run(`terminating or finished pod`, func(t *testing.T) {
+		terminatingPod := getTestPod(nil)
+		terminatingPod.DeletionTimestamp = &metav1.Time{}
+		finishedPod := getTestPod(nil)
+		finishedPod.Status.Phase = corev1.PodSucceeded
+
+		assert.False(t, needsRestart(terminatingPod, dynakube, nil))
+		assert.False(t, needsRestart(finishedPod, dynakube, nil))
+	})
+}
+
+func getInjectedAnnotations(revision string) map[string]string {
+	annotations := map[string]string{
+		dtwebhook.AnnotationDynatraceInjected: "true",
+		dtwebhook.AnnotationOneAgentInjected:  "true",
+	}
+	if revision != "" {
+		annotations[dtwebhook.AnnotationCodeModulesRevision] = revision
+	}
+	return annotations
+}
+
+func getTestPod(annotations map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testPodName,
+			Namespace:   testNamespaceName,
+			Annotations: annotations,
+		},
+	}
+}
+
+func getTestDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDynakubeName,
+			Namespace: "dynatrace",
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationFeatureWorkloadRestart: "true",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{
+					Version: testVersion,
+				},
+			},
+		},
+	}
+}
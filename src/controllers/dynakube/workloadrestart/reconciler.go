@@ -0,0 +1,196 @@
+package workloadrestart
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reconciler restarts the workloads in the namespaces of the dynakube, which own pods with a missing or outdated injection.
+// Restarts are limited by the max concurrent restarts and the restart interval feature flags of the dynakube.
+// It runs on every reconcile of the dynakube, which is also triggered when a namespace gets mapped to the dynakube.
+// A workload is restarted at most maxRestartAttempts times per code modules revision, afterwards an event is emitted instead.
+type Reconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	recorder  record.EventRecorder
+	dynakube  *dynatracev1beta1.DynaKube
+	now       time.Time
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, recorder record.EventRecorder, dynakube *dynatracev1beta1.DynaKube) *Reconciler {
+	return &Reconciler{
+		client:    clt,
+		apiReader: apiReader,
+		recorder:  recorder,
+		dynakube:  dynakube,
+		now:       time.Now().UTC(),
+	}
+}
+
+// Reconcile restarts workloads if the feature is enabled for the dynakube,
+// it returns true if there are restarts left for the next reconcile
+func (r *Reconciler) Reconcile(ctx context.Context) (bool, error) {
+	if !r.dynakube.FeatureWorkloadRestart() || !r.dynakube.NeedAppInjection() {
+		return false, nil
+	}
+
+	podSelector, err := r.dynakube.InjectionPodSelector()
+	if err != nil {
+		return false, err
+	}
+	namespaces, err := mapper.GetNamespacesForDynakube(ctx, r.apiReader, r.dynakube.Name)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	workloads, err := r.findOutdatedWorkloads(ctx, namespaces, podSelector)
+	if err != nil {
+		return false, err
+	}
+
+	inProgress := 0
+	var pending []workload
+	for _, outdated := range workloads {
+		if isOptedOut(outdated.object) {
+			continue
+		}
+		restartedAt, restarted := r.getRestartedAt(outdated.object)
+		if restarted && !isRolledOut(outdated.object) {
+			inProgress++
+			continue
+		}
+		if restarted && r.now.Sub(restartedAt) < r.dynakube.FeatureWorkloadRestartInterval() {
+			continue
+		}
+		if attempts := r.getRestartAttempts(outdated.object); attempts >= maxRestartAttempts {
+			if attempts == maxRestartAttempts {
+				if err := r.giveUp(ctx, outdated); err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
+		pending = append(pending, outdated)
+	}
+
+	maxConcurrent := r.dynakube.FeatureWorkloadRestartMaxConcurrent()
+	for i, outdated := range pending {
+		if inProgress >= maxConcurrent {
+			log.Info("max concurrent workload restarts reached, postponing restarts", "dynakube", r.dynakube.Name, "postponed", len(pending)-i)
+			return true, nil
+		}
+		if err := r.restart(ctx, outdated); err != nil {
+			return false, err
+		}
+		inProgress++
+	}
+	return inProgress > 0, nil
+}
+
+// findOutdatedWorkloads collects the owners of the pods that need a restart, sorted by namespace, kind and name
+func (r *Reconciler) findOutdatedWorkloads(ctx context.Context, namespaces []corev1.Namespace, podSelector labels.Selector) ([]workload, error) {
+	workloads := map[string]workload{}
+	for _, namespace := range namespaces {
+		var podList corev1.PodList
+		if err := r.apiReader.List(ctx, &podList, client.InNamespace(namespace.Name)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, pod := range podList.Items {
+			if !needsRestart(pod, *r.dynakube, podSelector) {
+				continue
+			}
+			owner, err := getOwnerWorkload(ctx, r.apiReader, pod)
+			if err != nil {
+				return nil, err
+			}
+			if owner != nil {
+				workloads[owner.key()] = *owner
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sortedWorkloads := make([]workload, 0, len(keys))
+	for _, key := range keys {
+		sortedWorkloads = append(sortedWorkloads, workloads[key])
+	}
+	return sortedWorkloads, nil
+}
+
+func (r *Reconciler) getRestartedAt(object client.Object) (time.Time, bool) {
+	template := getPodTemplate(object)
+	if template == nil {
+		return time.Time{}, false
+	}
+	restartedAt, err := time.Parse(time.RFC3339, template.Annotations[AnnotationRestartedAt])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return restartedAt, true
+}
+
+// getRestartAttempts returns the number of restarts of the workload for the current code modules revision
+func (r *Reconciler) getRestartAttempts(object client.Object) int {
+	annotations := object.GetAnnotations()
+	if annotations[AnnotationRestartRevision] != r.dynakube.CodeModulesRevision() {
+		return 0
+	}
+	attempts, err := strconv.Atoi(annotations[AnnotationRestartAttempts])
+	if err != nil {
+		return 0
+	}
+	return attempts
+}
+
+func (r *Reconciler) restart(ctx context.Context, outdated workload) error {
+	patch := client.MergeFrom(outdated.object.DeepCopyObject().(client.Object))
+	template := getPodTemplate(outdated.object)
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[AnnotationRestartedAt] = r.now.Format(time.RFC3339)
+	attempts := r.getRestartAttempts(outdated.object) + 1
+	setRestartAttempts(outdated.object, r.dynakube.CodeModulesRevision(), attempts)
+
+	log.Info("restarting workload to update the injection", "dynakube", r.dynakube.Name, "workload", outdated.key(), "attempt", attempts)
+	return errors.WithStack(r.client.Patch(ctx, outdated.object, patch))
+}
+
+// giveUp reports a workload, whose pods still need a restart after the last attempt, the attempts are increased once more,
+// so the event is only emitted once per code modules revision
+func (r *Reconciler) giveUp(ctx context.Context, outdated workload) error {
+	patch := client.MergeFrom(outdated.object.DeepCopyObject().(client.Object))
+	setRestartAttempts(outdated.object, r.dynakube.CodeModulesRevision(), maxRestartAttempts+1)
+	if err := r.client.Patch(ctx, outdated.object, patch); err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Info("giving up restarting workload, its pods still have a missing or outdated injection", "dynakube", r.dynakube.Name, "workload", outdated.key())
+	r.recorder.Eventf(outdated.object, corev1.EventTypeWarning, ReasonRestartAttemptsExceeded,
+		"pods still have a missing or outdated injection after %d restarts by dynakube %s, check if the webhook is reachable",
+		maxRestartAttempts, r.dynakube.Name)
+	return nil
+}
+
+func setRestartAttempts(object client.Object, revision string, attempts int) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationRestartRevision] = revision
+	annotations[AnnotationRestartAttempts] = strconv.Itoa(attempts)
+	object.SetAnnotations(annotations)
+}
@@ -0,0 +1,198 @@
+package workloadrestart
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testDynakubeName  = "test-dynakube"
+	testNamespaceName = "test-namespace"
+	testPodName       = "test-pod"
+	testVersion       = "1.2.3"
+)
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(`disabled by default`, func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations = nil
+		objects := append(getTestDeploymentObjects("app", nil), getTestNamespace())
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), dynakube).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Empty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+	})
+	t.Run(`restart deployment, statefulset and daemonset`, func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations[dynatracev1beta1.AnnotationFeatureWorkloadRestartMaxConcurrent] = "3"
+		objects := []client.Object{getTestNamespace()}
+		objects = append(objects, getTestDeploymentObjects("app", nil)...)
+		objects = append(objects, getTestOwnedObjects(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespaceName}}, kindStatefulSet, nil)...)
+		objects = append(objects, getTestOwnedObjects(&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: testNamespaceName}}, kindDaemonSet, nil)...)
+		clt := fake.NewClient(objects...)
+		reconciler := NewReconciler(clt, clt, record.NewFakeRecorder(10), dynakube)
+
+		pending, err := reconciler.Reconcile(ctx)
+		require.NoError(t, err)
+		assert.True(t, pending)
+		restartedAt := reconciler.now.Format(time.RFC3339)
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.StatefulSet{}, "db"))
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.DaemonSet{}, "agent"))
+	})
+	t.Run(`respect max concurrent restarts`, func(t *testing.T) {
+		objects := []client.Object{getTestNamespace()}
+		objects = append(objects, getTestDeploymentObjects("app-a", nil)...)
+		objects = append(objects, getTestDeploymentObjects("app-b", nil)...)
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.True(t, pending)
+		assert.NotEmpty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app-a"))
+		assert.Empty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app-b"))
+	})
+	t.Run(`restart in progress counts as concurrent restart`, func(t *testing.T) {
+		now := time.Now().UTC()
+		objects := []client.Object{getTestNamespace()}
+		objects = append(objects, getTestDeploymentObjects("app-a", map[string]string{AnnotationRestartedAt: now.Format(time.RFC3339)})...)
+		objects = append(objects, getTestDeploymentObjects("app-b", nil)...)
+		objects[2].(*appsv1.Deployment).Status.UpdatedReplicas = 0
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.True(t, pending)
+		assert.Empty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app-b"))
+	})
+	t.Run(`rate limit restarts of the same workload`, func(t *testing.T) {
+		restartedAt := time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+		objects := append(getTestDeploymentObjects("app", map[string]string{AnnotationRestartedAt: restartedAt}), getTestNamespace())
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+	})
+	t.Run(`count restart attempts per code modules revision`, func(t *testing.T) {
+		objects := append(getTestDeploymentObjects("app", nil), getTestNamespace())
+		objects[1].SetAnnotations(map[string]string{AnnotationRestartRevision: "1.0.0", AnnotationRestartAttempts: "3"})
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, pending)
+		var deployment appsv1.Deployment
+		require.NoError(t, clt.Get(ctx, client.ObjectKey{Name: "app", Namespace: testNamespaceName}, &deployment))
+		assert.Equal(t, testVersion, deployment.Annotations[AnnotationRestartRevision])
+		assert.Equal(t, "1", deployment.Annotations[AnnotationRestartAttempts])
+	})
+	t.Run(`emit event instead of restarting after the max attempts`, func(t *testing.T) {
+		restartedAt := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+		objects := append(getTestDeploymentObjects("app", map[string]string{AnnotationRestartedAt: restartedAt}), getTestNamespace())
+		objects[1].SetAnnotations(map[string]string{AnnotationRestartRevision: testVersion, AnnotationRestartAttempts: "3"})
+		clt := fake.NewClient(objects...)
+		recorder := record.NewFakeRecorder(10)
+
+		pending, err := NewReconciler(clt, clt, recorder, getTestDynakube()).Reconcile(ctx)
+
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+		require.Len(t, recorder.Events, 1)
+		assert.Contains(t, <-recorder.Events, ReasonRestartAttemptsExceeded)
+
+		pending, err = NewReconciler(clt, clt, recorder, getTestDynakube()).Reconcile(ctx)
+
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Equal(t, restartedAt, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+		assert.Empty(t, recorder.Events)
+	})
+	t.Run(`skip opted out workloads`, func(t *testing.T) {
+		objects := append(getTestDeploymentObjects("app", map[string]string{AnnotationAutomaticRestart: "false"}), getTestNamespace())
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Empty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+	})
+	t.Run(`ignore namespaces of other dynakubes`, func(t *testing.T) {
+		namespace := getTestNamespace()
+		namespace.Labels[dtwebhook.InjectionInstanceLabel] = "other"
+		objects := append(getTestDeploymentObjects("app", nil), namespace)
+		clt := fake.NewClient(objects...)
+
+		pending, err := NewReconciler(clt, clt, record.NewFakeRecorder(10), getTestDynakube()).Reconcile(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+		assert.Empty(t, getRestartedAt(t, clt, &appsv1.Deployment{}, "app"))
+	})
+}
+
+func getRestartedAt(t *testing.T, clt client.Client, object client.Object, name string) string {
+	require.NoError(t, clt.Get(context.Background(), client.ObjectKey{Name: name, Namespace: testNamespaceName}, object))
+	return getPodTemplate(object).Annotations[AnnotationRestartedAt]
+}
+
+// getTestDeploymentObjects creates a rolled out deployment with a replicaset and a pod without injection
+func getTestDeploymentObjects(name string, templateAnnotations map[string]string) []client.Object {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceName},
+		Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1},
+	}
+	deployment.Spec.Template.Annotations = templateAnnotations
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-rs",
+			Namespace:       testNamespaceName,
+			OwnerReferences: []metav1.OwnerReference{getControllerReference(kindDeployment, name)},
+		},
+	}
+	return []client.Object{replicaSet, deployment, getTestOwnedPod(kindReplicaSet, replicaSet.Name)}
+}
+
+func getTestOwnedObjects(object client.Object, kind string, templateAnnotations map[string]string) []client.Object {
+	getPodTemplate(object).Annotations = templateAnnotations
+	return []client.Object{object, getTestOwnedPod(kind, object.GetName())}
+}
+
+func getTestOwnedPod(kind string, name string) *corev1.Pod {
+	pod := getTestPod(nil)
+	pod.Name = name + "-pod"
+	pod.OwnerReferences = []metav1.OwnerReference{getControllerReference(kind, name)}
+	return &pod
+}
+
+func getControllerReference(kind string, name string) metav1.OwnerReference {
+	isController := true
+	return metav1.OwnerReference{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &isController}
+}
+
+func getTestNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespaceName,
+			Labels: map[string]string{dtwebhook.InjectionInstanceLabel: testDynakubeName},
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package workloadrestart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	kindReplicaSet  = "ReplicaSet"
+	kindDeployment  = "Deployment"
+	kindStatefulSet = "StatefulSet"
+	kindDaemonSet   = "DaemonSet"
+)
+
+type workload struct {
+	kind   string
+	object client.Object
+}
+
+func (w workload) key() string {
+	return fmt.Sprintf("%s/%s/%s", w.object.GetNamespace(), w.kind, w.object.GetName())
+}
+
+// getOwnerWorkload finds the Deployment, StatefulSet or DaemonSet that controls the pod,
+// nil is returned for pods that are not controlled by one of them, e.g. Jobs.
+func getOwnerWorkload(ctx context.Context, apiReader client.Reader, pod corev1.Pod) (*workload, error) {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return nil, nil
+	}
+
+	var object client.Object
+	kind := owner.Kind
+	switch kind {
+	case kindReplicaSet:
+		var replicaSet appsv1.ReplicaSet
+		if err := apiReader.Get(ctx, client.ObjectKey{Name: owner.Name, Namespace: pod.Namespace}, &replicaSet); err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		deploymentOwner := metav1.GetControllerOf(&replicaSet)
+		if deploymentOwner == nil || deploymentOwner.Kind != kindDeployment {
+			return nil, nil
+		}
+		kind = kindDeployment
+		object = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: deploymentOwner.Name, Namespace: pod.Namespace}}
+	case kindStatefulSet:
+		object = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: owner.Name, Namespace: pod.Namespace}}
+	case kindDaemonSet:
+		object = &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: owner.Name, Namespace: pod.Namespace}}
+	default:
+		return nil, nil
+	}
+
+	if err := apiReader.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &workload{kind: kind, object: object}, nil
+}
+
+func ignoreNotFound(err error) error {
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return errors.WithStack(err)
+}
+
+func getPodTemplate(object client.Object) *corev1.PodTemplateSpec {
+	switch typedObject := object.(type) {
+	case *appsv1.Deployment:
+		return &typedObject.Spec.Template
+	case *appsv1.StatefulSet:
+		return &typedObject.Spec.Template
+	case *appsv1.DaemonSet:
+		return &typedObject.Spec.Template
+	}
+	return nil
+}
+
+// isRolledOut checks if the controller of the workload has finished updating its pods
+func isRolledOut(object client.Object) bool {
+	switch typedObject := object.(type) {
+	case *appsv1.Deployment:
+		replicas := int32(1)
+		if typedObject.Spec.Replicas != nil {
+			replicas = *typedObject.Spec.Replicas
+		}
+		return typedObject.Status.ObservedGeneration >= typedObject.Generation &&
+			typedObject.Status.UpdatedReplicas >= replicas &&
+			typedObject.Status.Replicas == typedObject.Status.UpdatedReplicas
+	case *appsv1.StatefulSet:
+		return typedObject.Status.ObservedGeneration >= typedObject.Generation &&
+			typedObject.Status.UpdateRevision == typedObject.Status.CurrentRevision
+	case *appsv1.DaemonSet:
+		return typedObject.Status.ObservedGeneration >= typedObject.Generation &&
+			typedObject.Status.UpdatedNumberScheduled >= typedObject.Status.DesiredNumberScheduled &&
+			typedObject.Status.NumberUnavailable == 0
+	}
+	return true
+}
+
+func isOptedOut(object client.Object) bool {
+	if object.GetAnnotations()[AnnotationAutomaticRestart] == "false" {
+		return true
+	}
+	template := getPodTemplate(object)
+	return template != nil && template.Annotations[AnnotationAutomaticRestart] == "false"
+}
@@ -46,6 +46,10 @@ type Client interface {
 
 	GetConnectionInfo() (ConnectionInfo, error)
 
+	// GetNetworkZoneConnectionInfo returns the connection info with only the communication hosts of the network zone,
+	// there is no fallback to the default network zone
+	GetNetworkZoneConnectionInfo(networkZone string) (ConnectionInfo, error)
+
 	GetProcessModuleConfig(prevRevision uint) (*ProcessModuleConfig, error)
 
 	// GetCommunicationHostForClient returns a CommunicationHost for the client's API URL. Or error, if failed to be parsed.
@@ -107,6 +111,8 @@ const (
 	TokenScopeInstallerDownload     = "InstallerDownload"
 	TokenScopeDataExport            = "DataExport"
 	TokenScopeMetricsIngest         = "metrics.ingest"
+	TokenScopeTracesIngest          = "openTelemetryTrace.ingest"
+	TokenScopeLogsIngest            = "logs.ingest"
 	TokenScopeEntitiesRead          = "entities.read"
 	TokenScopeSettingsRead          = "settings.read"
 	TokenScopeSettingsWrite         = "settings.write"
@@ -136,10 +142,8 @@ func NewClient(url, apiToken, paasToken string, opts ...Option) (Client, error)
 		apiToken:  apiToken,
 		paasToken: paasToken,
 
-		hostCache: make(map[string]hostInfo),
-		httpClient: &http.Client{
-			Transport: http.DefaultTransport.(*http.Transport).Clone(),
-		},
+		hostCache:  make(map[string]hostInfo),
+		httpClient: newHttpClient(),
 	}
 
 	for _, opt := range opts {
@@ -149,6 +153,27 @@ func NewClient(url, apiToken, paasToken string, opts ...Option) (Client, error)
 	return dc, nil
 }
 
+// NewHttpClient creates the http client, which a Client created with the same opts uses for its requests.
+// The proxy and the certificates of the opts are applied to its transport.
+func NewHttpClient(opts ...Option) *http.Client {
+	dc := &dynatraceClient{
+		hostCache:  make(map[string]hostInfo),
+		httpClient: newHttpClient(),
+	}
+
+	for _, opt := range opts {
+		opt(dc)
+	}
+
+	return dc.httpClient
+}
+
+func newHttpClient() *http.Client {
+	return &http.Client{
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
 // Option can be passed to NewClient and customizes the created client instance.
 type Option func(*dynatraceClient)
 
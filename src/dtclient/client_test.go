@@ -83,3 +83,14 @@ func TestCerts(t *testing.T) {
 	certs(&dtc)
 	assert.NotNil(t, transport.TLSClientConfig.RootCAs)
 }
+
+func TestNewHttpClient(t *testing.T) {
+	httpClient := NewHttpClient(Proxy("http://proxy:3128"), SkipCertificateValidation(true), NetworkZone("zone"))
+	transport := httpClient.Transport.(*http.Transport)
+
+	proxyUrl, err := transport.Proxy(&http.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy:3128", proxyUrl.Host)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	assert.NotSame(t, http.DefaultTransport, transport)
+}
@@ -26,7 +26,15 @@ func (dtc *dynatraceClient) GetCommunicationHostForClient() (CommunicationHost,
 }
 
 func (dtc *dynatraceClient) GetConnectionInfo() (ConnectionInfo, error) {
-	resp, err := dtc.makeRequest(dtc.getOneAgentConnectionInfoUrl(), dynatracePaaSToken)
+	return dtc.getConnectionInfo(dtc.getOneAgentConnectionInfoUrl())
+}
+
+func (dtc *dynatraceClient) GetNetworkZoneConnectionInfo(networkZone string) (ConnectionInfo, error) {
+	return dtc.getConnectionInfo(dtc.getOneAgentNetworkZoneConnectionInfoUrl(networkZone))
+}
+
+func (dtc *dynatraceClient) getConnectionInfo(connectionInfoUrl string) (ConnectionInfo, error) {
+	resp, err := dtc.makeRequest(connectionInfoUrl, dynatracePaaSToken)
 	if err != nil {
 		return ConnectionInfo{}, err
 	}
@@ -164,3 +164,19 @@ func handleCommunicationHosts(request *http.Request, writer http.ResponseWriter)
 		writeError(writer, http.StatusMethodNotAllowed)
 	}
 }
+
+func TestGetOneAgentConnectionInfoUrl(t *testing.T) {
+	t.Run(`without network zone`, func(t *testing.T) {
+		dc := &dynatraceClient{url: "https://tenant/api"}
+		assert.Equal(t, "https://tenant/api/v1/deployment/installer/agent/connectioninfo", dc.getOneAgentConnectionInfoUrl())
+	})
+	t.Run(`network zone option is ignored`, func(t *testing.T) {
+		dc := &dynatraceClient{url: "https://tenant/api", networkZone: "zone"}
+		assert.Equal(t, "https://tenant/api/v1/deployment/installer/agent/connectioninfo", dc.getOneAgentConnectionInfoUrl())
+	})
+	t.Run(`of network zone`, func(t *testing.T) {
+		dc := &dynatraceClient{url: "https://tenant/api"}
+		assert.Equal(t, "https://tenant/api/v1/deployment/installer/agent/connectioninfo?networkZone=zone+a",
+			dc.getOneAgentNetworkZoneConnectionInfoUrl("zone a"))
+	})
+}
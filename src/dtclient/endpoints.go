@@ -1,6 +1,9 @@
 package dtclient
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func (dtc *dynatraceClient) getAgentUrl(os, installerType, flavor, arch, version string, technologies []string) string {
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/version/%s?flavor=%s&arch=%s&bitness=64",
@@ -28,6 +31,10 @@ func (dtc *dynatraceClient) getOneAgentConnectionInfoUrl() string {
 	return fmt.Sprintf("%s/v1/deployment/installer/agent/connectioninfo", dtc.url)
 }
 
+func (dtc *dynatraceClient) getOneAgentNetworkZoneConnectionInfoUrl(networkZone string) string {
+	return fmt.Sprintf("%s?networkZone=%s", dtc.getOneAgentConnectionInfoUrl(), url.QueryEscape(networkZone))
+}
+
 func (dtc *dynatraceClient) getActiveGateConnectionInfoUrl() string {
 	return fmt.Sprintf("%s/v1/deployment/installer/gateway/connectioninfo", dtc.url)
 }
@@ -51,6 +51,11 @@ func (o *MockDynatraceClient) GetConnectionInfo() (ConnectionInfo, error) {
 	return args.Get(0).(ConnectionInfo), args.Error(1)
 }
 
+func (o *MockDynatraceClient) GetNetworkZoneConnectionInfo(networkZone string) (ConnectionInfo, error) {
+	args := o.Called(networkZone)
+	return args.Get(0).(ConnectionInfo), args.Error(1)
+}
+
 func (o *MockDynatraceClient) GetCommunicationHostForClient() (CommunicationHost, error) {
 	args := o.Called()
 	return args.Get(0).(CommunicationHost), args.Error(1)
@@ -13,6 +13,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -24,6 +25,8 @@ const (
 	MetricsUrlSecretField   = "DT_METRICS_INGEST_URL"
 	MetricsTokenSecretField = "DT_METRICS_INGEST_API_TOKEN"
 	StatsdUrlSecretField    = "DT_STATSD_INGEST_URL"
+	OtlpUrlSecretField      = "DT_OTLP_INGEST_URL"
+	OtlpTokenSecretField    = "DT_OTLP_INGEST_API_TOKEN"
 	configFile              = "endpoint.properties"
 )
 
@@ -45,7 +48,13 @@ func NewEndpointSecretGenerator(client client.Client, apiReader client.Reader, n
 // GenerateForNamespace creates the data-ingest-endpoint secret for namespace while only having the name of the corresponding dynakube
 // Used by the podInjection webhook in case the namespace lacks the secret.
 func (g *EndpointSecretGenerator) GenerateForNamespace(ctx context.Context, dkName, targetNs string) error {
-	log.Info("reconciling data-ingest endpoint secret for", "namespace", targetNs)
+	return g.GenerateForNamespaceWithName(ctx, dkName, targetNs, config.EnrichmentEndpointSecretName)
+}
+
+// GenerateForNamespaceWithName creates the data-ingest-endpoint secret with the given name for namespace,
+// used for dynakubes that share the namespace with another dynakube
+func (g *EndpointSecretGenerator) GenerateForNamespaceWithName(ctx context.Context, dkName, targetNs, secretName string) error {
+	log.Info("reconciling data-ingest endpoint secret for", "namespace", targetNs, "secret", secretName)
 	var dk dynatracev1beta1.DynaKube
 	if err := g.client.Get(ctx, client.ObjectKey{Name: dkName, Namespace: g.namespace}, &dk); err != nil {
 		return errors.WithStack(err)
@@ -60,7 +69,7 @@ func (g *EndpointSecretGenerator) GenerateForNamespace(ctx context.Context, dkNa
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      config.EnrichmentEndpointSecretName,
+			Name:      secretName,
 			Namespace: targetNs,
 			Labels:    coreLabels.BuildMatchLabels(),
 		},
@@ -95,7 +104,7 @@ func (g *EndpointSecretGenerator) GenerateForDynakube(ctx context.Context, dk *d
 		secret := &corev1.Secret{
 			TypeMeta: metav1.TypeMeta{},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      config.EnrichmentEndpointSecretName,
+				Name:      dtwebhook.SecretNameForDynakube(config.EnrichmentEndpointSecretName, targetNs, dk.Name),
 				Namespace: targetNs.Name,
 				Labels:    coreLabels.BuildMatchLabels(),
 			},
@@ -121,7 +130,7 @@ func (g *EndpointSecretGenerator) RemoveEndpointSecrets(ctx context.Context, dk
 	for _, targetNs := range nsList {
 		endpointSecret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      config.EnrichmentEndpointSecretName,
+				Name:      dtwebhook.SecretNameForDynakube(config.EnrichmentEndpointSecretName, targetNs, dk.Name),
 				Namespace: targetNs.GetName(),
 			},
 		}
@@ -159,6 +168,12 @@ func (g *EndpointSecretGenerator) prepare(ctx context.Context, dk *dynatracev1be
 	data := map[string][]byte{
 		configFile: bytes.NewBufferString(endpointPropertiesBuilder.String()).Bytes(),
 	}
+
+	// the otlp fields are separate keys, so they can be referenced by the env vars of the containers
+	if needsOtlp(dk) {
+		data[OtlpUrlSecretField] = []byte(fields[OtlpUrlSecretField])
+		data[OtlpTokenSecretField] = []byte(fields[OtlpTokenSecretField])
+	}
 	return data, nil
 }
 
@@ -182,6 +197,15 @@ func (g *EndpointSecretGenerator) PrepareFields(ctx context.Context, dk *dynatra
 		}
 	}
 
+	if needsOtlp(dk) {
+		fields[OtlpTokenSecretField] = fields[MetricsTokenSecretField]
+		if otlpUrl, err := otlpIngestUrlFor(dk); err != nil {
+			return nil, err
+		} else {
+			fields[OtlpUrlSecretField] = otlpUrl
+		}
+	}
+
 	if dk.NeedsStatsd() {
 		if statsdUrl, err := statsdIngestUrl(dk); err != nil {
 			return nil, err
@@ -217,6 +241,24 @@ func metricsIngestUrlForClusterActiveGate(dk *dynatracev1beta1.DynaKube) (string
 	return fmt.Sprintf("https://%s.%s/e/%s/api/v2/metrics/ingest", serviceName, dk.Namespace, tenant), nil
 }
 
+func needsOtlp(dk *dynatracev1beta1.DynaKube) bool {
+	return dk.FeatureOtelInjection() && !dk.FeatureDisableMetadataEnrichment()
+}
+
+func otlpIngestUrlFor(dk *dynatracev1beta1.DynaKube) (string, error) {
+	if dk.IsActiveGateMode(dynatracev1beta1.MetricsIngestCapability.DisplayName) {
+		tenant, err := dk.TenantUUID()
+		if err != nil {
+			return "", err
+		}
+		serviceName := capability.BuildServiceName(dk.Name, statefulset.MultiActiveGateName)
+		return fmt.Sprintf("https://%s.%s/e/%s/api/v2/otlp", serviceName, dk.Namespace, tenant), nil
+	} else if len(dk.Spec.APIURL) > 0 {
+		return fmt.Sprintf("%s/v2/otlp", dk.Spec.APIURL), nil
+	}
+	return "", fmt.Errorf("failed to create otlp endpoint, DynaKube.spec.apiUrl is empty")
+}
+
 func statsdIngestUrl(dk *dynatracev1beta1.DynaKube) (string, error) {
 	serviceName := capability.BuildServiceName(dk.Name, statefulset.MultiActiveGateName)
 	return fmt.Sprintf("%s.%s:%d", serviceName, dk.Namespace, statefulset.StatsdIngestPort), nil
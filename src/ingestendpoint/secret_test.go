@@ -322,6 +322,43 @@ func TestGenerateDataIngestSecret_ForDynakube(t *testing.T) {
 	})
 }
 
+func TestGenerateDataIngestSecret_WithOtlp(t *testing.T) {
+	t.Run(`otlp fields are separate keys of the secret`, func(t *testing.T) {
+		instance := buildTestDynakube()
+		instance.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureOtelInjection: "true"}
+		fakeClient := buildTestClientBeforeGenerate(instance)
+		endpointSecretGenerator := NewEndpointSecretGenerator(fakeClient, fakeClient, testNamespaceDynatrace)
+
+		err := endpointSecretGenerator.GenerateForNamespace(context.TODO(), testDynakubeName, testNamespace1)
+		require.NoError(t, err)
+
+		checkTestSecretContains(t, fakeClient, types.NamespacedName{Namespace: testNamespace1, Name: config.EnrichmentEndpointSecretName}, testDataIngestSecretWithMetrics)
+		var secret corev1.Secret
+		require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace1, Name: config.EnrichmentEndpointSecretName}, &secret))
+		assert.Equal(t, "https://tenant.test/api/v2/otlp", string(secret.Data[OtlpUrlSecretField]))
+		assert.Equal(t, testDataIngestToken, string(secret.Data[OtlpTokenSecretField]))
+	})
+	t.Run(`otlp url of the local activegate`, func(t *testing.T) {
+		instance := buildTestDynakubeWithDataIngestCapability([]dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.MetricsIngestCapability.DisplayName})
+
+		otlpUrl, err := otlpIngestUrlFor(instance)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://dynakube-activegate.dynatrace/e/tenant/api/v2/otlp", otlpUrl)
+	})
+	t.Run(`no otlp fields without feature flag`, func(t *testing.T) {
+		fakeClient := buildTestClientBeforeGenerate(buildTestDynakube())
+		endpointSecretGenerator := NewEndpointSecretGenerator(fakeClient, fakeClient, testNamespaceDynatrace)
+
+		err := endpointSecretGenerator.GenerateForNamespace(context.TODO(), testDynakubeName, testNamespace1)
+		require.NoError(t, err)
+
+		var secret corev1.Secret
+		require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace1, Name: config.EnrichmentEndpointSecretName}, &secret))
+		assert.NotContains(t, secret.Data, OtlpUrlSecretField)
+	})
+}
+
 func testGenerateEndpointsSecret(t *testing.T, instance *dynatracev1beta1.DynaKube, fakeClient client.Client) {
 	endpointSecretGenerator := NewEndpointSecretGenerator(fakeClient, fakeClient, testNamespaceDynatrace)
 
@@ -343,6 +380,29 @@ func TestRemoveEndpointSecrets(t *testing.T) {
 
 }
 
+func TestGenerateDataIngestSecret_ForRoutedNamespace(t *testing.T) {
+	dk := buildTestDynakube()
+	fakeClient := buildTestClientBeforeGenerate(dk)
+
+	var sharedNamespace corev1.Namespace
+	require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: testNamespace2}, &sharedNamespace))
+	sharedNamespace.Labels[dtwebhook.InjectionInstanceLabel] = "other-dynakube"
+	sharedNamespace.Annotations = map[string]string{dtwebhook.InjectionRoutedInstancesAnnotation: dk.Name}
+	require.NoError(t, fakeClient.Update(context.TODO(), &sharedNamespace))
+
+	testGenerateEndpointsSecret(t, dk, fakeClient)
+
+	routedSecretName := config.EnrichmentEndpointSecretName + "-" + dk.Name
+	checkTestSecretContains(t, fakeClient, types.NamespacedName{Namespace: testNamespace1, Name: config.EnrichmentEndpointSecretName}, testDataIngestSecretWithMetrics)
+	checkTestSecretContains(t, fakeClient, types.NamespacedName{Namespace: testNamespace2, Name: routedSecretName}, testDataIngestSecretWithMetrics)
+	checkTestSecretDoesntExist(t, fakeClient, types.NamespacedName{Namespace: testNamespace2, Name: config.EnrichmentEndpointSecretName})
+
+	endpointSecretGenerator := NewEndpointSecretGenerator(fakeClient, fakeClient, dk.Namespace)
+	require.NoError(t, endpointSecretGenerator.RemoveEndpointSecrets(context.TODO(), dk))
+
+	checkTestSecretDoesntExist(t, fakeClient, types.NamespacedName{Namespace: testNamespace2, Name: routedSecretName})
+}
+
 func checkTestSecretContains(t *testing.T, fakeClient client.Client, secretName types.NamespacedName, data string) {
 	var testSecret corev1.Secret
 	err := fakeClient.Get(context.TODO(), secretName, &testSecret)
@@ -3,14 +3,22 @@ package initgeneration
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/processmoduleconfigmap"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
 	"github.com/Dynatrace/dynatrace-operator/src/standalone"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,7 +53,13 @@ func NewInitGenerator(client client.Client, apiReader client.Reader, namespace s
 // GenerateForNamespace creates the init secret for namespace while only having the name of the corresponding dynakube
 // Used by the podInjection webhook in case the namespace lacks the init secret.
 func (g *InitGenerator) GenerateForNamespace(ctx context.Context, dk dynatracev1beta1.DynaKube, targetNs string) error {
-	log.Info("reconciling namespace init secret for", "namespace", targetNs)
+	return g.GenerateForNamespaceWithName(ctx, dk, targetNs, config.AgentInitSecretName)
+}
+
+// GenerateForNamespaceWithName creates the init secret with the given name for namespace,
+// used for dynakubes that share the namespace with another dynakube
+func (g *InitGenerator) GenerateForNamespaceWithName(ctx context.Context, dk dynatracev1beta1.DynaKube, targetNs, secretName string) error {
+	log.Info("reconciling namespace init secret for", "namespace", targetNs, "secret", secretName)
 	g.canWatchNodes = false
 	data, err := g.generate(ctx, &dk)
 	if err != nil {
@@ -56,7 +70,7 @@ func (g *InitGenerator) GenerateForNamespace(ctx context.Context, dk dynatracev1
 	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      config.AgentInitSecretName,
+			Name:      secretName,
 			Namespace: targetNs,
 			Labels:    coreLabels.BuildMatchLabels(),
 		},
@@ -92,7 +106,7 @@ func (g *InitGenerator) GenerateForDynakube(ctx context.Context, dk *dynatracev1
 		secret := &corev1.Secret{
 			TypeMeta: metav1.TypeMeta{},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      config.AgentInitSecretName,
+				Name:      dtwebhook.SecretNameForDynakube(config.AgentInitSecretName, targetNs, dk.Name),
 				Namespace: targetNs.Name,
 				Labels:    coreLabels.BuildMatchLabels(),
 			},
@@ -157,6 +171,14 @@ func (g *InitGenerator) createSecretConfigForDynaKube(ctx context.Context, dynak
 		return nil, errors.WithStack(err)
 	}
 
+	var processModuleConfig *dtclient.ProcessModuleConfig
+	if dynakube.FeatureOneAgentOfflineInstall() {
+		processModuleConfig, err = processmoduleconfigmap.GetProcessModuleConfig(ctx, g.apiReader, dynakube)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
 	return &standalone.SecretConfig{
 		ApiUrl:              dynakube.Spec.APIURL,
 		ApiToken:            getAPIToken(tokens),
@@ -172,9 +194,41 @@ func (g *InitGenerator) createSecretConfigForDynaKube(ctx context.Context, dynak
 		HostGroup:           dynakube.HostGroup(),
 		ClusterID:           string(kubeSystemUID),
 		InitialConnectRetry: dynakube.FeatureAgentInitialConnectRetry(),
+		FallbackApiUrls:     getFallbackApiUrls(dynakube),
+		DownloadDeadline:    dynakube.FeatureAgentDownloadDeadline(),
+		OfflineChecksum:     dynakube.FeatureOneAgentOfflineSha256(),
+		ProcessModuleConfig: processModuleConfig,
 	}, nil
 }
 
+// getFallbackApiUrls lists the endpoints the init container can download the OneAgent from if the api url fails,
+// the routing ActiveGate of the dynakube comes first, followed by the communication hosts of the tenant
+func getFallbackApiUrls(dynakube *dynatracev1beta1.DynaKube) []string {
+	tenantUUID := dynakube.Status.ConnectionInfo.TenantUUID
+	if tenantUUID == "" {
+		return nil
+	}
+
+	apiHost := ""
+	if apiUrl, err := url.Parse(dynakube.Spec.APIURL); err == nil {
+		apiHost = apiUrl.Hostname()
+	}
+
+	fallbackApiUrls := []string{}
+	if dynakube.IsActiveGateMode(dynatracev1beta1.RoutingCapability.DisplayName) {
+		serviceName := capability.BuildServiceName(dynakube.Name, statefulset.MultiActiveGateName)
+		fallbackApiUrls = append(fallbackApiUrls, fmt.Sprintf("https://%s.%s/e/%s/api", serviceName, dynakube.Namespace, tenantUUID))
+	}
+	for _, communicationHost := range dynakube.Status.ConnectionInfo.CommunicationHosts {
+		if communicationHost.Host == apiHost {
+			continue
+		}
+		hostWithPort := net.JoinHostPort(communicationHost.Host, strconv.FormatUint(uint64(communicationHost.Port), 10))
+		fallbackApiUrls = append(fallbackApiUrls, fmt.Sprintf("%s://%s/e/%s/api", communicationHost.Protocol, hostWithPort, tenantUUID))
+	}
+	return fallbackApiUrls
+}
+
 func getPaasToken(tokens corev1.Secret) string {
 	if len(tokens.Data[dtclient.DynatracePaasToken]) != 0 {
 		return string(tokens.Data[dtclient.DynatracePaasToken])
@@ -7,6 +7,7 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/processmoduleconfigmap"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/Dynatrace/dynatrace-operator/src/standalone"
@@ -354,6 +355,59 @@ func TestPrepareSecretConfigForDynaKube(t *testing.T) {
 		testForCorrectContent(t, testSecretDynakubeComplexOnlyApi)
 	})
 	t.Run("Initial connect retry is set correctly", testInitialConnectRetrySetCorrectly)
+	t.Run("Download deadline is set correctly", testDownloadDeadlineSetCorrectly)
+	t.Run("Offline installation is set correctly", testOfflineInstallationSetCorrectly)
+}
+
+func TestGetFallbackApiUrls(t *testing.T) {
+	communicationHosts := []dynatracev1beta1.CommunicationHostStatus{
+		{Protocol: "https", Host: "test-url", Port: 443},
+		{Protocol: "https", Host: "sg.test-url", Port: 9999},
+	}
+
+	t.Run("communication hosts without the host of the api url", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Status.ConnectionInfo.CommunicationHosts = communicationHosts
+
+		assert.Equal(t, []string{"https://sg.test-url:9999/e/" + testTenantUUID + "/api"}, getFallbackApiUrls(dk))
+	})
+	t.Run("routing ActiveGate comes first", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Spec.ActiveGate.Capabilities = []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.RoutingCapability.DisplayName}
+		dk.Status.ConnectionInfo.CommunicationHosts = communicationHosts
+
+		assert.Equal(t, []string{
+			"https://" + testDynakubeSimpleName + "-activegate." + operatorNamespace + "/e/" + testTenantUUID + "/api",
+			"https://sg.test-url:9999/e/" + testTenantUUID + "/api",
+		}, getFallbackApiUrls(dk))
+	})
+	t.Run("no fallback without tenant uuid", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Status.ConnectionInfo.TenantUUID = ""
+		dk.Status.ConnectionInfo.CommunicationHosts = communicationHosts
+
+		assert.Empty(t, getFallbackApiUrls(dk))
+	})
+}
+
+func testDownloadDeadlineSetCorrectly(t *testing.T) {
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testDynakubeSimpleName,
+			Namespace:   operatorNamespace,
+			Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentDownloadDeadline: "30"},
+		},
+	}
+	clt := fake.NewClient(testSecretDynakubeSimple)
+	initGenerator := InitGenerator{
+		client:        clt,
+		namespace:     operatorNamespace,
+		dynakubeQuery: kubeobjects.NewDynakubeQuery(clt, operatorNamespace),
+	}
+	secretConfig, err := initGenerator.createSecretConfigForDynaKube(context.TODO(), dynakube, kubesystemUID, map[string]string{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 30, secretConfig.DownloadDeadline)
 }
 
 func testInitialConnectRetrySetCorrectly(t *testing.T) {
@@ -405,6 +459,7 @@ func testForCorrectContent(t *testing.T, secret *corev1.Secret) {
 		HasHost:             true,
 		TlsCert:             "testing",
 		InitialConnectRetry: -1,
+		FallbackApiUrls:     []string{},
 	}
 	if content, ok := secret.Data["paasToken"]; ok {
 		expectedConfig.PaasToken = string(content)
@@ -413,3 +468,33 @@ func testForCorrectContent(t *testing.T, secret *corev1.Secret) {
 	}
 	assert.Equal(t, &expectedConfig, secretConfig)
 }
+
+func testOfflineInstallationSetCorrectly(t *testing.T) {
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDynakubeSimpleName,
+			Namespace: operatorNamespace,
+			Annotations: map[string]string{
+				dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC:    "oneagent-package",
+				dynatracev1beta1.AnnotationFeatureOneAgentOfflineSha256: "0123456789abcdef",
+			},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{}},
+		},
+	}
+	snapshot := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: processmoduleconfigmap.ConfigMapName(testDynakubeSimpleName), Namespace: operatorNamespace},
+		Data:       map[string]string{processmoduleconfigmap.ConfigMapKey: `{"revision":3,"properties":[{"section":"general","key":"tenant","value":"abc"}]}`},
+	}
+	clt := fake.NewClient(testSecretDynakubeSimple, snapshot)
+	initGenerator := NewInitGenerator(clt, clt, operatorNamespace)
+
+	secretConfig, err := initGenerator.createSecretConfigForDynaKube(context.TODO(), dynakube, kubesystemUID, map[string]string{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", secretConfig.OfflineChecksum)
+	require.NotNil(t, secretConfig.ProcessModuleConfig)
+	assert.Equal(t, uint(3), secretConfig.ProcessModuleConfig.Revision)
+	assert.Len(t, secretConfig.ProcessModuleConfig.Properties, 1)
+}
@@ -0,0 +1,9 @@
+package offline
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("oneagent-offline-installer")
+)
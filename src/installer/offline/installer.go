@@ -0,0 +1,99 @@
+package offline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/symlink"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/zip"
+	"github.com/Dynatrace/dynatrace-operator/src/processmoduleconfig"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type Properties struct {
+	PackagePath string
+	Checksum    string // expected sha256 checksum of the package, it's required
+
+	PathResolver metadata.PathResolver
+}
+
+// OfflineInstaller installs the OneAgent from a pre-seeded package, the package is only extracted if its sha256 checksum matches
+type OfflineInstaller struct {
+	fs        afero.Fs
+	extractor zip.Extractor
+	props     *Properties
+}
+
+func NewOfflineInstaller(fs afero.Fs, props *Properties) *OfflineInstaller {
+	return &OfflineInstaller{
+		fs:        fs,
+		extractor: zip.NewOneAgentExtractor(fs, props.PathResolver),
+		props:     props,
+	}
+}
+
+func (installer OfflineInstaller) InstallAgent(targetDir string) (bool, error) {
+	log.Info("installing agent from package", "package", installer.props.PackagePath, "target dir", targetDir)
+	packageFile, err := installer.fs.Open(installer.props.PackagePath)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to open the OneAgent package")
+	}
+	defer func() { _ = packageFile.Close() }()
+
+	if err := installer.verifyChecksum(packageFile); err != nil {
+		return false, err
+	}
+
+	if err := installer.extractor.ExtractZip(packageFile, targetDir); err != nil {
+		_ = installer.fs.RemoveAll(targetDir)
+		log.Info("failed to unzip OneAgent package", "err", err)
+		return false, errors.WithStack(err)
+	}
+
+	if err := symlink.CreateSymlinkForCurrentVersionIfNotExists(installer.fs, targetDir); err != nil {
+		_ = installer.fs.RemoveAll(targetDir)
+		log.Info("failed to create symlink for agent installation", "targetDir", targetDir)
+		return false, err
+	}
+	return true, nil
+}
+
+func (installer OfflineInstaller) UpdateProcessModuleConfig(targetDir string, processModuleConfig *dtclient.ProcessModuleConfig) error {
+	return processmoduleconfig.UpdateProcessModuleConfigInPlace(installer.fs, targetDir, processModuleConfig)
+}
+
+// verifyChecksum compares the sha256 checksum of the package with the expected one, the package is read from the start again afterwards
+func (installer OfflineInstaller) verifyChecksum(packageFile afero.File) error {
+	expectedChecksum, err := installer.getExpectedChecksum()
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, packageFile); err != nil {
+		return errors.WithStack(err)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != expectedChecksum {
+		return errors.Errorf("checksum of the OneAgent package '%s' doesn't match, expected '%s' but got '%s'",
+			installer.props.PackagePath, expectedChecksum, checksum)
+	}
+	log.Info("verified checksum of the OneAgent package", "sha256", checksum)
+
+	_, err = packageFile.Seek(0, io.SeekStart)
+	return errors.WithStack(err)
+}
+
+// getExpectedChecksum returns the configured checksum, a checksum file next to the package isn't trusted,
+// as whoever is able to replace the package could replace it too
+func (installer OfflineInstaller) getExpectedChecksum() (string, error) {
+	if installer.props.Checksum == "" {
+		return "", errors.Errorf("checksum of the OneAgent package '%s' is missing", installer.props.PackagePath)
+	}
+	return strings.ToLower(installer.props.Checksum), nil
+}
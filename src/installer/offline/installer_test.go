@@ -0,0 +1,86 @@
+package offline
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/zip"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPackagePath = "/offline/oneagent.zip"
+	testTargetDir   = "/test"
+)
+
+func TestInstallAgent(t *testing.T) {
+	t.Run(`install with the configured checksum`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		checksum := setupTestPackage(t, fs)
+		installer := createTestOfflineInstaller(fs, strings.ToUpper(checksum))
+
+		installed, err := installer.InstallAgent(testTargetDir)
+
+		require.NoError(t, err)
+		assert.True(t, installed)
+	})
+	t.Run(`checksum doesn't match`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		setupTestPackage(t, fs)
+		installer := createTestOfflineInstaller(fs, "0123456789abcdef")
+
+		installed, err := installer.InstallAgent(testTargetDir)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "doesn't match")
+		assert.False(t, installed)
+		exists, _ := afero.Exists(fs, testTargetDir)
+		assert.False(t, exists)
+	})
+	t.Run(`checksum file next to the package is ignored`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		checksum := setupTestPackage(t, fs)
+		require.NoError(t, afero.WriteFile(fs, testPackagePath+".sha256", []byte(checksum+"  oneagent.zip\n"), 0644))
+		installer := createTestOfflineInstaller(fs, "")
+
+		installed, err := installer.InstallAgent(testTargetDir)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is missing")
+		assert.False(t, installed)
+		exists, _ := afero.Exists(fs, testTargetDir)
+		assert.False(t, exists)
+	})
+	t.Run(`package is missing`, func(t *testing.T) {
+		installer := createTestOfflineInstaller(afero.NewMemMapFs(), "0123456789abcdef")
+
+		_, err := installer.InstallAgent(testTargetDir)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to open the OneAgent package")
+	})
+}
+
+func createTestOfflineInstaller(fs afero.Fs, checksum string) *OfflineInstaller {
+	return NewOfflineInstaller(fs, &Properties{
+		PackagePath:  testPackagePath,
+		Checksum:     checksum,
+		PathResolver: metadata.PathResolver{},
+	})
+}
+
+// setupTestPackage writes the test zip as package and returns its checksum
+func setupTestPackage(t *testing.T, fs afero.Fs) string {
+	content, err := base64.StdEncoding.DecodeString(zip.TestRawZip)
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, testPackagePath, content, 0644))
+
+	checksum := sha256.Sum256(content)
+	return hex.EncodeToString(checksum[:])
+}
@@ -41,6 +41,11 @@ func CreateSymlinkForCurrentVersionIfNotExists(fs afero.Fs, targetDir string) er
 	return nil
 }
 
+// FindInstalledVersion returns the version of the OneAgent installed in the target directory, it's empty if none is installed
+func FindInstalledVersion(fs afero.Fs, targetDir string) (string, error) {
+	return findVersionFromFileSystem(fs, filepath.Join(targetDir, binDir))
+}
+
 func findVersionFromFileSystem(fs afero.Fs, targetDir string) (string, error) {
 	var version string
 	aferoFs := afero.Afero{
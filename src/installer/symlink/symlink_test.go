@@ -33,3 +33,23 @@ func TestFindVersionFromFileSystem(t *testing.T) {
 		assert.Empty(t, version)
 	})
 }
+
+func TestFindInstalledVersion(t *testing.T) {
+	testPath := "/test"
+	testVersion := "1.239.14.20220325-164521"
+	t.Run("get version of installed agent", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		err := fs.MkdirAll(filepath.Join(testPath, binDir, testVersion), 0755)
+		require.NoError(t, err)
+
+		version, err := FindInstalledVersion(fs, testPath)
+		require.NoError(t, err)
+		assert.Equal(t, testVersion, version)
+	})
+	t.Run("no agent installed", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+
+		_, err := FindInstalledVersion(fs, testPath)
+		require.Error(t, err)
+	})
+}
@@ -80,6 +80,46 @@ func TestMapFromDynakube(t *testing.T) {
 
 		assert.Error(t, err)
 	})
+	t.Run("Route pods of shared namespace to dynakube with pod selector", func(t *testing.T) {
+		canaryDk := createTestDynakubeWithMultipleFeatures("canary-dk", labels, nil)
+		canaryDk.Annotations = map[string]string{
+			dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary",
+		}
+		namespace := createNamespace("test-namespace", labels)
+		clt := fake.NewClient(dk, canaryDk, namespace)
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", canaryDk)
+
+		err := dm.MapFromDynakube()
+
+		assert.NoError(t, err)
+		var ns corev1.Namespace
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: namespace.Name}, &ns)
+		assert.NoError(t, err)
+		assert.Equal(t, dk.Name, ns.Labels[dtwebhook.InjectionInstanceLabel])
+		assert.Equal(t, canaryDk.Name, ns.Annotations[dtwebhook.InjectionRoutedInstancesAnnotation])
+
+		namespaces, err := GetNamespacesForDynakube(context.TODO(), clt, canaryDk.Name)
+		assert.NoError(t, err)
+		assert.Len(t, namespaces, 1)
+	})
+	t.Run("Dynakube with pod selector is used for the instance label if it's the only one", func(t *testing.T) {
+		canaryDk := createTestDynakubeWithMultipleFeatures("canary-dk", labels, nil)
+		canaryDk.Annotations = map[string]string{
+			dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary",
+		}
+		namespace := createNamespace("test-namespace", labels)
+		clt := fake.NewClient(canaryDk, namespace)
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", canaryDk)
+
+		err := dm.MapFromDynakube()
+
+		assert.NoError(t, err)
+		var ns corev1.Namespace
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: namespace.Name}, &ns)
+		assert.NoError(t, err)
+		assert.Equal(t, canaryDk.Name, ns.Labels[dtwebhook.InjectionInstanceLabel])
+		assert.NotContains(t, ns.Annotations, dtwebhook.InjectionRoutedInstancesAnnotation)
+	})
 	t.Run("Ignore kube namespaces", func(t *testing.T) {
 		dk := createTestDynakubeWithMultipleFeatures("appMonitoring", nil, nil)
 		namespace := createNamespace("kube-something", nil)
@@ -160,4 +200,19 @@ func TestUnmapFromDynaKube(t *testing.T) {
 		assert.Equal(t, 0, len(ns.Labels))
 		assert.Equal(t, 1, len(ns.Annotations))
 	})
+	t.Run("Remove routed dynakube from shared namespace", func(t *testing.T) {
+		sharedNamespace := createNamespace("shared", map[string]string{dtwebhook.InjectionInstanceLabel: "prod"})
+		sharedNamespace.Annotations = map[string]string{dtwebhook.InjectionRoutedInstancesAnnotation: "canary," + dk.Name}
+		clt := fake.NewClient(sharedNamespace)
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", dk)
+
+		err := dm.UnmapFromDynaKube()
+
+		assert.NoError(t, err)
+		var ns corev1.Namespace
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: sharedNamespace.Name}, &ns)
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", ns.Labels[dtwebhook.InjectionInstanceLabel])
+		assert.Equal(t, "canary", ns.Annotations[dtwebhook.InjectionRoutedInstancesAnnotation])
+	})
 }
@@ -3,6 +3,8 @@ package mapper
 import (
 	"context"
 	"regexp"
+	"sort"
+	"strings"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
@@ -17,8 +19,10 @@ type ConflictChecker struct {
 	alreadyUsed bool
 }
 
+// check finds dynakubes that would inject into the same pods,
+// dynakubes with a pod selector can share a namespace, as the pods are routed by their labels
 func (c *ConflictChecker) check(dk *dynatracev1beta1.DynaKube) error {
-	if !dk.NeedAppInjection() {
+	if !dk.NeedAppInjection() || dk.FeatureInjectionPodSelector() != "" {
 		return nil
 	}
 	if c.alreadyUsed {
@@ -28,16 +32,20 @@ func (c *ConflictChecker) check(dk *dynatracev1beta1.DynaKube) error {
 	return nil
 }
 
+// GetNamespacesForDynakube returns the namespaces the dynakube injects into, including the ones it's only routed to
 func GetNamespacesForDynakube(ctx context.Context, clt client.Reader, dkName string) ([]corev1.Namespace, error) {
 	nsList := &corev1.NamespaceList{}
-	listOps := []client.ListOption{
-		client.MatchingLabels(map[string]string{dtwebhook.InjectionInstanceLabel: dkName}),
-	}
-	err := clt.List(ctx, nsList, listOps...)
+	err := clt.List(ctx, nsList)
 	if err != nil {
 		return nil, err
 	}
-	return nsList.Items, err
+	namespaces := []corev1.Namespace{}
+	for _, namespace := range nsList.Items {
+		if namespace.Labels[dtwebhook.InjectionInstanceLabel] == dkName || dtwebhook.IsRoutedDynakube(namespace, dkName) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
 }
 
 func addNamespaceInjectLabel(dkName string, ns *corev1.Namespace) {
@@ -73,8 +81,8 @@ func match(dk *dynatracev1beta1.DynaKube, namespace *corev1.Namespace) (bool, er
 // finds conflicting dynakubes(2 dynakube with codeModules on the same namespace)
 // adds/updates/removes labels from the namespace.
 func updateNamespace(namespace *corev1.Namespace, deployedDynakubes *dynatracev1beta1.DynaKubeList) (bool, error) {
-	namespaceUpdated := false
 	conflict := ConflictChecker{}
+	matchingDynakubes := []*dynatracev1beta1.DynaKube{}
 	for i := range deployedDynakubes.Items {
 		dynakube := &deployedDynakubes.Items[i]
 		if isIgnoredNamespace(dynakube, namespace.Name) {
@@ -83,39 +91,101 @@ func updateNamespace(namespace *corev1.Namespace, deployedDynakubes *dynatracev1
 		matches, err := match(dynakube, namespace)
 
 		if err != nil {
-			return namespaceUpdated, err
+			return false, err
 		}
-		if matches {
+		if matches && dynakube.NeedAppInjection() {
 			if err := conflict.check(dynakube); err != nil {
-				return namespaceUpdated, err
+				return false, err
 			}
+			matchingDynakubes = append(matchingDynakubes, dynakube)
 		}
+	}
 
-		labelsUpdated := updateLabels(matches, dynakube, namespace)
-		namespaceUpdated = labelsUpdated || namespaceUpdated
+	labelsUpdated := updateLabels(selectInstanceDynakube(matchingDynakubes), deployedDynakubes, namespace)
+	routingUpdated := updateRoutedInstances(matchingDynakubes, namespace)
+	return labelsUpdated || routingUpdated, nil
+}
+
+// selectInstanceDynakube selects the dynakube for the instance label of the namespace,
+// a dynakube without a pod selector is preferred, as it's the default for every pod of the namespace
+func selectInstanceDynakube(matchingDynakubes []*dynatracev1beta1.DynaKube) *dynatracev1beta1.DynaKube {
+	if len(matchingDynakubes) == 0 {
+		return nil
 	}
-	return namespaceUpdated, nil
+	for _, dynakube := range matchingDynakubes {
+		if dynakube.FeatureInjectionPodSelector() == "" {
+			return dynakube
+		}
+	}
+	dtwebhook.SortByInjectionPriority(matchingDynakubes)
+	return matchingDynakubes[0]
 }
 
-func updateLabels(matches bool, dynakube *dynatracev1beta1.DynaKube, namespace *corev1.Namespace) bool {
-	updated := false
+func updateLabels(instanceDynakube *dynatracev1beta1.DynaKube, deployedDynakubes *dynatracev1beta1.DynaKubeList, namespace *corev1.Namespace) bool {
 	if namespace.Labels == nil {
 		namespace.Labels = make(map[string]string)
 	}
 
 	associatedDynakubeName, instanceLabelFound := namespace.Labels[dtwebhook.InjectionInstanceLabel]
 
-	if matches && dynakube.NeedAppInjection() {
-		if !instanceLabelFound || associatedDynakubeName != dynakube.Name {
-			updated = true
-			addNamespaceInjectLabel(dynakube.Name, namespace)
-			log.Info("started monitoring namespace", "namespace", namespace.Name, "dynakube", dynakube.Name)
+	if instanceDynakube != nil {
+		if !instanceLabelFound || associatedDynakubeName != instanceDynakube.Name {
+			addNamespaceInjectLabel(instanceDynakube.Name, namespace)
+			log.Info("started monitoring namespace", "namespace", namespace.Name, "dynakube", instanceDynakube.Name)
+			return true
+		}
+		return false
+	}
+
+	// only labels of known dynakubes are removed
+	for _, dynakube := range deployedDynakubes.Items {
+		if instanceLabelFound && associatedDynakubeName == dynakube.Name {
+			delete(namespace.Labels, dtwebhook.InjectionInstanceLabel)
+			return true
+		}
+	}
+	return false
+}
+
+// updateRoutedInstances lists every other matching dynakube in the namespace annotation,
+// the annotation is only kept if the namespace is shared by dynakubes with a pod selector
+func updateRoutedInstances(matchingDynakubes []*dynatracev1beta1.DynaKube, namespace *corev1.Namespace) bool {
+	routedNames := []string{}
+	for _, dynakube := range matchingDynakubes {
+		if dynakube.Name != namespace.Labels[dtwebhook.InjectionInstanceLabel] {
+			routedNames = append(routedNames, dynakube.Name)
+		}
+	}
+	sort.Strings(routedNames)
+	routedInstances := strings.Join(routedNames, ",")
+
+	if namespace.Annotations[dtwebhook.InjectionRoutedInstancesAnnotation] == routedInstances {
+		return false
+	}
+	if routedInstances == "" {
+		delete(namespace.Annotations, dtwebhook.InjectionRoutedInstancesAnnotation)
+		return true
+	}
+	if namespace.Annotations == nil {
+		namespace.Annotations = make(map[string]string)
+	}
+	namespace.Annotations[dtwebhook.InjectionRoutedInstancesAnnotation] = routedInstances
+	log.Info("routing pods of namespace", "namespace", namespace.Name, "dynakubes", routedInstances)
+	return true
+}
+
+func removeRoutedInstance(namespace *corev1.Namespace, dkName string) {
+	routedNames := []string{}
+	for _, name := range dtwebhook.RoutedDynakubeNames(*namespace) {
+		if name != dkName {
+			routedNames = append(routedNames, name)
 		}
-	} else if instanceLabelFound && associatedDynakubeName == dynakube.Name {
-		updated = true
-		delete(namespace.Labels, dtwebhook.InjectionInstanceLabel)
 	}
-	return updated
+	if len(routedNames) == 0 {
+		delete(namespace.Annotations, dtwebhook.InjectionRoutedInstancesAnnotation)
+		return
+	}
+	namespace.Annotations[dtwebhook.InjectionRoutedInstancesAnnotation] = strings.Join(routedNames, ",")
 }
 
 func isIgnoredNamespace(dk *dynatracev1beta1.DynaKube, namespaceName string) bool {
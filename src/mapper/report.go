@@ -0,0 +1,111 @@
+package mapper
+
+import (
+	"context"
+	"sort"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceMapping shows which namespaces are covered by a dynakube, the names are sorted
+type NamespaceMapping struct {
+	// Matched namespaces are labeled for the dynakube or routed to it
+	Matched []string
+	// Ignored namespaces match the namespace selector, but are excluded by the ignored-namespaces feature flag
+	Ignored []string
+	// Uninjected namespaces are matched namespaces with active pods the webhook never injected
+	Uninjected []string
+}
+
+// GetNamespaceMapping reports the current mapping of the namespaces to the dynakube,
+// it only reads the labels of the namespaces and doesn't update them
+func (dm DynakubeMapper) GetNamespaceMapping() (NamespaceMapping, error) {
+	return dm.getNamespaceMapping(true)
+}
+
+// GetNamespaceMappingWithoutPods reports the mapping like GetNamespaceMapping, but doesn't list the pods of the matched namespaces,
+// so the uninjected namespaces are left empty
+func (dm DynakubeMapper) GetNamespaceMappingWithoutPods() (NamespaceMapping, error) {
+	return dm.getNamespaceMapping(false)
+}
+
+func (dm DynakubeMapper) getNamespaceMapping(checkPods bool) (NamespaceMapping, error) {
+	nsList := &corev1.NamespaceList{}
+	if err := dm.apiReader.List(dm.ctx, nsList); err != nil {
+		return NamespaceMapping{}, errors.WithStack(err)
+	}
+	return getNamespaceMapping(dm.ctx, dm.apiReader, dm.dk, nsList.Items, checkPods)
+}
+
+func getNamespaceMapping(ctx context.Context, apiReader client.Reader, dk *dynatracev1beta1.DynaKube, namespaces []corev1.Namespace, checkPods bool) (NamespaceMapping, error) {
+	mapping := NamespaceMapping{}
+	podSelector, err := dk.InjectionPodSelector()
+	if err != nil {
+		return mapping, err
+	}
+
+	for i := range namespaces {
+		namespace := &namespaces[i]
+		if namespace.Labels[dtwebhook.InjectionInstanceLabel] == dk.Name || dtwebhook.IsRoutedDynakube(*namespace, dk.Name) {
+			mapping.Matched = append(mapping.Matched, namespace.Name)
+			if !checkPods {
+				continue
+			}
+			uninjected, err := hasUninjectedPods(ctx, apiReader, namespace.Name, podSelector)
+			if err != nil {
+				return mapping, err
+			}
+			if uninjected {
+				mapping.Uninjected = append(mapping.Uninjected, namespace.Name)
+			}
+			continue
+		}
+		if !isIgnoredNamespace(dk, namespace.Name) {
+			continue
+		}
+		matches, err := match(dk, namespace)
+		if err != nil {
+			return mapping, err
+		}
+		if matches {
+			mapping.Ignored = append(mapping.Ignored, namespace.Name)
+		}
+	}
+
+	sort.Strings(mapping.Matched)
+	sort.Strings(mapping.Ignored)
+	sort.Strings(mapping.Uninjected)
+	return mapping, nil
+}
+
+// hasUninjectedPods lists the pods of the namespace, the pod selector of the dynakube is applied by the api server
+func hasUninjectedPods(ctx context.Context, apiReader client.Reader, namespaceName string, podSelector labels.Selector) (bool, error) {
+	listOptions := []client.ListOption{client.InNamespace(namespaceName)}
+	if podSelector != nil {
+		listOptions = append(listOptions, client.MatchingLabelsSelector{Selector: podSelector})
+	}
+	podList := &corev1.PodList{}
+	if err := apiReader.List(ctx, podList, listOptions...); err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, pod := range podList.Items {
+		if dtwebhook.IsInjectionMissing(pod) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Status converts the mapping for the status of the dynakube
+func (mapping NamespaceMapping) Status() dynatracev1beta1.InjectionStatus {
+	return dynatracev1beta1.InjectionStatus{
+		MatchedNamespaces:    dynatracev1beta1.NewNamespaceListStatus(mapping.Matched),
+		IgnoredNamespaces:    dynatracev1beta1.NewNamespaceListStatus(mapping.Ignored),
+		UninjectedNamespaces: dynatracev1beta1.NewNamespaceListStatus(mapping.Uninjected),
+	}
+}
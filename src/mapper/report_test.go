@@ -0,0 +1,88 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNamespaceMapping(t *testing.T) {
+	dk := createTestDynakubeWithAppInject("dk-test", nil, nil)
+
+	t.Run(`matched, ignored and uninjected namespaces`, func(t *testing.T) {
+		clt := fake.NewClient(
+			createNamespace("injected", map[string]string{dtwebhook.InjectionInstanceLabel: dk.Name}),
+			createNamespace("uninjected", map[string]string{dtwebhook.InjectionInstanceLabel: dk.Name}),
+			createNamespace("other", map[string]string{dtwebhook.InjectionInstanceLabel: "other-dk"}),
+			createNamespace("kube-system", nil),
+			createPod("injected", map[string]string{dtwebhook.AnnotationDynatraceInjected: "true"}, nil),
+			createPod("uninjected", nil, nil),
+		)
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", dk)
+
+		mapping, err := dm.GetNamespaceMapping()
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"injected", "uninjected"}, mapping.Matched)
+		assert.Equal(t, []string{"kube-system"}, mapping.Ignored)
+		assert.Equal(t, []string{"uninjected"}, mapping.Uninjected)
+	})
+	t.Run(`routed namespace only reports pods of the pod selector`, func(t *testing.T) {
+		routedDk := createTestDynakubeWithAppInject("dk-routed", nil, nil)
+		routedDk.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary"}
+		namespace := createNamespace("shared", map[string]string{dtwebhook.InjectionInstanceLabel: dk.Name})
+		namespace.Annotations = map[string]string{dtwebhook.InjectionRoutedInstancesAnnotation: routedDk.Name}
+		clt := fake.NewClient(namespace, createPod("shared", nil, map[string]string{"track": "stable"}))
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", routedDk)
+
+		mapping, err := dm.GetNamespaceMapping()
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shared"}, mapping.Matched)
+		assert.Empty(t, mapping.Uninjected)
+	})
+	t.Run(`mapping without pods doesn't report uninjected namespaces`, func(t *testing.T) {
+		clt := fake.NewClient(
+			createNamespace("uninjected", map[string]string{dtwebhook.InjectionInstanceLabel: dk.Name}),
+			createPod("uninjected", nil, nil),
+		)
+		dm := NewDynakubeMapper(context.TODO(), clt, clt, "dynatrace", dk)
+
+		mapping, err := dm.GetNamespaceMappingWithoutPods()
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"uninjected"}, mapping.Matched)
+		assert.Empty(t, mapping.Uninjected)
+	})
+	t.Run(`status is truncated`, func(t *testing.T) {
+		mapping := NamespaceMapping{}
+		for i := 0; i < dynatracev1beta1.MaxNamespaceListStatusNames+5; i++ {
+			mapping.Matched = append(mapping.Matched, fmt.Sprintf("namespace-%d", i))
+		}
+
+		status := mapping.Status()
+
+		assert.Equal(t, dynatracev1beta1.MaxNamespaceListStatusNames+5, status.MatchedNamespaces.Count)
+		assert.Len(t, status.MatchedNamespaces.Names, dynatracev1beta1.MaxNamespaceListStatusNames)
+		assert.Equal(t, 0, status.IgnoredNamespaces.Count)
+	})
+}
+
+func createPod(namespace string, annotations map[string]string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Namespace:   namespace,
+			Annotations: annotations,
+			Labels:      labels,
+		},
+	}
+}
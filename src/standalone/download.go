@@ -0,0 +1,102 @@
+package standalone
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/installer"
+	"github.com/pkg/errors"
+)
+
+var (
+	initialDownloadBackoff = time.Second
+	maxDownloadBackoff     = 30 * time.Second
+)
+
+// downloadEndpoint is an api url the OneAgent can be downloaded from, with the client and installer using it
+type downloadEndpoint struct {
+	apiUrl    string
+	dtclient  dtclient.Client
+	installer installer.Installer
+}
+
+type downloadAttempt struct {
+	Endpoint string `json:"endpoint"`
+	Error    string `json:"error,omitempty"`
+}
+
+// downloadResult is written to the shared volume, so it can be seen which endpoint the OneAgent was downloaded from
+type downloadResult struct {
+	Success  bool              `json:"success"`
+	Endpoint string            `json:"endpoint,omitempty"`
+	Attempts []downloadAttempt `json:"attempts"`
+	Duration string            `json:"duration"`
+}
+
+// downloadOneAgent tries the endpoints in order until one of them succeeds,
+// the whole list is retried with an exponential backoff until the download deadline is reached
+func (runner *Runner) downloadOneAgent(endpoints []downloadEndpoint, installerPackage installerPackage) error {
+	start := time.Now()
+	deadline := start.Add(time.Duration(runner.config.DownloadDeadline) * time.Second)
+	backoff := initialDownloadBackoff
+	result := downloadResult{Attempts: []downloadAttempt{}}
+
+	var err error
+	for {
+		for _, endpoint := range endpoints {
+			err = installFromEndpoint(endpoint, installerPackage.getTargetDir())
+			if err == nil {
+				result.Attempts = append(result.Attempts, downloadAttempt{Endpoint: endpoint.apiUrl})
+				result.Success = true
+				result.Endpoint = endpoint.apiUrl
+				result.Duration = time.Since(start).String()
+				if installerPackage.containerFlavor == "" {
+					runner.report.Decisions.DownloadEndpoint = endpoint.apiUrl
+				}
+				return runner.createDownloadResultFile(result, installerPackage.getDownloadResultFileName())
+			}
+			log.Info("failed to download OneAgent", "endpoint", endpoint.apiUrl, "error", err.Error())
+			result.Attempts = append(result.Attempts, downloadAttempt{Endpoint: endpoint.apiUrl, Error: err.Error()})
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			break
+		}
+		log.Info("retrying OneAgent download", "backoff", backoff.String())
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxDownloadBackoff {
+			backoff = maxDownloadBackoff
+		}
+	}
+
+	result.Duration = time.Since(start).String()
+	if resultErr := runner.createDownloadResultFile(result, installerPackage.getDownloadResultFileName()); resultErr != nil {
+		log.Info("failed to create download result file", "error", resultErr.Error())
+	}
+	return errors.WithMessagef(err, "failed to download OneAgent from %d endpoints after %d attempts", len(endpoints), len(result.Attempts))
+}
+
+func installFromEndpoint(endpoint downloadEndpoint, targetDir string) error {
+	log.Info("downloading OneAgent", "endpoint", endpoint.apiUrl, "targetDir", targetDir)
+	_, err := endpoint.installer.InstallAgent(targetDir)
+	if err != nil {
+		return err
+	}
+	processModuleConfig, err := endpoint.dtclient.GetProcessModuleConfig(0)
+	if err != nil {
+		return err
+	}
+	return endpoint.installer.UpdateProcessModuleConfig(targetDir, processModuleConfig)
+}
+
+func (runner *Runner) createDownloadResultFile(result downloadResult, fileName string) error {
+	content, err := json.Marshal(result)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(runner.createConfFile(filepath.Join(config.AgentShareDirMount, fileName), string(content)))
+}
@@ -6,21 +6,34 @@ import (
 
 type dtclientBuilder struct {
 	config  *SecretConfig
+	apiUrl  string
 	options []dtclient.Option
+
+	trustActiveGateCert bool
 }
 
 func newDTClientBuilder(config *SecretConfig) *dtclientBuilder {
 	return &dtclientBuilder{
 		config:  config,
+		apiUrl:  config.ApiUrl,
 		options: []dtclient.Option{},
 	}
 }
 
+// newFallbackDTClientBuilder creates the builder for a fallback api url,
+// as the fallback can be the ActiveGate of the dynakube its certificate is trusted as well
+func newFallbackDTClientBuilder(config *SecretConfig, apiUrl string) *dtclientBuilder {
+	builder := newDTClientBuilder(config)
+	builder.apiUrl = apiUrl
+	builder.trustActiveGateCert = true
+	return builder
+}
+
 func (builder *dtclientBuilder) createClient() (dtclient.Client, error) {
 	log.Info("creating dtclient")
 	builder.setOptions()
 	client, err := dtclient.NewClient(
-		builder.config.ApiUrl,
+		builder.apiUrl,
 		builder.config.ApiToken,
 		builder.config.PaasToken,
 		builder.options...,
@@ -60,8 +73,15 @@ func (builder *dtclientBuilder) addNetworkZone() {
 }
 
 func (builder *dtclientBuilder) addTrustedCerts() {
-	if builder.config.TrustedCAs != "" {
+	trustedCerts := builder.config.TrustedCAs
+	if trustedCerts != "" {
 		log.Info("using TrustedCAs, check the secret for more details")
-		builder.options = append(builder.options, dtclient.Certs([]byte(builder.config.TrustedCAs)))
+	}
+	if builder.trustActiveGateCert && builder.config.TlsCert != "" {
+		log.Info("trusting the ActiveGate certificate")
+		trustedCerts += "\n" + builder.config.TlsCert
+	}
+	if trustedCerts != "" {
+		builder.options = append(builder.options, dtclient.Certs([]byte(trustedCerts)))
 	}
 }
@@ -33,6 +33,20 @@ func TestCreateClient(t *testing.T) {
 		assert.Len(t, builder.options, 3)
 
 	})
+
+	t.Run(`fallback api url`, func(t *testing.T) {
+		config := basicTestSecretConfigForClient()
+		config.TlsCert = testTlsCert
+		builder := newFallbackDTClientBuilder(config, testFallbackApiUrl)
+
+		client, err := builder.createClient()
+
+		require.NoError(t, err)
+		require.NotNil(t, client)
+
+		assert.Equal(t, testFallbackApiUrl, builder.apiUrl)
+		assert.Len(t, builder.options, 1)
+	})
 }
 
 func basicTestSecretConfigForClient() *SecretConfig {
@@ -1,7 +1,9 @@
 package standalone
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +16,10 @@ import (
 type containerInfo struct {
 	Name  string `json:"name"`
 	Image string `json:"image"`
+
+	// Flavor and Technologies are only set, if the container doesn't use the ones of the pod
+	Flavor       string   `json:"flavor,omitempty"`
+	Technologies []string `json:"technologies,omitempty"`
 }
 
 type environment struct {
@@ -36,8 +42,12 @@ type environment struct {
 	WorkloadKind string `json:"workloadKind"`
 	WorkloadName string `json:"workloadName"`
 
+	MetadataAttributes map[string]string `json:"metadataAttributes"`
+
 	OneAgentInjected   bool `json:"oneAgentInjected"`
 	DataIngestInjected bool `json:"dataIngestInjected"`
+
+	InjectionReportAnnotation bool `json:"injectionReportAnnotation"`
 }
 
 func newEnv() (*environment, error) {
@@ -66,6 +76,10 @@ func (env *environment) setRequiredFields() error {
 		requiredFieldSetters = append(requiredFieldSetters, env.getDataIngestFieldSetters()...)
 	}
 
+	if env.InjectionReportAnnotation && !env.OneAgentInjected {
+		requiredFieldSetters = append(requiredFieldSetters, env.getInjectionReportFieldSetters()...)
+	}
+
 	for _, setField := range requiredFieldSetters {
 		if err := setField(); err != nil {
 			errs = append(errs, err)
@@ -100,14 +114,29 @@ func (env *environment) getDataIngestFieldSetters() []func() error {
 	}
 }
 
+// getInjectionReportFieldSetters returns the setters for the fields needed to annotate the pod,
+// they are already part of the OneAgent fields
+func (env *environment) getInjectionReportFieldSetters() []func() error {
+	return []func() error{
+		env.addK8PodName,
+		env.addK8Namespace,
+	}
+}
+
 func (env *environment) setOptionalFields() {
 	env.addInstallerUrl()
 	env.addInstallerFlavor()
+	env.addMetadataAttributes()
 }
 
 func (env *environment) setMutationTypeFields() {
 	env.addOneAgentInjected()
 	env.addDataIngestInjected()
+	env.addInjectionReportAnnotation()
+}
+
+func (env *environment) isOfflineMode() bool {
+	return env.Mode == config.AgentOfflineMode
 }
 
 func (env *environment) addMode() error {
@@ -142,7 +171,7 @@ func (env *environment) addInstallerTech() error {
 	if err != nil {
 		return err
 	}
-	env.InstallerTech = strings.Split(technologies, ",")
+	env.InstallerTech = parseTechnologies(technologies)
 	return nil
 }
 
@@ -177,9 +206,13 @@ func (env *environment) addContainers() error {
 		if err != nil {
 			return err
 		}
+		containerFlavor, _ := checkEnvVar(fmt.Sprintf(config.AgentContainerFlavorEnvTemplate, i))
+		containerTechnologies, _ := checkEnvVar(fmt.Sprintf(config.AgentContainerTechnologiesEnvTemplate, i))
 		containers = append(containers, containerInfo{
-			Name:  containerName,
-			Image: imageName,
+			Name:         containerName,
+			Image:        imageName,
+			Flavor:       containerFlavor,
+			Technologies: parseTechnologies(containerTechnologies),
 		})
 	}
 	env.Containers = containers
@@ -273,6 +306,22 @@ func (env *environment) addDataIngestInjected() {
 	env.DataIngestInjected = dataIngestInjected == "true"
 }
 
+func (env *environment) addInjectionReportAnnotation() {
+	injectionReportAnnotation, _ := checkEnvVar(config.InjectionReportAnnotationEnv)
+	env.InjectionReportAnnotation = injectionReportAnnotation == "true"
+}
+
+// parseTechnologies splits the technologies, which are query escaped by the webhook
+func parseTechnologies(rawTechnologies string) []string {
+	if rawTechnologies == "" {
+		return nil
+	}
+	if unescaped, err := url.QueryUnescape(rawTechnologies); err == nil {
+		rawTechnologies = unescaped
+	}
+	return strings.Split(rawTechnologies, ",")
+}
+
 func checkEnvVar(envvar string) (string, error) {
 	result := os.Getenv(envvar)
 	if result == "" {
@@ -280,3 +329,16 @@ func checkEnvVar(envvar string) (string, error) {
 	}
 	return result, nil
 }
+
+func (env *environment) addMetadataAttributes() {
+	rawAttributes, _ := checkEnvVar(config.EnrichmentMetadataEnv)
+	if rawAttributes == "" {
+		return
+	}
+	attributes := map[string]string{}
+	if err := json.Unmarshal([]byte(rawAttributes), &attributes); err != nil {
+		log.Info("ignoring invalid metadata attributes", "error", err.Error())
+		return
+	}
+	env.MetadataAttributes = attributes
+}
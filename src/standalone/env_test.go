@@ -97,6 +97,73 @@ func TestNewEnv(t *testing.T) {
 
 		assert.True(t, env.OneAgentInjected)
 		assert.False(t, env.DataIngestInjected)
+		assert.False(t, env.InjectionReportAnnotation)
+	})
+	t.Run(`injection report annotation needs the pod for only data-ingest injection`, func(t *testing.T) {
+		resetEnv := prepDataIngestTestEnv(t)
+		defer resetEnv()
+		t.Setenv(config.InjectionReportAnnotationEnv, "true")
+
+		_, err := newEnv()
+		require.Error(t, err)
+
+		t.Setenv(config.K8sPodNameEnv, "pod")
+		t.Setenv(config.K8sNamespaceEnv, "namespace")
+
+		env, err := newEnv()
+		require.NoError(t, err)
+		assert.True(t, env.InjectionReportAnnotation)
+		assert.Equal(t, "pod", env.K8PodName)
+		assert.Equal(t, "namespace", env.K8Namespace)
+	})
+}
+
+func TestContainerInstallerFields(t *testing.T) {
+	t.Run(`flavor and technologies of a container`, func(t *testing.T) {
+		resetEnv := prepOneAgentTestEnv(t)
+		defer resetEnv()
+		t.Setenv(fmt.Sprintf(config.AgentContainerFlavorEnvTemplate, 2), "musl")
+		t.Setenv(fmt.Sprintf(config.AgentContainerTechnologiesEnvTemplate, 2), "java%2Cnodejs")
+
+		env, err := newEnv()
+
+		require.NoError(t, err)
+		assert.Empty(t, env.Containers[0].Flavor)
+		assert.Empty(t, env.Containers[0].Technologies)
+		assert.Equal(t, "musl", env.Containers[1].Flavor)
+		assert.Equal(t, []string{"java", "nodejs"}, env.Containers[1].Technologies)
+	})
+	t.Run(`parse technologies`, func(t *testing.T) {
+		assert.Nil(t, parseTechnologies(""))
+		assert.Equal(t, []string{"all"}, parseTechnologies("all"))
+		assert.Equal(t, []string{"java", "php"}, parseTechnologies("java,php"))
+		assert.Equal(t, []string{"java", "php"}, parseTechnologies("java%2Cphp"))
+	})
+}
+
+func TestMetadataAttributes(t *testing.T) {
+	t.Run(`not set`, func(t *testing.T) {
+		env := &environment{}
+
+		env.addMetadataAttributes()
+
+		assert.Nil(t, env.MetadataAttributes)
+	})
+	t.Run(`valid attributes`, func(t *testing.T) {
+		t.Setenv(config.EnrichmentMetadataEnv, `{"k8s.pod.label.team":"checkout"}`)
+		env := &environment{}
+
+		env.addMetadataAttributes()
+
+		assert.Equal(t, map[string]string{"k8s.pod.label.team": "checkout"}, env.MetadataAttributes)
+	})
+	t.Run(`invalid attributes are ignored`, func(t *testing.T) {
+		t.Setenv(config.EnrichmentMetadataEnv, `not-json`)
+		env := &environment{}
+
+		env.addMetadataAttributes()
+
+		assert.Nil(t, env.MetadataAttributes)
 	})
 }
 
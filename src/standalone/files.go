@@ -1,9 +1,12 @@
 package standalone
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Dynatrace/dynatrace-operator/src/config"
 	"github.com/pkg/errors"
@@ -49,6 +52,9 @@ dt.kubernetes.cluster.id=%s
 
 	curlOptionsFormatString = `initialConnectRetryMs %d
 `
+
+	containerTagsFormatString = `tags %s
+`
 )
 
 func (runner *Runner) getBaseConfContent(container containerInfo) string {
@@ -60,7 +66,20 @@ func (runner *Runner) getBaseConfContent(container containerInfo) string {
 		container.Name,
 		runner.env.K8BasePodName,
 		runner.env.K8Namespace,
-	)
+	) + runner.getContainerTagsContent()
+}
+
+// getContainerTagsContent adds the metadata attributes as custom tags, the tags are separated by whitespaces
+// so it is replaced in the keys and values
+func (runner *Runner) getContainerTagsContent() string {
+	if len(runner.env.MetadataAttributes) == 0 {
+		return ""
+	}
+	tags := []string{}
+	for _, key := range sortedMetadataKeys(runner.env.MetadataAttributes) {
+		tags = append(tags, fmt.Sprintf("%s=%s", sanitizeTag(key), sanitizeTag(runner.env.MetadataAttributes[key])))
+	}
+	return fmt.Sprintf(containerTagsFormatString, strings.Join(tags, " "))
 }
 
 func (runner *Runner) getK8ConfContent() string {
@@ -89,6 +108,13 @@ func (runner *Runner) createJsonEnrichmentFile() error {
 		runner.env.WorkloadName,
 		runner.env.K8ClusterID,
 	)
+	jsonContent = strings.TrimSuffix(jsonContent, "\n")
+	for _, key := range sortedMetadataKeys(runner.env.MetadataAttributes) {
+		jsonKey, _ := json.Marshal(key)
+		jsonValue, _ := json.Marshal(runner.env.MetadataAttributes[key])
+		jsonContent += fmt.Sprintf(",\n%s: %s", jsonKey, jsonValue)
+	}
+	jsonContent += "\n"
 	jsonPath := filepath.Join(config.EnrichmentMountPath, fmt.Sprintf(config.EnrichmentFilenameTemplate, "json"))
 
 	return errors.WithStack(runner.createConfFile(jsonPath, jsonContent))
@@ -104,6 +130,9 @@ func (runner *Runner) createPropsEnrichmentFile() error {
 		runner.env.WorkloadName,
 		runner.env.K8ClusterID,
 	)
+	for _, key := range sortedMetadataKeys(runner.env.MetadataAttributes) {
+		propsContent += fmt.Sprintf("%s=%s\n", key, sanitizeProperty(runner.env.MetadataAttributes[key]))
+	}
 	propsPath := filepath.Join(config.EnrichmentMountPath, fmt.Sprintf(config.EnrichmentFilenameTemplate, "properties"))
 
 	return errors.WithStack(runner.createConfFile(propsPath, propsContent))
@@ -133,5 +162,23 @@ func (runner *Runner) createConfFile(path string, content string) error {
 	}
 
 	log.Info("created file", "filePath", path, "content", content)
+	runner.report.Files = append(runner.report.Files, path)
 	return nil
 }
+
+func sortedMetadataKeys(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sanitizeTag(value string) string {
+	return strings.Join(strings.Fields(value), "_")
+}
+
+func sanitizeProperty(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
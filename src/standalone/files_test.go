@@ -68,3 +68,24 @@ func TestCurlOptions(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, runner.getCurlOptionsContent(), string(content))
 }
+
+func TestContainerTags(t *testing.T) {
+	t.Run(`no metadata attributes`, func(t *testing.T) {
+		runner := Runner{env: &environment{}}
+
+		assert.Empty(t, runner.getContainerTagsContent())
+	})
+	t.Run(`metadata attributes are sorted and sanitized`, func(t *testing.T) {
+		runner := Runner{
+			env: &environment{
+				MetadataAttributes: map[string]string{
+					"k8s.pod.label.team":        "check out",
+					"k8s.namespace.label.owner": "sre",
+				},
+			},
+		}
+
+		assert.Equal(t, "tags k8s.namespace.label.owner=sre k8s.pod.label.team=check_out\n", runner.getContainerTagsContent())
+		assert.Contains(t, runner.getBaseConfContent(containerInfo{Name: "app"}), runner.getContainerTagsContent())
+	})
+}
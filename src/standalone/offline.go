@@ -0,0 +1,59 @@
+package standalone
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/offline"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+var offlinePackagePath = filepath.Join(config.AgentOfflineDirMount, config.AgentOfflinePackageFilename)
+
+func newOfflineInstaller(fs afero.Fs, secretConfig *SecretConfig) *offline.OfflineInstaller {
+	return offline.NewOfflineInstaller(
+		fs,
+		&offline.Properties{
+			PackagePath: offlinePackagePath,
+			Checksum:    secretConfig.OfflineChecksum,
+		},
+	)
+}
+
+// installOneAgentOffline installs the OneAgent from the pre-seeded package without reaching the tenant,
+// the process module config is the snapshot the operator added to the init secret
+func (runner *Runner) installOneAgentOffline() error {
+	log.Info("installing OneAgent from the offline package", "package", offlinePackagePath)
+	start := time.Now()
+	err := runner.installFromOfflinePackage()
+
+	attempt := downloadAttempt{Endpoint: offlinePackagePath}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	result := downloadResult{
+		Success:  err == nil,
+		Attempts: []downloadAttempt{attempt},
+		Duration: time.Since(start).String(),
+	}
+	if result.Success {
+		result.Endpoint = offlinePackagePath
+		runner.report.Decisions.DownloadEndpoint = offlinePackagePath
+	}
+	if resultErr := runner.createDownloadResultFile(result, config.AgentDownloadResultFileName); resultErr != nil {
+		log.Info("failed to create download result file", "error", resultErr.Error())
+	}
+	return err
+}
+
+func (runner *Runner) installFromOfflinePackage() error {
+	if runner.config.ProcessModuleConfig == nil {
+		return errors.New("process module config snapshot is missing in the init secret")
+	}
+	if _, err := runner.installer.InstallAgent(config.AgentBinDirMount); err != nil {
+		return err
+	}
+	return runner.installer.UpdateProcessModuleConfig(config.AgentBinDirMount, runner.config.ProcessModuleConfig)
+}
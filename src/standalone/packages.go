@@ -0,0 +1,102 @@
+package standalone
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+)
+
+const allTechnologies = "all"
+
+// installerPackage is a OneAgent package the injected containers need. The flavor of the pod is installed into the bin directory,
+// the flavors of single containers into a sub directory of it, which is mounted into the containers by the webhook
+type installerPackage struct {
+	// containerFlavor is empty for the flavor of the pod
+	containerFlavor string
+	technologies    []string
+}
+
+func (installerPackage installerPackage) getFlavor(env *environment) string {
+	if installerPackage.containerFlavor == "" {
+		return env.InstallerFlavor
+	}
+	return installerPackage.containerFlavor
+}
+
+func (installerPackage installerPackage) getTargetDir() string {
+	if installerPackage.containerFlavor == "" {
+		return config.AgentBinDirMount
+	}
+	return filepath.Join(config.AgentBinDirMount, fmt.Sprintf(config.AgentFlavorSubPathTemplate, installerPackage.containerFlavor))
+}
+
+func (installerPackage installerPackage) getDownloadResultFileName() string {
+	if installerPackage.containerFlavor == "" {
+		return config.AgentDownloadResultFileName
+	}
+	return fmt.Sprintf(config.AgentFlavorDownloadResultFileNameTemplate, installerPackage.containerFlavor)
+}
+
+// containerFlavorInstallation is the download of a flavor, which single containers need in addition to the one of the pod
+type containerFlavorInstallation struct {
+	installerPackage installerPackage
+	endpoints        []downloadEndpoint
+}
+
+// getInstallerPackages returns a package for every flavor the containers need, with the technologies of all containers using it.
+// The package of the pod is skipped, if every container has its own flavor.
+func (env *environment) getInstallerPackages() []installerPackage {
+	podTechnologies := [][]string{}
+	containerFlavorTechnologies := map[string][][]string{}
+	for _, container := range env.Containers {
+		technologies := container.Technologies
+		if len(technologies) == 0 {
+			technologies = env.InstallerTech
+		}
+		if container.Flavor == "" {
+			podTechnologies = append(podTechnologies, technologies)
+		} else {
+			containerFlavorTechnologies[container.Flavor] = append(containerFlavorTechnologies[container.Flavor], technologies)
+		}
+	}
+
+	installerPackages := []installerPackage{}
+	if len(podTechnologies) > 0 {
+		installerPackages = append(installerPackages, installerPackage{technologies: unionTechnologies(podTechnologies)})
+	} else if len(containerFlavorTechnologies) == 0 {
+		installerPackages = append(installerPackages, installerPackage{technologies: env.InstallerTech})
+	}
+
+	containerFlavors := make([]string, 0, len(containerFlavorTechnologies))
+	for flavor := range containerFlavorTechnologies {
+		containerFlavors = append(containerFlavors, flavor)
+	}
+	sort.Strings(containerFlavors)
+	for _, flavor := range containerFlavors {
+		installerPackages = append(installerPackages, installerPackage{
+			containerFlavor: flavor,
+			technologies:    unionTechnologies(containerFlavorTechnologies[flavor]),
+		})
+	}
+	return installerPackages
+}
+
+// unionTechnologies returns every technology once, "all" already contains the others
+func unionTechnologies(technologiesOfContainers [][]string) []string {
+	union := []string{}
+	added := map[string]bool{}
+	for _, technologies := range technologiesOfContainers {
+		for _, technology := range technologies {
+			if technology == allTechnologies {
+				return []string{allTechnologies}
+			}
+			if !added[technology] {
+				added[technology] = true
+				union = append(union, technology)
+			}
+		}
+	}
+	return union
+}
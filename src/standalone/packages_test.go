@@ -0,0 +1,94 @@
+package standalone
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/arch"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInstallerPackages(t *testing.T) {
+	t.Run(`one package for the pod`, func(t *testing.T) {
+		env := &environment{
+			InstallerTech: []string{"java"},
+			Containers:    []containerInfo{{Name: "app"}, {Name: "sidecar"}},
+		}
+
+		assert.Equal(t, []installerPackage{{technologies: []string{"java"}}}, env.getInstallerPackages())
+	})
+	t.Run(`package for the pod without containers`, func(t *testing.T) {
+		env := &environment{InstallerTech: []string{"all"}}
+
+		assert.Equal(t, []installerPackage{{technologies: []string{"all"}}}, env.getInstallerPackages())
+	})
+	t.Run(`union of the technologies of the containers`, func(t *testing.T) {
+		env := &environment{
+			InstallerTech: []string{"all"},
+			Containers: []containerInfo{
+				{Name: "app", Technologies: []string{"java"}},
+				{Name: "sidecar", Technologies: []string{"nodejs", "java"}},
+			},
+		}
+
+		assert.Equal(t, []installerPackage{{technologies: []string{"java", "nodejs"}}}, env.getInstallerPackages())
+	})
+	t.Run(`all technologies contain the others`, func(t *testing.T) {
+		env := &environment{
+			InstallerTech: []string{"all"},
+			Containers: []containerInfo{
+				{Name: "app", Technologies: []string{"java"}},
+				{Name: "sidecar"},
+			},
+		}
+
+		assert.Equal(t, []installerPackage{{technologies: []string{"all"}}}, env.getInstallerPackages())
+	})
+	t.Run(`package for the flavor of a container`, func(t *testing.T) {
+		env := &environment{
+			InstallerTech: []string{"all"},
+			Containers: []containerInfo{
+				{Name: "app", Technologies: []string{"java"}},
+				{Name: "sidecar", Flavor: arch.FlavorMusl, Technologies: []string{"nodejs"}},
+			},
+		}
+
+		assert.Equal(t, []installerPackage{
+			{technologies: []string{"java"}},
+			{containerFlavor: arch.FlavorMusl, technologies: []string{"nodejs"}},
+		}, env.getInstallerPackages())
+	})
+	t.Run(`no package for the pod if every container has its own flavor`, func(t *testing.T) {
+		env := &environment{
+			InstallerTech: []string{"all"},
+			Containers: []containerInfo{
+				{Name: "app", Flavor: arch.FlavorMusl, Technologies: []string{"java"}},
+				{Name: "sidecar", Flavor: arch.FlavorDefault, Technologies: []string{"nodejs"}},
+			},
+		}
+
+		assert.Equal(t, []installerPackage{
+			{containerFlavor: arch.FlavorDefault, technologies: []string{"nodejs"}},
+			{containerFlavor: arch.FlavorMusl, technologies: []string{"java"}},
+		}, env.getInstallerPackages())
+	})
+}
+
+func TestInstallerPackage(t *testing.T) {
+	env := &environment{InstallerFlavor: arch.FlavorMultidistro}
+	t.Run(`package of the pod`, func(t *testing.T) {
+		podPackage := installerPackage{}
+
+		assert.Equal(t, arch.FlavorMultidistro, podPackage.getFlavor(env))
+		assert.Equal(t, config.AgentBinDirMount, podPackage.getTargetDir())
+		assert.Equal(t, config.AgentDownloadResultFileName, podPackage.getDownloadResultFileName())
+	})
+	t.Run(`package of a container flavor`, func(t *testing.T) {
+		containerPackage := installerPackage{containerFlavor: arch.FlavorMusl}
+
+		assert.Equal(t, arch.FlavorMusl, containerPackage.getFlavor(env))
+		assert.Equal(t, filepath.Join(config.AgentBinDirMount, "flavors", arch.FlavorMusl), containerPackage.getTargetDir())
+		assert.Equal(t, "download_result_musl.json", containerPackage.getDownloadResultFileName())
+	})
+}
@@ -0,0 +1,148 @@
+package standalone
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/symlink"
+	"github.com/Dynatrace/dynatrace-operator/src/version"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// injectionReport describes what the init container configured, so it can be checked without its logs
+type injectionReport struct {
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+	ErrorMasked bool   `json:"errorMasked,omitempty"`
+
+	OperatorVersion string `json:"operatorVersion"`
+	OneAgentVersion string `json:"oneAgentVersion,omitempty"`
+
+	Mode               config.InstallMode `json:"mode,omitempty"`
+	OneAgentInjected   bool               `json:"oneAgentInjected"`
+	DataIngestInjected bool               `json:"dataIngestInjected"`
+
+	Decisions  injectionDecisions `json:"decisions"`
+	Containers []containerReport  `json:"containers"`
+	Files      []string           `json:"files"`
+	Duration   string             `json:"duration"`
+}
+
+type injectionDecisions struct {
+	DownloadEndpoint  string `json:"downloadEndpoint,omitempty"`
+	HostTenant        string `json:"hostTenant,omitempty"`
+	TlsCertPropagated bool   `json:"tlsCertPropagated"`
+	CurlOptions       bool   `json:"curlOptions"`
+	Enrichment        bool   `json:"enrichment"`
+}
+
+type containerReport struct {
+	Name       string `json:"name"`
+	Image      string `json:"image"`
+	Flavor     string `json:"flavor,omitempty"`
+	ConfFile   string `json:"confFile"`
+	K8sFields  bool   `json:"k8sFields"`
+	HostTenant bool   `json:"hostTenant"`
+}
+
+// newPodClient creates the client to annotate the pod with the injection report,
+// without it the report is only written to the shared volume
+func newPodClient() kubernetes.Interface {
+	kubeConfig, err := rest.InClusterConfig()
+	if err != nil {
+		log.Info("injection report annotation isn't possible", "error", err.Error())
+		return nil
+	}
+	clientSet, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		log.Info("injection report annotation isn't possible", "error", err.Error())
+		return nil
+	}
+	return clientSet
+}
+
+// reportInjection writes the injection report and adds it to the pod if enabled,
+// failures are only logged, so the report doesn't change the outcome of the injection
+func (runner *Runner) reportInjection(resultedError *error, start time.Time) {
+	runner.finishReport(*resultedError, time.Since(start))
+	content, err := json.Marshal(runner.report)
+	if err != nil {
+		log.Info("failed to create injection report", "error", err.Error())
+		return
+	}
+
+	if err := runner.createConfFile(runner.getInjectionReportPath(), string(content)); err != nil {
+		log.Info("failed to create injection report file", "error", err.Error())
+	}
+
+	if runner.env.InjectionReportAnnotation {
+		if err := runner.annotatePod(content); err != nil {
+			log.Info("failed to add injection report to pod", "error", err.Error())
+		}
+	}
+}
+
+func (runner *Runner) finishReport(err error, duration time.Duration) {
+	report := &runner.report
+	report.Success = err == nil
+	if err != nil {
+		report.Error = err.Error()
+		report.ErrorMasked = !runner.env.FailurePolicy
+	}
+	report.OperatorVersion = version.Version
+	report.Mode = runner.env.Mode
+	report.OneAgentInjected = runner.env.OneAgentInjected
+	report.DataIngestInjected = runner.env.DataIngestInjected
+	if runner.hostTenant != config.AgentNoHostTenant {
+		report.Decisions.HostTenant = runner.hostTenant
+	}
+	report.Duration = duration.String()
+
+	if runner.env.OneAgentInjected && report.Success {
+		oneAgentVersion, err := symlink.FindInstalledVersion(runner.fs, config.AgentBinDirMount)
+		if err != nil {
+			log.Info("failed to get the installed OneAgent version", "error", err.Error())
+		}
+		report.OneAgentVersion = oneAgentVersion
+	}
+	if report.Containers == nil {
+		report.Containers = []containerReport{}
+	}
+	if report.Files == nil {
+		report.Files = []string{}
+	}
+}
+
+// getInjectionReportPath returns the path in the shared volume, the enrichment volume is used if the OneAgent isn't injected
+func (runner *Runner) getInjectionReportPath() string {
+	if runner.env.OneAgentInjected {
+		return filepath.Join(config.AgentShareDirMount, config.InjectionReportFileName)
+	}
+	return filepath.Join(config.EnrichmentMountPath, config.InjectionReportFileName)
+}
+
+func (runner *Runner) annotatePod(content []byte) error {
+	if runner.podClient == nil {
+		return errors.New("no client to annotate the pod")
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				config.InjectionReportAnnotation: string(content),
+			},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = runner.podClient.CoreV1().Pods(runner.env.K8Namespace).
+		Patch(context.TODO(), runner.env.K8PodName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return errors.WithStack(err)
+}
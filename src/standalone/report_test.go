@@ -0,0 +1,123 @@
+package standalone
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/version"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testAgentVersion = "1.239.14.20220325-164521"
+
+func TestReportInjection(t *testing.T) {
+	t.Run(`report of successful injection`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.HasHost = false
+		runner.env.Mode = config.AgentCsiMode
+		require.NoError(t, runner.fs.MkdirAll(filepath.Join(config.AgentBinDirMount, "agent/bin", testAgentVersion), 0755))
+
+		err := runner.Run()
+
+		require.NoError(t, err)
+		report := readInjectionReport(t, runner.fs, filepath.Join(config.AgentShareDirMount, config.InjectionReportFileName))
+		assert.True(t, report.Success)
+		assert.Empty(t, report.Error)
+		assert.Equal(t, version.Version, report.OperatorVersion)
+		assert.Equal(t, testAgentVersion, report.OneAgentVersion)
+		assert.Equal(t, config.AgentCsiMode, report.Mode)
+		assert.True(t, report.OneAgentInjected)
+		assert.True(t, report.DataIngestInjected)
+		assert.Empty(t, report.Decisions.HostTenant)
+		assert.True(t, report.Decisions.TlsCertPropagated)
+		assert.True(t, report.Decisions.CurlOptions)
+		assert.True(t, report.Decisions.Enrichment)
+		require.Len(t, report.Containers, len(runner.env.Containers))
+		assert.Equal(t, runner.env.Containers[0].Name, report.Containers[0].Name)
+		assert.False(t, report.Containers[0].HostTenant)
+		assert.Contains(t, report.Files, report.Containers[0].ConfFile)
+		assert.Contains(t, report.Files, filepath.Join(config.AgentShareDirMount, config.LdPreloadFilename))
+	})
+	t.Run(`report contains masked error`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.HasHost = true
+		runner.env.FailurePolicy = false
+
+		err := runner.Run()
+
+		require.NoError(t, err)
+		report := readInjectionReport(t, runner.fs, filepath.Join(config.AgentShareDirMount, config.InjectionReportFileName))
+		assert.False(t, report.Success)
+		assert.Contains(t, report.Error, "host tenant info is missing")
+		assert.True(t, report.ErrorMasked)
+		assert.Empty(t, report.OneAgentVersion)
+		assert.Empty(t, report.Containers)
+	})
+	t.Run(`report of only data-ingest injection`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.env.OneAgentInjected = false
+
+		err := runner.Run()
+
+		require.NoError(t, err)
+		report := readInjectionReport(t, runner.fs, filepath.Join(config.EnrichmentMountPath, config.InjectionReportFileName))
+		assert.True(t, report.Success)
+		assert.False(t, report.OneAgentInjected)
+		assert.True(t, report.Decisions.Enrichment)
+		assertIfFileNotExists(t, runner.fs, filepath.Join(config.AgentShareDirMount, config.InjectionReportFileName))
+	})
+	t.Run(`add report to pod`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.HasHost = false
+		runner.env.Mode = config.AgentCsiMode
+		runner.env.InjectionReportAnnotation = true
+		podClient := fake.NewSimpleClientset(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: runner.env.K8PodName, Namespace: runner.env.K8Namespace},
+		})
+		runner.podClient = podClient
+
+		err := runner.Run()
+
+		require.NoError(t, err)
+		pod, err := podClient.CoreV1().Pods(runner.env.K8Namespace).Get(context.TODO(), runner.env.K8PodName, metav1.GetOptions{})
+		require.NoError(t, err)
+		var report injectionReport
+		require.NoError(t, json.Unmarshal([]byte(pod.Annotations[config.InjectionReportAnnotation]), &report))
+		assert.True(t, report.Success)
+		assert.Len(t, report.Containers, len(runner.env.Containers))
+	})
+	t.Run(`failing annotation doesn't fail the injection`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.HasHost = false
+		runner.env.Mode = config.AgentCsiMode
+		runner.env.InjectionReportAnnotation = true
+		runner.podClient = fake.NewSimpleClientset()
+
+		err := runner.Run()
+
+		require.NoError(t, err)
+		assertIfFileExists(t, runner.fs, filepath.Join(config.AgentShareDirMount, config.InjectionReportFileName))
+	})
+}
+
+func readInjectionReport(t *testing.T, fs afero.Fs, path string) injectionReport {
+	content, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+
+	var report injectionReport
+	require.NoError(t, json.Unmarshal(content, &report))
+	return report
+}
@@ -3,6 +3,7 @@ package standalone
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/arch"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
@@ -11,15 +12,21 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/installer/url"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Runner struct {
-	fs         afero.Fs
-	env        *environment
-	config     *SecretConfig
-	dtclient   dtclient.Client
-	installer  installer.Installer
-	hostTenant string
+	fs                afero.Fs
+	env               *environment
+	config            *SecretConfig
+	dtclient          dtclient.Client
+	installer         installer.Installer
+	fallbackEndpoints []downloadEndpoint
+	// containerFlavorInstallations are the downloads of the flavors, single containers need in addition to the one of the pod
+	containerFlavorInstallations []containerFlavorInstallation
+	podClient                    kubernetes.Interface
+	hostTenant                   string
+	report                       injectionReport
 }
 
 func NewRunner(fs afero.Fs) (*Runner, error) {
@@ -31,45 +38,122 @@ func NewRunner(fs afero.Fs) (*Runner, error) {
 
 	var config *SecretConfig
 	var client dtclient.Client
-	var oneAgentInstaller *url.UrlInstaller
+	var oneAgentInstaller installer.Installer
+	var fallbackEndpoints []downloadEndpoint
+	var containerFlavorInstallations []containerFlavorInstallation
 	if env.OneAgentInjected {
 		config, err = newSecretConfigViaFs(fs)
 		if err != nil {
 			return nil, err
 		}
 
-		client, err = newDTClientBuilder(config).createClient()
-		if err != nil {
-			return nil, err
+		if env.isOfflineMode() {
+			oneAgentInstaller = newOfflineInstaller(fs, config)
+		} else {
+			client, err = newDTClientBuilder(config).createClient()
+			if err != nil {
+				return nil, err
+			}
+
+			oneAgentInstaller, fallbackEndpoints, containerFlavorInstallations, err = newPackageInstallations(fs, env, config, client)
+			if err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		oneAgentInstaller = url.NewUrlInstaller(
-			fs,
-			client,
-			&url.Properties{
-				Os:            dtclient.OsUnix,
-				Type:          dtclient.InstallerTypePaaS,
-				Flavor:        env.InstallerFlavor,
-				Arch:          arch.Arch,
-				Technologies:  env.InstallerTech,
-				TargetVersion: url.VersionLatest,
-				Url:           env.InstallerUrl,
-			},
-		)
+	var podClient kubernetes.Interface
+	if env.InjectionReportAnnotation {
+		podClient = newPodClient()
 	}
 	log.Info("standalone runner created successfully")
 	return &Runner{
-		fs:        fs,
-		env:       env,
-		config:    config,
-		dtclient:  client,
-		installer: oneAgentInstaller,
+		fs:                           fs,
+		env:                          env,
+		config:                       config,
+		dtclient:                     client,
+		installer:                    oneAgentInstaller,
+		fallbackEndpoints:            fallbackEndpoints,
+		containerFlavorInstallations: containerFlavorInstallations,
+		podClient:                    podClient,
 	}, nil
 }
 
+// newPackageInstallations creates the installers for the packages the containers need, the installer of the pod's flavor
+// is nil if every container has its own flavor
+func newPackageInstallations(fs afero.Fs, env *environment, config *SecretConfig, client dtclient.Client) (
+	installer.Installer, []downloadEndpoint, []containerFlavorInstallation, error) {
+	var podInstaller installer.Installer
+	var podFallbackEndpoints []downloadEndpoint
+	containerFlavorInstallations := []containerFlavorInstallation{}
+	for _, installerPackage := range env.getInstallerPackages() {
+		packageInstaller := newOneAgentInstaller(fs, env, client, installerPackage)
+		fallbackEndpoints, err := newFallbackEndpoints(fs, env, config, installerPackage)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if installerPackage.containerFlavor == "" {
+			podInstaller = packageInstaller
+			podFallbackEndpoints = fallbackEndpoints
+			continue
+		}
+		endpoints := []downloadEndpoint{{
+			apiUrl:    config.ApiUrl,
+			dtclient:  client,
+			installer: packageInstaller,
+		}}
+		containerFlavorInstallations = append(containerFlavorInstallations, containerFlavorInstallation{
+			installerPackage: installerPackage,
+			endpoints:        append(endpoints, fallbackEndpoints...),
+		})
+	}
+	return podInstaller, podFallbackEndpoints, containerFlavorInstallations, nil
+}
+
+func newOneAgentInstaller(fs afero.Fs, env *environment, client dtclient.Client, installerPackage installerPackage) *url.UrlInstaller {
+	return url.NewUrlInstaller(
+		fs,
+		client,
+		&url.Properties{
+			Os:            dtclient.OsUnix,
+			Type:          dtclient.InstallerTypePaaS,
+			Flavor:        installerPackage.getFlavor(env),
+			Arch:          arch.Arch,
+			Technologies:  installerPackage.technologies,
+			TargetVersion: url.VersionLatest,
+			Url:           env.InstallerUrl,
+		},
+	)
+}
+
+// newFallbackEndpoints creates the endpoints for the fallback api urls of the secret,
+// they aren't used if the installer url is set, as it doesn't depend on the api url
+func newFallbackEndpoints(fs afero.Fs, env *environment, config *SecretConfig, installerPackage installerPackage) ([]downloadEndpoint, error) {
+	if env.InstallerUrl != "" {
+		return nil, nil
+	}
+
+	fallbackEndpoints := []downloadEndpoint{}
+	for _, apiUrl := range config.FallbackApiUrls {
+		client, err := newFallbackDTClientBuilder(config, apiUrl).createClient()
+		if err != nil {
+			return nil, err
+		}
+		fallbackEndpoints = append(fallbackEndpoints, downloadEndpoint{
+			apiUrl:    apiUrl,
+			dtclient:  client,
+			installer: newOneAgentInstaller(fs, env, client, installerPackage),
+		})
+	}
+	return fallbackEndpoints, nil
+}
+
 func (runner *Runner) Run() (resultedError error) {
 	log.Info("standalone agent init started")
 	defer runner.consumeErrorIfNecessary(&resultedError)
+	// runs before the error is masked, so the report contains it
+	defer runner.reportInjection(&resultedError, time.Now())
 
 	if runner.env.OneAgentInjected {
 		if err := runner.setHostTenant(); err != nil {
@@ -81,6 +165,11 @@ func (runner *Runner) Run() (resultedError error) {
 				return err
 			}
 			log.Info("OneAgent download finished")
+		} else if runner.env.isOfflineMode() {
+			if err := runner.installOneAgentOffline(); err != nil {
+				return err
+			}
+			log.Info("OneAgent offline installation finished")
 		}
 	}
 
@@ -113,17 +202,23 @@ func (runner *Runner) setHostTenant() error {
 }
 
 func (runner *Runner) installOneAgent() error {
-	log.Info("downloading OneAgent")
-	_, err := runner.installer.InstallAgent(config.AgentBinDirMount)
-	if err != nil {
-		return err
-	}
-	processModuleConfig, err := runner.dtclient.GetProcessModuleConfig(0)
-	if err != nil {
-		return err
+	if runner.installer != nil {
+		log.Info("downloading OneAgent")
+		endpoints := []downloadEndpoint{{
+			apiUrl:    runner.config.ApiUrl,
+			dtclient:  runner.dtclient,
+			installer: runner.installer,
+		}}
+		if err := runner.downloadOneAgent(append(endpoints, runner.fallbackEndpoints...), installerPackage{}); err != nil {
+			return err
+		}
 	}
-	if err := runner.installer.UpdateProcessModuleConfig(config.AgentBinDirMount, processModuleConfig); err != nil {
-		return err
+
+	for _, installation := range runner.containerFlavorInstallations {
+		log.Info("downloading OneAgent for containers", "flavor", installation.installerPackage.containerFlavor)
+		if err := runner.downloadOneAgent(installation.endpoints, installation.installerPackage); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -147,6 +242,7 @@ func (runner *Runner) configureInstallation() error {
 			if err := runner.propagateTLSCert(); err != nil {
 				return errors.WithStack(err)
 			}
+			runner.report.Decisions.TlsCertPropagated = true
 		}
 
 		if runner.config.InitialConnectRetry > -1 {
@@ -154,6 +250,7 @@ func (runner *Runner) configureInstallation() error {
 			if err := runner.createCurlOptionsFile(); err != nil {
 				return errors.WithStack(err)
 			}
+			runner.report.Decisions.CurlOptions = true
 		}
 	}
 	if runner.env.DataIngestInjected {
@@ -161,6 +258,7 @@ func (runner *Runner) configureInstallation() error {
 		if err := runner.enrichMetadata(); err != nil {
 			return errors.WithStack(err)
 		}
+		runner.report.Decisions.Enrichment = true
 	}
 	return nil
 }
@@ -174,17 +272,21 @@ func (runner *Runner) createContainerConfigurationFiles() error {
 		log.Info("creating conf file for container", "container", container)
 		confFilePath := filepath.Join(config.AgentShareDirMount, fmt.Sprintf(config.AgentContainerConfFilenameTemplate, container.Name))
 		content := runner.getBaseConfContent(container)
+		containerReport := containerReport{Name: container.Name, Image: container.Image, Flavor: container.Flavor, ConfFile: confFilePath}
 		if runner.hostTenant != config.AgentNoHostTenant {
 			if runner.config.TenantUUID == runner.hostTenant {
 				log.Info("adding k8s fields")
 				content += runner.getK8ConfContent()
+				containerReport.K8sFields = true
 			}
 			log.Info("adding hostTenant field")
 			content += runner.getHostConfContent()
+			containerReport.HostTenant = true
 		}
 		if err := runner.createConfFile(confFilePath, content); err != nil {
 			return err
 		}
+		runner.report.Containers = append(runner.report.Containers, containerReport)
 	}
 	return nil
 }
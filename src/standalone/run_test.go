@@ -1,13 +1,18 @@
 package standalone
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/Dynatrace/dynatrace-operator/src/arch"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/installer"
+	"github.com/Dynatrace/dynatrace-operator/src/installer/offline"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,6 +43,7 @@ func TestNewRunner(t *testing.T) {
 		assert.NotNil(t, runner.dtclient)
 		assert.NotNil(t, runner.config)
 		assert.NotNil(t, runner.installer)
+		assert.Len(t, runner.fallbackEndpoints, 1)
 		assert.Empty(t, runner.hostTenant)
 	})
 	t.Run(`create runner with only oneagent`, func(t *testing.T) {
@@ -64,8 +70,45 @@ func TestNewRunner(t *testing.T) {
 		assert.Nil(t, runner.dtclient)
 		assert.Nil(t, runner.config)
 		assert.Nil(t, runner.installer)
+		assert.Empty(t, runner.fallbackEndpoints)
 		assert.Empty(t, runner.hostTenant)
 	})
+	t.Run(`create runner without fallback endpoints if installer url is set`, func(t *testing.T) {
+		resetEnv := prepOneAgentTestEnv(t)
+		require.NoError(t, os.Setenv(config.AgentInstallerUrlEnv, "https://installer.test.com/agent.zip"))
+		runner, err := NewRunner(fs)
+		resetEnv()
+		require.NoError(t, os.Unsetenv(config.AgentInstallerUrlEnv))
+
+		require.NoError(t, err)
+		assert.NotNil(t, runner.installer)
+		assert.Empty(t, runner.fallbackEndpoints)
+	})
+	t.Run(`create runner for offline installation`, func(t *testing.T) {
+		resetEnv := prepOneAgentTestEnv(t)
+		require.NoError(t, os.Setenv(config.AgentInstallModeEnv, string(config.AgentOfflineMode)))
+		runner, err := NewRunner(fs)
+		resetEnv()
+
+		require.NoError(t, err)
+		assert.IsType(t, &offline.OfflineInstaller{}, runner.installer)
+		assert.Nil(t, runner.dtclient)
+		assert.Empty(t, runner.fallbackEndpoints)
+	})
+	t.Run(`create runner with flavor of a container`, func(t *testing.T) {
+		resetEnv := prepOneAgentTestEnv(t)
+		require.NoError(t, os.Setenv(config.AgentInstallModeEnv, string(config.AgentInstallerMode)))
+		t.Setenv(fmt.Sprintf(config.AgentContainerFlavorEnvTemplate, 1), arch.FlavorMusl)
+		runner, err := NewRunner(fs)
+		resetEnv()
+
+		require.NoError(t, err)
+		assert.NotNil(t, runner.installer)
+		assert.Len(t, runner.fallbackEndpoints, 1)
+		require.Len(t, runner.containerFlavorInstallations, 1)
+		assert.Equal(t, arch.FlavorMusl, runner.containerFlavorInstallations[0].installerPackage.containerFlavor)
+		assert.Len(t, runner.containerFlavorInstallations[0].endpoints, 2)
+	})
 }
 
 func TestConsumeErrorIfNecessary(t *testing.T) {
@@ -177,6 +220,163 @@ func TestInstallOneAgent(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestInstallContainerFlavors(t *testing.T) {
+	flavorDir := filepath.Join(config.AgentBinDirMount, "flavors", arch.FlavorMusl)
+	t.Run(`install flavor of containers in addition to the one of the pod`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.dtclient.(*dtclient.MockDynatraceClient).
+			On("GetProcessModuleConfig", uint(0)).
+			Return(&testProcessModuleConfig, nil)
+		runner.installer.(*installer.InstallerMock).
+			On("UpdateProcessModuleConfig", config.AgentBinDirMount, &testProcessModuleConfig).
+			Return(nil)
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(true, nil)
+		runner.containerFlavorInstallations = []containerFlavorInstallation{createMockedContainerFlavorInstallation(arch.FlavorMusl)}
+
+		err := runner.installOneAgent()
+
+		require.NoError(t, err)
+		runner.containerFlavorInstallations[0].endpoints[0].installer.(*installer.InstallerMock).AssertCalled(t, "InstallAgent", flavorDir)
+		assert.True(t, readDownloadResult(t, runner.fs).Success)
+		assertIfFileExists(t, runner.fs, filepath.Join(config.AgentShareDirMount, "download_result_musl.json"))
+	})
+	t.Run(`install only flavor of containers`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.installer = nil
+		runner.containerFlavorInstallations = []containerFlavorInstallation{createMockedContainerFlavorInstallation(arch.FlavorMusl)}
+
+		err := runner.installOneAgent()
+
+		require.NoError(t, err)
+		assertIfFileNotExists(t, runner.fs, filepath.Join(config.AgentShareDirMount, config.AgentDownloadResultFileName))
+		assertIfFileExists(t, runner.fs, filepath.Join(config.AgentShareDirMount, "download_result_musl.json"))
+	})
+}
+
+func TestDownloadOneAgent(t *testing.T) {
+	t.Run(`fall back to the next endpoint`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(false, fmt.Errorf("BOOM"))
+		fallbackEndpoint := createMockedDownloadEndpoint(testFallbackApiUrl)
+		fallbackEndpoint.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(true, nil)
+		runner.fallbackEndpoints = []downloadEndpoint{fallbackEndpoint}
+
+		err := runner.installOneAgent()
+
+		require.NoError(t, err)
+		result := readDownloadResult(t, runner.fs)
+		assert.True(t, result.Success)
+		assert.Equal(t, testFallbackApiUrl, result.Endpoint)
+		require.Len(t, result.Attempts, 2)
+		assert.Equal(t, testApiUrl, result.Attempts[0].Endpoint)
+		assert.Equal(t, "BOOM", result.Attempts[0].Error)
+		assert.Empty(t, result.Attempts[1].Error)
+	})
+	t.Run(`retry until the deadline`, func(t *testing.T) {
+		defer setTestDownloadBackoff(time.Millisecond)()
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.DownloadDeadline = 10
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(false, fmt.Errorf("BOOM")).Once()
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(true, nil).Once()
+		runner.dtclient.(*dtclient.MockDynatraceClient).
+			On("GetProcessModuleConfig", uint(0)).
+			Return(&testProcessModuleConfig, nil)
+		runner.installer.(*installer.InstallerMock).
+			On("UpdateProcessModuleConfig", config.AgentBinDirMount, &testProcessModuleConfig).
+			Return(nil)
+
+		err := runner.installOneAgent()
+
+		require.NoError(t, err)
+		result := readDownloadResult(t, runner.fs)
+		assert.True(t, result.Success)
+		assert.Equal(t, testApiUrl, result.Endpoint)
+		assert.Len(t, result.Attempts, 2)
+	})
+	t.Run(`every endpoint fails`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(false, fmt.Errorf("BOOM"))
+		fallbackEndpoint := createMockedDownloadEndpoint(testFallbackApiUrl)
+		fallbackEndpoint.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(false, fmt.Errorf("BOOM"))
+		runner.fallbackEndpoints = []downloadEndpoint{fallbackEndpoint}
+
+		err := runner.installOneAgent()
+
+		require.Error(t, err)
+		result := readDownloadResult(t, runner.fs)
+		assert.False(t, result.Success)
+		assert.Empty(t, result.Endpoint)
+		assert.Len(t, result.Attempts, 2)
+	})
+}
+
+func TestInstallOneAgentOffline(t *testing.T) {
+	t.Run(`install with the process module config snapshot`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.ProcessModuleConfig = &testProcessModuleConfig
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(true, nil)
+		runner.installer.(*installer.InstallerMock).
+			On("UpdateProcessModuleConfig", config.AgentBinDirMount, &testProcessModuleConfig).
+			Return(nil)
+
+		err := runner.installOneAgentOffline()
+
+		require.NoError(t, err)
+		result := readDownloadResult(t, runner.fs)
+		assert.True(t, result.Success)
+		assert.Equal(t, offlinePackagePath, result.Endpoint)
+	})
+	t.Run(`fail without process module config snapshot`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.ProcessModuleConfig = nil
+
+		err := runner.installOneAgentOffline()
+
+		require.Error(t, err)
+		result := readDownloadResult(t, runner.fs)
+		assert.False(t, result.Success)
+		require.Len(t, result.Attempts, 1)
+		assert.Contains(t, result.Attempts[0].Error, "snapshot is missing")
+	})
+	t.Run(`fail if the package can't be installed`, func(t *testing.T) {
+		runner := createMockedRunner(t)
+		runner.fs = afero.NewMemMapFs()
+		runner.config.ProcessModuleConfig = &testProcessModuleConfig
+		runner.installer.(*installer.InstallerMock).
+			On("InstallAgent", config.AgentBinDirMount).
+			Return(false, fmt.Errorf("checksum doesn't match"))
+
+		err := runner.installOneAgentOffline()
+
+		require.Error(t, err)
+		assert.False(t, readDownloadResult(t, runner.fs).Success)
+	})
+}
+
 func TestRun(t *testing.T) {
 	runner := createMockedRunner(t)
 	runner.config.HasHost = false
@@ -310,6 +510,31 @@ func TestEnrichMetadata(t *testing.T) {
 		assertIfEnrichmentFilesExists(t, *runner)
 		// TODO: Check content ?
 	})
+	t.Run(`create enrichment files with metadata attributes`, func(t *testing.T) {
+		runner.fs = afero.NewMemMapFs()
+		runner.env.MetadataAttributes = map[string]string{
+			"k8s.pod.label.team":             "checkout",
+			"k8s.pod.annotation.description": "line one\nline \"two\"",
+		}
+		defer func() { runner.env.MetadataAttributes = nil }()
+
+		err := runner.enrichMetadata()
+		require.NoError(t, err)
+
+		jsonContent, err := afero.ReadFile(runner.fs, filepath.Join(config.EnrichmentMountPath, fmt.Sprintf(config.EnrichmentFilenameTemplate, "json")))
+		require.NoError(t, err)
+
+		enrichment := map[string]string{}
+		require.NoError(t, json.Unmarshal([]byte("{"+string(jsonContent)+"}"), &enrichment))
+		assert.Equal(t, "checkout", enrichment["k8s.pod.label.team"])
+		assert.Equal(t, "line one\nline \"two\"", enrichment["k8s.pod.annotation.description"])
+		assert.Equal(t, runner.env.K8PodUID, enrichment["k8s.pod.uid"])
+
+		propsContent, err := afero.ReadFile(runner.fs, filepath.Join(config.EnrichmentMountPath, fmt.Sprintf(config.EnrichmentFilenameTemplate, "properties")))
+		require.NoError(t, err)
+		assert.Contains(t, string(propsContent), "k8s.pod.label.team=checkout\n")
+		assert.Contains(t, string(propsContent), "k8s.pod.annotation.description=line one line \"two\"\n")
+	})
 }
 
 func TestPropagateTLSCert(t *testing.T) {
@@ -361,9 +586,55 @@ func createMockedRunner(t *testing.T) *Runner {
 	runner := creatTestRunner(t)
 	runner.installer = &installer.InstallerMock{}
 	runner.dtclient = &dtclient.MockDynatraceClient{}
+	runner.fallbackEndpoints = nil
 	return runner
 }
 
+func createMockedDownloadEndpoint(apiUrl string) downloadEndpoint {
+	client := &dtclient.MockDynatraceClient{}
+	client.On("GetProcessModuleConfig", uint(0)).Return(&testProcessModuleConfig, nil)
+	oneAgentInstaller := &installer.InstallerMock{}
+	oneAgentInstaller.On("UpdateProcessModuleConfig", config.AgentBinDirMount, &testProcessModuleConfig).Return(nil)
+	return downloadEndpoint{
+		apiUrl:    apiUrl,
+		dtclient:  client,
+		installer: oneAgentInstaller,
+	}
+}
+
+func createMockedContainerFlavorInstallation(flavor string) containerFlavorInstallation {
+	installerPackage := installerPackage{containerFlavor: flavor}
+	endpoint := createMockedDownloadEndpoint(testApiUrl)
+	endpoint.installer = &installer.InstallerMock{}
+	endpoint.installer.(*installer.InstallerMock).
+		On("InstallAgent", installerPackage.getTargetDir()).
+		Return(true, nil)
+	endpoint.installer.(*installer.InstallerMock).
+		On("UpdateProcessModuleConfig", installerPackage.getTargetDir(), &testProcessModuleConfig).
+		Return(nil)
+	return containerFlavorInstallation{
+		installerPackage: installerPackage,
+		endpoints:        []downloadEndpoint{endpoint},
+	}
+}
+
+func setTestDownloadBackoff(backoff time.Duration) func() {
+	previousInitial, previousMax := initialDownloadBackoff, maxDownloadBackoff
+	initialDownloadBackoff, maxDownloadBackoff = backoff, backoff
+	return func() {
+		initialDownloadBackoff, maxDownloadBackoff = previousInitial, previousMax
+	}
+}
+
+func readDownloadResult(t *testing.T, fs afero.Fs) downloadResult {
+	content, err := afero.ReadFile(fs, filepath.Join(config.AgentShareDirMount, config.AgentDownloadResultFileName))
+	require.NoError(t, err)
+
+	var result downloadResult
+	require.NoError(t, json.Unmarshal(content, &result))
+	return result
+}
+
 func assertIfAgentFilesExists(t *testing.T, runner Runner) {
 	// container confs
 	for _, container := range runner.env.Containers {
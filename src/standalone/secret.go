@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/spf13/afero"
 )
 
@@ -19,6 +20,14 @@ type SecretConfig struct {
 	TrustedCAs    string `json:"trustedCAs"`
 	SkipCertCheck bool   `json:"skipCertCheck"`
 
+	// For the download
+	FallbackApiUrls  []string `json:"fallbackApiUrls"`
+	DownloadDeadline int      `json:"downloadDeadline"`
+
+	// For the offline installation
+	OfflineChecksum     string                        `json:"offlineChecksum"`
+	ProcessModuleConfig *dtclient.ProcessModuleConfig `json:"processModuleConfig,omitempty"`
+
 	// For the injection
 	TenantUUID          string            `json:"tenantUUID"`
 	HasHost             bool              `json:"hasHost"`
@@ -44,6 +53,9 @@ func (secret SecretConfig) logContent() {
 	if secret.TlsCert != "" {
 		secret.TlsCert = "***"
 	}
+	if secret.ProcessModuleConfig != nil {
+		secret.ProcessModuleConfig = &dtclient.ProcessModuleConfig{Revision: secret.ProcessModuleConfig.Revision}
+	}
 	log.Info("contents of secret config", "content", secret)
 }
 
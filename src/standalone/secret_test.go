@@ -21,6 +21,8 @@ const (
 	testNetworkZone = "zone"
 	testTrustedCA   = "secret"
 
+	testFallbackApiUrl = "https://fallback.test.com/e/test/api"
+
 	testTenantUUID = "test"
 	testNodeName   = "node1"
 	testTlsCert    = "tls"
@@ -38,8 +40,11 @@ var testSecretConfig = SecretConfig{
 	NetworkZone:   testNetworkZone,
 	TrustedCAs:    testTrustedCA,
 	SkipCertCheck: true,
-	TenantUUID:    testTenantUUID,
-	HasHost:       true,
+	FallbackApiUrls: []string{
+		testFallbackApiUrl,
+	},
+	TenantUUID: testTenantUUID,
+	HasHost:    true,
 	MonitoringNodes: map[string]string{
 		testNodeName: testTenantUUID,
 	},
@@ -64,6 +69,7 @@ func TestNewSecretConfigViaFs(t *testing.T) {
 	assert.Equal(t, testNetworkZone, config.NetworkZone)
 	assert.Equal(t, testTrustedCA, config.TrustedCAs)
 	assert.True(t, config.SkipCertCheck)
+	assert.Equal(t, []string{testFallbackApiUrl}, config.FallbackApiUrls)
 	assert.Equal(t, testTenantUUID, config.TenantUUID)
 	assert.True(t, config.HasHost)
 	assert.Equal(t, testTlsCert, config.TlsCert)
@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -170,7 +171,7 @@ func newTestEnvironment() (*ControllerTestEnvironment, error) {
 		CommunicationHosts: communicationHosts,
 	}
 	testEnvironment.Reconciler = dynakube.NewDynaKubeController(
-		kubernetesClient, kubernetesClient, scheme.Scheme,
+		kubernetesClient, kubernetesClient, scheme.Scheme, record.NewFakeRecorder(10),
 		mockDynatraceClientFunc(&testEnvironment.CommunicationHosts), cfg)
 
 	return testEnvironment, nil
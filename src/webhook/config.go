@@ -3,6 +3,9 @@ package webhook
 const (
 	// InjectionInstanceLabel can be set in a Namespace and indicates the corresponding DynaKube object assigned to it.
 	InjectionInstanceLabel = "dynakube.internal.dynatrace.com/instance"
+	// InjectionRoutedInstancesAnnotation is set in a Namespace and lists the additional DynaKubes, which use a pod selector,
+	// the pods of the namespace can be routed to.
+	InjectionRoutedInstancesAnnotation = "dynakube.internal.dynatrace.com/routed-instances"
 
 	// AnnotationDynatraceInjected is set to "true" by the webhook to Pods to indicate that it has been injected.
 	AnnotationDynatraceInjected = "dynakube.dynatrace.com/injected"
@@ -11,11 +14,20 @@ const (
 	// AnnotationOneAgentInject can be set at pod level to enable/disable OneAgent injection.
 	AnnotationOneAgentInject   = OneAgentPrefix + ".dynatrace.com/inject"
 	AnnotationOneAgentInjected = OneAgentPrefix + ".dynatrace.com/injected"
+	// AnnotationCodeModulesRevision is set by the webhook to Pods to record the image or version of the injected code modules.
+	AnnotationCodeModulesRevision = OneAgentPrefix + ".dynatrace.com/code-modules-revision"
+	// AnnotationOneAgentReason is set by the webhook to Pods to indicate why the OneAgent injection was skipped or failed.
+	AnnotationOneAgentReason = OneAgentPrefix + ".dynatrace.com/reason"
 
 	DataIngestPrefix = "data-ingest"
 	// AnnotationDataIngestInject can be set at pod level to enable/disable data-ingest injection.
 	AnnotationDataIngestInject   = DataIngestPrefix + ".dynatrace.com/inject"
 	AnnotationDataIngestInjected = DataIngestPrefix + ".dynatrace.com/injected"
+	// AnnotationDataIngestReason is set by the webhook to Pods to indicate why the data-ingest injection was skipped or failed.
+	AnnotationDataIngestReason = DataIngestPrefix + ".dynatrace.com/reason"
+	// AnnotationOtelInject can be set at pod level to disable the OpenTelemetry configuration of the data-ingest injection,
+	// if it's enabled for the DynaKube.
+	AnnotationOtelInject = DataIngestPrefix + ".dynatrace.com/otel"
 
 	// AnnotationFlavor can be set on a Pod to configure which code modules flavor to download. It's set to "default"
 	// if not set.
@@ -37,6 +49,25 @@ const (
 	// "fail", the init container will exit with error code 1. Defaults to "silent".
 	AnnotationFailurePolicy = "oneagent.dynatrace.com/failure-policy"
 
+	// AnnotationContainerInjectPrefix can be set on a Pod together with the name of a container
+	// (e.g. "container.inject.dynatrace.com/istio-proxy": "false") to exclude or include a single container for OneAgent injection,
+	// it takes precedence over the excluded containers of the Pod, Namespace and DynaKube.
+	AnnotationContainerInjectPrefix = "container.inject.dynatrace.com/"
+
+	// AnnotationContainerFlavorPrefix can be set on a Pod together with the name of a container
+	// (e.g. "flavor.oneagent.dynatrace.com/sidecar": "musl") to download another code modules flavor for the container than for the Pod.
+	// It's only used if the code modules are downloaded by the init container.
+	AnnotationContainerFlavorPrefix = "flavor.oneagent.dynatrace.com/"
+
+	// AnnotationContainerTechnologiesPrefix can be set on a Pod together with the name of a container
+	// (e.g. "technologies.oneagent.dynatrace.com/app": "java") to configure the code module technologies the container needs,
+	// the init container downloads the technologies of all containers. It's only used if the code modules are downloaded by the init container.
+	AnnotationContainerTechnologiesPrefix = "technologies.oneagent.dynatrace.com/"
+
+	// AnnotationExcludedContainers can be set on a Pod or Namespace to exclude containers from OneAgent injection,
+	// the value is a comma separated list of patterns (see path.Match) that are matched against the name and the image of the container.
+	AnnotationExcludedContainers = OneAgentPrefix + ".dynatrace.com/excluded-containers"
+
 	// DefaultInstallPath is the default directory to install the app-only OneAgent package.
 	DefaultInstallPath = "/opt/dynatrace/oneagent-paas"
 
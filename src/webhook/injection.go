@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsInjectionMissing checks if an active pod was never mutated by the webhook, e.g. it was created before its namespace was mapped to a dynakube.
+// Pods that were skipped by the webhook have a reason annotation, pods that opted out of every injection are ignored.
+func IsInjectionMissing(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if pod.Annotations[AnnotationDynatraceInjected] == "true" {
+		return false
+	}
+	if pod.Annotations[AnnotationOneAgentReason] != "" || pod.Annotations[AnnotationDataIngestReason] != "" {
+		return false
+	}
+	return pod.Annotations[AnnotationOneAgentInject] != "false" || pod.Annotations[AnnotationDataIngestInject] != "false"
+}
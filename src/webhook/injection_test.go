@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsInjectionMissing(t *testing.T) {
+	t.Run(`pod never seen by the webhook`, func(t *testing.T) {
+		assert.True(t, IsInjectionMissing(createPodWithAnnotations(nil)))
+	})
+	t.Run(`injected pod`, func(t *testing.T) {
+		assert.False(t, IsInjectionMissing(createPodWithAnnotations(map[string]string{AnnotationDynatraceInjected: "true"})))
+	})
+	t.Run(`pod skipped by the webhook`, func(t *testing.T) {
+		assert.False(t, IsInjectionMissing(createPodWithAnnotations(map[string]string{AnnotationDataIngestReason: "disabled-by-dynakube"})))
+	})
+	t.Run(`pod opted out of the injection`, func(t *testing.T) {
+		pod := createPodWithAnnotations(map[string]string{
+			AnnotationOneAgentInject:   "false",
+			AnnotationDataIngestInject: "false",
+		})
+
+		assert.False(t, IsInjectionMissing(pod))
+	})
+	t.Run(`terminating or finished pod`, func(t *testing.T) {
+		terminatingPod := createPodWithAnnotations(nil)
+		terminatingPod.DeletionTimestamp = &metav1.Time{}
+		finishedPod := createPodWithAnnotations(nil)
+		finishedPod.Status.Phase = corev1.PodFailed
+
+		assert.False(t, IsInjectionMissing(terminatingPod))
+		assert.False(t, IsInjectionMissing(finishedPod))
+	})
+}
+
+func createPodWithAnnotations(annotations map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Annotations: annotations,
+		},
+	}
+}
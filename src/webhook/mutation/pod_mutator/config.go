@@ -2,14 +2,31 @@ package pod_mutator
 
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
 	injectEvent          = "Inject"
 	updatePodEvent       = "UpdatePod"
 	missingDynakubeEvent = "MissingDynakube"
+
+	auditLogEnv         = "AUDIT_LOG"
+	injectionPreviewEnv = "INJECTION_PREVIEW"
 )
 
 var (
-	log = logger.NewDTLogger().WithName("mutation-webhook.pod")
+	log      = logger.NewDTLogger().WithName("mutation-webhook.pod")
+	auditLog = logger.NewDTLogger().WithName("mutation-webhook.audit")
+
+	injectionDecisionsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "webhook",
+		Name:      "pod_injection_decisions",
+		Help:      "Number of injection decisions for pods by the webhook",
+	}, []string{"decision", "reason", "mutator", "dynakube"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(injectionDecisionsMetric)
+}
@@ -49,8 +49,12 @@ func (mutator *DataIngestPodMutator) Mutate(request *dtwebhook.MutationRequest)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	setupVolumes(request.Pod)
+	setupVolumes(request.Pod, getEndpointSecretName(request.BaseRequest))
+	newContainers := getUninjectedContainers(request.Pod)
 	mutateUserContainers(request.Pod)
+	if otelEnabled(request.BaseRequest) {
+		addOtelToUserContainers(newContainers, workload, request.Namespace.Name, getEndpointSecretName(request.BaseRequest))
+	}
 	updateInstallContainer(request.InstallContainer, workload)
 	setInjectedAnnotation(request.Pod)
 	return nil
@@ -61,22 +65,28 @@ func (mutator *DataIngestPodMutator) Reinvoke(request *dtwebhook.ReinvocationReq
 		return false
 	}
 	log.Info("reinvoking", "pod", request.Pod.GenerateName)
-	return reinvokeUserContainers(request.Pod)
+	newContainers := getUninjectedContainers(request.Pod)
+	updated := reinvokeUserContainers(request.Pod)
+	if updated && otelEnabled(request.BaseRequest) {
+		addOtelToUserContainers(newContainers, getWorkloadFromInstallContainer(request.Pod), request.Namespace.Name, getEndpointSecretName(request.BaseRequest))
+	}
+	return updated
 }
 
 func (mutator *DataIngestPodMutator) ensureDataIngestSecret(request *dtwebhook.MutationRequest) error {
 	endpointGenerator := dtingestendpoint.NewEndpointSecretGenerator(mutator.client, mutator.apiReader, mutator.webhookNamespace)
 
+	endpointSecretName := getEndpointSecretName(request.BaseRequest)
 	var endpointSecret corev1.Secret
 	err := mutator.apiReader.Get(
 		request.Context,
 		client.ObjectKey{
-			Name:      config.EnrichmentEndpointSecretName,
+			Name:      endpointSecretName,
 			Namespace: request.Namespace.Name,
 		},
 		&endpointSecret)
 	if k8serrors.IsNotFound(err) {
-		err := endpointGenerator.GenerateForNamespace(request.Context, request.DynaKube.Name, request.Namespace.Name)
+		err := endpointGenerator.GenerateForNamespaceWithName(request.Context, request.DynaKube.Name, request.Namespace.Name, endpointSecretName)
 		if err != nil {
 			log.Error(err, "failed to create the data-ingest endpoint secret before pod injection")
 			return errors.WithStack(err)
@@ -105,3 +115,8 @@ func containerIsInjected(container *corev1.Container) bool {
 	}
 	return false
 }
+
+// getEndpointSecretName returns the name of the data-ingest endpoint secret of the dynakube, see dtwebhook.SecretNameForDynakube
+func getEndpointSecretName(request *dtwebhook.BaseRequest) string {
+	return dtwebhook.SecretNameForDynakube(config.EnrichmentEndpointSecretName, request.Namespace, request.DynaKube.Name)
+}
@@ -0,0 +1,138 @@
+package dataingest_mutation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	otelEndpointEnv           = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otelHeadersEnv            = "OTEL_EXPORTER_OTLP_HEADERS"
+	otelProtocolEnv           = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	otelResourceAttributesEnv = "OTEL_RESOURCE_ATTRIBUTES"
+
+	// Dynatrace only accepts otlp over http with protobuf
+	otelProtocol = "http/protobuf"
+
+	otelPodNameEnv  = "DT_K8S_POD_NAME"
+	otelPodUIDEnv   = "DT_K8S_POD_UID"
+	otelNodeNameEnv = "DT_K8S_NODE_NAME"
+)
+
+// otelEnabled checks if the OpenTelemetry SDKs of the containers should be configured,
+// the pod annotation can only turn it off for single pods
+func otelEnabled(request *dtwebhook.BaseRequest) bool {
+	return request.DynaKube.FeatureOtelInjection() &&
+		kubeobjects.GetFieldBool(request.Pod.Annotations, dtwebhook.AnnotationOtelInject, true)
+}
+
+func addOtelToUserContainers(containers []*corev1.Container, workload *workloadInfo, namespace, endpointSecretName string) {
+	for _, container := range containers {
+		addOtelEnvs(container, workload, namespace, endpointSecretName)
+	}
+}
+
+// addOtelEnvs configures the OTLP exporter of the container to send to the data-ingest endpoint,
+// containers with their own exporter endpoint are left untouched
+func addOtelEnvs(container *corev1.Container, workload *workloadInfo, namespace, endpointSecretName string) {
+	if kubeobjects.EnvVarIsIn(container.Env, otelEndpointEnv) {
+		log.Info("container already has an otlp endpoint, skipping otel configuration", "name", container.Name)
+		return
+	}
+
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: otelPodNameEnv, ValueFrom: kubeobjects.NewEnvVarSourceForField("metadata.name")},
+		corev1.EnvVar{Name: otelPodUIDEnv, ValueFrom: kubeobjects.NewEnvVarSourceForField("metadata.uid")},
+		corev1.EnvVar{Name: otelNodeNameEnv, ValueFrom: kubeobjects.NewEnvVarSourceForField("spec.nodeName")},
+		corev1.EnvVar{Name: dtingestendpoint.OtlpTokenSecretField, ValueFrom: newEndpointSecretKeyRef(endpointSecretName, dtingestendpoint.OtlpTokenSecretField)},
+		corev1.EnvVar{Name: otelEndpointEnv, ValueFrom: newEndpointSecretKeyRef(endpointSecretName, dtingestendpoint.OtlpUrlSecretField)},
+		corev1.EnvVar{Name: otelProtocolEnv, Value: otelProtocol},
+		// the token is expanded by kubernetes, as it's defined before, the header value has to be url encoded
+		corev1.EnvVar{Name: otelHeadersEnv, Value: fmt.Sprintf("Authorization=Api-Token%%20$(%s)", dtingestendpoint.OtlpTokenSecretField)},
+	)
+	addOtelResourceAttributes(container, getOtelResourceAttributes(container, workload, namespace))
+}
+
+// addOtelResourceAttributes puts the existing attributes of the container last, so they take precedence.
+// The env is moved behind the added envs, as kubernetes only expands references to envs defined before.
+func addOtelResourceAttributes(container *corev1.Container, attributes string) {
+	existing := kubeobjects.FindEnvVar(container.Env, otelResourceAttributesEnv)
+	if existing != nil && existing.ValueFrom != nil {
+		return
+	}
+	if existing != nil && existing.Value != "" {
+		attributes = attributes + "," + existing.Value
+	}
+	container.Env = removeEnv(container.Env, otelResourceAttributesEnv)
+	container.Env = append(container.Env, corev1.EnvVar{Name: otelResourceAttributesEnv, Value: attributes})
+}
+
+func removeEnv(envs []corev1.EnvVar, name string) []corev1.EnvVar {
+	remaining := make([]corev1.EnvVar, 0, len(envs))
+	for _, env := range envs {
+		if env.Name != name {
+			remaining = append(remaining, env)
+		}
+	}
+	return remaining
+}
+
+func getOtelResourceAttributes(container *corev1.Container, workload *workloadInfo, namespace string) string {
+	attributes := []string{
+		"k8s.namespace.name=" + namespace,
+		fmt.Sprintf("k8s.pod.name=$(%s)", otelPodNameEnv),
+		fmt.Sprintf("k8s.pod.uid=$(%s)", otelPodUIDEnv),
+		fmt.Sprintf("k8s.node.name=$(%s)", otelNodeNameEnv),
+		"k8s.container.name=" + container.Name,
+	}
+	if workload != nil {
+		attributes = append(attributes,
+			"dt.kubernetes.workload.kind="+workload.kind,
+			"dt.kubernetes.workload.name="+workload.name,
+		)
+	}
+	return strings.Join(attributes, ",")
+}
+
+func newEndpointSecretKeyRef(endpointSecretName, key string) *corev1.EnvVarSource {
+	optional := true
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: endpointSecretName},
+			Key:                  key,
+			Optional:             &optional,
+		},
+	}
+}
+
+// getWorkloadFromInstallContainer reads the workload, found during the first injection, from the envs of the install-container
+func getWorkloadFromInstallContainer(pod *corev1.Pod) *workloadInfo {
+	for _, initContainer := range pod.Spec.InitContainers {
+		if initContainer.Name != dtwebhook.InstallContainerName {
+			continue
+		}
+		kind := kubeobjects.FindEnvVar(initContainer.Env, config.EnrichmentWorkloadKindEnv)
+		name := kubeobjects.FindEnvVar(initContainer.Env, config.EnrichmentWorkloadNameEnv)
+		if kind == nil || name == nil {
+			return nil
+		}
+		return &workloadInfo{kind: kind.Value, name: name.Value}
+	}
+	return nil
+}
+
+func getUninjectedContainers(pod *corev1.Pod) []*corev1.Container {
+	var containers []*corev1.Container
+	for i := range pod.Spec.Containers {
+		if !containerIsInjected(&pod.Spec.Containers[i]) {
+			containers = append(containers, &pod.Spec.Containers[i])
+		}
+	}
+	return containers
+}
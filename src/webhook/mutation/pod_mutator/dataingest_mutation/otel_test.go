@@ -0,0 +1,130 @@
+package dataingest_mutation
+
+import (
+	"regexp"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestOtelEnabled(t *testing.T) {
+	t.Run(`off by default`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), nil)
+
+		assert.False(t, otelEnabled(request.BaseRequest))
+	})
+	t.Run(`on by feature flag`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestOtelDynakube(), nil)
+
+		assert.True(t, otelEnabled(request.BaseRequest))
+	})
+	t.Run(`turned off by pod annotation`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestOtelDynakube(), map[string]string{dtwebhook.AnnotationOtelInject: "false"})
+
+		assert.False(t, otelEnabled(request.BaseRequest))
+	})
+}
+
+func TestAddOtelEnvs(t *testing.T) {
+	t.Run(`add exporter and resource attributes`, func(t *testing.T) {
+		container := &corev1.Container{Name: "app"}
+
+		addOtelEnvs(container, createTestWorkloadInfo(), testNamespaceName, config.EnrichmentEndpointSecretName)
+
+		endpoint := kubeobjects.FindEnvVar(container.Env, otelEndpointEnv)
+		require.NotNil(t, endpoint)
+		assert.Equal(t, config.EnrichmentEndpointSecretName, endpoint.ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, dtingestendpoint.OtlpUrlSecretField, endpoint.ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "Authorization=Api-Token%20$(DT_OTLP_INGEST_API_TOKEN)", kubeobjects.FindEnvVar(container.Env, otelHeadersEnv).Value)
+		assert.Equal(t, otelProtocol, kubeobjects.FindEnvVar(container.Env, otelProtocolEnv).Value)
+		assert.Equal(t,
+			"k8s.namespace.name=test-namespace,k8s.pod.name=$(DT_K8S_POD_NAME),k8s.pod.uid=$(DT_K8S_POD_UID),k8s.node.name=$(DT_K8S_NODE_NAME),"+
+				"k8s.container.name=app,dt.kubernetes.workload.kind=test,dt.kubernetes.workload.name=test",
+			kubeobjects.FindEnvVar(container.Env, otelResourceAttributesEnv).Value)
+		assertEnvReferencesAreDefinedBefore(t, container.Env)
+	})
+	t.Run(`existing resource attributes take precedence`, func(t *testing.T) {
+		container := &corev1.Container{Name: "app", Env: []corev1.EnvVar{
+			{Name: otelResourceAttributesEnv, Value: "service.name=app"},
+			{Name: "OTHER", Value: "value"},
+		}}
+
+		addOtelEnvs(container, nil, testNamespaceName, config.EnrichmentEndpointSecretName)
+
+		attributes := kubeobjects.FindEnvVar(container.Env, otelResourceAttributesEnv).Value
+		assert.Contains(t, attributes, "k8s.container.name=app,service.name=app")
+		assert.NotContains(t, attributes, "workload")
+		assert.Equal(t, "OTHER", container.Env[0].Name)
+		assert.Equal(t, otelResourceAttributesEnv, container.Env[len(container.Env)-1].Name)
+		assertEnvReferencesAreDefinedBefore(t, container.Env)
+	})
+	t.Run(`resource attributes from a reference are left untouched`, func(t *testing.T) {
+		valueFrom := kubeobjects.NewEnvVarSourceForField("metadata.annotations['attributes']")
+		container := &corev1.Container{Name: "app", Env: []corev1.EnvVar{{Name: otelResourceAttributesEnv, ValueFrom: valueFrom}}}
+
+		addOtelEnvs(container, nil, testNamespaceName, config.EnrichmentEndpointSecretName)
+
+		assert.Equal(t, otelResourceAttributesEnv, container.Env[0].Name)
+		assert.Equal(t, valueFrom, container.Env[0].ValueFrom)
+		assert.Empty(t, container.Env[0].Value)
+	})
+	t.Run(`container with own endpoint is skipped`, func(t *testing.T) {
+		container := &corev1.Container{Name: "app", Env: []corev1.EnvVar{{Name: otelEndpointEnv, Value: "http://collector:4318"}}}
+
+		addOtelEnvs(container, createTestWorkloadInfo(), testNamespaceName, config.EnrichmentEndpointSecretName)
+
+		assert.Len(t, container.Env, 1)
+	})
+}
+
+func TestMutateWithOtel(t *testing.T) {
+	t.Run(`containers get the otel envs`, func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		request := createTestMutationRequest(getTestOtelDynakube(), nil)
+
+		err := mutator.Mutate(request)
+		require.NoError(t, err)
+
+		assert.True(t, kubeobjects.EnvVarIsIn(request.Pod.Spec.Containers[0].Env, otelEndpointEnv))
+	})
+	t.Run(`new containers get the otel envs on reinvocation`, func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		request := createTestReinvocationRequest(getTestOtelDynakube(), map[string]string{dtwebhook.AnnotationDataIngestInjected: "true"})
+		installContainer := &request.Pod.Spec.InitContainers[len(request.Pod.Spec.InitContainers)-1]
+		addWorkloadInfoEnvs(installContainer, &workloadInfo{kind: "Deployment", name: "app"})
+
+		updated := mutator.Reinvoke(request)
+		require.True(t, updated)
+
+		attributes := kubeobjects.FindEnvVar(request.Pod.Spec.Containers[0].Env, otelResourceAttributesEnv)
+		require.NotNil(t, attributes)
+		assert.Contains(t, attributes.Value, "dt.kubernetes.workload.kind=Deployment,dt.kubernetes.workload.name=app")
+	})
+}
+
+func getTestOtelDynakube() *dynatracev1beta1.DynaKube {
+	dynakube := getTestDynakube()
+	dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureOtelInjection: "true"}
+	return dynakube
+}
+
+var envReferencePattern = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// assertEnvReferencesAreDefinedBefore checks that kubernetes expands every $(NAME) reference, which requires the env to be defined before
+func assertEnvReferencesAreDefinedBefore(t *testing.T, envs []corev1.EnvVar) {
+	defined := map[string]bool{}
+	for _, env := range envs {
+		for _, reference := range envReferencePattern.FindAllStringSubmatch(env.Value, -1) {
+			assert.True(t, defined[reference[1]], "%s references %s, which isn't defined before", env.Name, reference[1])
+		}
+		defined[env.Name] = true
+	}
+}
@@ -5,18 +5,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-func setupVolumes(pod *corev1.Pod) {
-	addIngestEndpointVolume(pod)
+func setupVolumes(pod *corev1.Pod, endpointSecretName string) {
+	addIngestEndpointVolume(pod, endpointSecretName)
 	addWorkloadEnrichmentVolume(pod)
 }
 
-func addIngestEndpointVolume(pod *corev1.Pod) {
+func addIngestEndpointVolume(pod *corev1.Pod, endpointSecretName string) {
 	pod.Spec.Volumes = append(pod.Spec.Volumes,
 		corev1.Volume{
 			Name: ingestEndpointVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: config.EnrichmentEndpointSecretName,
+					SecretName: endpointSecretName,
 				},
 			},
 		},
@@ -3,6 +3,8 @@ package dataingest_mutation
 import (
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -12,7 +14,7 @@ func TestSetupVolumes(t *testing.T) {
 	t.Run("should add dataingest volumes", func(t *testing.T) {
 		pod := &corev1.Pod{}
 
-		setupVolumes(pod)
+		setupVolumes(pod, config.EnrichmentEndpointSecretName)
 
 		require.Len(t, pod.Spec.Volumes, 2)
 		assert.NotNil(t, pod.Spec.Volumes[0].Secret)
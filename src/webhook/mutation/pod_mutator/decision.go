@@ -0,0 +1,127 @@
+package pod_mutator
+
+import (
+	"fmt"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/dataingest_mutation"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/oneagent_mutation"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	decisionInjected  = "injected"
+	decisionSkipped   = "skipped"
+	decisionReinvoked = "reinvoked"
+	decisionFailed    = "failed"
+
+	reasonEnabled              = "enabled"
+	reasonNewContainers        = "new-containers"
+	reasonApmExists            = "oneagent-apm-exists"
+	reasonInvalidRequest       = "invalid-request"
+	reasonNoDynakubeAssigned   = "no-dynakube-assigned"
+	reasonDynakubeNotFound     = "dynakube-not-found"
+	reasonNoPodSelectorMatch   = "no-pod-selector-match"
+	reasonAlreadyInjected      = "already-injected"
+	reasonReinvocationDisabled = "reinvocation-disabled"
+	reasonDisabledByAnnotation = "disabled-by-annotation"
+	reasonDisabledByDynakube   = "disabled-by-dynakube"
+	reasonMutationError        = "mutation-error"
+)
+
+// injectionDecision describes why the webhook did or didn't mutate a pod,
+// the mutator is empty for decisions about the whole pod
+type injectionDecision struct {
+	decision  string
+	reason    string
+	mutator   string
+	dynakube  string
+	namespace string
+	podName   string
+	err       error
+}
+
+type mutatorInfo struct {
+	name             string
+	injectAnnotation string
+	reasonAnnotation string
+}
+
+func getMutatorInfo(mutator dtwebhook.PodMutator) mutatorInfo {
+	switch mutator.(type) {
+	case *oneagent_mutation.OneAgentPodMutator:
+		return mutatorInfo{"oneagent", dtwebhook.AnnotationOneAgentInject, dtwebhook.AnnotationOneAgentReason}
+	case *dataingest_mutation.DataIngestPodMutator:
+		return mutatorInfo{"data-ingest", dtwebhook.AnnotationDataIngestInject, dtwebhook.AnnotationDataIngestReason}
+	default:
+		return mutatorInfo{name: fmt.Sprintf("%T", mutator)}
+	}
+}
+
+func newPodDecision(decision, reason string, pod *corev1.Pod, namespace, dynakube string) injectionDecision {
+	podName := ""
+	if pod != nil {
+		podName = pod.Name
+		if podName == "" {
+			podName = pod.GenerateName
+		}
+	}
+	return injectionDecision{
+		decision:  decision,
+		reason:    reason,
+		dynakube:  dynakube,
+		namespace: namespace,
+		podName:   podName,
+	}
+}
+
+func newMutatorDecision(decision, reason string, mutator dtwebhook.PodMutator, request *dtwebhook.BaseRequest) injectionDecision {
+	podDecision := newPodDecision(decision, reason, request.Pod, request.Namespace.Name, request.DynaKube.Name)
+	podDecision.mutator = getMutatorInfo(mutator).name
+	return podDecision
+}
+
+// getSkipReason tells if the mutator was disabled by the pod annotation or by the dynakube
+func getSkipReason(mutator dtwebhook.PodMutator, request *dtwebhook.BaseRequest) string {
+	annotation := getMutatorInfo(mutator).injectAnnotation
+	if _, ok := request.Pod.Annotations[annotation]; ok && annotation != "" {
+		return reasonDisabledByAnnotation
+	}
+	return reasonDisabledByDynakube
+}
+
+// recordDecision counts the decision in the metrics and writes it to the audit log, if it's enabled
+func (webhook *podMutatorWebhook) recordDecision(decision injectionDecision) {
+	if webhook.isPreview {
+		return
+	}
+	injectionDecisionsMetric.WithLabelValues(decision.decision, decision.reason, decision.mutator, decision.dynakube).Inc()
+	if !webhook.auditLogEnabled {
+		return
+	}
+	keysAndValues := []interface{}{
+		"decision", decision.decision,
+		"reason", decision.reason,
+		"mutator", decision.mutator,
+		"dynakube", decision.dynakube,
+		"namespace", decision.namespace,
+		"podName", decision.podName,
+	}
+	if decision.err != nil {
+		keysAndValues = append(keysAndValues, "error", decision.err.Error())
+	}
+	auditLog.Info("admission decision", keysAndValues...)
+}
+
+// setReasonAnnotation records on the pod why the mutator didn't inject, mutators without a reason annotation are ignored
+func setReasonAnnotation(pod *corev1.Pod, mutator dtwebhook.PodMutator, reason string) bool {
+	annotation := getMutatorInfo(mutator).reasonAnnotation
+	if annotation == "" {
+		return false
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[annotation] = reason
+	return true
+}
@@ -0,0 +1,89 @@
+package pod_mutator
+
+import (
+	"testing"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/dataingest_mutation"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/oneagent_mutation"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetSkipReason(t *testing.T) {
+	t.Run(`disabled by pod annotation`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube())
+		request.Pod.Annotations = map[string]string{dtwebhook.AnnotationOneAgentInject: "false"}
+
+		assert.Equal(t, reasonDisabledByAnnotation, getSkipReason(&oneagent_mutation.OneAgentPodMutator{}, request.BaseRequest))
+		assert.Equal(t, reasonDisabledByDynakube, getSkipReason(&dataingest_mutation.DataIngestPodMutator{}, request.BaseRequest))
+	})
+	t.Run(`unknown mutator is disabled by dynakube`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube())
+
+		assert.Equal(t, reasonDisabledByDynakube, getSkipReason(createSimplePodMutatorMock(), request.BaseRequest))
+	})
+}
+
+func TestSetReasonAnnotation(t *testing.T) {
+	t.Run(`set annotation of known mutators`, func(t *testing.T) {
+		pod := corev1.Pod{}
+
+		assert.True(t, setReasonAnnotation(&pod, &oneagent_mutation.OneAgentPodMutator{}, reasonMutationError))
+		assert.True(t, setReasonAnnotation(&pod, &dataingest_mutation.DataIngestPodMutator{}, reasonDisabledByDynakube))
+		assert.Equal(t, reasonMutationError, pod.Annotations[dtwebhook.AnnotationOneAgentReason])
+		assert.Equal(t, reasonDisabledByDynakube, pod.Annotations[dtwebhook.AnnotationDataIngestReason])
+	})
+	t.Run(`ignore unknown mutators`, func(t *testing.T) {
+		pod := corev1.Pod{}
+
+		assert.False(t, setReasonAnnotation(&pod, createSimplePodMutatorMock(), reasonMutationError))
+		assert.Empty(t, pod.Annotations)
+	})
+}
+
+func TestRecordDecision(t *testing.T) {
+	t.Run(`count decision`, func(t *testing.T) {
+		podWebhook := createTestWebhook(t, nil, nil)
+		podWebhook.auditLogEnabled = true
+		counter := injectionDecisionsMetric.WithLabelValues(decisionFailed, reasonMutationError, "oneagent", testDynakubeName)
+		before := testutil.ToFloat64(counter)
+
+		decision := newMutatorDecision(decisionFailed, reasonMutationError, &oneagent_mutation.OneAgentPodMutator{}, createTestMutationRequest(getTestDynakube()).BaseRequest)
+		decision.err = errors.New("BOOM")
+		podWebhook.recordDecision(decision)
+
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+	})
+	t.Run(`ignore decisions of preview`, func(t *testing.T) {
+		podWebhook := createTestWebhook(t, nil, nil)
+		podWebhook.isPreview = true
+		counter := injectionDecisionsMetric.WithLabelValues(decisionSkipped, reasonApmExists, "", testDynakubeName)
+		before := testutil.ToFloat64(counter)
+
+		podWebhook.recordDecision(newPodDecision(decisionSkipped, reasonApmExists, getTestPod(), testNamespaceName, testDynakubeName))
+
+		assert.Equal(t, before, testutil.ToFloat64(counter))
+	})
+}
+
+func TestHandlePodMutationDecisions(t *testing.T) {
+	t.Run(`annotate reason of skipped mutator`, func(t *testing.T) {
+		mutator := createSimplePodMutatorMock()
+		skippedMutator := &dataingest_mutation.DataIngestPodMutator{}
+		podWebhook := createTestWebhook(t, []dtwebhook.PodMutator{mutator, skippedMutator}, nil)
+		mutationRequest := createTestMutationRequest(getTestDynakube())
+		mutationRequest.Pod.Annotations = map[string]string{dtwebhook.AnnotationDataIngestInject: "false"}
+		counter := injectionDecisionsMetric.WithLabelValues(decisionSkipped, reasonDisabledByAnnotation, "data-ingest", testDynakubeName)
+		before := testutil.ToFloat64(counter)
+
+		err := podWebhook.handlePodMutation(mutationRequest)
+		require.NoError(t, err)
+		assert.Equal(t, reasonDisabledByAnnotation, mutationRequest.Pod.Annotations[dtwebhook.AnnotationDataIngestReason])
+		assert.Equal(t, "true", mutationRequest.Pod.Annotations[dtwebhook.AnnotationDynatraceInjected])
+		assert.Equal(t, before+1, testutil.ToFloat64(counter))
+	})
+}
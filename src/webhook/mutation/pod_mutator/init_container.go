@@ -12,7 +12,7 @@ import (
 )
 
 func createInstallInitContainerBase(webhookImage, clusterID string, pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) *corev1.Container {
-	return &corev1.Container{
+	initContainer := &corev1.Container{
 		Name:            dtwebhook.InstallContainerName,
 		Image:           webhookImage,
 		ImagePullPolicy: corev1.PullIfNotPresent,
@@ -30,6 +30,10 @@ func createInstallInitContainerBase(webhookImage, clusterID string, pod *corev1.
 		SecurityContext: copyUserContainerSecurityContext(pod),
 		Resources:       *dynakube.InitResources(),
 	}
+	if dynakube.FeatureInjectionReportAnnotation() {
+		initContainer.Env = append(initContainer.Env, corev1.EnvVar{Name: config.InjectionReportAnnotationEnv, Value: "true"})
+	}
+	return initContainer
 }
 
 func copyUserContainerSecurityContext(pod *corev1.Pod) *corev1.SecurityContext {
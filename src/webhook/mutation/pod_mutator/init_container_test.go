@@ -3,6 +3,10 @@ package pod_mutator
 import (
 	"testing"
 
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,5 +22,14 @@ func TestCreateInstallInitContainerBase(t *testing.T) {
 		assert.Equal(t, initContainer.Image, webhookImage)
 		assert.Equal(t, initContainer.Resources, testResourceRequirements)
 		assert.Equal(t, initContainer.SecurityContext, testSecurityContext)
+		assert.NotContains(t, initContainer.Env, corev1.EnvVar{Name: config.InjectionReportAnnotationEnv, Value: "true"})
+	})
+	t.Run("should enable the injection report annotation", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureInjectionReport: "true"}
+		pod := getTestPod()
+		initContainer := createInstallInitContainerBase("test-image", "id", pod, *dynakube)
+		require.NotNil(t, initContainer)
+		assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: config.InjectionReportAnnotationEnv, Value: "true"})
 	})
 }
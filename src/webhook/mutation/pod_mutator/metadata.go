@@ -0,0 +1,49 @@
+package pod_mutator
+
+import (
+	"encoding/json"
+	"path"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addMetadataAttributesEnv passes the pod labels/annotations and namespace labels allowed by the dynakube
+// to the install container, which adds them to the enrichment files and the container.conf
+func addMetadataAttributesEnv(installContainer *corev1.Container, pod *corev1.Pod, namespace corev1.Namespace, dynakube dynatracev1beta1.DynaKube) {
+	attributes := map[string]string{}
+	copyAllowedMetadata(attributes, config.EnrichmentPodLabelPrefix, pod.Labels, dynakube.FeatureMetadataPodLabels())
+	copyAllowedMetadata(attributes, config.EnrichmentPodAnnotationPrefix, pod.Annotations, dynakube.FeatureMetadataPodAnnotations())
+	copyAllowedMetadata(attributes, config.EnrichmentNamespaceLabelPrefix, namespace.Labels, dynakube.FeatureMetadataNamespaceLabels())
+	if len(attributes) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		log.Error(err, "failed to marshal metadata attributes")
+		return
+	}
+	installContainer.Env = append(installContainer.Env, corev1.EnvVar{Name: config.EnrichmentMetadataEnv, Value: string(raw)})
+}
+
+func copyAllowedMetadata(attributes map[string]string, prefix string, source map[string]string, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+	for key, value := range source {
+		if isAllowedMetadataKey(key, patterns) {
+			attributes[prefix+key] = value
+		}
+	}
+}
+
+func isAllowedMetadataKey(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, key); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
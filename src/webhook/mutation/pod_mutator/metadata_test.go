@@ -0,0 +1,71 @@
+package pod_mutator
+
+import (
+	"encoding/json"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddMetadataAttributesEnv(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"team":                   "checkout",
+				"app.kubernetes.io/name": "shop",
+				"pod-template-hash":      "abc",
+			},
+			Annotations: map[string]string{
+				"cost-center": "4711",
+				"other":       "value",
+			},
+		},
+	}
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"owner": "sre",
+			},
+		},
+	}
+
+	t.Run(`no allow-list, no env`, func(t *testing.T) {
+		installContainer := &corev1.Container{}
+
+		addMetadataAttributesEnv(installContainer, pod, namespace, dynatracev1beta1.DynaKube{})
+
+		assert.Empty(t, installContainer.Env)
+	})
+	t.Run(`only allowed keys are added`, func(t *testing.T) {
+		dynakube := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureMetadataPodLabels:       `["team", "app.kubernetes.io/*"]`,
+					dynatracev1beta1.AnnotationFeatureMetadataPodAnnotations:  `["cost-center"]`,
+					dynatracev1beta1.AnnotationFeatureMetadataNamespaceLabels: `["owner"]`,
+				},
+			},
+		}
+		installContainer := &corev1.Container{}
+
+		addMetadataAttributesEnv(installContainer, pod, namespace, dynakube)
+
+		env := kubeobjects.FindEnvVar(installContainer.Env, config.EnrichmentMetadataEnv)
+		require.NotNil(t, env)
+
+		attributes := map[string]string{}
+		require.NoError(t, json.Unmarshal([]byte(env.Value), &attributes))
+		assert.Equal(t, map[string]string{
+			"k8s.pod.label.team":                   "checkout",
+			"k8s.pod.label.app.kubernetes.io/name": "shop",
+			"k8s.pod.annotation.cost-center":       "4711",
+			"k8s.namespace.label.owner":            "sre",
+		}, attributes)
+	})
+}
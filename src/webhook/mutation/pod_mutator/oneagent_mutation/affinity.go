@@ -0,0 +1,38 @@
+package oneagent_mutation
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addCodeModulesNodeAffinity makes sure the pod is only scheduled on nodes, where the csi driver can already provide the code modules.
+// The terms of a required node affinity are ORed, so the requirement is added to every existing term.
+func addCodeModulesNodeAffinity(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) {
+	if !dynakube.NeedsCSIDriver() || !dynakube.FeatureInjectionNodeAffinity() {
+		return
+	}
+
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      dtcsi.CodeModulesReadyLabel(dynakube.Name),
+		Operator: corev1.NodeSelectorOpExists,
+	}
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		nodeSelector.NodeSelectorTerms[i].MatchExpressions = append(nodeSelector.NodeSelectorTerms[i].MatchExpressions, requirement)
+	}
+}
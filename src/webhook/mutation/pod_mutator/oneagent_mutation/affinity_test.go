@@ -0,0 +1,76 @@
+package oneagent_mutation
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddCodeModulesNodeAffinity(t *testing.T) {
+	t.Run("should not add affinity without feature flag", func(t *testing.T) {
+		pod := &corev1.Pod{}
+
+		addCodeModulesNodeAffinity(pod, getNodeAffinityTestDynakube(false))
+
+		assert.Nil(t, pod.Spec.Affinity)
+	})
+	t.Run("should add affinity", func(t *testing.T) {
+		pod := &corev1.Pod{}
+
+		addCodeModulesNodeAffinity(pod, getNodeAffinityTestDynakube(true))
+
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		require.Len(t, terms, 1)
+		require.Len(t, terms[0].MatchExpressions, 1)
+		assert.Equal(t, dtcsi.CodeModulesReadyLabel(testDynakubeName), terms[0].MatchExpressions[0].Key)
+		assert.Equal(t, corev1.NodeSelectorOpExists, terms[0].MatchExpressions[0].Operator)
+	})
+	t.Run("should add requirement to every existing term", func(t *testing.T) {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		addCodeModulesNodeAffinity(pod, getNodeAffinityTestDynakube(true))
+
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		require.Len(t, terms, 2)
+		for _, term := range terms {
+			require.Len(t, term.MatchExpressions, 2)
+			assert.Equal(t, dtcsi.CodeModulesReadyLabel(testDynakubeName), term.MatchExpressions[1].Key)
+		}
+	})
+}
+
+func getNodeAffinityTestDynakube(nodeAffinity bool) dynatracev1beta1.DynaKube {
+	dynakube := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testDynakubeName,
+			Annotations: map[string]string{},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+			},
+		},
+	}
+	if nodeAffinity {
+		dynakube.Annotations[dynatracev1beta1.AnnotationFeatureInjectionNodeAffinity] = "true"
+	}
+	return dynakube
+}
@@ -1,8 +1,12 @@
 package oneagent_mutation
 
 import (
+	"fmt"
 	"net/url"
 
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/arch"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
 	corev1 "k8s.io/api/core/v1"
@@ -16,11 +20,29 @@ type installerInfo struct {
 	failurePolicy string
 }
 
-func setInjectedAnnotation(pod *corev1.Pod) {
+// containerInstallerInfo is the code modules flavor and technologies of a single container,
+// they are empty if the container uses the ones of the pod
+type containerInstallerInfo struct {
+	flavor       string
+	technologies string
+}
+
+// getBinSubPath returns the directory of the bin volume, the flavor of the container is installed into
+func (containerInstaller containerInstallerInfo) getBinSubPath() string {
+	if containerInstaller.flavor == "" {
+		return ""
+	}
+	return fmt.Sprintf(config.AgentFlavorSubPathTemplate, containerInstaller.flavor)
+}
+
+func setInjectedAnnotation(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) {
 	if pod.Annotations == nil {
 		pod.Annotations = make(map[string]string)
 	}
 	pod.Annotations[dtwebhook.AnnotationOneAgentInjected] = "true"
+	if revision := dynakube.CodeModulesRevision(); revision != "" {
+		pod.Annotations[dtwebhook.AnnotationCodeModulesRevision] = revision
+	}
 }
 
 func getInstallerInfo(pod *corev1.Pod) installerInfo {
@@ -32,3 +54,32 @@ func getInstallerInfo(pod *corev1.Pod) installerInfo {
 		failurePolicy: kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationFailurePolicy, "silent"),
 	}
 }
+
+// getContainerInstallerInfo reads the container annotations of the pod, they are only used if the init container downloads the code modules,
+// as the csi driver, the offline package and the installer url provide a single package for the pod
+func (mutator *OneAgentPodMutator) getContainerInstallerInfo(request *dtwebhook.BaseRequest, container *corev1.Container) containerInstallerInfo {
+	installer := getInstallerInfo(request.Pod)
+	if mutator.getVolumeMode(request.DynaKube) != string(config.AgentInstallerMode) || installer.installerURL != "" {
+		return containerInstallerInfo{}
+	}
+
+	containerInstaller := containerInstallerInfo{}
+	flavor := request.Pod.Annotations[dtwebhook.AnnotationContainerFlavorPrefix+container.Name]
+	if isSupportedFlavor(flavor) && flavor != installer.flavor {
+		containerInstaller.flavor = flavor
+	} else if flavor != "" && flavor != installer.flavor {
+		log.Info("ignoring unsupported code modules flavor", "container", container.Name, "flavor", flavor)
+	}
+	if technologies := request.Pod.Annotations[dtwebhook.AnnotationContainerTechnologiesPrefix+container.Name]; technologies != "" {
+		containerInstaller.technologies = url.QueryEscape(technologies)
+	}
+	return containerInstaller
+}
+
+func isSupportedFlavor(flavor string) bool {
+	switch flavor {
+	case arch.FlavorDefault, arch.FlavorMultidistro, arch.FlavorMusl:
+		return true
+	}
+	return false
+}
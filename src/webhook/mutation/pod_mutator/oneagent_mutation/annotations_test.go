@@ -1,5 +1,15 @@
 package oneagent_mutation
 
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/arch"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
 const (
 	testFlavor        = "testFlavor"
 	testTechnologies  = "testTech"
@@ -17,3 +27,76 @@ func getTestInstallerInfo() installerInfo {
 		failurePolicy: testFailurePolicy,
 	}
 }
+
+func TestGetContainerInstallerInfo(t *testing.T) {
+	mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+
+	t.Run("no container annotations", func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), nil)
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Equal(t, containerInstallerInfo{}, containerInstaller)
+		assert.Empty(t, containerInstaller.getBinSubPath())
+	})
+	t.Run("flavor and technologies of the container", func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationContainerFlavorPrefix + "container":       arch.FlavorMusl,
+			dtwebhook.AnnotationContainerTechnologiesPrefix + "container": "java",
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Equal(t, containerInstallerInfo{flavor: arch.FlavorMusl, technologies: "java"}, containerInstaller)
+		assert.Equal(t, "flavors/musl", containerInstaller.getBinSubPath())
+	})
+	t.Run("flavor of the pod is ignored", func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationFlavor:                              arch.FlavorMusl,
+			dtwebhook.AnnotationContainerFlavorPrefix + "container": arch.FlavorMusl,
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Empty(t, containerInstaller.flavor)
+	})
+	t.Run("unsupported flavor is ignored", func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationContainerFlavorPrefix + "container": "../../etc",
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Empty(t, containerInstaller.flavor)
+	})
+	t.Run("ignored with the csi driver", func(t *testing.T) {
+		request := createTestMutationRequest(getTestCSIDynakube(), map[string]string{
+			dtwebhook.AnnotationContainerFlavorPrefix + "container": arch.FlavorMusl,
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Equal(t, containerInstallerInfo{}, containerInstaller)
+	})
+	t.Run("ignored with the installer url", func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationInstallerUrl:                        "https://test-url",
+			dtwebhook.AnnotationContainerFlavorPrefix + "container": arch.FlavorMusl,
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Equal(t, containerInstallerInfo{}, containerInstaller)
+	})
+	t.Run("ignored with the offline installation", func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC: "oneagent-package"}
+		request := createTestMutationRequest(dynakube, map[string]string{
+			dtwebhook.AnnotationContainerFlavorPrefix + "container": arch.FlavorMusl,
+		})
+
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, &request.Pod.Spec.Containers[0])
+
+		assert.Equal(t, containerInstallerInfo{}, containerInstaller)
+	})
+}
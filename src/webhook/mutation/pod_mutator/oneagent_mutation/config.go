@@ -17,6 +17,9 @@ const (
 	oneAgentBinVolumeName     = "oneagent-bin"
 	oneAgentShareVolumeName   = "oneagent-share"
 	injectionConfigVolumeName = "injection-config"
+	oneAgentOfflineVolumeName = "oneagent-offline"
+
+	writableAgentDirsSubPath = "oneagent-writable"
 
 	oneAgentCustomKeysPath = "/var/lib/dynatrace/oneagent/agent/customkeys"
 	customCertFileName     = "custom.pem"
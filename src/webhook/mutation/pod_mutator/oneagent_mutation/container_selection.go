@@ -0,0 +1,49 @@
+package oneagent_mutation
+
+import (
+	"path"
+	"strings"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isContainerExcluded checks if the OneAgent shouldn't be injected into the container.
+// The container annotation of the pod decides, if present, otherwise the container is excluded
+// if it matches any pattern of the pod, the namespace or the dynakube.
+func isContainerExcluded(request *dtwebhook.BaseRequest, container *corev1.Container) bool {
+	if inject, ok := request.Pod.Annotations[dtwebhook.AnnotationContainerInjectPrefix+container.Name]; ok {
+		return inject == "false"
+	}
+
+	patterns := splitPatterns(request.Pod.Annotations[dtwebhook.AnnotationExcludedContainers])
+	patterns = append(patterns, splitPatterns(request.Namespace.Annotations[dtwebhook.AnnotationExcludedContainers])...)
+	patterns = append(patterns, request.DynaKube.FeatureExcludedContainers()...)
+
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, container.Name) || matchesPattern(pattern, container.Image) {
+			log.Info("container excluded from OneAgent injection", "name", container.Name, "pattern", pattern)
+			return true
+		}
+	}
+	return false
+}
+
+func splitPatterns(raw string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(raw, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+func matchesPattern(pattern string, value string) bool {
+	matched, err := path.Match(pattern, value)
+	if err != nil {
+		log.Info("invalid container pattern", "pattern", pattern, "error", err.Error())
+		return false
+	}
+	return matched
+}
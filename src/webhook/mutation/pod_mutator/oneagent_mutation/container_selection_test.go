@@ -0,0 +1,93 @@
+package oneagent_mutation
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestIsContainerExcluded(t *testing.T) {
+	sidecar := &corev1.Container{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.15.0"}
+
+	t.Run(`included by default`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), nil)
+
+		assert.False(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`excluded by container annotation`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{dtwebhook.AnnotationContainerInjectPrefix + sidecar.Name: "false"})
+
+		assert.True(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`excluded by pod annotation`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{dtwebhook.AnnotationExcludedContainers: "fluent-bit, istio-*"})
+
+		assert.True(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`excluded by namespace annotation matching the image`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), nil)
+		request.Namespace.Annotations = map[string]string{dtwebhook.AnnotationExcludedContainers: "docker.io/istio/*"}
+
+		assert.True(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`excluded by feature flag`, func(t *testing.T) {
+		dynakube := getTestDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureExcludedContainers: `["istio-proxy"]`}
+		request := createTestMutationRequest(dynakube, nil)
+
+		assert.True(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`container annotation overrides the excluded containers`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationExcludedContainers:                   "istio-proxy",
+			dtwebhook.AnnotationContainerInjectPrefix + sidecar.Name: "true",
+		})
+
+		assert.False(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+	t.Run(`invalid pattern is ignored`, func(t *testing.T) {
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{dtwebhook.AnnotationExcludedContainers: "[istio"})
+
+		assert.False(t, isContainerExcluded(request.BaseRequest, sidecar))
+	})
+}
+
+func TestMutateUserContainersWithExcludedContainers(t *testing.T) {
+	t.Run(`excluded containers are skipped`, func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{dtwebhook.AnnotationExcludedContainers: "container"})
+		request.Pod.Spec.Containers = append(request.Pod.Spec.Containers, corev1.Container{Name: "app", Image: "app-image"})
+		request.InstallContainer.Env = []corev1.EnvVar{{Name: config.AgentContainerCountEnv, Value: "2"}}
+
+		mutator.mutateUserContainers(request)
+
+		assert.False(t, containerIsInjected(&request.Pod.Spec.Containers[0]))
+		assert.True(t, containerIsInjected(&request.Pod.Spec.Containers[1]))
+		assert.Equal(t, "1", kubeobjects.FindEnvVar(request.InstallContainer.Env, config.AgentContainerCountEnv).Value)
+		assert.Equal(t, "app", kubeobjects.FindEnvVar(request.InstallContainer.Env, getContainerNameEnv(1)).Value)
+	})
+	t.Run(`excluded containers are skipped on reinvocation`, func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		request := createTestReinvocationRequest(getTestDynakube(), map[string]string{dtwebhook.AnnotationContainerInjectPrefix + "container": "false"})
+		request.Pod.Spec.Containers = append(request.Pod.Spec.Containers, corev1.Container{Name: "app", Image: "app-image"})
+		initContainer := findOneAgentInstallContainer(request.Pod.Spec.InitContainers)
+		initContainer.Env = []corev1.EnvVar{{Name: config.AgentContainerCountEnv, Value: "0"}}
+
+		updated := mutator.reinvokeUserContainers(request)
+
+		require.True(t, updated)
+		assert.False(t, containerIsInjected(&request.Pod.Spec.Containers[0]))
+		assert.True(t, containerIsInjected(&request.Pod.Spec.Containers[1]))
+		assert.Equal(t, "1", kubeobjects.FindEnvVar(initContainer.Env, config.AgentContainerCountEnv).Value)
+		assert.Equal(t, "app", kubeobjects.FindEnvVar(initContainer.Env, getContainerNameEnv(1)).Value)
+
+		assert.False(t, mutator.reinvokeUserContainers(request))
+	})
+}
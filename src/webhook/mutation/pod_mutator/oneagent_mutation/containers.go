@@ -1,7 +1,6 @@
 package oneagent_mutation
 
 import (
-	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
 	corev1 "k8s.io/api/core/v1"
@@ -10,14 +9,27 @@ import (
 func (mutator *OneAgentPodMutator) configureInitContainer(request *dtwebhook.MutationRequest, installer installerInfo) {
 	addInstallerInitEnvs(request.InstallContainer, installer, mutator.getVolumeMode(request.DynaKube))
 	addInitVolumeMounts(request.InstallContainer)
+	if request.DynaKube.FeatureOneAgentOfflineInstall() {
+		addOfflineVolumeMount(request.InstallContainer)
+	}
 }
 
+// mutateUserContainers injects every container that isn't excluded,
+// only the injected containers are passed to the install-container
 func (mutator *OneAgentPodMutator) mutateUserContainers(request *dtwebhook.MutationRequest) {
+	injectedContainers := 0
 	for i := range request.Pod.Spec.Containers {
 		container := &request.Pod.Spec.Containers[i]
-		addContainerInfoInitEnv(request.InstallContainer, i+1, container.Name, container.Image)
-		mutator.addOneAgentToContainer(request.Pod, container, request.DynaKube)
+		if isContainerExcluded(request.BaseRequest, container) {
+			continue
+		}
+		injectedContainers++
+		addContainerInfoInitEnv(request.InstallContainer, injectedContainers, container.Name, container.Image)
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, container)
+		addContainerInstallerInitEnv(request.InstallContainer, injectedContainers, containerInstaller)
+		mutator.addOneAgentToContainer(request.BaseRequest, container, containerInstaller)
 	}
+	setContainerCountInitEnv(request.InstallContainer, injectedContainers)
 }
 
 // reinvokeUserContainers mutates each user container that hasn't been injected yet.
@@ -27,29 +39,43 @@ func (mutator *OneAgentPodMutator) reinvokeUserContainers(request *dtwebhook.Rei
 	pod := request.Pod
 	initContainer := findOneAgentInstallContainer(pod.Spec.InitContainers)
 	newContainers := []*corev1.Container{}
+	oldContainersLen := 0
 
 	for i := range pod.Spec.Containers {
 		currentContainer := &pod.Spec.Containers[i]
 		if containerIsInjected(currentContainer) {
+			oldContainersLen++
+			continue
+		}
+		if isContainerExcluded(request.BaseRequest, currentContainer) {
 			continue
 		}
 		newContainers = append(newContainers, currentContainer)
 	}
 
-	oldContainersLen := len(pod.Spec.Containers) - len(newContainers)
 	for i := range newContainers {
 		currentContainer := newContainers[i]
 		addContainerInfoInitEnv(initContainer, oldContainersLen+i+1, currentContainer.Name, currentContainer.Image)
-		mutator.addOneAgentToContainer(request.Pod, currentContainer, request.DynaKube)
+		containerInstaller := mutator.getContainerInstallerInfo(request.BaseRequest, currentContainer)
+		addContainerInstallerInitEnv(initContainer, oldContainersLen+i+1, containerInstaller)
+		mutator.addOneAgentToContainer(request.BaseRequest, currentContainer, containerInstaller)
+	}
+	if len(newContainers) > 0 {
+		setContainerCountInitEnv(initContainer, oldContainersLen+len(newContainers))
 	}
 	return len(newContainers) > 0
 }
 
-func (mutator *OneAgentPodMutator) addOneAgentToContainer(pod *corev1.Pod, container *corev1.Container, dynakube dynatracev1beta1.DynaKube) {
+func (mutator *OneAgentPodMutator) addOneAgentToContainer(request *dtwebhook.BaseRequest, container *corev1.Container, containerInstaller containerInstallerInfo) {
 	log.Info("adding OneAgent to container", "name", container.Name)
+	pod := request.Pod
+	dynakube := request.DynaKube
 	installPath := kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationInstallPath, dtwebhook.DefaultInstallPath)
 
-	addOneAgentVolumeMounts(container, installPath)
+	addOneAgentVolumeMounts(container, installPath, containerInstaller.getBinSubPath())
+	if dynakube.NeedsCSIDriver() && dynakube.FeatureReadOnlyCSIVolume() {
+		addWritableAgentVolumeMounts(container, installPath)
+	}
 	addDeploymentMetadataEnv(container, dynakube, mutator.clusterID)
 	addPreloadEnv(container, installPath)
 
@@ -62,7 +88,7 @@ func (mutator *OneAgentPodMutator) addOneAgentToContainer(pod *corev1.Pod, conta
 	}
 
 	if dynakube.NeedsOneAgentProxy() {
-		addProxyEnv(container)
+		addProxyEnv(container, getInitSecretName(request))
 	}
 
 	if dynakube.Spec.NetworkZone != "" {
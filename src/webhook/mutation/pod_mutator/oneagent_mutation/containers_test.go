@@ -1,8 +1,10 @@
 package oneagent_mutation
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-operator/src/arch"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
@@ -73,6 +75,27 @@ func TestMutateUserContainers(t *testing.T) {
 		assert.Len(t, request.Pod.Spec.Containers[0].VolumeMounts, initialContainerVolumeMountsLen+5)
 		assert.Len(t, request.Pod.Spec.Containers[0].Env, initialNumberOfContainerEnvsLen+4)
 	})
+	t.Run("add flavor and technologies of the container", func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		request := createTestMutationRequest(getTestDynakube(), map[string]string{
+			dtwebhook.AnnotationContainerFlavorPrefix + "container":       arch.FlavorMusl,
+			dtwebhook.AnnotationContainerTechnologiesPrefix + "container": "java,nodejs",
+		})
+
+		mutator.mutateUserContainers(request)
+
+		flavorEnv := kubeobjects.FindEnvVar(request.InstallContainer.Env, fmt.Sprintf(config.AgentContainerFlavorEnvTemplate, 1))
+		require.NotNil(t, flavorEnv)
+		assert.Equal(t, arch.FlavorMusl, flavorEnv.Value)
+		technologiesEnv := kubeobjects.FindEnvVar(request.InstallContainer.Env, fmt.Sprintf(config.AgentContainerTechnologiesEnvTemplate, 1))
+		require.NotNil(t, technologiesEnv)
+		assert.Equal(t, "java%2Cnodejs", technologiesEnv.Value)
+		assert.Contains(t, request.Pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      oneAgentBinVolumeName,
+			MountPath: dtwebhook.DefaultInstallPath,
+			SubPath:   "flavors/" + arch.FlavorMusl,
+		})
+	})
 }
 
 func TestReinvokeUserContainers(t *testing.T) {
@@ -3,6 +3,7 @@ package oneagent_mutation
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
@@ -29,14 +30,14 @@ func addNetworkZoneEnv(container *corev1.Container, networkZone string) {
 	)
 }
 
-func addProxyEnv(container *corev1.Container) {
+func addProxyEnv(container *corev1.Container, initSecretName string) {
 	container.Env = append(container.Env,
 		corev1.EnvVar{
 			Name: proxyEnv,
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: config.AgentInitSecretName,
+						Name: initSecretName,
 					},
 					Key: dynatracev1beta1.ProxyKey,
 				},
@@ -62,6 +63,29 @@ func addContainerInfoInitEnv(initContainer *corev1.Container, containerIndex int
 		corev1.EnvVar{Name: getContainerImageEnv(containerIndex), Value: image})
 }
 
+// addContainerInstallerInitEnv passes the code modules flavor and technologies of the container to the install-container,
+// if they differ from the ones of the pod
+func addContainerInstallerInitEnv(initContainer *corev1.Container, containerIndex int, containerInstaller containerInstallerInfo) {
+	if containerInstaller.flavor != "" {
+		initContainer.Env = append(initContainer.Env,
+			corev1.EnvVar{Name: fmt.Sprintf(config.AgentContainerFlavorEnvTemplate, containerIndex), Value: containerInstaller.flavor})
+	}
+	if containerInstaller.technologies != "" {
+		initContainer.Env = append(initContainer.Env,
+			corev1.EnvVar{Name: fmt.Sprintf(config.AgentContainerTechnologiesEnvTemplate, containerIndex), Value: containerInstaller.technologies})
+	}
+}
+
+// setContainerCountInitEnv updates the number of containers the install-container has to configure,
+// as excluded containers are not passed to it
+func setContainerCountInitEnv(initContainer *corev1.Container, count int) {
+	for i := range initContainer.Env {
+		if initContainer.Env[i].Name == config.AgentContainerCountEnv {
+			initContainer.Env[i].Value = strconv.Itoa(count)
+		}
+	}
+}
+
 func getContainerNameEnv(containerIndex int) string {
 	return fmt.Sprintf(config.AgentContainerNameEnvTemplate, containerIndex)
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/config"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,7 +41,7 @@ func TestAddProxyEnv(t *testing.T) {
 	t.Run("Add proxy env", func(t *testing.T) {
 		container := &corev1.Container{}
 
-		addProxyEnv(container)
+		addProxyEnv(container, config.AgentInitSecretName)
 
 		require.Len(t, container.Env, 1)
 		assert.IsType(t, container.Env[0].ValueFrom, &corev1.EnvVarSource{})
@@ -45,11 +45,12 @@ func (mutator *OneAgentPodMutator) Mutate(request *dtwebhook.MutationRequest) er
 	}
 
 	installerInfo := getInstallerInfo(request.Pod)
-	mutator.addVolumes(request.Pod, request.DynaKube)
+	mutator.addVolumes(request.Pod, request.DynaKube, getInitSecretName(request.BaseRequest))
+	addCodeModulesNodeAffinity(request.Pod, request.DynaKube)
 	mutator.configureInitContainer(request, installerInfo)
 	mutator.mutateUserContainers(request)
 	addInjectionConfigVolumeMount(request.InstallContainer)
-	setInjectedAnnotation(request.Pod)
+	setInjectedAnnotation(request.Pod, request.DynaKube)
 	return nil
 }
 
@@ -64,16 +65,19 @@ func (mutator *OneAgentPodMutator) Reinvoke(request *dtwebhook.ReinvocationReque
 func (mutator *OneAgentPodMutator) getVolumeMode(dynakube dynatracev1beta1.DynaKube) string {
 	if dynakube.NeedsCSIDriver() {
 		return string(config.AgentCsiMode)
+	} else if dynakube.FeatureOneAgentOfflineInstall() {
+		return string(config.AgentOfflineMode)
 	}
 	return string(config.AgentInstallerMode)
 }
 
 func (mutator *OneAgentPodMutator) ensureInitSecret(request *dtwebhook.MutationRequest) error {
 	var initSecret corev1.Secret
-	secretObjectKey := client.ObjectKey{Name: config.AgentInitSecretName, Namespace: request.Namespace.Name}
+	initSecretName := getInitSecretName(request.BaseRequest)
+	secretObjectKey := client.ObjectKey{Name: initSecretName, Namespace: request.Namespace.Name}
 	if err := mutator.apiReader.Get(request.Context, secretObjectKey, &initSecret); k8serrors.IsNotFound(err) {
 		initGenerator := initgeneration.NewInitGenerator(mutator.client, mutator.apiReader, mutator.webhookNamespace)
-		err := initGenerator.GenerateForNamespace(request.Context, request.DynaKube, request.Namespace.Name)
+		err := initGenerator.GenerateForNamespaceWithName(request.Context, request.DynaKube, request.Namespace.Name, initSecretName)
 		if err != nil {
 			log.Error(err, "failed to create the init secret before oneagent pod injection")
 			return errors.WithStack(err)
@@ -86,6 +90,11 @@ func (mutator *OneAgentPodMutator) ensureInitSecret(request *dtwebhook.MutationR
 	return nil
 }
 
+// getInitSecretName returns the name of the init secret of the dynakube, see dtwebhook.SecretNameForDynakube
+func getInitSecretName(request *dtwebhook.BaseRequest) string {
+	return dtwebhook.SecretNameForDynakube(config.AgentInitSecretName, request.Namespace, request.DynaKube.Name)
+}
+
 func containerIsInjected(container *corev1.Container) bool {
 	for _, e := range container.Env {
 		if e.Name == dynatraceMetadataEnv {
@@ -90,6 +90,13 @@ func TestGetVolumeMode(t *testing.T) {
 
 		assert.Equal(t, string(config.AgentInstallerMode), mutator.getVolumeMode(*getTestDynakube()))
 	})
+	t.Run("should return offline volume mode", func(t *testing.T) {
+		mutator := createTestPodMutator(nil)
+		dynakube := getTestDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC: "oneagent-package"}
+
+		assert.Equal(t, string(config.AgentOfflineMode), mutator.getVolumeMode(*dynakube))
+	})
 }
 
 func TestEnsureInitSecret(t *testing.T) {
@@ -151,6 +158,18 @@ func TestMutate(t *testing.T) {
 		assert.Len(t, request.InstallContainer.Env, 6+(initialContainersLen*2))
 		assert.Len(t, request.InstallContainer.VolumeMounts, 3)
 	})
+	t.Run("should record the code modules revision", func(t *testing.T) {
+		mutator := createTestPodMutator([]client.Object{getTestInitSecret()})
+		dynakube := getTestDynakube()
+		dynakube.Spec.OneAgent.ApplicationMonitoring.Version = "1.2.3"
+		request := createTestMutationRequest(dynakube, nil)
+
+		err := mutator.Mutate(request)
+		require.NoError(t, err)
+
+		assert.Equal(t, "true", request.Pod.Annotations[dtwebhook.AnnotationOneAgentInjected])
+		assert.Equal(t, "1.2.3", request.Pod.Annotations[dtwebhook.AnnotationCodeModulesRevision])
+	})
 }
 
 func TestReinvoke(t *testing.T) {
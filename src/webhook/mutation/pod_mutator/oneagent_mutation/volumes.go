@@ -9,15 +9,18 @@ import (
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
 	appvolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes/app"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects/address"
 	corev1 "k8s.io/api/core/v1"
 )
 
-func (mutator *OneAgentPodMutator) addVolumes(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) {
-	addInjectionConfigVolume(pod)
+func (mutator *OneAgentPodMutator) addVolumes(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube, initSecretName string) {
+	addInjectionConfigVolume(pod, initSecretName)
 	addOneAgentVolumes(pod, dynakube)
 }
 
-func addOneAgentVolumeMounts(container *corev1.Container, installPath string) {
+// addOneAgentVolumeMounts mounts the code modules at the install path, the flavor of a single container is mounted from its
+// sub path of the bin volume, so the preload path is the same for every flavor
+func addOneAgentVolumeMounts(container *corev1.Container, installPath string, binSubPath string) {
 	container.VolumeMounts = append(container.VolumeMounts,
 		corev1.VolumeMount{
 			Name:      oneAgentShareVolumeName,
@@ -27,6 +30,7 @@ func addOneAgentVolumeMounts(container *corev1.Container, installPath string) {
 		corev1.VolumeMount{
 			Name:      oneAgentBinVolumeName,
 			MountPath: installPath,
+			SubPath:   binSubPath,
 		},
 		corev1.VolumeMount{
 			Name:      oneAgentShareVolumeName,
@@ -35,6 +39,19 @@ func addOneAgentVolumeMounts(container *corev1.Container, installPath string) {
 		})
 }
 
+// addWritableAgentVolumeMounts makes the writable directories of the agent available,
+// when the csi volume is read-only and can't provide them itself
+func addWritableAgentVolumeMounts(container *corev1.Container, installPath string) {
+	for _, writableDir := range dtcsi.AgentWritableDirs {
+		container.VolumeMounts = append(container.VolumeMounts,
+			corev1.VolumeMount{
+				Name:      oneAgentShareVolumeName,
+				MountPath: filepath.Join(installPath, writableDir),
+				SubPath:   filepath.Join(writableAgentDirsSubPath, writableDir),
+			})
+	}
+}
+
 func getContainerConfSubPath(containerName string) string {
 	return fmt.Sprintf(config.AgentContainerConfFilenameTemplate, containerName)
 }
@@ -55,6 +72,13 @@ func addInitVolumeMounts(initContainer *corev1.Container) {
 	)
 }
 
+// addOfflineVolumeMount makes the pre-seeded OneAgent package available to the init container
+func addOfflineVolumeMount(initContainer *corev1.Container) {
+	initContainer.VolumeMounts = append(initContainer.VolumeMounts,
+		corev1.VolumeMount{Name: oneAgentOfflineVolumeName, MountPath: config.AgentOfflineDirMount, ReadOnly: true},
+	)
+}
+
 func addCurlOptionsVolumeMount(container *corev1.Container) {
 	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
 		Name:      oneAgentShareVolumeName,
@@ -63,13 +87,13 @@ func addCurlOptionsVolumeMount(container *corev1.Container) {
 	})
 }
 
-func addInjectionConfigVolume(pod *corev1.Pod) {
+func addInjectionConfigVolume(pod *corev1.Pod, initSecretName string) {
 	pod.Spec.Volumes = append(pod.Spec.Volumes,
 		corev1.Volume{
 			Name: injectionConfigVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
-					SecretName: config.AgentInitSecretName,
+					SecretName: initSecretName,
 				},
 			},
 		},
@@ -95,6 +119,24 @@ func addOneAgentVolumes(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) {
 			},
 		},
 	)
+	if dynakube.FeatureOneAgentOfflineInstall() {
+		pod.Spec.Volumes = append(pod.Spec.Volumes,
+			corev1.Volume{
+				Name:         oneAgentOfflineVolumeName,
+				VolumeSource: getOfflineVolumeSource(dynakube),
+			},
+		)
+	}
+}
+
+// getOfflineVolumeSource returns the claim with the pre-seeded OneAgent package, it's mounted read-only
+func getOfflineVolumeSource(dynakube dynatracev1beta1.DynaKube) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: dynakube.FeatureOneAgentOfflinePVC(),
+			ReadOnly:  true,
+		},
+	}
 }
 
 func getInstallerVolumeSource(dynakube dynatracev1beta1.DynaKube) corev1.VolumeSource {
@@ -107,6 +149,9 @@ func getInstallerVolumeSource(dynakube dynatracev1beta1.DynaKube) corev1.VolumeS
 				csivolumes.CSIVolumeAttributeDynakubeField: dynakube.Name,
 			},
 		}
+		if dynakube.FeatureReadOnlyCSIVolume() {
+			volumeSource.CSI.ReadOnly = address.Of(true)
+		}
 	} else {
 		volumeSource.EmptyDir = &corev1.EmptyDirVolumeSource{}
 	}
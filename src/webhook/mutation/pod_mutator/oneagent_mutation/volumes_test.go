@@ -6,9 +6,11 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/config"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestAddOneAgentVolumeMounts(t *testing.T) {
@@ -16,11 +18,24 @@ func TestAddOneAgentVolumeMounts(t *testing.T) {
 		container := &corev1.Container{}
 		installPath := "test/path"
 
-		addOneAgentVolumeMounts(container, installPath)
+		addOneAgentVolumeMounts(container, installPath, "")
 		require.Len(t, container.VolumeMounts, 3)
 	})
 }
 
+func TestAddWritableAgentVolumeMounts(t *testing.T) {
+	t.Run("should add writable agent directories", func(t *testing.T) {
+		container := &corev1.Container{}
+		installPath := "test/path"
+
+		addWritableAgentVolumeMounts(container, installPath)
+		require.Len(t, container.VolumeMounts, len(dtcsi.AgentWritableDirs))
+		assert.Equal(t, oneAgentShareVolumeName, container.VolumeMounts[0].Name)
+		assert.Equal(t, filepath.Join(installPath, dtcsi.AgentWritableDirs[0]), container.VolumeMounts[0].MountPath)
+		assert.Equal(t, filepath.Join(writableAgentDirsSubPath, dtcsi.AgentWritableDirs[0]), container.VolumeMounts[0].SubPath)
+	})
+}
+
 func TestAddCertVolumeMounts(t *testing.T) {
 	t.Run("should add cert volume mounts", func(t *testing.T) {
 		container := &corev1.Container{}
@@ -65,6 +80,26 @@ func TestAddOneAgentVolumes(t *testing.T) {
 		addOneAgentVolumes(pod, dynakube)
 		require.Len(t, pod.Spec.Volumes, 2)
 		assert.NotNil(t, pod.Spec.Volumes[0].VolumeSource.CSI)
+		assert.Nil(t, pod.Spec.Volumes[0].VolumeSource.CSI.ReadOnly)
+	})
+
+	t.Run("should add read-only csi volume", func(t *testing.T) {
+		pod := &corev1.Pod{}
+		dynakube := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureReadOnlyCSIVolume: "true"},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+				},
+			},
+		}
+
+		addOneAgentVolumes(pod, dynakube)
+		require.Len(t, pod.Spec.Volumes, 2)
+		require.NotNil(t, pod.Spec.Volumes[0].VolumeSource.CSI)
+		assert.True(t, *pod.Spec.Volumes[0].VolumeSource.CSI.ReadOnly)
 	})
 
 	t.Run("should add oneagent volumes, without csi", func(t *testing.T) {
@@ -81,4 +116,36 @@ func TestAddOneAgentVolumes(t *testing.T) {
 		require.Len(t, pod.Spec.Volumes, 2)
 		assert.NotNil(t, pod.Spec.Volumes[0].VolumeSource.EmptyDir)
 	})
+
+	t.Run("should add offline volume from pvc", func(t *testing.T) {
+		pod := &corev1.Pod{}
+		dynakube := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC: "oneagent-package"},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+				},
+			},
+		}
+
+		addOneAgentVolumes(pod, dynakube)
+		require.Len(t, pod.Spec.Volumes, 3)
+		assert.Equal(t, oneAgentOfflineVolumeName, pod.Spec.Volumes[2].Name)
+		require.NotNil(t, pod.Spec.Volumes[2].VolumeSource.PersistentVolumeClaim)
+		assert.Equal(t, "oneagent-package", pod.Spec.Volumes[2].VolumeSource.PersistentVolumeClaim.ClaimName)
+		assert.True(t, pod.Spec.Volumes[2].VolumeSource.PersistentVolumeClaim.ReadOnly)
+	})
+}
+
+func TestAddOfflineVolumeMount(t *testing.T) {
+	t.Run("should add read-only offline volume mount", func(t *testing.T) {
+		container := &corev1.Container{}
+
+		addOfflineVolumeMount(container)
+		require.Len(t, container.VolumeMounts, 1)
+		assert.Equal(t, config.AgentOfflineDirMount, container.VolumeMounts[0].MountPath)
+		assert.True(t, container.VolumeMounts[0].ReadOnly)
+	})
 }
@@ -38,6 +38,9 @@ type podMutatorWebhook struct {
 	clusterID        string
 	apmExists        bool
 	deployedViaOLM   bool
+	auditLogEnabled  bool
+	// isPreview excludes the decisions of injection previews from the metrics
+	isPreview bool
 
 	mutators []dtwebhook.PodMutator
 }
@@ -52,12 +55,13 @@ func (webhook *podMutatorWebhook) Handle(ctx context.Context, request admission.
 	emptyPatch := admission.Patched("")
 
 	if webhook.apmExists {
+		webhook.recordDecision(newPodDecision(decisionSkipped, reasonApmExists, nil, request.Namespace, ""))
 		return emptyPatch
 	}
 
 	mutationRequest, err := webhook.createMutationRequestBase(ctx, request)
 	if err != nil {
-		return silentErrorResponse(mutationRequest.Pod, err)
+		return silentErrorResponse(nil, err)
 	}
 	if noMutationRequired(mutationRequest) {
 		return emptyPatch
@@ -77,9 +81,10 @@ func (webhook *podMutatorWebhook) Handle(ctx context.Context, request admission.
 		return emptyPatch
 	}
 
+	originalPod := mutationRequest.Pod.DeepCopy()
 	if err := webhook.handlePodMutation(mutationRequest); err != nil {
 		log.Error(err, "failed to inject into pod")
-		return silentErrorResponse(mutationRequest.Pod, err)
+		return failedResponseForPod(originalPod, webhook.mutators, request, err)
 	}
 	log.Info("injection finished for pod", "podName", podName, "namespace", request.Namespace)
 
@@ -106,14 +111,22 @@ func (webhook *podMutatorWebhook) isInjected(mutationRequest *dtwebhook.Mutation
 
 func (webhook *podMutatorWebhook) handlePodMutation(mutationRequest *dtwebhook.MutationRequest) error {
 	mutationRequest.InstallContainer = createInstallInitContainerBase(webhook.webhookImage, webhook.clusterID, mutationRequest.Pod, mutationRequest.DynaKube)
+	addMetadataAttributesEnv(mutationRequest.InstallContainer, mutationRequest.Pod, mutationRequest.Namespace, mutationRequest.DynaKube)
 	isMutated := false
 	for _, mutator := range webhook.mutators {
 		if !mutator.Enabled(mutationRequest.BaseRequest) {
+			reason := getSkipReason(mutator, mutationRequest.BaseRequest)
+			webhook.recordDecision(newMutatorDecision(decisionSkipped, reason, mutator, mutationRequest.BaseRequest))
+			setReasonAnnotation(mutationRequest.Pod, mutator, reason)
 			continue
 		}
 		if err := mutator.Mutate(mutationRequest); err != nil {
+			decision := newMutatorDecision(decisionFailed, reasonMutationError, mutator, mutationRequest.BaseRequest)
+			decision.err = err
+			webhook.recordDecision(decision)
 			return err
 		}
+		webhook.recordDecision(newMutatorDecision(decisionInjected, reasonEnabled, mutator, mutationRequest.BaseRequest))
 		isMutated = true
 	}
 	if !isMutated {
@@ -131,6 +144,7 @@ func (webhook *podMutatorWebhook) handlePodReinvocation(mutationRequest *dtwebho
 	var needsUpdate bool
 
 	if mutationRequest.DynaKube.FeatureDisableWebhookReinvocationPolicy() {
+		webhook.recordDecision(newPodDecision(decisionSkipped, reasonReinvocationDisabled, mutationRequest.Pod, mutationRequest.Namespace.Name, mutationRequest.DynaKube.Name))
 		return false
 	}
 
@@ -138,7 +152,10 @@ func (webhook *podMutatorWebhook) handlePodReinvocation(mutationRequest *dtwebho
 	for _, mutator := range webhook.mutators {
 		if mutator.Enabled(mutationRequest.BaseRequest) {
 			if update := mutator.Reinvoke(reinvocationRequest); update {
+				webhook.recordDecision(newMutatorDecision(decisionReinvoked, reasonNewContainers, mutator, mutationRequest.BaseRequest))
 				needsUpdate = true
+			} else {
+				webhook.recordDecision(newMutatorDecision(decisionSkipped, reasonAlreadyInjected, mutator, mutationRequest.BaseRequest))
 			}
 		}
 	}
@@ -161,9 +178,26 @@ func createResponseForPod(pod *corev1.Pod, req admission.Request) admission.Resp
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
 }
 
+// failedResponseForPod only adds the reason of the failure to the pod, the pod is otherwise left untouched
+func failedResponseForPod(pod *corev1.Pod, mutators []dtwebhook.PodMutator, req admission.Request, err error) admission.Response {
+	annotated := false
+	for _, mutator := range mutators {
+		annotated = setReasonAnnotation(pod, mutator, reasonMutationError) || annotated
+	}
+	if !annotated {
+		return silentErrorResponse(pod, err)
+	}
+	rsp := createResponseForPod(pod, req)
+	rsp.Result = silentErrorResponse(pod, err).Result
+	return rsp
+}
+
 func silentErrorResponse(pod *corev1.Pod, err error) admission.Response {
 	rsp := admission.Patched("")
-	podName := pod.GenerateName
+	podName := ""
+	if pod != nil {
+		podName = pod.GenerateName
+	}
 	log.Error(err, "failed to inject into pod", "podName", podName)
 	rsp.Result.Message = fmt.Sprintf("Failed to inject into pod: %s because %s", podName, err.Error())
 	return rsp
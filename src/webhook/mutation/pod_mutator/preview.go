@@ -0,0 +1,227 @@
+package pod_mutator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	"gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	previewDynakubeParam = "dynakube"
+	maxPreviewBodySize   = 1 << 20
+)
+
+// dynatraceAnnotationDomains are the annotations the mutators read, they are listed in the preview
+var dynatraceAnnotationDomains = []string{
+	"dynatrace.com/",
+	strings.TrimSuffix(dtwebhook.AnnotationContainerInjectPrefix, "/"),
+}
+
+// InjectionPreview is the result of running the pod mutation without changing anything in the cluster
+type InjectionPreview struct {
+	Namespace            string                         `json:"namespace"`
+	DynaKube             string                         `json:"dynakube"`
+	Decisions            []string                       `json:"decisions"`
+	PodAnnotations       map[string]string              `json:"podAnnotations,omitempty"`
+	NamespaceAnnotations map[string]string              `json:"namespaceAnnotations,omitempty"`
+	Patch                []jsonpatch.JsonPatchOperation `json:"patch"`
+	Pod                  *corev1.Pod                    `json:"pod"`
+}
+
+func (preview *InjectionPreview) decide(format string, args ...interface{}) {
+	preview.Decisions = append(preview.Decisions, fmt.Sprintf(format, args...))
+}
+
+// InjectionPreviewer runs the same mutators as the webhook, but every write to the cluster is done as a dry-run
+// and no events are sent
+type InjectionPreviewer struct {
+	webhook *podMutatorWebhook
+}
+
+func NewInjectionPreviewer(kubeClient client.Client, apiReader client.Reader, metaClient client.Client, webhookImage, webhookNamespace, clusterID string) *InjectionPreviewer {
+	return &InjectionPreviewer{
+		webhook: &podMutatorWebhook{
+			apiReader:        apiReader,
+			webhookNamespace: webhookNamespace,
+			webhookImage:     webhookImage,
+			clusterID:        clusterID,
+			// a FakeRecorder without channel discards the events
+			recorder:  newPodMutatorEventRecorder(&record.FakeRecorder{}),
+			isPreview: true,
+			mutators: createMutators(webhookImage, clusterID, webhookNamespace,
+				client.NewDryRunClient(kubeClient), apiReader, client.NewDryRunClient(metaClient)),
+		},
+	}
+}
+
+// NewInjectionPreviewerForCluster looks up the image of the webhook deployment and the id of the cluster,
+// so the preview can be done outside of the webhook
+func NewInjectionPreviewerForCluster(ctx context.Context, kubeClient client.Client, webhookNamespace string) (*InjectionPreviewer, error) {
+	webhookImage, err := getWebhookDeploymentImage(ctx, kubeClient, webhookNamespace)
+	if err != nil {
+		return nil, err
+	}
+	clusterID, err := getClusterID(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	return NewInjectionPreviewer(kubeClient, kubeClient, kubeClient, webhookImage, webhookNamespace, clusterID), nil
+}
+
+// Preview mutates a copy of the pod, the dynakube of the namespace is used if no dynakubeName is given
+func (previewer *InjectionPreviewer) Preview(ctx context.Context, pod *corev1.Pod, dynakubeName string) (*InjectionPreview, error) {
+	// the event recorder holds the pod, so every preview works on its own copy of the webhook
+	webhook := *previewer.webhook
+	if pod.Namespace == "" {
+		return nil, errors.New("the namespace of the pod is required for the preview")
+	}
+
+	var namespace corev1.Namespace
+	if err := webhook.apiReader.Get(ctx, client.ObjectKey{Name: pod.Namespace}, &namespace); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	preview := &InjectionPreview{
+		Namespace:            namespace.Name,
+		PodAnnotations:       filterDynatraceAnnotations(pod.Annotations),
+		NamespaceAnnotations: filterDynatraceAnnotations(namespace.Annotations),
+	}
+
+	var dynakube *dynatracev1beta1.DynaKube
+	if dynakubeName == "" {
+		name, err := getDynakubeName(namespace, false)
+		if err != nil {
+			return nil, err
+		}
+		preview.decide("dynakube %s is assigned by the %s label of the namespace", name, dtwebhook.InjectionInstanceLabel)
+		if routedNames := dtwebhook.RoutedDynakubeNames(namespace); len(routedNames) > 0 {
+			preview.decide("the namespace is shared with the dynakubes %s by the %s annotation", strings.Join(routedNames, ", "), dtwebhook.InjectionRoutedInstancesAnnotation)
+		}
+		dynakube, err = webhook.routeToDynakube(ctx, namespace, pod, name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if dynakube == nil {
+			preview.decide("no dynakube selects the pod by its %s annotation, the pod is not injected", dynatracev1beta1.AnnotationFeatureInjectionPodSelector)
+			return preview, nil
+		}
+		if dynakube.Name != name {
+			preview.decide("dynakube %s is selected by its pod selector", dynakube.Name)
+		}
+	} else {
+		preview.decide("dynakube %s was given for the preview, the namespace label is ignored", dynakubeName)
+		var err error
+		dynakube, err = webhook.getDynakube(ctx, dynakubeName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	preview.DynaKube = dynakube.Name
+
+	originalPod, err := json.Marshal(pod)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mutationRequest := dtwebhook.NewMutationRequest(ctx, namespace, nil, pod.DeepCopy(), *dynakube)
+	webhook.setupEventRecorder(mutationRequest)
+
+	if webhook.isInjected(mutationRequest) {
+		preview.decide("pod is already injected, only the reinvocation is applied")
+		if dynakube.FeatureDisableWebhookReinvocationPolicy() {
+			preview.decide("reinvocation is disabled by the %s feature flag", "webhook-reinvocation-policy")
+		}
+		for _, mutator := range webhook.mutators {
+			preview.decide(describeMutatorDecision(mutator, mutationRequest.BaseRequest))
+		}
+		if !webhook.handlePodReinvocation(mutationRequest) {
+			preview.decide("reinvocation made no change")
+		}
+	} else {
+		for _, mutator := range webhook.mutators {
+			preview.decide(describeMutatorDecision(mutator, mutationRequest.BaseRequest))
+		}
+		if err := webhook.handlePodMutation(mutationRequest); err != nil {
+			return nil, err
+		}
+	}
+
+	mutatedPod, err := json.Marshal(mutationRequest.Pod)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	response := admission.PatchResponseFromRaw(originalPod, mutatedPod)
+	preview.Patch = response.Patches
+	preview.Pod = mutationRequest.Pod
+	return preview, nil
+}
+
+// ServeHTTP accepts a pod as json or yaml and responds with the preview as json,
+// the dynakube can be given with the "dynakube" query parameter
+func (previewer *InjectionPreviewer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxPreviewBodySize))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var pod corev1.Pod
+	if err := yaml.Unmarshal(body, &pod); err != nil {
+		http.Error(writer, fmt.Sprintf("failed to decode pod: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	preview, err := previewer.Preview(request.Context(), &pod, request.URL.Query().Get(previewDynakubeParam))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(preview); err != nil {
+		log.Error(err, "failed to write injection preview")
+	}
+}
+
+func describeMutatorDecision(mutator dtwebhook.PodMutator, request *dtwebhook.BaseRequest) string {
+	info := getMutatorInfo(mutator)
+	name, annotation := info.name, info.injectAnnotation
+	state := "skipped"
+	if mutator.Enabled(request) {
+		state = "enabled"
+	}
+	if value, ok := request.Pod.Annotations[annotation]; ok && annotation != "" {
+		return fmt.Sprintf("%s mutator %s by the pod annotation %s=%s", name, state, annotation, value)
+	}
+	return fmt.Sprintf("%s mutator %s by the feature flags of the dynakube", name, state)
+}
+
+func filterDynatraceAnnotations(annotations map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for key, value := range annotations {
+		for _, domain := range dynatraceAnnotationDomains {
+			if strings.Contains(key, domain) {
+				filtered[key] = value
+				break
+			}
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
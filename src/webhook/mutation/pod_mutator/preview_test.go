@@ -0,0 +1,125 @@
+package pod_mutator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPreview(t *testing.T) {
+	t.Run(`mutates a copy of the pod`, func(t *testing.T) {
+		mutator := createSimplePodMutatorMock()
+		previewer := createTestPreviewer(t, mutator)
+		pod := getTestPod()
+		pod.Annotations = map[string]string{dtwebhook.AnnotationOneAgentInject: "true", "other": "value"}
+
+		preview, err := previewer.Preview(context.TODO(), pod, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, testDynakubeName, preview.DynaKube)
+		assert.Equal(t, testNamespaceName, preview.Namespace)
+		assert.Contains(t, preview.Decisions[0], dtwebhook.InjectionInstanceLabel)
+		assert.Equal(t, map[string]string{dtwebhook.AnnotationOneAgentInject: "true"}, preview.PodAnnotations)
+		assert.NotEmpty(t, preview.Patch)
+		assert.Len(t, preview.Pod.Spec.InitContainers, 2)
+		assert.Len(t, pod.Spec.InitContainers, 1)
+		mutator.(*dtwebhook.PodMutatorMock).AssertNumberOfCalls(t, "Mutate", 1)
+	})
+	t.Run(`given dynakube overrides the namespace label`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+
+		preview, err := previewer.Preview(context.TODO(), getTestPod(), testDynakubeName)
+
+		require.NoError(t, err)
+		assert.Contains(t, preview.Decisions[0], "was given")
+	})
+	t.Run(`already injected pod is reinvoked`, func(t *testing.T) {
+		mutator := createAlreadyInjectedPodMutatorMock()
+		previewer := createTestPreviewer(t, mutator)
+
+		preview, err := previewer.Preview(context.TODO(), getTestPod(), "")
+
+		require.NoError(t, err)
+		assert.Contains(t, preview.Decisions[1], "reinvocation")
+		mutator.(*dtwebhook.PodMutatorMock).AssertNumberOfCalls(t, "Mutate", 0)
+		mutator.(*dtwebhook.PodMutatorMock).AssertNumberOfCalls(t, "Reinvoke", 1)
+	})
+	t.Run(`previewer of NewInjectionPreviewer sends no events for the preview pod`, func(t *testing.T) {
+		clt := fake.NewClient(getTestDynakube(), getTestNamespace())
+		previewer := NewInjectionPreviewer(clt, clt, clt, testImage, testNamespaceName, testClusterID)
+		mutator := createSimplePodMutatorMock()
+		previewer.webhook.mutators = []dtwebhook.PodMutator{mutator}
+
+		preview, err := previewer.Preview(context.TODO(), getTestPod(), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, testDynakubeName, preview.DynaKube)
+		assert.Len(t, preview.Pod.Spec.InitContainers, 2)
+		assert.Nil(t, previewer.webhook.recorder.pod)
+		mutator.(*dtwebhook.PodMutatorMock).AssertNumberOfCalls(t, "Mutate", 1)
+	})
+	t.Run(`namespace is required`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+		pod := getTestPod()
+		pod.Namespace = ""
+
+		_, err := previewer.Preview(context.TODO(), pod, "")
+
+		require.Error(t, err)
+	})
+	t.Run(`missing dynakube`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+
+		_, err := previewer.Preview(context.TODO(), getTestPod(), "unknown")
+
+		require.Error(t, err)
+	})
+}
+
+func TestPreviewServeHTTP(t *testing.T) {
+	t.Run(`responds with the preview`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+		body, err := json.Marshal(getTestPod())
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+
+		previewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/inject/preview?dynakube="+testDynakubeName, strings.NewReader(string(body))))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var preview InjectionPreview
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &preview))
+		assert.Equal(t, testDynakubeName, preview.DynaKube)
+		assert.NotEmpty(t, preview.Patch)
+	})
+	t.Run(`invalid pod`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+		recorder := httptest.NewRecorder()
+
+		previewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/inject/preview", strings.NewReader("{")))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run(`only post`, func(t *testing.T) {
+		previewer := createTestPreviewer(t, createSimplePodMutatorMock())
+		recorder := httptest.NewRecorder()
+
+		previewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/inject/preview", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
+
+func createTestPreviewer(t *testing.T, mutators ...dtwebhook.PodMutator) *InjectionPreviewer {
+	return &InjectionPreviewer{
+		webhook: createTestWebhook(t, mutators, []client.Object{getTestDynakube(), getTestNamespace()}),
+	}
+}
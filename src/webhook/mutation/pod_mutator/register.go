@@ -3,6 +3,7 @@ package pod_mutator
 import (
 	"context"
 	"net/http"
+	"os"
 
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
@@ -10,6 +11,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/dataingest_mutation"
 	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation/pod_mutator/oneagent_mutation"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -60,28 +62,39 @@ func registerInjectEndpoint(mgr manager.Manager, webhookNamespace string, webhoo
 		webhookImage:     webhookPodImage,
 		apmExists:        apmExists,
 		deployedViaOLM:   deployedViaOLM,
+		auditLogEnabled:  os.Getenv(auditLogEnv) == "true",
 		clusterID:        clusterID,
 		recorder:         newPodMutatorEventRecorder(mgr.GetEventRecorderFor("Webhook Server")),
-		mutators: []dtwebhook.PodMutator{
-			oneagent_mutation.NewOneAgentPodMutator(
-				webhookPodImage,
-				clusterID,
-				webhookNamespace,
-				kubeClient,
-				apiReader,
-			),
-			dataingest_mutation.NewDataIngestPodMutator(
-				webhookNamespace,
-				kubeClient,
-				apiReader,
-				metaClient,
-			),
-		},
+		mutators:         createMutators(webhookPodImage, clusterID, webhookNamespace, kubeClient, apiReader, metaClient),
 	}})
 	log.Info("registered /inject endpoint")
+
+	// the preview isn't authenticated, so it is only served if it is enabled explicitly
+	if os.Getenv(injectionPreviewEnv) == "true" {
+		mgr.GetWebhookServer().Register("/inject/preview", NewInjectionPreviewer(kubeClient, apiReader, metaClient, webhookPodImage, webhookNamespace, clusterID))
+		log.Info("registered /inject/preview endpoint")
+	}
 	return nil
 }
 
+func createMutators(webhookImage, clusterID, webhookNamespace string, kubeClient client.Client, apiReader client.Reader, metaClient client.Client) []dtwebhook.PodMutator {
+	return []dtwebhook.PodMutator{
+		oneagent_mutation.NewOneAgentPodMutator(
+			webhookImage,
+			clusterID,
+			webhookNamespace,
+			kubeClient,
+			apiReader,
+		),
+		dataingest_mutation.NewDataIngestPodMutator(
+			webhookNamespace,
+			kubeClient,
+			apiReader,
+			metaClient,
+		),
+	}
+}
+
 func registerLivezEndpoint(mgr manager.Manager) {
 	mgr.GetWebhookServer().Register("/livez", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -105,6 +118,19 @@ func getWebhookContainerImage(apiReader client.Reader, podName string, namespace
 	return webhookContainer.Image, nil
 }
 
+func getWebhookDeploymentImage(ctx context.Context, apiReader client.Reader, namespaceName string) (string, error) {
+	var deployment appsv1.Deployment
+	if err := apiReader.Get(ctx, client.ObjectKey{Name: dtwebhook.DeploymentName, Namespace: namespaceName}, &deployment); err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == dtwebhook.WebhookContainerName {
+			return container.Image, nil
+		}
+	}
+	return "", errors.Errorf("no %s container in the webhook deployment", dtwebhook.WebhookContainerName)
+}
+
 func getClusterID(apiReader client.Reader) (string, error) {
 	var clusterUID types.UID
 	var err error
@@ -15,30 +15,49 @@ import (
 func (webhook *podMutatorWebhook) createMutationRequestBase(ctx context.Context, request admission.Request) (*dtwebhook.MutationRequest, error) {
 	pod, err := getPodFromRequest(request, webhook.decoder)
 	if err != nil {
+		webhook.recordRequestFailure(reasonInvalidRequest, nil, request.Namespace, "", err)
 		return nil, err
 	}
 	namespace, err := getNamespaceFromRequest(ctx, webhook.apiReader, request)
 	if err != nil {
+		webhook.recordRequestFailure(reasonInvalidRequest, pod, request.Namespace, "", err)
 		return nil, err
 	}
 	dynakubeName, err := getDynakubeName(*namespace, webhook.deployedViaOLM)
 	if err != nil && !webhook.deployedViaOLM {
+		webhook.recordRequestFailure(reasonNoDynakubeAssigned, pod, namespace.Name, "", err)
 		return nil, err
 	} else if err != nil {
 		// in case of olm deployment, all pods are sent to us
 		// but not all of them need to be mutated,
 		// therefore their namespace might not have a dynakube assigned
 		// in which case we don't need to do anything
+		webhook.recordDecision(newPodDecision(decisionSkipped, reasonNoDynakubeAssigned, pod, namespace.Name, ""))
 		return nil, nil
 	}
-	dynakube, err := webhook.getDynakube(ctx, dynakubeName)
+	dynakube, err := webhook.routeToDynakube(ctx, *namespace, pod, dynakubeName)
 	if err != nil {
+		reason := reasonInvalidRequest
+		if k8serrors.IsNotFound(err) {
+			reason = reasonDynakubeNotFound
+		}
+		webhook.recordRequestFailure(reason, pod, namespace.Name, dynakubeName, err)
 		return nil, err
+	} else if dynakube == nil {
+		log.Info("no dynakube of the namespace selects the pod", "podName", pod.GenerateName, "namespace", namespace.Name)
+		webhook.recordDecision(newPodDecision(decisionSkipped, reasonNoPodSelectorMatch, pod, namespace.Name, dynakubeName))
+		return nil, nil
 	}
 	mutationRequest := dtwebhook.NewMutationRequest(ctx, *namespace, nil, pod, *dynakube)
 	return mutationRequest, nil
 }
 
+func (webhook *podMutatorWebhook) recordRequestFailure(reason string, pod *corev1.Pod, namespace, dynakube string, err error) {
+	decision := newPodDecision(decisionFailed, reason, pod, namespace, dynakube)
+	decision.err = err
+	webhook.recordDecision(decision)
+}
+
 func getPodFromRequest(req admission.Request, decoder admission.Decoder) (*corev1.Pod, error) {
 	pod := &corev1.Pod{}
 	err := decoder.Decode(req, pod)
@@ -78,3 +97,27 @@ func (webhook *podMutatorWebhook) getDynakube(ctx context.Context, dynakubeName
 	}
 	return &dk, nil
 }
+
+// routeToDynakube selects the dynakube for the pod, from the dynakube of the namespace and the dynakubes the namespace is routed to,
+// see dtwebhook.SelectDynakubeForPod for the conflict resolution
+func (webhook *podMutatorWebhook) routeToDynakube(ctx context.Context, namespace corev1.Namespace, pod *corev1.Pod, dynakubeName string) (*dynatracev1beta1.DynaKube, error) {
+	dynakube, err := webhook.getDynakube(ctx, dynakubeName)
+	if err != nil {
+		return nil, err
+	}
+	routedNames := dtwebhook.RoutedDynakubeNames(namespace)
+	if len(routedNames) == 0 && dynakube.FeatureInjectionPodSelector() == "" {
+		return dynakube, nil
+	}
+
+	candidates := []*dynatracev1beta1.DynaKube{dynakube}
+	for _, routedName := range routedNames {
+		routedDynakube, err := webhook.getDynakube(ctx, routedName)
+		if err != nil {
+			log.Info("skipping dynakube for routing", "dynakube", routedName, "error", err.Error())
+			continue
+		}
+		candidates = append(candidates, routedDynakube)
+	}
+	return dtwebhook.SelectDynakubeForPod(pod, candidates), nil
+}
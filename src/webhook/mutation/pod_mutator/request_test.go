@@ -49,6 +49,46 @@ func TestCreateMutationRequestBase(t *testing.T) {
 	})
 }
 
+func TestRouteToDynakube(t *testing.T) {
+	canaryDynakube := getTestDynakube()
+	canaryDynakube.Name = "canary"
+	canaryDynakube.Annotations = map[string]string{
+		dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary",
+	}
+	namespace := getTestNamespace()
+	namespace.Annotations = map[string]string{
+		dtwebhook.InjectionRoutedInstancesAnnotation: canaryDynakube.Name,
+	}
+	podWebhook := createTestWebhook(t,
+		[]dtwebhook.PodMutator{},
+		[]client.Object{getTestDynakube(), canaryDynakube},
+	)
+
+	t.Run("should keep the dynakube of the namespace", func(t *testing.T) {
+		dynakube, err := podWebhook.routeToDynakube(context.TODO(), *namespace, getTestPod(), testDynakubeName)
+		require.NoError(t, err)
+		require.NotNil(t, dynakube)
+		assert.Equal(t, testDynakubeName, dynakube.Name)
+	})
+	t.Run("should route by the pod selector", func(t *testing.T) {
+		pod := getTestPod()
+		pod.Labels = map[string]string{"track": "canary"}
+
+		dynakube, err := podWebhook.routeToDynakube(context.TODO(), *namespace, pod, testDynakubeName)
+		require.NoError(t, err)
+		require.NotNil(t, dynakube)
+		assert.Equal(t, canaryDynakube.Name, dynakube.Name)
+	})
+	t.Run("should select no dynakube if only pod selectors don't match", func(t *testing.T) {
+		namespace := getTestNamespace()
+		namespace.Labels[dtwebhook.InjectionInstanceLabel] = canaryDynakube.Name
+
+		dynakube, err := podWebhook.routeToDynakube(context.TODO(), *namespace, getTestPod(), canaryDynakube.Name)
+		require.NoError(t, err)
+		assert.Nil(t, dynakube)
+	})
+}
+
 func TestGetPodFromRequest(t *testing.T) {
 	t.Run("should return the pod struct", func(t *testing.T) {
 		podWebhook := createTestWebhook(t,
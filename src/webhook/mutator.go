@@ -26,8 +26,9 @@ type PodMutator interface {
 
 // BaseRequest is the base request for all mutation requests
 type BaseRequest struct {
-	Pod      *corev1.Pod
-	DynaKube dynatracev1beta1.DynaKube
+	Pod       *corev1.Pod
+	Namespace corev1.Namespace
+	DynaKube  dynatracev1beta1.DynaKube
 }
 
 // MutationRequest contains all the information needed to mutate a pod
@@ -36,7 +37,6 @@ type BaseRequest struct {
 type MutationRequest struct {
 	*BaseRequest
 	Context          context.Context
-	Namespace        corev1.Namespace
 	InstallContainer *corev1.Container
 }
 
@@ -47,25 +47,25 @@ type ReinvocationRequest struct {
 	*BaseRequest
 }
 
-func newBaseRequest(pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) *BaseRequest {
+func newBaseRequest(pod *corev1.Pod, namespace corev1.Namespace, dynakube dynatracev1beta1.DynaKube) *BaseRequest {
 	return &BaseRequest{
-		Pod:      pod,
-		DynaKube: dynakube,
+		Pod:       pod,
+		Namespace: namespace,
+		DynaKube:  dynakube,
 	}
 }
 
 func NewMutationRequest(ctx context.Context, namespace corev1.Namespace, installContainer *corev1.Container, pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) *MutationRequest {
 	return &MutationRequest{
-		BaseRequest:      newBaseRequest(pod, dynakube),
+		BaseRequest:      newBaseRequest(pod, namespace, dynakube),
 		Context:          ctx,
-		Namespace:        namespace,
 		InstallContainer: installContainer,
 	}
 }
 
 func NewReinvocationRequest(ctx context.Context, namespace corev1.Namespace, installContainer *corev1.Container, pod *corev1.Pod, dynakube dynatracev1beta1.DynaKube) *ReinvocationRequest {
 	return &ReinvocationRequest{
-		BaseRequest: newBaseRequest(pod, dynakube),
+		BaseRequest: newBaseRequest(pod, namespace, dynakube),
 	}
 }
 
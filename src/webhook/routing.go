@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"sort"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RoutedDynakubeNames returns the dynakubes the pods of the namespace can be routed to besides the one of the InjectionInstanceLabel
+func RoutedDynakubeNames(namespace corev1.Namespace) []string {
+	raw := namespace.Annotations[InjectionRoutedInstancesAnnotation]
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+// IsRoutedDynakube checks if the dynakube only injects into the namespace because of its pod selector
+func IsRoutedDynakube(namespace corev1.Namespace, dynakubeName string) bool {
+	for _, name := range RoutedDynakubeNames(namespace) {
+		if name == dynakubeName {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretNameForDynakube returns the name of a secret the dynakube needs in the namespace,
+// routed dynakubes share the namespace with another dynakube, so their secrets are suffixed with the name of the dynakube
+func SecretNameForDynakube(secretName string, namespace corev1.Namespace, dynakubeName string) string {
+	if IsRoutedDynakube(namespace, dynakubeName) {
+		return secretName + "-" + dynakubeName
+	}
+	return secretName
+}
+
+// SortByInjectionPriority sorts the dynakubes by the order they are considered for the injection of a pod,
+// the highest priority comes first, on the same priority dynakubes with a pod selector come first, as they are more specific,
+// the name is used as last resort to keep the order deterministic
+func SortByInjectionPriority(dynakubes []*dynatracev1beta1.DynaKube) {
+	sort.SliceStable(dynakubes, func(i, j int) bool {
+		first, second := dynakubes[i], dynakubes[j]
+		if first.FeatureInjectionPriority() != second.FeatureInjectionPriority() {
+			return first.FeatureInjectionPriority() > second.FeatureInjectionPriority()
+		}
+		firstHasSelector := first.FeatureInjectionPodSelector() != ""
+		secondHasSelector := second.FeatureInjectionPodSelector() != ""
+		if firstHasSelector != secondHasSelector {
+			return firstHasSelector
+		}
+		return first.Name < second.Name
+	})
+}
+
+// SelectDynakubeForPod returns the first dynakube, ordered by SortByInjectionPriority, whose pod selector matches the pod,
+// dynakubes with an invalid pod selector are skipped, nil is returned if no dynakube matches
+func SelectDynakubeForPod(pod *corev1.Pod, dynakubes []*dynatracev1beta1.DynaKube) *dynatracev1beta1.DynaKube {
+	SortByInjectionPriority(dynakubes)
+	for _, dynakube := range dynakubes {
+		selector, err := dynakube.InjectionPodSelector()
+		if err != nil {
+			continue
+		}
+		if selector == nil || selector.Matches(labels.Set(pod.Labels)) {
+			return dynakube
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createRoutingDynakube(name, podSelector, priority string) *dynatracev1beta1.DynaKube {
+	annotations := map[string]string{}
+	if podSelector != "" {
+		annotations[dynatracev1beta1.AnnotationFeatureInjectionPodSelector] = podSelector
+	}
+	if priority != "" {
+		annotations[dynatracev1beta1.AnnotationFeatureInjectionPriority] = priority
+	}
+	return &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}
+
+func createRoutingPod(podLabels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}}
+}
+
+func TestSelectDynakubeForPod(t *testing.T) {
+	prod := createRoutingDynakube("prod", "", "")
+	canary := createRoutingDynakube("canary", "track=canary", "")
+	partner := createRoutingDynakube("partner", "owner=partner", "10")
+
+	t.Run(`pod without matching selector goes to the default dynakube`, func(t *testing.T) {
+		selected := SelectDynakubeForPod(createRoutingPod(nil), []*dynatracev1beta1.DynaKube{prod, canary, partner})
+
+		require.NotNil(t, selected)
+		assert.Equal(t, "prod", selected.Name)
+	})
+	t.Run(`pod selector wins on the same priority`, func(t *testing.T) {
+		selected := SelectDynakubeForPod(createRoutingPod(map[string]string{"track": "canary"}), []*dynatracev1beta1.DynaKube{prod, canary, partner})
+
+		require.NotNil(t, selected)
+		assert.Equal(t, "canary", selected.Name)
+	})
+	t.Run(`highest priority wins`, func(t *testing.T) {
+		pod := createRoutingPod(map[string]string{"track": "canary", "owner": "partner"})
+
+		selected := SelectDynakubeForPod(pod, []*dynatracev1beta1.DynaKube{prod, canary, partner})
+
+		require.NotNil(t, selected)
+		assert.Equal(t, "partner", selected.Name)
+	})
+	t.Run(`name decides on same priority and specificity`, func(t *testing.T) {
+		otherCanary := createRoutingDynakube("a-canary", "track=canary", "")
+
+		selected := SelectDynakubeForPod(createRoutingPod(map[string]string{"track": "canary"}), []*dynatracev1beta1.DynaKube{canary, otherCanary})
+
+		require.NotNil(t, selected)
+		assert.Equal(t, "a-canary", selected.Name)
+	})
+	t.Run(`no dynakube selects the pod`, func(t *testing.T) {
+		invalid := createRoutingDynakube("invalid", "track in (", "100")
+
+		assert.Nil(t, SelectDynakubeForPod(createRoutingPod(nil), []*dynatracev1beta1.DynaKube{canary, invalid}))
+	})
+}
+
+func TestSecretNameForDynakube(t *testing.T) {
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{InjectionInstanceLabel: "prod"},
+			Annotations: map[string]string{InjectionRoutedInstancesAnnotation: "canary,partner"},
+		},
+	}
+
+	assert.Equal(t, []string{"canary", "partner"}, RoutedDynakubeNames(namespace))
+	assert.Equal(t, "secret", SecretNameForDynakube("secret", namespace, "prod"))
+	assert.Equal(t, "secret-canary", SecretNameForDynakube("secret", namespace, "canary"))
+	assert.Empty(t, RoutedDynakubeNames(corev1.Namespace{}))
+}
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"context"
+
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
 	"github.com/go-logr/logr"
@@ -10,6 +12,11 @@ var log = logger.NewDTLogger().WithName("validation-webhook")
 
 type validator func(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string
 
+// referenceValidator reads the referenced objects with the api reader, so objects created right before the DynaKube are found,
+// which the cache might not have seen yet. It returns the message for missing or incomplete references and the message
+// for references that couldn't be read, only the first one can deny the DynaKube.
+type referenceValidator func(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string)
+
 var validators = []validator{
 	NoApiUrl,
 	IsInvalidApiUrl,
@@ -21,9 +28,12 @@ var validators = []validator{
 	conflictingOneAgentConfiguration,
 	conflictingNodeSelector,
 	conflictingNamespaceSelector,
+	invalidInjectionPodSelector,
+	invalidInjectionPriority,
 	conflictingReadOnlyFilesystemAndMultipleOsAgentsOnNode,
 	noResourcesAvailable,
 	imageFieldSetWithoutCSIFlag,
+	missingOfflineChecksum,
 }
 
 var warnings = []validator{
@@ -38,6 +48,17 @@ var warnings = []validator{
 	deprecatedFeatureFlagDisableReadOnlyAgent,
 	deprecatedFeatureFlagDisableWebhookReinvocationPolicy,
 	deprecatedFeatureFlagDisableMetadataEnrichment,
+	ambiguousInjectionPodSelector,
+}
+
+// missing references are reported as warnings, unless the DynaKube enables the deny-missing-references feature flag,
+// references that couldn't be read are always warnings
+var references = []referenceValidator{
+	missingTokenSecret,
+	missingCustomPullSecret,
+	missingTrustedCAs,
+	missingActiveGateTlsSecret,
+	missingPriorityClass,
 }
 
 func SetLogger(logger logr.Logger) {
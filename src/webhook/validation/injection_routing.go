@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+)
+
+const (
+	errorInvalidInjectionPodSelector = `The DynaKube's feature flag ` + dynatracev1beta1.AnnotationFeatureInjectionPodSelector + ` is not a valid label selector: %s
+`
+	errorInvalidInjectionPriority = `The DynaKube's feature flag ` + dynatracev1beta1.AnnotationFeatureInjectionPriority + ` must be an integer, got: %s
+`
+	warningAmbiguousInjectionPodSelector = `The DynaKube uses the same pod selector and priority as the Dynakube %s, pods of shared namespaces are routed by the name of the Dynakubes.
+Use the ` + dynatracev1beta1.AnnotationFeatureInjectionPriority + ` feature flag to define which Dynakube should inject.
+`
+)
+
+func invalidInjectionPodSelector(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	if _, err := dynakube.InjectionPodSelector(); err != nil {
+		log.Info("requested dynakube has an invalid pod selector", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return fmt.Sprintf(errorInvalidInjectionPodSelector, err.Error())
+	}
+	return ""
+}
+
+func invalidInjectionPriority(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	raw, ok := dynakube.Annotations[dynatracev1beta1.AnnotationFeatureInjectionPriority]
+	if !ok {
+		return ""
+	}
+	if _, err := strconv.Atoi(raw); err != nil {
+		log.Info("requested dynakube has an invalid injection priority", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return fmt.Sprintf(errorInvalidInjectionPriority, raw)
+	}
+	return ""
+}
+
+func ambiguousInjectionPodSelector(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	if !dynakube.NeedAppInjection() || dynakube.FeatureInjectionPodSelector() == "" {
+		return ""
+	}
+	dynakubes := &dynatracev1beta1.DynaKubeList{}
+	if err := dv.clt.List(context.TODO(), dynakubes); err != nil {
+		log.Info("error occurred while listing dynakubes", "err", err.Error())
+		return ""
+	}
+	for _, item := range dynakubes.Items {
+		if item.Name == dynakube.Name || !item.NeedAppInjection() {
+			continue
+		}
+		if item.FeatureInjectionPodSelector() == dynakube.FeatureInjectionPodSelector() &&
+			item.FeatureInjectionPriority() == dynakube.FeatureInjectionPriority() {
+			return fmt.Sprintf(warningAmbiguousInjectionPodSelector, item.Name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createRoutedDynakube(name string, annotations map[string]string) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   testNamespace,
+			Annotations: annotations,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testApiUrl,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+			},
+		},
+	}
+}
+
+func TestInjectionRouting(t *testing.T) {
+	t.Run(`dynakube with pod selector can share namespaces`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t,
+			createRoutedDynakube(testName, map[string]string{
+				dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary",
+				dynatracev1beta1.AnnotationFeatureInjectionPriority:    "10",
+			}),
+			createRoutedDynakube("prod", nil),
+			&dummyNamespace)
+	})
+	t.Run(`invalid pod selector`, func(t *testing.T) {
+		dynakube := createRoutedDynakube(testName, map[string]string{
+			dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track in (",
+		})
+		_, err := dynakube.InjectionPodSelector()
+
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidInjectionPodSelector, err.Error())}, dynakube)
+	})
+	t.Run(`invalid priority`, func(t *testing.T) {
+		assertDeniedResponse(t,
+			[]string{fmt.Sprintf(errorInvalidInjectionPriority, "high")},
+			createRoutedDynakube(testName, map[string]string{
+				dynatracev1beta1.AnnotationFeatureInjectionPriority: "high",
+			}))
+	})
+	t.Run(`same pod selector and priority is ambiguous`, func(t *testing.T) {
+		annotations := map[string]string{
+			dynatracev1beta1.AnnotationFeatureInjectionPodSelector: "track=canary",
+		}
+		assertAllowedResponseWithWarnings(t, 1,
+			createRoutedDynakube(testName, annotations),
+			createRoutedDynakube("other-canary", annotations),
+			&dummyNamespace)
+	})
+}
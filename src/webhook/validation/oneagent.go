@@ -13,6 +13,8 @@ const (
 `
 	errorImageFieldSetWithoutCSIFlag = `The DynaKube's specification tries to enable ApplicationMonitoring mode and get the respective image, but the CSI driver is not enabled.`
 
+	errorMissingOfflineChecksum = `The DynaKube's specification installs the OneAgent from a pre-seeded package, but the expected sha256 checksum of the package is missing. Set it with the ` + dynatracev1beta1.AnnotationFeatureOneAgentOfflineSha256 + ` feature flag.`
+
 	errorNodeSelectorConflict = `The DynaKube's specification tries to specify a nodeSelector conflicts with an another Dynakube's nodeSelector, which is not supported.
 The conflicting Dynakube: %s
 `
@@ -80,6 +82,14 @@ func imageFieldSetWithoutCSIFlag(dv *dynakubeValidator, dynakube *dynatracev1bet
 	return ""
 }
 
+func missingOfflineChecksum(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	if dynakube.FeatureOneAgentOfflineInstall() && dynakube.FeatureOneAgentOfflineSha256() == "" {
+		log.Info("requested dynakube installs the OneAgent offline without a checksum", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return errorMissingOfflineChecksum
+	}
+	return ""
+}
+
 func hasConflictingMatchLabels(labelMap, otherLabelMap map[string]string) bool {
 	if labelMap == nil || otherLabelMap == nil {
 		return true
@@ -288,5 +288,40 @@ func TestImageFieldSetWithoutCSIFlag(t *testing.T) {
 			},
 		}, &defaultCSIDaemonSet)
 	})
+}
 
+func TestMissingOfflineChecksum(t *testing.T) {
+	t.Run(`offline install with checksum`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC:    "oneagent-package",
+					dynatracev1beta1.AnnotationFeatureOneAgentOfflineSha256: "0123456789abcdef",
+				},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+				},
+			},
+		})
+	})
+	t.Run(`offline install without checksum`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorMissingOfflineChecksum}, &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        testName,
+				Namespace:   testNamespace,
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureOneAgentOfflinePVC: "oneagent-package"},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+				},
+			},
+		})
+	})
 }
@@ -0,0 +1,200 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/google/cel-go/cel"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PolicyConfigMapName is the name of the ConfigMap in the namespace of the DynaKubes, which contains the policy rules.
+	// Every key of the ConfigMap is the name of a rule, the value is a rule in yaml, e.g.
+	//   expression: "object.spec.apiUrl.startsWith('https://tenant.live.dynatrace.com')"
+	//   action: deny
+	//   message: "only the production tenant is allowed"
+	// The CEL expression has to evaluate to true for a valid DynaKube, which is available as "object".
+	PolicyConfigMapName = "dynatrace-dynakube-policies"
+
+	policyActionDeny = "deny"
+	policyActionWarn = "warn"
+
+	policyObjectVariable = "object"
+
+	// policyCostLimit and policyEvaluationTimeout bound the evaluation of a rule, as the rules are evaluated on every admission request,
+	// the cost limit is the same as the one of kubernetes for a single CEL validation rule
+	policyCostLimit               = 1000000
+	policyInterruptCheckFrequency = 100
+	policyEvaluationTimeout       = time.Second
+
+	errorPolicyViolated         = `The DynaKube violates the policy rule '%s': %s`
+	errorPolicyEvaluationFailed = `The policy rule '%s' could not be evaluated: %s`
+	warningPolicyInvalid        = `The policy rule '%s' is invalid and was ignored: %s`
+	warningPoliciesNotLoaded    = `The policy rules could not be loaded: %s`
+)
+
+type policyRule struct {
+	Expression string `json:"expression"`
+	Action     string `json:"action,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+type compiledPolicyRule struct {
+	name    string
+	rule    policyRule
+	program cel.Program
+}
+
+type compiledPolicies struct {
+	rules        []compiledPolicyRule
+	invalidRules []string
+}
+
+// policyEngine caches the compiled rules until the ConfigMap changes
+type policyEngine struct {
+	mutex           sync.Mutex
+	resourceVersion string
+	policies        compiledPolicies
+}
+
+func newPolicyEngine() *policyEngine {
+	return &policyEngine{}
+}
+
+func (engine *policyEngine) getPolicies(configMap corev1.ConfigMap) compiledPolicies {
+	if engine == nil {
+		return compilePolicies(configMap)
+	}
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	key := configMap.Namespace + "/" + configMap.ResourceVersion
+	if engine.resourceVersion != key || configMap.ResourceVersion == "" {
+		engine.policies = compilePolicies(configMap)
+		engine.resourceVersion = key
+	}
+	return engine.policies
+}
+
+func compilePolicies(configMap corev1.ConfigMap) compiledPolicies {
+	policies := compiledPolicies{}
+	env, err := cel.NewEnv(cel.Variable(policyObjectVariable, cel.DynType))
+	if err != nil {
+		policies.invalidRules = append(policies.invalidRules, fmt.Sprintf(warningPoliciesNotLoaded, err.Error()))
+		return policies
+	}
+
+	names := make([]string, 0, len(configMap.Data))
+	for name := range configMap.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		compiled, err := compilePolicyRule(env, name, configMap.Data[name])
+		if err != nil {
+			log.Info("invalid policy rule", "rule", name, "error", err.Error())
+			policies.invalidRules = append(policies.invalidRules, fmt.Sprintf(warningPolicyInvalid, name, err.Error()))
+			continue
+		}
+		policies.rules = append(policies.rules, compiled)
+	}
+	return policies
+}
+
+func compilePolicyRule(env *cel.Env, name string, raw string) (compiledPolicyRule, error) {
+	rule := policyRule{}
+	if err := yaml.UnmarshalStrict([]byte(raw), &rule); err != nil {
+		return compiledPolicyRule{}, errors.WithStack(err)
+	}
+	if rule.Action == "" {
+		rule.Action = policyActionDeny
+	}
+	if rule.Action != policyActionDeny && rule.Action != policyActionWarn {
+		return compiledPolicyRule{}, errors.Errorf("unknown action %s, use %s or %s", rule.Action, policyActionDeny, policyActionWarn)
+	}
+	if rule.Expression == "" {
+		return compiledPolicyRule{}, errors.New("expression is missing")
+	}
+
+	ast, issues := env.Compile(rule.Expression)
+	if issues != nil && issues.Err() != nil {
+		return compiledPolicyRule{}, errors.WithStack(issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return compiledPolicyRule{}, errors.Errorf("expression must return a bool, got %s", ast.OutputType())
+	}
+	program, err := env.Program(ast,
+		cel.CostLimit(policyCostLimit),
+		cel.InterruptCheckFrequency(policyInterruptCheckFrequency))
+	if err != nil {
+		return compiledPolicyRule{}, errors.WithStack(err)
+	}
+	return compiledPolicyRule{name: name, rule: rule, program: program}, nil
+}
+
+// runPolicies evaluates the policy rules of the ConfigMap against the dynakube,
+// the messages of violated rules are returned as errors or warnings, depending on the action of the rule
+func (validator *dynakubeValidator) runPolicies(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) ([]string, []string) {
+	var configMap corev1.ConfigMap
+	err := validator.apiReader.Get(ctx, client.ObjectKey{Name: PolicyConfigMapName, Namespace: dynakube.Namespace}, &configMap)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		log.Info("failed to get the policy rules", "error", err.Error())
+		return nil, []string{fmt.Sprintf(warningPoliciesNotLoaded, err.Error())}
+	}
+
+	policies := validator.policies.getPolicies(configMap)
+	policyErrors := []string{}
+	policyWarnings := append([]string{}, policies.invalidRules...)
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dynakube)
+	if err != nil {
+		return nil, append(policyWarnings, fmt.Sprintf(warningPoliciesNotLoaded, err.Error()))
+	}
+
+	for _, compiled := range policies.rules {
+		message := evaluatePolicyRule(ctx, compiled, object)
+		if message == "" {
+			continue
+		}
+		if compiled.rule.Action == policyActionWarn {
+			policyWarnings = append(policyWarnings, message)
+		} else {
+			policyErrors = append(policyErrors, message)
+		}
+	}
+	return policyErrors, policyWarnings
+}
+
+func evaluatePolicyRule(ctx context.Context, compiled compiledPolicyRule, object map[string]interface{}) string {
+	evalCtx, cancel := context.WithTimeout(ctx, policyEvaluationTimeout)
+	defer cancel()
+	result, _, err := compiled.program.ContextEval(evalCtx, map[string]interface{}{policyObjectVariable: object})
+	if err != nil {
+		return fmt.Sprintf(errorPolicyEvaluationFailed, compiled.name, err.Error())
+	}
+	valid, ok := result.Value().(bool)
+	if !ok {
+		return fmt.Sprintf(errorPolicyEvaluationFailed, compiled.name, "expression didn't return a bool")
+	}
+	if valid {
+		return ""
+	}
+	message := compiled.rule.Message
+	if message == "" {
+		message = compiled.rule.Expression
+	}
+	return fmt.Sprintf(errorPolicyViolated, compiled.name, message)
+}
@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createPolicyConfigMap(rules map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: PolicyConfigMapName, Namespace: testNamespace},
+		Data:       rules,
+	}
+}
+
+func createPolicyDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: defaultDynakubeObjectMeta,
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL:        testApiUrl,
+			SkipCertCheck: true,
+		},
+	}
+}
+
+func TestPolicyRules(t *testing.T) {
+	t.Run(`no policy configmap`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, createPolicyDynakube())
+	})
+	t.Run(`deny rule`, func(t *testing.T) {
+		configMap := createPolicyConfigMap(map[string]string{
+			"no-skip-cert-check": "expression: \"!object.spec.skipCertCheck\"\nmessage: skipCertCheck is forbidden",
+		})
+
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorPolicyViolated, "no-skip-cert-check", "skipCertCheck is forbidden")}, createPolicyDynakube(), configMap)
+	})
+	t.Run(`deny rule without message`, func(t *testing.T) {
+		expression := "object.spec.apiUrl.startsWith('https://approved.live.dynatrace.com')"
+		configMap := createPolicyConfigMap(map[string]string{
+			"approved-tenants": fmt.Sprintf("expression: \"%s\"", expression),
+		})
+
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorPolicyViolated, "approved-tenants", expression)}, createPolicyDynakube(), configMap)
+	})
+	t.Run(`warn rule`, func(t *testing.T) {
+		configMap := createPolicyConfigMap(map[string]string{
+			"network-zone": "expression: \"has(object.spec.networkZone)\"\naction: warn\nmessage: networkZone is mandatory",
+		})
+
+		response := assertAllowedResponse(t, createPolicyDynakube(), configMap)
+		assert.Equal(t, []string{fmt.Sprintf(errorPolicyViolated, "network-zone", "networkZone is mandatory")}, response.Warnings)
+	})
+	t.Run(`valid dynakube`, func(t *testing.T) {
+		configMap := createPolicyConfigMap(map[string]string{
+			"replicas": "expression: \"!has(object.spec.activeGate.replicas) || object.spec.activeGate.replicas <= 3\"",
+		})
+
+		assertAllowedResponseWithoutWarnings(t, createPolicyDynakube(), configMap)
+	})
+	t.Run(`evaluation error denies`, func(t *testing.T) {
+		configMap := createPolicyConfigMap(map[string]string{
+			"pull-secret": "expression: \"object.spec.customPullSecret != ''\"",
+		})
+
+		assertDeniedResponse(t, []string{"The policy rule 'pull-secret' could not be evaluated"}, createPolicyDynakube(), configMap)
+	})
+	t.Run(`expensive rule is stopped by the cost limit`, func(t *testing.T) {
+		list := "[" + strings.Repeat("1,", 199) + "1]"
+		configMap := createPolicyConfigMap(map[string]string{
+			"expensive": fmt.Sprintf("expression: \"%s.all(a, %s.all(b, %s.all(c, a == b && b == c)))\"", list, list, list),
+		})
+
+		assertDeniedResponse(t, []string{"The policy rule 'expensive' could not be evaluated", "cost limit exceeded"}, createPolicyDynakube(), configMap)
+	})
+	t.Run(`invalid rules are ignored with a warning`, func(t *testing.T) {
+		configMap := createPolicyConfigMap(map[string]string{
+			"syntax":     "expression: \"object.spec.(\"",
+			"action":     "expression: \"true\"\naction: block",
+			"not-a-bool": "expression: \"'text'\"",
+		})
+
+		response := assertAllowedResponse(t, createPolicyDynakube(), configMap)
+		require.Len(t, response.Warnings, 3)
+		assert.Contains(t, response.Warnings[0], "The policy rule 'action' is invalid")
+		assert.Contains(t, response.Warnings[1], "The policy rule 'not-a-bool' is invalid")
+		assert.Contains(t, response.Warnings[2], "The policy rule 'syntax' is invalid")
+	})
+}
+
+func TestPolicyEngine(t *testing.T) {
+	t.Run(`cache compiled rules by resource version`, func(t *testing.T) {
+		engine := newPolicyEngine()
+		configMap := createPolicyConfigMap(map[string]string{"rule": "expression: \"true\""})
+		configMap.ResourceVersion = "1"
+
+		policies := engine.getPolicies(*configMap)
+		require.Len(t, policies.rules, 1)
+
+		configMap.Data["other"] = "expression: \"true\""
+		assert.Len(t, engine.getPolicies(*configMap).rules, 1)
+
+		configMap.ResourceVersion = "2"
+		assert.Len(t, engine.getPolicies(*configMap).rules, 2)
+	})
+}
@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	errorMissingReferencedSecret    = `The secret %s referenced by the DynaKube's %s doesn't exist in the namespace %s.`
+	errorIncompleteReferencedSecret = `The secret %s referenced by the DynaKube's %s is missing the key(s): %s.`
+	errorMissingReferencedConfigMap = `The configmap %s referenced by the DynaKube's %s doesn't exist in the namespace %s.`
+	errorIncompleteReferencedConfig = `The configmap %s referenced by the DynaKube's %s is missing the key(s): %s.`
+	errorMissingPriorityClass       = `The priority class %s referenced by the DynaKube's %s doesn't exist.`
+
+	activeGateTlsKeystoreKey = "server.p12"
+	activeGateTlsPasswordKey = "password"
+)
+
+// missingTokenSecret checks the secret which holds the tokens, only the api token is mandatory
+func missingTokenSecret(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string) {
+	if dynakube.Tokens() == "" {
+		// the name of a DynaKube created with generateName is not known yet
+		return "", ""
+	}
+	return checkReferencedSecret(ctx, dv, dynakube, dynakube.Tokens(), "tokens", dtclient.DynatraceApiToken)
+}
+
+func missingCustomPullSecret(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string) {
+	if dynakube.Spec.CustomPullSecret == "" {
+		return "", ""
+	}
+	return checkReferencedSecret(ctx, dv, dynakube, dynakube.Spec.CustomPullSecret, "customPullSecret", corev1.DockerConfigJsonKey)
+}
+
+func missingActiveGateTlsSecret(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string) {
+	if !dynakube.HasActiveGateCaCert() {
+		return "", ""
+	}
+	return checkReferencedSecret(ctx, dv, dynakube, dynakube.Spec.ActiveGate.TlsSecretName, "activeGate.tlsSecretName",
+		activeGateTlsKeystoreKey, activeGateTlsPasswordKey, dynatracev1beta1.TlsCertKey)
+}
+
+func missingTrustedCAs(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string) {
+	if dynakube.Spec.TrustedCAs == "" {
+		return "", ""
+	}
+	var configMap corev1.ConfigMap
+	err := dv.apiReader.Get(ctx, client.ObjectKey{Name: dynakube.Spec.TrustedCAs, Namespace: dynakube.Namespace}, &configMap)
+	if k8serrors.IsNotFound(err) {
+		log.Info("requested dynakube references a missing configmap", "name", dynakube.Name, "configmap", dynakube.Spec.TrustedCAs)
+		return fmt.Sprintf(errorMissingReferencedConfigMap, dynakube.Spec.TrustedCAs, "trustedCAs", dynakube.Namespace), ""
+	} else if err != nil {
+		return "", errors.Wrapf(err, "error occurred while reading the configmap %s referenced by the DynaKube", dynakube.Spec.TrustedCAs).Error()
+	}
+	if configMap.Data[dynatracev1beta1.TrustedCAKey] == "" {
+		return fmt.Sprintf(errorIncompleteReferencedConfig, dynakube.Spec.TrustedCAs, "trustedCAs", dynatracev1beta1.TrustedCAKey), ""
+	}
+	return "", ""
+}
+
+func missingPriorityClass(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) (string, string) {
+	var messages, readErrors []string
+	if dynakube.ActiveGateMode() {
+		message, readError := checkPriorityClass(ctx, dv, dynakube.Spec.ActiveGate.PriorityClassName, "activeGate.priorityClassName")
+		messages = appendIfNotEmpty(messages, message)
+		readErrors = appendIfNotEmpty(readErrors, readError)
+	}
+	if hostInjectSpec := getHostInjectSpec(dynakube); hostInjectSpec != nil {
+		message, readError := checkPriorityClass(ctx, dv, hostInjectSpec.PriorityClassName, "oneAgent.priorityClassName")
+		messages = appendIfNotEmpty(messages, message)
+		readErrors = appendIfNotEmpty(readErrors, readError)
+	}
+	return strings.Join(messages, "\n"), strings.Join(readErrors, "\n")
+}
+
+func checkReferencedSecret(ctx context.Context, dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube, name string, field string, requiredKeys ...string) (string, string) {
+	var secret corev1.Secret
+	err := dv.apiReader.Get(ctx, client.ObjectKey{Name: name, Namespace: dynakube.Namespace}, &secret)
+	if k8serrors.IsNotFound(err) {
+		log.Info("requested dynakube references a missing secret", "name", dynakube.Name, "secret", name)
+		return fmt.Sprintf(errorMissingReferencedSecret, name, field, dynakube.Namespace), ""
+	} else if err != nil {
+		return "", errors.Wrapf(err, "error occurred while reading the secret %s referenced by the DynaKube", name).Error()
+	}
+
+	var missingKeys []string
+	for _, key := range requiredKeys {
+		if len(secret.Data[key]) == 0 {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		return fmt.Sprintf(errorIncompleteReferencedSecret, name, field, strings.Join(missingKeys, ", ")), ""
+	}
+	return "", ""
+}
+
+func checkPriorityClass(ctx context.Context, dv *dynakubeValidator, name string, field string) (string, string) {
+	if name == "" {
+		return "", ""
+	}
+	var priorityClass schedulingv1.PriorityClass
+	err := dv.apiReader.Get(ctx, client.ObjectKey{Name: name}, &priorityClass)
+	if k8serrors.IsNotFound(err) {
+		return fmt.Sprintf(errorMissingPriorityClass, name, field), ""
+	} else if err != nil {
+		return "", errors.Wrapf(err, "error occurred while reading the priority class %s referenced by the DynaKube", name).Error()
+	}
+	return "", ""
+}
+
+func getHostInjectSpec(dynakube *dynatracev1beta1.DynaKube) *dynatracev1beta1.HostInjectSpec {
+	switch {
+	case dynakube.ClassicFullStackMode():
+		return dynakube.Spec.OneAgent.ClassicFullStack
+	case dynakube.HostMonitoringMode():
+		return dynakube.Spec.OneAgent.HostMonitoring
+	case dynakube.CloudNativeFullstackMode():
+		return &dynakube.Spec.OneAgent.CloudNativeFullStack.HostInjectSpec
+	}
+	return nil
+}
+
+func appendIfNotEmpty(messages []string, message string) []string {
+	if message == "" {
+		return messages
+	}
+	return append(messages, message)
+}
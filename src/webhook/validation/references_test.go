@@ -0,0 +1,202 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	testTokenSecretName   = "test-tokens"
+	testPullSecretName    = "test-pull-secret"
+	testTrustedCAsName    = "test-trusted-cas"
+	testTlsSecretName     = "test-tls-secret"
+	testPriorityClassName = "test-priority-class"
+)
+
+func TestMissingReferences(t *testing.T) {
+	t.Run(`all references exist`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, createReferencingDynakube(),
+			createTestSecret(testTokenSecretName, dtclient.DynatraceApiToken),
+			createTestSecret(testPullSecretName, corev1.DockerConfigJsonKey),
+			createTestSecret(testTlsSecretName, activeGateTlsKeystoreKey, activeGateTlsPasswordKey, dynatracev1beta1.TlsCertKey),
+			createTestConfigMap(testTrustedCAsName, dynatracev1beta1.TrustedCAKey),
+			createTestPriorityClass(testPriorityClassName))
+	})
+	t.Run(`missing references are warnings`, func(t *testing.T) {
+		response := assertAllowedResponse(t, createReferencingDynakube(),
+			createTestSecret(testTokenSecretName, dtclient.DynatraceApiToken))
+
+		assert.ElementsMatch(t, []string{
+			fmt.Sprintf(errorMissingReferencedSecret, testPullSecretName, "customPullSecret", testNamespace),
+			fmt.Sprintf(errorMissingReferencedConfigMap, testTrustedCAsName, "trustedCAs", testNamespace),
+			fmt.Sprintf(errorMissingReferencedSecret, testTlsSecretName, "activeGate.tlsSecretName", testNamespace),
+			fmt.Sprintf(errorMissingPriorityClass, testPriorityClassName, "activeGate.priorityClassName") + "\n" +
+				fmt.Sprintf(errorMissingPriorityClass, testPriorityClassName, "oneAgent.priorityClassName"),
+		}, response.Warnings)
+	})
+	t.Run(`incomplete references are warnings`, func(t *testing.T) {
+		response := assertAllowedResponse(t, createReferencingDynakube(),
+			createTestSecret(testTokenSecretName, dtclient.DynatracePaasToken),
+			createTestSecret(testPullSecretName, corev1.DockerConfigJsonKey),
+			createTestSecret(testTlsSecretName, activeGateTlsKeystoreKey),
+			createTestConfigMap(testTrustedCAsName, "other"),
+			createTestPriorityClass(testPriorityClassName))
+
+		assert.ElementsMatch(t, []string{
+			fmt.Sprintf(errorIncompleteReferencedSecret, testTokenSecretName, "tokens", dtclient.DynatraceApiToken),
+			fmt.Sprintf(errorIncompleteReferencedConfig, testTrustedCAsName, "trustedCAs", dynatracev1beta1.TrustedCAKey),
+			fmt.Sprintf(errorIncompleteReferencedSecret, testTlsSecretName, "activeGate.tlsSecretName",
+				activeGateTlsPasswordKey+", "+dynatracev1beta1.TlsCertKey),
+		}, response.Warnings)
+	})
+	t.Run(`missing references are denied with feature flag`, func(t *testing.T) {
+		dynakube := createReferencingDynakube()
+		dynakube.Annotations = map[string]string{
+			dynatracev1beta1.AnnotationFeatureDenyMissingReferences: "true",
+		}
+		dynakube.Spec.CustomPullSecret = ""
+		dynakube.Spec.TrustedCAs = ""
+		dynakube.Spec.ActiveGate = dynatracev1beta1.ActiveGateSpec{}
+		dynakube.Spec.OneAgent.ClassicFullStack.PriorityClassName = ""
+
+		assertDeniedResponse(t, []string{
+			fmt.Sprintf(errorIncompleteReferencedSecret, testTokenSecretName, "tokens", dtclient.DynatraceApiToken),
+		}, dynakube, createTestSecret(testTokenSecretName))
+	})
+	t.Run(`read errors are warnings with feature flag`, func(t *testing.T) {
+		dynakube := createReferencingDynakube()
+		dynakube.Annotations = map[string]string{
+			dynatracev1beta1.AnnotationFeatureDenyMissingReferences: "true",
+		}
+		data, err := json.Marshal(dynakube)
+		require.NoError(t, err)
+		validator := &dynakubeValidator{
+			clt:       fake.NewClient(),
+			apiReader: failingReader{Reader: fake.NewClient()},
+			cfg:       &rest.Config{},
+		}
+
+		response := validator.Handle(context.TODO(), admission.Request{
+			AdmissionRequest: v1.AdmissionRequest{
+				Name:      testName,
+				Namespace: testNamespace,
+				Object:    runtime.RawExtension{Raw: data},
+			},
+		})
+
+		assert.True(t, response.Allowed)
+		warnings := strings.Join(response.Warnings, "\n")
+		for _, name := range []string{testTokenSecretName, testPullSecretName, testTrustedCAsName, testTlsSecretName, testPriorityClassName} {
+			assert.Contains(t, warnings, fmt.Sprintf(`"%s" is forbidden`, name))
+		}
+	})
+	t.Run(`references are read with the api reader`, func(t *testing.T) {
+		dynakube := createReferencingDynakube()
+		data, err := json.Marshal(dynakube)
+		require.NoError(t, err)
+		validator := &dynakubeValidator{
+			clt: fake.NewClient(),
+			apiReader: fake.NewClient(
+				createTestSecret(testTokenSecretName, dtclient.DynatraceApiToken),
+				createTestSecret(testPullSecretName, corev1.DockerConfigJsonKey),
+				createTestSecret(testTlsSecretName, activeGateTlsKeystoreKey, activeGateTlsPasswordKey, dynatracev1beta1.TlsCertKey),
+				createTestConfigMap(testTrustedCAsName, dynatracev1beta1.TrustedCAKey),
+				createTestPriorityClass(testPriorityClassName)),
+			cfg: &rest.Config{},
+		}
+
+		response := validator.Handle(context.TODO(), admission.Request{
+			AdmissionRequest: v1.AdmissionRequest{
+				Name:      testName,
+				Namespace: testNamespace,
+				Object:    runtime.RawExtension{Raw: data},
+			},
+		})
+
+		assert.True(t, response.Allowed)
+		assert.Empty(t, response.Warnings)
+	})
+}
+
+type failingReader struct {
+	client.Reader
+}
+
+func (reader failingReader) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return k8serrors.NewForbidden(corev1.Resource("secrets"), key.Name, errors.New("forbidden"))
+}
+
+func createReferencingDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: defaultDynakubeObjectMeta,
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL:           testApiUrl,
+			Tokens:           testTokenSecretName,
+			CustomPullSecret: testPullSecretName,
+			TrustedCAs:       testTrustedCAsName,
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities:      []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.RoutingCapability.DisplayName},
+				TlsSecretName:     testTlsSecretName,
+				PriorityClassName: testPriorityClassName,
+				CapabilityProperties: dynatracev1beta1.CapabilityProperties{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+				},
+			},
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					PriorityClassName: testPriorityClassName,
+				},
+			},
+		},
+	}
+}
+
+func createTestSecret(name string, keys ...string) *corev1.Secret {
+	data := map[string][]byte{}
+	for _, key := range keys {
+		data[key] = []byte("value")
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func createTestConfigMap(name string, keys ...string) *corev1.ConfigMap {
+	data := map[string]string{}
+	for _, key := range keys {
+		data[key] = "value"
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func createTestPriorityClass(name string) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+}
@@ -20,12 +20,14 @@ type dynakubeValidator struct {
 	clt       client.Client
 	apiReader client.Reader
 	cfg       *rest.Config
+	policies  *policyEngine
 }
 
 func newDynakubeValidator(apiReader client.Reader, cfg *rest.Config) admission.Handler {
 	return &dynakubeValidator{
 		apiReader: apiReader,
 		cfg:       cfg,
+		policies:  newPolicyEngine(),
 	}
 }
 
@@ -42,7 +44,7 @@ func (validator *dynakubeValidator) InjectClient(clt client.Client) error {
 	return nil
 }
 
-func (validator *dynakubeValidator) Handle(_ context.Context, request admission.Request) admission.Response {
+func (validator *dynakubeValidator) Handle(ctx context.Context, request admission.Request) admission.Response {
 	log.Info("validating request", "name", request.Name, "namespace", request.Namespace)
 
 	dynakube := &dynatracev1beta1.DynaKube{}
@@ -51,11 +53,22 @@ func (validator *dynakubeValidator) Handle(_ context.Context, request admission.
 		return admission.Errored(http.StatusInternalServerError, errors.WithStack(err))
 	}
 	validationErrors := validator.runValidators(validators, dynakube)
+	policyErrors, policyWarnings := validator.runPolicies(ctx, dynakube)
+	validationErrors = append(validationErrors, policyErrors...)
+	referenceMessages, referenceReadErrors := validator.runReferenceValidators(ctx, references, dynakube)
+	if dynakube.FeatureDenyMissingReferences() {
+		validationErrors = append(validationErrors, referenceMessages...)
+	}
 	response := admission.Allowed("")
 	if len(validationErrors) > 0 {
 		response = admission.Denied(sumErrors(validationErrors))
 	}
 	warningMessages := validator.runValidators(warnings, dynakube)
+	warningMessages = append(warningMessages, policyWarnings...)
+	if !dynakube.FeatureDenyMissingReferences() {
+		warningMessages = append(warningMessages, referenceMessages...)
+	}
+	warningMessages = append(warningMessages, referenceReadErrors...)
 	if len(warningMessages) > 0 {
 		if hasPreviewWarning(warningMessages) {
 			warningMessages = append(warningMessages, basePreviewWarning)
@@ -75,6 +88,21 @@ func (validator *dynakubeValidator) runValidators(validators []validator, dynaku
 	return results
 }
 
+func (validator *dynakubeValidator) runReferenceValidators(ctx context.Context, validators []referenceValidator, dynakube *dynatracev1beta1.DynaKube) ([]string, []string) {
+	results := []string{}
+	readErrors := []string{}
+	for _, validate := range validators {
+		errMsg, readError := validate(ctx, validator, dynakube)
+		if errMsg != "" {
+			results = append(results, errMsg)
+		}
+		if readError != "" {
+			readErrors = append(readErrors, readError)
+		}
+	}
+	return results, readErrors
+}
+
 func sumErrors(validationErrors []string) string {
 	summedErrors := fmt.Sprintf("\n%d error(s) found in the Dynakube", len(validationErrors))
 	for i, errMsg := range validationErrors {
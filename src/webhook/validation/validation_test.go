@@ -8,6 +8,7 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -207,10 +208,7 @@ func assertAllowedResponse(t *testing.T, dynakube *dynatracev1beta1.DynaKube, ot
 }
 
 func handleRequest(t *testing.T, dynakube *dynatracev1beta1.DynaKube, other ...client.Object) admission.Response {
-	clt := fake.NewClient()
-	if other != nil {
-		clt = fake.NewClient(other...)
-	}
+	clt := fake.NewClient(withTokenSecret(dynakube, other)...)
 	validator := &dynakubeValidator{
 		clt:       clt,
 		apiReader: clt,
@@ -238,3 +236,21 @@ func TestDynakubeValidator_InjectClient(t *testing.T) {
 	assert.NotNil(t, validator.clt)
 	assert.Equal(t, clt, validator.clt)
 }
+
+// withTokenSecret adds a valid token secret for the dynakube, unless the test provides its own
+func withTokenSecret(dynakube *dynatracev1beta1.DynaKube, other []client.Object) []client.Object {
+	tokenSecretName := dynakube.Tokens()
+	if tokenSecretName == "" {
+		return other
+	}
+	for _, obj := range other {
+		if _, isSecret := obj.(*corev1.Secret); isSecret && obj.GetName() == tokenSecretName {
+			return other
+		}
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: tokenSecretName, Namespace: dynakube.Namespace},
+		Data:       map[string][]byte{dtclient.DynatraceApiToken: []byte("test-token")},
+	}
+	return append([]client.Object{tokenSecret}, other...)
+}